@@ -21,18 +21,35 @@ package main
 // @name Authorization Type "Bearer" followed by a space and JWT token.
 import (
 	"ans-spareparts-api/config"
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/features/audit"
 	"ans-spareparts-api/internal/features/auth"
 	"ans-spareparts-api/internal/features/category"
+	"ans-spareparts-api/internal/features/document"
 	"ans-spareparts-api/internal/features/inventory"
+	"ans-spareparts-api/internal/features/payment"
 	"ans-spareparts-api/internal/features/product"
+	"ans-spareparts-api/internal/features/quotation"
+	"ans-spareparts-api/internal/features/report"
+	"ans-spareparts-api/internal/features/shift"
+	"ans-spareparts-api/internal/features/stock"
+	"ans-spareparts-api/internal/features/stream"
+	"ans-spareparts-api/internal/features/trash"
 	"ans-spareparts-api/internal/features/user"
+	"ans-spareparts-api/internal/features/webhook"
 	"ans-spareparts-api/internal/infra/database"
 	"ans-spareparts-api/internal/infra/hash"
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/internal/infra/idempotency"
 	"ans-spareparts-api/internal/infra/jwtx"
 	"ans-spareparts-api/internal/infra/logger"
+	"ans-spareparts-api/internal/infra/loginguard"
+	"ans-spareparts-api/internal/infra/outbox"
+	"ans-spareparts-api/internal/infra/ratelimit"
 	"ans-spareparts-api/internal/infra/redisx"
 	"ans-spareparts-api/internal/middleware"
 	"ans-spareparts-api/internal/router"
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -90,7 +107,7 @@ func main() {
 		Secret:   cfg.JWT.Secret,
 		Issuer:   cfg.App.Name,
 		Audience: "",                     // ถ้าไม่มี requirement ก็เว้นว่าง
-		Expiry:   cfg.JWT.AccessTokenTTL, //24 h
+		Expiry:   cfg.JWT.AccessTokenTTL, // 15m ต่ออายุด้วย refresh token
 		Leeway:   30 * time.Second,       // กัน clock skew
 	}, rdb)
 
@@ -99,16 +116,75 @@ func main() {
 
 	// Initialize repositories
 	userRepo := user.NewRepository(db, rdb, 5*time.Minute)
-	productRepo := product.NewRepository(db, rdb, 30*time.Minute)
+	productRepo := product.NewRepository(db, rdb, 30*time.Minute, product.NewSearcher(cfg.Search.Backend))
 	categoryRepo := category.NewRepository(db, rdb, 24*time.Hour)
 	inventoryRepo := inventory.NewRepository(db, rdb, 10*time.Hour)
+	documentRepo := document.NewRepository(db)
+	quotationRepo := quotation.NewRepository(db)
+	shiftRepo := shift.NewRepository(db)
+	stockRepo := stock.NewRepository(db, rdb)
+	webhookRepo := webhook.NewRepository(db)
+	streamRepo := stream.NewRepository(db)
+	reportRepo := report.NewRepository(db, rdb, 5*time.Minute)
+	auditRepo := audit.NewRepository(db)
+	refreshTokenRepo := auth.NewRefreshTokenRepository(db)
 
 	// Initialze usecases
-	authUseCase := auth.NewService(userRepo, tokenManager, hasher, "cashier")
-	userUseCase := user.NewService(userRepo)
-	productUseCase := product.NewService(productRepo, categoryRepo, inventoryRepo)
-	categoryUseCase := category.NewService(categoryRepo)
-	inventoryUseCase := inventory.NewService(inventoryRepo)
+	// audit ต้องสร้างก่อน เพราะ service ที่แก้ไขข้อมูลใช้บันทึกประวัติ
+	auditUseCase := audit.NewService(auditRepo)
+	loginGuard := loginguard.NewRedisGuard(rdb, loginguard.Options{
+		MaxUserFailures: cfg.Login.MaxUserFailures,
+		MaxIPFailures:   cfg.Login.MaxIPFailures,
+		Window:          cfg.Login.FailureWindow,
+		Lockout:         cfg.Login.Lockout,
+		DelayStep:       cfg.Login.DelayStep,
+		MaxDelay:        cfg.Login.MaxDelay,
+	})
+	authUseCase := auth.NewService(userRepo, tokenManager, hasher, "cashier", auditUseCase, loginGuard,
+		refreshTokenRepo, cfg.JWT.RefreshTokenTTL)
+	userUseCase := user.NewService(userRepo, auditUseCase)
+	productUseCase := product.NewService(productRepo, categoryRepo, inventoryRepo, auditUseCase)
+	categoryUseCase := category.NewService(categoryRepo, auditUseCase)
+	inventoryUseCase := inventory.NewService(inventoryRepo, stockRepo, auditUseCase)
+	documentUseCase := document.NewService(documentRepo)
+	quotationUseCase := quotation.NewService(quotationRepo, productRepo, documentUseCase, cfg.Quotation.Validity)
+	shiftUseCase := shift.NewService(shiftRepo)
+	paymentUseCase := payment.NewService(cfg.Payment.PromptPayID)
+	stockUseCase := stock.NewService(stockRepo)
+	reportUseCase := report.NewService(reportRepo)
+	webhookUseCase := webhook.NewService(webhookRepo, webhook.Options{
+		MaxAttempts: cfg.Webhook.MaxAttempts,
+		Timeout:     cfg.Webhook.Timeout,
+		BatchSize:   cfg.Webhook.BatchSize,
+	})
+	streamHub := stream.NewHub(rdb, cfg.Stream.Channel)
+	streamUseCase := stream.NewService(streamRepo, streamHub, cfg.Stream.ReplayLimit)
+
+	// background jobs: หยุดพร้อมกันตอน shutdown
+	bgCtx, stopBackground := context.WithCancel(ctxlog.With(context.Background(), rootLogger))
+	defer stopBackground()
+	go quotation.RunExpirySweeper(bgCtx, quotationUseCase, cfg.Quotation.SweepInterval)
+	// ลบสินค้าก่อนหมวดหมู่ เพื่อให้หมวดที่ว่างแล้วถูกลบได้ในรอบเดียวกัน
+	go trash.RunPurger(bgCtx, cfg.Trash.Retention, cfg.Trash.PurgeInterval,
+		productUseCase, categoryUseCase, userUseCase)
+
+	// outbox relay: ส่ง domain event ที่ commit แล้วไปยัง sink ต่างๆ
+	eventBus := outbox.NewDispatcher()
+	eventBus.Subscribe(domain.EventInventoryQuantityUpdated, stream.NewPublisher(rdb, cfg.Stream.Channel))
+	sinks := []outbox.Sink{eventBus, webhook.NewSink(webhookRepo)}
+	if cfg.Outbox.RedisStream != "" {
+		sinks = append(sinks, outbox.NewRedisStream(rdb, cfg.Outbox.RedisStream, cfg.Outbox.RedisStreamMax))
+	}
+	for _, u := range cfg.Outbox.WebhookURLs {
+		sinks = append(sinks, outbox.NewWebhook(u, nil))
+	}
+	relay := outbox.NewRelay(db, outbox.Options{
+		BatchSize:   cfg.Outbox.BatchSize,
+		MaxAttempts: cfg.Outbox.MaxAttempts,
+	}, sinks...)
+	go relay.Run(bgCtx, cfg.Outbox.RelayInterval)
+	go streamHub.Run(bgCtx)
+	go webhook.RunDeliverer(bgCtx, webhookUseCase, cfg.Webhook.BatchSize, cfg.Webhook.DeliverInterval)
 
 	// Create fiber app
 	app := fiber.New(fiber.Config{
@@ -160,7 +236,24 @@ func main() {
 		ProductUC:    productUseCase,
 		CategoryUC:   categoryUseCase,
 		InventoryUC:  inventoryUseCase,
+		QuotationUC:  quotationUseCase,
+		ShiftUC:      shiftUseCase,
+		PaymentUC:    paymentUseCase,
+		StockUC:      stockUseCase,
+		ReportUC:     reportUseCase,
+		AuditUC:      auditUseCase,
+		WebhookUC:    webhookUseCase,
+		StreamUC:     streamUseCase,
 		TokenManager: tokenManager,
+
+		StreamHeartbeat:    cfg.Stream.Heartbeat,
+		IdempotencyStore:   idempotency.NewRedisStore(rdb),
+		IdempotencyTTL:     cfg.Idempotency.TTL,
+		IdempotencyLockTTL: cfg.Idempotency.LockTTL,
+
+		RateLimiter:   ratelimit.NewRedisLimiter(rdb),
+		RateLimit:     middleware.RateLimitRule{Name: "api", Limit: cfg.RateLimit.Limit, Window: cfg.RateLimit.Window},
+		AuthRateLimit: middleware.RateLimitRule{Name: "auth", Limit: cfg.RateLimit.AuthLimit, Window: cfg.RateLimit.AuthWindow},
 	})
 
 	// --- Start Server (Graceful Shutdown Pattern)---
@@ -183,6 +276,9 @@ func main() {
 
 	rootLogger.Info("shutting down server...")
 
+	// หยุด background jobs
+	stopBackground()
+
 	// ปิด fiber
 	if err := app.Shutdown(); err != nil {
 		rootLogger.Error("fiber shutdown error", zap.Error(err))
@@ -18,6 +18,17 @@ type Config struct {
 	JWT   JWTConfig
 	Log   LogConfig
 	GORM  GormConfig
+
+	Quotation   QuotationConfig
+	Payment     PaymentConfig
+	Search      SearchConfig
+	Trash       TrashConfig
+	Outbox      OutboxConfig
+	Webhook     WebhookConfig
+	Stream      StreamConfig
+	Idempotency IdempotencyConfig
+	RateLimit   RateLimitConfig
+	Login       LoginConfig
 }
 
 type AppConfig struct {
@@ -57,8 +68,10 @@ type RedisConfig struct {
 
 type JWTConfig struct {
 	Secret         string        `env:"JWT_SECRET" envDefault:"supersecret"`
-	AccessTokenTTL time.Duration `env:"JWT_TTL" envDefault:"24h"`
-	BlacklistTTL   time.Duration `env:"JWT_BLACKLIST_TTL" envDefault:"24h"`
+	AccessTokenTTL time.Duration `env:"JWT_TTL" envDefault:"15m"` // สั้นๆ ต่ออายุด้วย refresh token
+	// RefreshTokenTTL อายุของ refresh token นับจากการ rotate ครั้งล่าสุด (ไม่ได้ใช้นานเกินนี้ต้อง login ใหม่)
+	RefreshTokenTTL time.Duration `env:"JWT_REFRESH_TTL" envDefault:"168h"`
+	BlacklistTTL    time.Duration `env:"JWT_BLACKLIST_TTL" envDefault:"24h"`
 }
 
 type LogConfig struct {
@@ -80,6 +93,80 @@ type GormConfig struct {
 	NamingSingularTable   bool          `env:"GORM_NAMING_SINGULAR" envDefault:"false"`
 }
 
+type QuotationConfig struct {
+	Validity      time.Duration `env:"QUOTATION_VALIDITY" envDefault:"720h"` // 30 วัน
+	SweepInterval time.Duration `env:"QUOTATION_SWEEP_INTERVAL" envDefault:"15m"`
+}
+
+type PaymentConfig struct {
+	// PromptPayID เบอร์มือถือ / เลขผู้เสียภาษี 13 หลัก / e-Wallet ID ของร้านที่ผูก PromptPay
+	PromptPayID string `env:"PROMPTPAY_ID" envDefault:""`
+}
+
+type SearchConfig struct {
+	// Backend ค้นหาสินค้า: postgres (pg_trgm + tsvector) | like
+	Backend string `env:"SEARCH_BACKEND" envDefault:"postgres"`
+}
+
+type TrashConfig struct {
+	// Retention ระยะเวลาที่เก็บข้อมูลใน trash ก่อนลบถาวร
+	Retention     time.Duration `env:"TRASH_RETENTION" envDefault:"720h"` // 30 วัน
+	PurgeInterval time.Duration `env:"TRASH_PURGE_INTERVAL" envDefault:"24h"`
+}
+
+type OutboxConfig struct {
+	RelayInterval time.Duration `env:"OUTBOX_RELAY_INTERVAL" envDefault:"2s"`
+	BatchSize     int           `env:"OUTBOX_BATCH_SIZE" envDefault:"100"`
+	MaxAttempts   int           `env:"OUTBOX_MAX_ATTEMPTS" envDefault:"10"`
+	// RedisStream ชื่อ stream ที่จะ XADD event ลงไป ว่าง = ไม่ส่งเข้า redis
+	RedisStream    string `env:"OUTBOX_REDIS_STREAM" envDefault:""`
+	RedisStreamMax int64  `env:"OUTBOX_REDIS_STREAM_MAXLEN" envDefault:"100000"`
+	// WebhookURLs ปลายทางที่จะ POST event ไปให้ คั่นด้วย ,
+	WebhookURLs []string `env:"OUTBOX_WEBHOOK_URLS" envSeparator:","`
+}
+
+// WebhookConfig การส่ง webhook ไปยัง subscription ที่ตั้งผ่าน API
+type WebhookConfig struct {
+	DeliverInterval time.Duration `env:"WEBHOOK_DELIVER_INTERVAL" envDefault:"5s"`
+	BatchSize       int           `env:"WEBHOOK_BATCH_SIZE" envDefault:"50"`
+	MaxAttempts     int           `env:"WEBHOOK_MAX_ATTEMPTS" envDefault:"8"` // ครบแล้วเป็น dead-letter
+	Timeout         time.Duration `env:"WEBHOOK_TIMEOUT" envDefault:"10s"`
+}
+
+// StreamConfig SSE แจ้งยอดสต็อกแบบสด (กระจายข้าม instance ผ่าน redis pub/sub)
+type StreamConfig struct {
+	Channel     string        `env:"STREAM_REDIS_CHANNEL" envDefault:"stream:inventory"`
+	Heartbeat   time.Duration `env:"STREAM_HEARTBEAT" envDefault:"15s"`
+	ReplayLimit int           `env:"STREAM_REPLAY_LIMIT" envDefault:"1000"` // พลาดเกินนี้ให้ client โหลดใหม่
+}
+
+// IdempotencyConfig การจำ response ของ request ที่ส่ง Idempotency-Key
+type IdempotencyConfig struct {
+	TTL time.Duration `env:"IDEMPOTENCY_TTL" envDefault:"24h"` // เก็บ response ไว้ตอบซ้ำนานเท่านี้
+	// LockTTL เวลาจองคีย์ระหว่างที่ request แรกยังทำอยู่ (ต่ออายุทุกครึ่งหนึ่งจน handler จบ)
+	// คือเวลาที่คีย์ค้างอยู่ได้ถ้า instance ตายกลางทาง
+	LockTTL time.Duration `env:"IDEMPOTENCY_LOCK_TTL" envDefault:"1m"`
+}
+
+// RateLimitConfig โควต้า request ต่อผู้ใช้/IP (นับร่วมกันทุก instance ผ่าน redis) ค่า 0 = ปิด
+type RateLimitConfig struct {
+	Limit  int           `env:"RATE_LIMIT" envDefault:"300"`
+	Window time.Duration `env:"RATE_LIMIT_WINDOW" envDefault:"1m"`
+	// Auth ใช้กับ /auth/login และ /auth/register นับต่อ IP เข้มกว่าเพื่อกัน brute force
+	AuthLimit  int           `env:"RATE_LIMIT_AUTH" envDefault:"10"`
+	AuthWindow time.Duration `env:"RATE_LIMIT_AUTH_WINDOW" envDefault:"1m"`
+}
+
+// LoginConfig การป้องกันเดารหัสผ่าน: หน่วงเวลาเพิ่มขึ้นทุกครั้งที่ผิด และล็อกชั่วคราวเมื่อครบเกณฑ์
+type LoginConfig struct {
+	MaxUserFailures int           `env:"LOGIN_MAX_USER_FAILURES" envDefault:"5"` // ต่อ username
+	MaxIPFailures   int           `env:"LOGIN_MAX_IP_FAILURES" envDefault:"20"`  // ต่อ IP ทุก username รวมกัน
+	FailureWindow   time.Duration `env:"LOGIN_FAILURE_WINDOW" envDefault:"15m"`
+	Lockout         time.Duration `env:"LOGIN_LOCKOUT" envDefault:"15m"`
+	DelayStep       time.Duration `env:"LOGIN_DELAY_STEP" envDefault:"250ms"`
+	MaxDelay        time.Duration `env:"LOGIN_MAX_DELAY" envDefault:"4s"`
+}
+
 // Load เรียกใช้ใน Main.go: ถ้าผิดพลาดให้ Panic
 func Load() *Config {
 	if err := godotenv.Load(); err != nil {
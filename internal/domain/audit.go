@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// การกระทำที่บันทึกใน audit log
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditRestore = "restore"
+	AuditImport  = "import"
+	AuditUnlock  = "unlock"
+)
+
+// AuditLog หนึ่งการแก้ไขข้อมูล: ใคร (actor) ทำอะไร (action) กับ record ไหน และ field ใดเปลี่ยนจากอะไรเป็นอะไร
+type AuditLog struct {
+	ID            uint            `json:"id" gorm:"primaryKey"`
+	ActorID       *uint           `json:"actor_id"` // nil = ระบบ / ผู้ใช้ที่ยังไม่ login (เช่น register)
+	ActorUsername string          `json:"actor_username"`
+	ActorRole     string          `json:"actor_role"`
+	RequestID     string          `json:"request_id"`
+	EntityType    string          `json:"entity_type" gorm:"size:30;not null"`
+	EntityID      uint            `json:"entity_id" gorm:"not null"`
+	Action        string          `json:"action" gorm:"size:20;not null"`
+	Changes       json.RawMessage `json:"changes" gorm:"type:jsonb;not null"` // {"field": {"old": .., "new": ..}}
+	CreatedAt     time.Time       `json:"created_at"`
+}
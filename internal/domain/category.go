@@ -7,9 +7,11 @@ import (
 )
 
 type Category struct {
-	ID       uint           `json:"id" gorm:"primaryKey"`
-	Name     string         `json:"name" gorm:"unique;not null" validate:"required,min-2,max-50"`
-	CreateAt time.Time      `json:"create_at"`
-	UpdateAt time.Time      `json:"update_at"`
-	DeleteAt gorm.DeletedAt `json:"-" gorm:"index"`
+	ID      uint   `json:"id" gorm:"primaryKey"`
+	Name    string `json:"name" gorm:"unique;not null" validate:"required,min-2,max-50"`
+	Version uint   `json:"version" gorm:"not null;default:1"`
+	// ชื่อ field ต้องตรงกับคอลัมน์ created_at/updated_at/deleted_at ไม่งั้น soft delete จะไม่ทำงาน
+	CreatedAt time.Time      `json:"create_at"`
+	UpdatedAt time.Time      `json:"update_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// DocumentSequence เก็บเลขที่เอกสารล่าสุด แยกตาม ประเภทเอกสาร + สาขา + ปี
+// (ใบกำกับภาษีต้องมีเลขที่เรียงต่อเนื่องภายในแต่ละสาขาและแต่ละปี)
+type DocumentSequence struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	DocType   string    `json:"doc_type" gorm:"not null;uniqueIndex:idx_document_sequences_key"`
+	Branch    string    `json:"branch" gorm:"not null;uniqueIndex:idx_document_sequences_key"`
+	Year      int       `json:"year" gorm:"not null;uniqueIndex:idx_document_sequences_key"`
+	LastNo    int64     `json:"last_no" gorm:"not null;default:0"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
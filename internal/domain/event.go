@@ -0,0 +1,137 @@
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Event เหตุการณ์ของข้อมูล ถูกเขียนลง outbox_events ใน transaction เดียวกับการแก้ไข
+// แล้ว relay ส่งต่อให้ระบบอื่นภายหลัง (at-least-once: ผู้รับต้องกันซ้ำด้วย event id เอง)
+type Event interface {
+	EventType() string
+	AggregateID() uint
+}
+
+// แค็ตตาล็อกชนิด event: "<aggregate>.<การกระทำ>" ชื่อเหล่านี้เป็นสัญญากับผู้รับภายนอก ห้ามเปลี่ยน
+const (
+	EventProductCreated  = "product.created"
+	EventProductUpdated  = "product.updated"
+	EventProductDeleted  = "product.deleted"
+	EventProductRestored = "product.restored"
+
+	EventCategoryCreated  = "category.created"
+	EventCategoryUpdated  = "category.updated"
+	EventCategoryDeleted  = "category.deleted"
+	EventCategoryRestored = "category.restored"
+
+	EventInventoryQuantityUpdated = "inventory.quantity.updated"
+)
+
+// EventTypes ชนิด event ทั้งหมด (ใช้ตรวจค่าที่ผู้ใช้ส่งมา เช่นตอนสมัครรับ event)
+var EventTypes = []string{
+	EventProductCreated, EventProductUpdated, EventProductDeleted, EventProductRestored,
+	EventCategoryCreated, EventCategoryUpdated, EventCategoryDeleted, EventCategoryRestored,
+	EventInventoryQuantityUpdated,
+}
+
+// AggregateType ส่วนหน้าจุดของชนิด event เช่น "product"
+func AggregateType(eventType string) string {
+	aggregate, _, _ := strings.Cut(eventType, ".")
+	return aggregate
+}
+
+// ProductChanged สถานะล่าสุดของสินค้า ใช้กับ created/updated
+type ProductChanged struct {
+	Type       string  `json:"-"`
+	ProductID  uint    `json:"product_id"`
+	SKU        string  `json:"sku"`
+	Name       string  `json:"name"`
+	Price      float64 `json:"price"`
+	CategoryID uint    `json:"category_id"`
+	IsActive   bool    `json:"is_active"`
+	Version    uint    `json:"version,omitempty"` // ไม่มีเมื่อมาจาก import (upsert เป็นชุด)
+}
+
+func (e ProductChanged) EventType() string { return e.Type }
+func (e ProductChanged) AggregateID() uint { return e.ProductID }
+
+// NewProductChanged ใช้ EventProductCreated หรือ EventProductUpdated
+func NewProductChanged(eventType string, p *Product) ProductChanged {
+	return ProductChanged{
+		Type:       eventType,
+		ProductID:  p.ID,
+		SKU:        p.SKU,
+		Name:       p.Name,
+		Price:      p.Price,
+		CategoryID: p.CategoryID,
+		IsActive:   p.IsActive,
+		Version:    p.Version,
+	}
+}
+
+// ProductRemoved ใช้กับ deleted/restored (ย้ายเข้า-ออกถังขยะ)
+type ProductRemoved struct {
+	Type      string `json:"-"`
+	ProductID uint   `json:"product_id"`
+	SKU       string `json:"sku"`
+}
+
+func (e ProductRemoved) EventType() string { return e.Type }
+func (e ProductRemoved) AggregateID() uint { return e.ProductID }
+
+// CategoryChanged ใช้กับทุก event ของหมวดหมู่ (deleted มีแค่ category_id)
+type CategoryChanged struct {
+	Type       string `json:"-"`
+	CategoryID uint   `json:"category_id"`
+	Name       string `json:"name,omitempty"`
+	Version    uint   `json:"version,omitempty"`
+}
+
+func (e CategoryChanged) EventType() string { return e.Type }
+func (e CategoryChanged) AggregateID() uint { return e.CategoryID }
+
+// ที่มาของการเปลี่ยนยอดสต็อก
+const (
+	QuantitySourceAdjust  = StockMoveAdjust // ปรับยอดตรงผ่าน PATCH inventory
+	QuantitySourceReceive = StockMoveReceive
+	QuantitySourceIssue   = StockMoveIssue
+)
+
+// InventoryQuantityChanged ยอดคงเหลือของสินค้าเปลี่ยน (Delta บวก = เพิ่ม)
+type InventoryQuantityChanged struct {
+	ProductID   uint   `json:"product_id"`
+	InventoryID uint   `json:"inventory_id"`
+	Delta       int    `json:"delta"`
+	Quantity    int    `json:"quantity"` // ยอดหลังเปลี่ยน
+	Location    string `json:"location"`
+	Version     uint   `json:"version"`
+	Source      string `json:"source"`
+	Reference   string `json:"reference,omitempty"`
+}
+
+func (e InventoryQuantityChanged) EventType() string { return EventInventoryQuantityUpdated }
+func (e InventoryQuantityChanged) AggregateID() uint { return e.ProductID }
+
+// สถานะของแถวใน outbox
+const (
+	OutboxPending   = "pending"
+	OutboxPublished = "published"
+	OutboxDead      = "dead" // ส่งไม่สำเร็จครบจำนวนครั้งแล้ว ต้องดูด้วยมือ
+)
+
+// OutboxEvent แถวของตาราง outbox_events
+type OutboxEvent struct {
+	ID            uint            `json:"id" gorm:"primaryKey"`
+	EventType     string          `json:"event_type" gorm:"size:50;not null"`
+	AggregateType string          `json:"aggregate_type" gorm:"size:30;not null"`
+	AggregateID   uint            `json:"aggregate_id" gorm:"not null"`
+	Payload       json.RawMessage `json:"payload" gorm:"type:jsonb;not null"`
+	RequestID     string          `json:"request_id"`
+	Status        string          `json:"status" gorm:"size:20;not null;default:pending"`
+	Attempts      int             `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	LastError     string          `json:"last_error"`
+	CreatedAt     time.Time       `json:"created_at"`
+	PublishedAt   *time.Time      `json:"published_at"`
+}
@@ -12,6 +12,9 @@ type Inventory struct {
 	ID        uint           `json:"id" gorm:"primaryKey"`
 	ProductID uint           `json:"product_id" gorm:"unique;not null"`
 	Quantity  int            `json:"quantity" gorm:"not null;default:0"`
+	Location  string         `json:"location"`
+	AvgCost   float64        `json:"avg_cost" gorm:"not null;default:0"` // ต้นทุนถัวเฉลี่ยต่อหน่วย
+	Version   uint           `json:"version" gorm:"not null;default:1"`  // เพิ่มทุกครั้งที่สต็อกเปลี่ยน
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
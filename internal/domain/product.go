@@ -20,6 +20,7 @@ type Product struct {
 	// ความสัมพันธ์แบบ one-to-one ไป Inventory (GORM จะใช้ ProductID ใน Inventory)
 	Inventory Inventory      `json:"inventory"`
 	IsActive  bool           `json:"is_active" gorm:"default:true"`
+	Version   uint           `json:"version" gorm:"not null;default:1"` // เพิ่มทุกครั้งที่แก้ไข (optimistic locking)
 	CreatedAt time.Time      `json:"create_at"`
 	UpdatedAt time.Time      `json:"update_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// สถานะของใบเสนอราคา
+const (
+	QuotationStatusDraft    = "draft"
+	QuotationStatusSent     = "sent"
+	QuotationStatusAccepted = "accepted"
+	QuotationStatusExpired  = "expired"
+)
+
+// Quotation ใบเสนอราคา ราคาในแต่ละบรรทัดถูกล็อกไว้ ณ เวลาที่ออกใบเสนอราคา
+type Quotation struct {
+	ID            uint            `json:"id" gorm:"primaryKey"`
+	Number        string          `json:"number" gorm:"uniqueIndex;not null"`
+	CustomerName  string          `json:"customer_name" gorm:"not null"`
+	CustomerPhone string          `json:"customer_phone"`
+	CustomerTaxID string          `json:"customer_tax_id"`
+	Status        string          `json:"status" gorm:"not null;default:draft"`
+	ValidUntil    time.Time       `json:"valid_until" gorm:"not null"`
+	Total         float64         `json:"total" gorm:"not null"`
+	Note          string          `json:"note"`
+	CreatedBy     uint            `json:"created_by"`
+	Lines         []QuotationLine `json:"lines"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt  `json:"-" gorm:"index"`
+}
+
+// QuotationLine เก็บ snapshot ของสินค้า (SKU, ชื่อ, ราคา) ไว้ เผื่อสินค้าถูกแก้ไขภายหลัง
+type QuotationLine struct {
+	ID          uint    `json:"id" gorm:"primaryKey"`
+	QuotationID uint    `json:"quotation_id" gorm:"not null;index"`
+	ProductID   uint    `json:"product_id" gorm:"not null"`
+	SKU         string  `json:"sku" gorm:"not null"`
+	Name        string  `json:"name" gorm:"not null"`
+	Quantity    int     `json:"quantity" gorm:"not null"`
+	UnitPrice   float64 `json:"unit_price" gorm:"not null"`
+	LineTotal   float64 `json:"line_total" gorm:"not null"`
+}
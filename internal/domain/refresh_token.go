@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// RefreshToken refresh token หนึ่งใบ เก็บเฉพาะ hash (ตัว token จริงอยู่ที่ client เท่านั้น)
+// ทุกใบที่ได้จากการ rotate ต่อกันมาจาก login ครั้งเดียวอยู่ใน family เดียวกัน
+type RefreshToken struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null"`
+	FamilyID  string    `json:"family_id" gorm:"size:64;not null"`
+	ParentID  *uint     `json:"parent_id"` // ใบก่อนหน้าที่ถูก rotate มาเป็นใบนี้ (nil = ได้จาก login)
+	TokenHash string    `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null"`
+	// UsedAt ถูกใช้ rotate ไปแล้ว ถ้ามีคนส่งใบนี้มาอีกคือ token รั่ว
+	UsedAt    *time.Time `json:"used_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
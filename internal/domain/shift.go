@@ -0,0 +1,50 @@
+package domain
+
+import "time"
+
+// สถานะของกะ (shift) ลิ้นชักเงินสด
+const (
+	ShiftStatusOpen   = "open"
+	ShiftStatusClosed = "closed"
+)
+
+// ช่องทางการชำระเงิน
+const (
+	PaymentMethodCash         = "cash"
+	PaymentMethodCard         = "card"
+	PaymentMethodBankTransfer = "bank_transfer"
+	PaymentMethodPromptPay    = "promptpay"
+)
+
+// PaymentMethods รายการช่องทางชำระเงินทั้งหมด (ใช้เรียงลำดับในรายงาน)
+var PaymentMethods = []string{
+	PaymentMethodCash,
+	PaymentMethodCard,
+	PaymentMethodBankTransfer,
+	PaymentMethodPromptPay,
+}
+
+// Shift กะการขายของแคชเชียร์หนึ่งคนบนเครื่อง (terminal) หนึ่งเครื่อง
+type Shift struct {
+	ID           uint         `json:"id" gorm:"primaryKey"`
+	UserID       uint         `json:"user_id" gorm:"not null;index"`
+	Terminal     string       `json:"terminal" gorm:"not null"`
+	Status       string       `json:"status" gorm:"not null;default:open"`
+	OpeningFloat float64      `json:"opening_float" gorm:"not null"`
+	OpenedAt     time.Time    `json:"opened_at" gorm:"not null"`
+	ClosedAt     *time.Time   `json:"closed_at"`
+	ClosingNote  string       `json:"closing_note"`
+	Totals       []ShiftTotal `json:"totals"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+// ShiftTotal ยอดที่คาดไว้ / ยอดที่นับได้ / ส่วนต่าง ต่อช่องทางชำระเงิน (บันทึกตอนปิดกะ)
+type ShiftTotal struct {
+	ID       uint    `json:"id" gorm:"primaryKey"`
+	ShiftID  uint    `json:"shift_id" gorm:"not null;index"`
+	Method   string  `json:"method" gorm:"not null"`
+	Expected float64 `json:"expected" gorm:"not null"`
+	Counted  float64 `json:"counted" gorm:"not null"`
+	Variance float64 `json:"variance" gorm:"not null"`
+}
@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// ประเภทการเคลื่อนไหวของสต็อก
+const (
+	StockMoveReceive = "receive" // รับเข้า (มีต้นทุน)
+	StockMoveIssue   = "issue"   // ตัดออก (ขาย / เบิก / ตัดเสีย)
+	StockMoveAdjust  = "adjust"  // ปรับยอดมือ ที่ต้นทุนเฉลี่ย ณ ขณะนั้น (Quantity มีเครื่องหมาย)
+)
+
+// StockMovement บันทึกการเคลื่อนไหวสต็อกแต่ละครั้ง (ledger)
+// เก็บยอดคงเหลือและมูลค่าหลังรายการ เพื่อใช้ทำรายงานมูลค่า ณ วันที่ใดก็ได้
+type StockMovement struct {
+	ID        uint    `json:"id" gorm:"primaryKey"`
+	ProductID uint    `json:"product_id" gorm:"not null;index"`
+	Type      string  `json:"type" gorm:"size:20;not null"`
+	Quantity  int     `json:"quantity" gorm:"not null"`
+	UnitCost  float64 `json:"unit_cost" gorm:"not null;default:0"`  // รับเข้า: ราคาทุนต่อหน่วย / ตัดออก: ต้นทุนเฉลี่ย ณ ขณะนั้น
+	TotalCost float64 `json:"total_cost" gorm:"not null;default:0"` // ต้นทุนแบบถัวเฉลี่ย (COGS สำหรับรายการตัดออก)
+	FIFOCost  float64 `json:"fifo_cost" gorm:"column:fifo_cost;not null;default:0"`
+	Reference string  `json:"reference"`
+
+	QtyAfter       int     `json:"qty_after" gorm:"not null"`
+	AvgCostAfter   float64 `json:"avg_cost_after" gorm:"not null;default:0"`
+	ValueAfter     float64 `json:"value_after" gorm:"not null;default:0"`
+	FIFOValueAfter float64 `json:"fifo_value_after" gorm:"column:fifo_value_after;not null;default:0"`
+
+	CreatedBy uint      `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// StockCostLayer ชั้นต้นทุนของการรับเข้าแต่ละครั้ง ถูกตัดออกตามลำดับ FIFO
+type StockCostLayer struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	ProductID    uint      `json:"product_id" gorm:"not null;index"`
+	MovementID   uint      `json:"movement_id" gorm:"not null"`
+	UnitCost     float64   `json:"unit_cost" gorm:"not null"`
+	QtyReceived  int       `json:"qty_received" gorm:"not null"`
+	QtyRemaining int       `json:"qty_remaining" gorm:"not null"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// WebhookAllEvents ใช้ใน EventTypes ของ subscription เพื่อรับทุกชนิด event
+const WebhookAllEvents = "*"
+
+// สถานะการส่ง webhook หนึ่ง event ไปยัง subscription หนึ่ง
+const (
+	WebhookDeliveryPending   = "pending"   // รอส่ง / รอ retry
+	WebhookDeliverySucceeded = "succeeded" // ปลายทางตอบ 2xx
+	WebhookDeliveryDead      = "dead"      // ส่งไม่สำเร็จครบจำนวนครั้ง (dead-letter) ต้องกด redeliver เอง
+)
+
+// StringList เก็บ []string เป็น jsonb
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal([]string(l))
+	return string(b), err
+}
+
+func (l *StringList) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, (*[]string)(l))
+	case string:
+		return json.Unmarshal([]byte(v), (*[]string)(l))
+	}
+	return fmt.Errorf("domain.StringList: unsupported type %T", src)
+}
+
+// WebhookSubscription ปลายทางที่สมัครรับ event (จัดการโดย manager)
+type WebhookSubscription struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	URL         string     `json:"url" gorm:"not null"`
+	EventTypes  StringList `json:"event_types" gorm:"type:jsonb;not null"`
+	Secret      string     `json:"-" gorm:"not null"` // ใช้เซ็น HMAC ต้องเก็บแบบอ่านกลับได้
+	Description string     `json:"description"`
+	IsActive    bool       `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Wants subscription นี้รับ event ชนิดนี้หรือไม่
+func (s *WebhookSubscription) Wants(eventType string) bool {
+	for _, t := range s.EventTypes {
+		if t == eventType || t == WebhookAllEvents {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery การส่ง event หนึ่งตัวไปยัง subscription หนึ่ง (หนึ่งแถวต่อคู่ subscription/event)
+type WebhookDelivery struct {
+	ID             uint            `json:"id" gorm:"primaryKey"`
+	SubscriptionID uint            `json:"subscription_id" gorm:"not null;index"`
+	EventID        uint            `json:"event_id" gorm:"not null"` // outbox_events.id
+	EventType      string          `json:"event_type" gorm:"size:50;not null"`
+	Payload        json.RawMessage `json:"payload" gorm:"type:jsonb;not null"` // body ที่ POST ไป
+	Status         string          `json:"status" gorm:"size:20;not null;default:pending"`
+	Attempts       int             `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at" gorm:"not null"`
+	ResponseStatus int             `json:"response_status"` // ของครั้งล่าสุด 0 = ต่อไม่ได้
+	LastError      string          `json:"last_error"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+}
+
+// WebhookAttempt log ของการส่งแต่ละครั้ง (append-only)
+type WebhookAttempt struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	DeliveryID     uint      `json:"delivery_id" gorm:"not null;index"`
+	ResponseStatus int       `json:"response_status"`
+	ResponseBody   string    `json:"response_body"` // ตัดเหลือไม่เกิน 1KB
+	Error          string    `json:"error"`
+	DurationMs     int64     `json:"duration_ms"`
+	CreatedAt      time.Time `json:"created_at"`
+}
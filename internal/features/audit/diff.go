@@ -0,0 +1,69 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// field ที่ระบบจัดการเองและเปลี่ยนทุกครั้ง ไม่ต้องบันทึก
+var ignoredFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+	"create_at":  true,
+	"update_at":  true,
+}
+
+// Diff เทียบ field ระดับบนสุดของ before/after ตาม JSON tag (field ที่เป็น json:"-" เช่น password จะไม่ถูกบันทึก)
+// before nil = สร้างใหม่ (ทุก field เป็นค่าใหม่), after nil = ลบ
+// field ที่เป็น object (ความสัมพันธ์ เช่น Product.Category) จะถูกข้าม เพราะมี audit ของตัวเองอยู่แล้ว
+func Diff(before, after any) (map[string]Change, error) {
+	b, err := toMap(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := toMap(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]Change)
+	for k, v := range a {
+		if skip(k, v) {
+			continue
+		}
+		if old, ok := b[k]; !ok || !reflect.DeepEqual(old, v) {
+			changes[k] = Change{Old: old, New: v}
+		}
+	}
+	for k, v := range b {
+		if _, ok := a[k]; ok || skip(k, v) {
+			continue
+		}
+		changes[k] = Change{Old: v}
+	}
+	return changes, nil
+}
+
+// toMap แปลงผ่าน JSON ให้ค่าทุกชนิดเทียบกันได้ (ตัวเลขเป็น float64); nil / nil pointer ได้ map ว่าง
+func toMap(v any) (map[string]any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func skip(key string, v any) bool {
+	if ignoredFields[key] {
+		return true
+	}
+	_, nested := v.(map[string]any)
+	return nested
+}
@@ -0,0 +1,75 @@
+package audit_test
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/features/audit"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	before := &domain.Product{ID: 7, Name: "Brake pad", Price: 100, SKU: "BRK-007", CategoryID: 1, Version: 3, UpdatedAt: time.Now()}
+	after := *before
+	after.Price = 120
+	after.Version = 4
+	after.UpdatedAt = time.Now().Add(time.Minute)
+	after.Category = domain.Category{ID: 2, Name: "Engine"}
+
+	tests := []struct {
+		name   string
+		before any
+		after  any
+		want   map[string]audit.Change
+	}{
+		{
+			name:   "Update_Only_Changed_Fields",
+			before: before,
+			after:  &after,
+			// updated_at และ object ความสัมพันธ์ (category) ไม่ถูกนับ
+			want: map[string]audit.Change{
+				"price":   {Old: float64(100), New: float64(120)},
+				"version": {Old: float64(3), New: float64(4)},
+			},
+		},
+		{
+			name:   "Create_All_Fields_New",
+			before: nil,
+			after:  &domain.Category{ID: 1, Name: "Brake", Version: 1},
+			want: map[string]audit.Change{
+				"id":      {New: float64(1)},
+				"name":    {New: "Brake"},
+				"version": {New: float64(1)},
+			},
+		},
+		{
+			name:   "Delete_All_Fields_Old",
+			before: &domain.User{ID: 3, Username: "john", Email: "john@mail.com", Role: "cashier", Password: "secret"},
+			after:  (*domain.User)(nil),
+			// password เป็น json:"-" จึงไม่หลุดเข้า audit
+			want: map[string]audit.Change{
+				"id":        {Old: float64(3)},
+				"username":  {Old: "john"},
+				"email":     {Old: "john@mail.com"},
+				"role":      {Old: "cashier"},
+				"is_active": {Old: false},
+			},
+		},
+		{
+			name:   "No_Change",
+			before: before,
+			after:  before,
+			want:   map[string]audit.Change{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := audit.Diff(test.before, test.after)
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
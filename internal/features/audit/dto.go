@@ -0,0 +1,93 @@
+package audit
+
+import (
+	"ans-spareparts-api/pkg/cursor"
+	"ans-spareparts-api/pkg/query"
+	"encoding/json"
+	"time"
+)
+
+// ประเภท record ที่บันทึกลง audit log
+const (
+	EntityProduct   = "product"
+	EntityCategory  = "category"
+	EntityInventory = "inventory"
+	EntityUser      = "user"
+	// EntityProductImport สรุปผลการ import หนึ่งครั้ง ไม่ผูกกับ record ใด (entity_id เป็น 0)
+	EntityProductImport = "product_import"
+)
+
+// listSchema field ที่กรองได้ของ GET /audit (เรียงตาม id อย่างเดียว ใหม่สุดก่อน)
+var listSchema = query.Schema{
+	Fields: map[string]query.Field{
+		"id":          {Column: "id", Type: query.Int, Ops: query.Comparable, Sortable: true},
+		"entity_type": {Column: "entity_type", Type: query.String, Ops: []query.Op{query.OpEq, query.OpIn}},
+		"entity_id":   {Column: "entity_id", Type: query.Int, Ops: []query.Op{query.OpEq, query.OpIn}},
+		"action":      {Column: "action", Type: query.String, Ops: []query.Op{query.OpEq, query.OpIn}},
+		"actor_id":    {Column: "actor_id", Type: query.Int, Ops: []query.Op{query.OpEq, query.OpIn}},
+		"request_id":  {Column: "request_id", Type: query.String, Ops: []query.Op{query.OpEq}},
+		"created_at":  {Column: "created_at", Type: query.Time, Ops: query.Comparable},
+	},
+	DefaultSort: []query.Sort{{Column: "id", Desc: true}},
+}
+
+// Entry สิ่งที่ service ส่งมาบันทึก; Changes ว่างจะคำนวณจาก Diff(Before, After)
+// Before nil = สร้างใหม่, After nil = ลบ
+type Entry struct {
+	EntityType string
+	EntityID   uint
+	Action     string
+	Before     any
+	After      any
+	Changes    map[string]Change
+}
+
+// Change ค่าก่อน/หลังของ field หนึ่ง
+type Change struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+type ListQuery struct {
+	Limit   int
+	Offset  int
+	Sort    []query.Sort
+	Filters []query.Filter
+
+	// Keyset ใช้ cursor แทน offset
+	Keyset bool
+	After  *cursor.Cursor
+}
+
+type Item struct {
+	ID            uint
+	ActorID       *uint
+	ActorUsername string
+	ActorRole     string
+	RequestID     string
+	EntityType    string
+	EntityID      uint
+	Action        string
+	Changes       json.RawMessage
+	CreatedAt     time.Time
+}
+
+type ListOutput struct {
+	Items []*Item
+	Total int64
+	// NextCursor มีเฉพาะโหมด Keyset
+	NextCursor string
+}
+
+type AuditLogResponse struct {
+	ID            uint            `json:"id" example:"42"`
+	ActorID       *uint           `json:"actor_id" example:"1"`
+	ActorUsername string          `json:"actor_username" example:"manager01"`
+	ActorRole     string          `json:"actor_role" example:"manager"`
+	RequestID     string          `json:"request_id"`
+	EntityType    string          `json:"entity_type" example:"product"`
+	EntityID      uint            `json:"entity_id" example:"7"`
+	Action        string          `json:"action" example:"update"`
+	Changes       json.RawMessage `json:"changes"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
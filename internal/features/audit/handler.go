@@ -0,0 +1,86 @@
+package audit
+
+import (
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/pkg/query"
+	"ans-spareparts-api/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	auditService Service
+}
+
+func NewHandler(auditService Service) *Handler {
+	return &Handler{
+		auditService: auditService,
+	}
+}
+
+// List godoc
+// @Summary List audit logs
+// @Description Who changed what and when, newest first (manager only). Filter with field[op]=value, e.g. entity_type=product&entity_id=7 or created_at[gte]=2025-01-01T00:00:00Z
+// @Tags audit
+// @Produce json
+// @Param entity_type query string false "product | category | inventory | user"
+// @Param entity_id query int false "Entity ID"
+// @Param action query string false "create | update | delete | restore | import"
+// @Param actor_id query int false "User ID of the actor"
+// @Param request_id query string false "Request ID"
+// @Param limit query int false "Page size"
+// @Param offset query int false "Offset"
+// @Param cursor query string false "Keyset cursor (empty for the first page)"
+// @Success 200 {array} AuditLogResponse
+// @Failure 400 {object} response.ErrorBody
+// @Failure 403 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /audit [get]
+func (h *Handler) List(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+
+	p, err := query.FromCtx(c, listSchema)
+	if err != nil {
+		log.Warn("handler.audit.list.invalid_input", zap.Error(err))
+		return response.Error(
+			c, fiber.StatusBadRequest, "BAD_REQUEST", err.Error(),
+		)
+	}
+
+	out, err := h.auditService.List(ctx, ListQuery{
+		Limit:   p.Limit,
+		Offset:  p.Offset,
+		Sort:    p.Sort,
+		Filters: p.Filters,
+		Keyset:  p.Keyset,
+		After:   p.After,
+	})
+	if err != nil {
+		return response.Error(
+			c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "internal server occured",
+		)
+	}
+
+	items := make([]*AuditLogResponse, len(out.Items))
+	for index, l := range out.Items {
+		items[index] = &AuditLogResponse{
+			ID:            l.ID,
+			ActorID:       l.ActorID,
+			ActorUsername: l.ActorUsername,
+			ActorRole:     l.ActorRole,
+			RequestID:     l.RequestID,
+			EntityType:    l.EntityType,
+			EntityID:      l.EntityID,
+			Action:        l.Action,
+			Changes:       l.Changes,
+			CreatedAt:     l.CreatedAt,
+		}
+	}
+
+	if p.Keyset {
+		return response.CursorPage(c, items, p.Limit, out.NextCursor)
+	}
+	return response.Page(c, items, out.Total, p.Limit, p.Offset)
+}
@@ -0,0 +1,95 @@
+package audit_test
+
+import (
+	"ans-spareparts-api/internal/features/audit"
+	mocks "ans-spareparts-api/internal/mock"
+	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/query"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAuditHandler_List(t *testing.T) {
+	actorID := uint(5)
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name               string
+		path               string
+		setup              func(*mocks.AuditService)
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name: "Success_Filter_By_Entity",
+			path: "/audit?entity_type=product&entity_id=7&limit=5",
+			setup: func(m *mocks.AuditService) {
+				m.On("List", mock.Anything, audit.ListQuery{
+					Limit: 5,
+					Filters: []query.Filter{
+						{Column: "entity_id", Op: query.OpEq, Value: int64(7)},
+						{Column: "entity_type", Op: query.OpEq, Value: "product"},
+					},
+				}).Return(&audit.ListOutput{
+					Items: []*audit.Item{{
+						ID: 42, ActorID: &actorID, ActorUsername: "manager01", ActorRole: "manager", RequestID: "req-1",
+						EntityType: "product", EntityID: 7, Action: "update",
+						Changes:   json.RawMessage(`{"price":{"old":100,"new":120}}`),
+						CreatedAt: createdAt,
+					}},
+					Total: 1,
+				}, nil).Once()
+			},
+			expectedStatusCode: fiber.StatusOK,
+			expectedBody: `{
+				"data": [{
+					"id": 42, "actor_id": 5, "actor_username": "manager01", "actor_role": "manager", "request_id": "req-1",
+					"entity_type": "product", "entity_id": 7, "action": "update",
+					"changes": {"price": {"old": 100, "new": 120}},
+					"created_at": "2025-01-02T03:04:05Z"
+				}],
+				"meta": {"total": 1, "limit": 5, "offset": 0}
+			}`,
+		},
+		{
+			name:               "Error_Unknown_Filter",
+			path:               "/audit?changes[like]=price",
+			setup:              func(m *mocks.AuditService) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name: "Error_InternalServer",
+			path: "/audit",
+			setup: func(m *mocks.AuditService) {
+				m.On("List", mock.Anything, mock.Anything).Return(nil, apperror.ErrInternalServer).Once()
+			},
+			expectedStatusCode: fiber.StatusInternalServerError,
+			expectedBody:       `{"code": "INTERNAL_ERROR", "message": "internal server occured"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svc := mocks.NewAuditService()
+			app := fiber.New()
+			app.Get("/audit", audit.NewHandler(svc).List)
+			test.setup(svc)
+
+			res, _ := app.Test(httptest.NewRequest(fiber.MethodGet, test.path, nil), -1)
+
+			assert.Equal(t, test.expectedStatusCode, res.StatusCode)
+			if test.expectedBody != "" {
+				resBody, _ := io.ReadAll(res.Body)
+				assert.JSONEq(t, test.expectedBody, string(resBody))
+			}
+			svc.AssertExpectations(t)
+		})
+	}
+}
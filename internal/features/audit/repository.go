@@ -0,0 +1,77 @@
+package audit
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/cursor"
+	"ans-spareparts-api/pkg/query"
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	// Create เพิ่ม audit log หนึ่งแถว (ตารางเป็น append-only ไม่มี update/delete)
+	Create(ctx context.Context, log *domain.AuditLog) error
+	List(ctx context.Context, q ListQuery) ([]*domain.AuditLog, int64, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(ctx context.Context, entry *domain.AuditLog) error {
+	log := ctxlog.From(ctx)
+
+	if err := r.db.WithContext(ctx).Create(entry).Error; err != nil {
+		m := apperror.MapDBError("repo.audit.create", err)
+		log.Debug("repo.audit.create.fail", zap.Error(err))
+		return m
+	}
+	return nil
+}
+
+func (r *repository) List(ctx context.Context, q ListQuery) ([]*domain.AuditLog, int64, error) {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	tx := query.Where(r.db.WithContext(ctx).Model(&domain.AuditLog{}), q.Filters)
+	sorts := listSchema.SortOrDefault(q.Sort)
+
+	var total int64
+	if q.Keyset {
+		// ดึงเกินมา 1 แถวเพื่อรู้ว่ามีหน้าถัดไปไหม
+		tx = cursor.Apply(tx, sorts, q.After).Limit(q.Limit + 1)
+	} else {
+		if err := tx.Count(&total).Error; err != nil {
+			m := apperror.MapDBError("repo.audit.list.count", err)
+			log.Debug("repo.audit.list.count.fail", zap.Error(err))
+			return nil, 0, m
+		}
+
+		tx = query.Order(tx, sorts)
+		if q.Offset != 0 {
+			tx = tx.Offset(q.Offset)
+		}
+		if q.Limit != 0 {
+			tx = tx.Limit(q.Limit)
+		}
+	}
+
+	var rows []*domain.AuditLog
+	if err := tx.Find(&rows).Error; err != nil {
+		m := apperror.MapDBError("repo.audit.list", err)
+		log.Debug("repo.audit.list.fail", zap.Error(err), zap.Duration("duration", time.Since(start)))
+		return nil, 0, m
+	}
+
+	log.Debug("repo.audit.list.ok", zap.Int("count", len(rows)), zap.Duration("duration", time.Since(start)))
+	return rows, total, nil
+}
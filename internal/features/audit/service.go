@@ -0,0 +1,124 @@
+package audit
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/internal/infra/jwtx"
+	"ans-spareparts-api/pkg/cursor"
+	"ans-spareparts-api/pkg/utils"
+	"context"
+	"encoding/json"
+
+	"go.uber.org/zap"
+)
+
+// Recorder ใช้โดย service อื่นหลังแก้ไขข้อมูลสำเร็จ
+// บันทึกแบบ best-effort: ถ้าเขียน audit ไม่ได้จะ log warn แต่ไม่ทำให้ request ล้ม
+type Recorder interface {
+	Record(ctx context.Context, e Entry)
+}
+
+type Service interface {
+	Recorder
+	List(ctx context.Context, q ListQuery) (*ListOutput, error)
+}
+
+type service struct {
+	auditRepo Repository
+}
+
+func NewService(auditRepo Repository) Service {
+	return &service{
+		auditRepo: auditRepo,
+	}
+}
+
+func (i *service) Record(ctx context.Context, e Entry) {
+	log := ctxlog.From(ctx).With(
+		zap.String("entity_type", e.EntityType),
+		zap.Uint("entity_id", e.EntityID),
+		zap.String("action", e.Action),
+	)
+
+	changes := e.Changes
+	if changes == nil {
+		var err error
+		if changes, err = Diff(e.Before, e.After); err != nil {
+			log.Warn("audit.record.diff_fail", zap.Error(err))
+			return
+		}
+	}
+
+	// update ที่ไม่มี field ไหนเปลี่ยนไม่ต้องบันทึก
+	if e.Action == domain.AuditUpdate && len(changes) == 0 {
+		return
+	}
+
+	raw, err := json.Marshal(changes)
+	if err != nil {
+		log.Warn("audit.record.marshal_fail", zap.Error(err))
+		return
+	}
+
+	entry := &domain.AuditLog{
+		RequestID:  ctxlog.RequestID(ctx),
+		EntityType: e.EntityType,
+		EntityID:   e.EntityID,
+		Action:     e.Action,
+		Changes:    raw,
+	}
+	// claims มีเฉพาะ request ที่ผ่าน RequireAuth
+	if claims, ok := jwtx.FormContext(ctx); ok {
+		actorID := claims.UserID
+		entry.ActorID = &actorID
+		entry.ActorUsername = claims.Username
+		entry.ActorRole = claims.Role
+	}
+
+	if err := i.auditRepo.Create(ctx, entry); err != nil {
+		log.Warn("audit.record.fail", zap.Error(err))
+	}
+}
+
+func (i *service) List(ctx context.Context, q ListQuery) (*ListOutput, error) {
+	limit, offset := utils.NormalizePagination(q.Limit, q.Offset)
+
+	rows, total, err := i.auditRepo.List(ctx, ListQuery{
+		Limit:   limit,
+		Offset:  offset,
+		Sort:    q.Sort,
+		Filters: q.Filters,
+		Keyset:  q.Keyset,
+		After:   q.After,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var next string
+	if q.Keyset {
+		// เรียงได้เฉพาะ id
+		sorts := listSchema.SortOrDefault(q.Sort)
+		rows, next = cursor.Page(rows, limit, func(l *domain.AuditLog) cursor.Cursor {
+			return cursor.New(sorts, l.ID, func(string) any { return l.ID })
+		})
+	}
+
+	items := make([]*Item, 0, len(rows))
+	for _, l := range rows {
+		items = append(items, &Item{
+			ID:            l.ID,
+			ActorID:       l.ActorID,
+			ActorUsername: l.ActorUsername,
+			ActorRole:     l.ActorRole,
+			RequestID:     l.RequestID,
+			EntityType:    l.EntityType,
+			EntityID:      l.EntityID,
+			Action:        l.Action,
+			Changes:       l.Changes,
+			CreatedAt:     l.CreatedAt,
+		})
+	}
+
+	return &ListOutput{Items: items, Total: total, NextCursor: next}, nil
+}
@@ -0,0 +1,146 @@
+package audit_test
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/features/audit"
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/internal/infra/jwtx"
+	mocks "ans-spareparts-api/internal/mock"
+	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/cursor"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type ServiceTestSuite struct {
+	Service   audit.Service
+	MockRepo  *mocks.AuditRepository
+	Ctx       context.Context
+	AnonymCtx context.Context
+}
+
+func (ts *ServiceTestSuite) SetupTest(t *testing.T) {
+	ts.MockRepo = mocks.NewMockAuditRepository()
+	ts.Service = audit.NewService(ts.MockRepo)
+
+	ts.AnonymCtx = ctxlog.WithRequestID(context.Background(), "req-1")
+	ts.Ctx = jwtx.InjectClaims(ts.AnonymCtx, &jwtx.Claims{UserID: 5, Username: "manager01", Role: "manager"})
+
+	t.Cleanup(func() {
+		ts.MockRepo.AssertExpectations(t)
+	})
+}
+
+func TestAuditService_Record(t *testing.T) {
+	category := &domain.Category{ID: 1, Name: "Brake", Version: 1}
+	renamed := &domain.Category{ID: 1, Name: "Brakes", Version: 2}
+
+	tests := []struct {
+		name  string
+		anon  bool
+		entry audit.Entry
+		setup func(ts *ServiceTestSuite)
+	}{
+		{
+			name:  "Update_With_Actor_And_RequestID",
+			entry: audit.Entry{EntityType: audit.EntityCategory, EntityID: 1, Action: domain.AuditUpdate, Before: category, After: renamed},
+			setup: func(ts *ServiceTestSuite) {
+				ts.MockRepo.On("Create", ts.Ctx, mock.MatchedBy(func(l *domain.AuditLog) bool {
+					return l.ActorID != nil && *l.ActorID == 5 && l.ActorUsername == "manager01" && l.ActorRole == "manager" &&
+						l.RequestID == "req-1" && l.EntityType == "category" && l.EntityID == 1 && l.Action == "update" &&
+						string(l.Changes) == `{"name":{"old":"Brake","new":"Brakes"},"version":{"old":1,"new":2}}`
+				})).Return(nil).Once()
+			},
+		},
+		{
+			name:  "Create_Without_Claims_Has_No_Actor",
+			anon:  true,
+			entry: audit.Entry{EntityType: audit.EntityUser, EntityID: 3, Action: domain.AuditCreate, After: &domain.User{ID: 3, Username: "john"}},
+			setup: func(ts *ServiceTestSuite) {
+				ts.MockRepo.On("Create", ts.AnonymCtx, mock.MatchedBy(func(l *domain.AuditLog) bool {
+					return l.ActorID == nil && l.RequestID == "req-1" && l.Action == "create"
+				})).Return(nil).Once()
+			},
+		},
+		{
+			name:  "Update_Without_Change_Is_Skipped",
+			entry: audit.Entry{EntityType: audit.EntityCategory, EntityID: 1, Action: domain.AuditUpdate, Before: category, After: category},
+			setup: func(ts *ServiceTestSuite) {},
+		},
+		{
+			name:  "Explicit_Changes_Are_Used_As_Is",
+			entry: audit.Entry{EntityType: audit.EntityProductImport, Action: domain.AuditImport, Changes: map[string]audit.Change{"created": {New: 3}}},
+			setup: func(ts *ServiceTestSuite) {
+				ts.MockRepo.On("Create", ts.Ctx, mock.MatchedBy(func(l *domain.AuditLog) bool {
+					return string(l.Changes) == `{"created":{"old":null,"new":3}}`
+				})).Return(nil).Once()
+			},
+		},
+		{
+			name:  "Repo_Error_Does_Not_Panic",
+			entry: audit.Entry{EntityType: audit.EntityProduct, EntityID: 7, Action: domain.AuditDelete, Before: &domain.Product{ID: 7}},
+			setup: func(ts *ServiceTestSuite) {
+				ts.MockRepo.On("Create", ts.Ctx, mock.Anything).Return(apperror.ErrInternalServer).Once()
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := &ServiceTestSuite{}
+			ts.SetupTest(t)
+
+			test.setup(ts)
+			ctx := ts.Ctx
+			if test.anon {
+				ctx = ts.AnonymCtx
+			}
+			ts.Service.Record(ctx, test.entry)
+		})
+	}
+}
+
+func TestAuditService_List(t *testing.T) {
+	rows := []*domain.AuditLog{{ID: 9}, {ID: 8}, {ID: 7}}
+
+	t.Run("Keyset_Next_Cursor", func(t *testing.T) {
+		ts := &ServiceTestSuite{}
+		ts.SetupTest(t)
+
+		ts.MockRepo.On("List", ts.Ctx, audit.ListQuery{Limit: 2, Keyset: true}).Return(rows, int64(0), nil).Once()
+
+		out, err := ts.Service.List(ts.Ctx, audit.ListQuery{Limit: 2, Keyset: true})
+		assert.NoError(t, err)
+		assert.Len(t, out.Items, 2)
+
+		next, err := cursor.Decode(out.NextCursor)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(8), next.ID)
+		assert.Equal(t, "-id", next.Key)
+	})
+
+	t.Run("Offset_Normalizes_Pagination", func(t *testing.T) {
+		ts := &ServiceTestSuite{}
+		ts.SetupTest(t)
+
+		ts.MockRepo.On("List", ts.Ctx, audit.ListQuery{Limit: 10}).Return(rows, int64(3), nil).Once()
+
+		out, err := ts.Service.List(ts.Ctx, audit.ListQuery{})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), out.Total)
+		assert.Empty(t, out.NextCursor)
+	})
+
+	t.Run("DB_Error", func(t *testing.T) {
+		ts := &ServiceTestSuite{}
+		ts.SetupTest(t)
+
+		ts.MockRepo.On("List", ts.Ctx, mock.Anything).Return(nil, int64(0), apperror.ErrInternalServer).Once()
+
+		_, err := ts.Service.List(ts.Ctx, audit.ListQuery{})
+		assert.ErrorIs(t, err, apperror.ErrInternalServer)
+	})
+}
@@ -1,5 +1,7 @@
 package auth
 
+import "time"
+
 // RegisterRequest ข้อมูลสำหรับการสมัครสมาขิก
 type RegisterRequest struct {
 	// example: json_doe
@@ -16,9 +18,21 @@ type LoginRequest struct {
 }
 
 
-// LoginResponse ข้อมูล Token หลังจาก Login สำเร็จ
+// LoginResponse ข้อมูล Token หลังจาก Login หรือ Refresh สำเร็จ
 type LoginResponse struct {
 	Token string `json:"token" example:"eyJhbGci01..."`
+	// RefreshToken ใช้ได้ครั้งเดียวกับ POST /auth/refresh แล้วจะได้ใบใหม่มาแทน
+	RefreshToken     string    `json:"refresh_token" example:"q3Zb8..."`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// LogoutRequest body ไม่บังคับ: ส่ง refresh token มาเพื่อ revoke ทั้ง family
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
 }
 
 type RegisterInput struct {
@@ -31,4 +45,13 @@ type RegisterInput struct {
 type LoginInput struct {
 	Username string
 	Password string
+	// IP ของผู้ขอ ใช้นับความผิดพลาดต่อ IP
+	IP string
+}
+
+// Tokens access token กับ refresh token ที่ออกให้ client
+type Tokens struct {
+	AccessToken      string
+	RefreshToken     string
+	RefreshExpiresAt time.Time
 }
@@ -4,6 +4,9 @@ import (
 	"ans-spareparts-api/internal/infra/httpx/ctxlog"
 	"ans-spareparts-api/pkg/apperror"
 	"ans-spareparts-api/pkg/response"
+	"errors"
+	"math"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
@@ -85,6 +88,7 @@ func (h *Handler) Register(c *fiber.Ctx) error {
 // @Success 200 {object} LoginResponse
 // @Failure 400 {object} response.ErrorBody
 // @Failure 401 {object} response.ErrorBody
+// @Failure 423 {object} response.ErrorBody "ACCOUNT_LOCKED: Too many failed attempts, see Retry-After"
 // @Router /auth/login [post]
 func (h *Handler) Login(c *fiber.Ctx) error {
 	ctx := c.UserContext()
@@ -98,11 +102,19 @@ func (h *Handler) Login(c *fiber.Ctx) error {
 		)
 	}
 
-	_, token, err := h.service.Login(ctx, LoginInput{
+	_, tokens, err := h.service.Login(ctx, LoginInput{
 		Username: req.Username,
 		Password: req.Password,
+		IP:       c.IP(),
 	})
 	if err != nil {
+		var locked *LockedError
+		if errors.As(err, &locked) {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+			return response.Error(
+				c, fiber.StatusLocked, "ACCOUNT_LOCKED", "too many failed login attempts, try again later",
+			)
+		}
 		if err == apperror.ErrUnauthorized || err == apperror.ErrUserForbidden {
 			return response.Error(
 				c, fiber.StatusUnauthorized, "UNAUTHERIZED", "invalid credentials",
@@ -115,16 +127,61 @@ func (h *Handler) Login(c *fiber.Ctx) error {
 	}
 
 	return response.OK(c, LoginResponse{
-		Token: token,
+		Token:            tokens.AccessToken,
+		RefreshToken:     tokens.RefreshToken,
+		RefreshExpiresAt: tokens.RefreshExpiresAt,
+	})
+}
+
+// Refresh godoc
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new access token and a new refresh token. Each refresh token works once; replaying a used one revokes the whole session.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body RefreshRequest true "Refresh token"
+// @Success 200 {object} LoginResponse
+// @Failure 400 {object} response.ErrorBody
+// @Failure 401 {object} response.ErrorBody
+// @Router /auth/refresh [post]
+func (h *Handler) Refresh(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+
+	var req RefreshRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Warn("handler.auth.refresh.invalid_input", zap.Error(err))
+		return response.Error(
+			c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid request body",
+		)
+	}
+
+	tokens, err := h.service.Refresh(ctx, req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, apperror.ErrInvalidToken) || errors.Is(err, apperror.ErrTokenExpired) || errors.Is(err, apperror.ErrUserForbidden) {
+			return response.Error(
+				c, fiber.StatusUnauthorized, "UNAUTHERIZED", "invalid refresh token",
+			)
+		}
+		return response.Error(
+			c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "internal server occured",
+		)
+	}
+
+	return response.OK(c, LoginResponse{
+		Token:            tokens.AccessToken,
+		RefreshToken:     tokens.RefreshToken,
+		RefreshExpiresAt: tokens.RefreshExpiresAt,
 	})
 }
 
 // Logout godoc
 // @Summary User logout
-// @Description Logout user and blacklist token
+// @Description Logout user, blacklist access token and revoke the refresh token session. Works with only the refresh token in the body when the access token has expired
 // @Tags auth
 // @Accept json
 // @Produce json
+// @Param body body LogoutRequest false "Refresh token to revoke"
 // @Success 200 {object} map[string]string
 // @Failure 401 {object} response.ErrorBody
 // @Security BearerAuth
@@ -133,19 +190,36 @@ func (h *Handler) Logout(c *fiber.Ctx) error {
 	ctx := c.UserContext()
 	log := ctxlog.From(ctx)
 
+	// route นี้ไม่ผ่าน RequireAuth: access token อาจหมดอายุแล้ว ส่งแค่ refresh token มาก็ logout ได้
 	token := c.Get("Authorization")
-	if token == "" {
-		log.Warn("handler.auth.logout.missingtoken", zap.String("token", token))
+	if len(token) > 7 && token[:7] == "Bearer " {
+		token = token[7:]
+	}
+
+	// body ไม่บังคับ (client เก่าไม่ได้ส่ง refresh token มา)
+	var req LogoutRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			log.Warn("handler.auth.logout.invalid_input", zap.Error(err))
+			return response.Error(
+				c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid request body",
+			)
+		}
+	}
+
+	if token == "" && req.RefreshToken == "" {
+		log.Warn("handler.auth.logout.missingtoken")
 		return response.Error(
 			c, fiber.StatusUnauthorized, "UNAUTHERIZED", "missing token",
 		)
 	}
 
-	if len(token) > 7 && token[:7] == "Bearer " {
-		token = token[7:]
+	err := h.service.Logout(ctx, token, req.RefreshToken)
+	if errors.Is(err, apperror.ErrInvalidToken) {
+		return response.Error(
+			c, fiber.StatusUnauthorized, "UNAUTHERIZED", "invalid token",
+		)
 	}
-
-	err := h.service.Logout(ctx, token)
 	if err != nil {
 		return response.Error(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "failed to logout")
 	}
@@ -154,3 +228,42 @@ func (h *Handler) Logout(c *fiber.Ctx) error {
 		"message": "logout success",
 	})
 }
+
+// UnlockUser godoc
+// @Summary Unlock user account
+// @Description Clear the temporary lockout caused by failed login attempts (manager only)
+// @Tags auth
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} response.ErrorBody
+// @Failure 403 {object} response.ErrorBody
+// @Failure 404 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /users/{id}/unlock [post]
+func (h *Handler) UnlockUser(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Warn("handler.auth.unlock.invalid_id", zap.Error(err))
+		return response.Error(
+			c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid user id",
+		)
+	}
+
+	err = h.service.UnlockUser(ctx, uint(id))
+	switch {
+	case err == nil:
+		return response.NoContent(c)
+	case errors.Is(err, apperror.ErrNotFound):
+		return response.Error(
+			c, fiber.StatusNotFound, "NOT_FOUND", "user not found",
+		)
+	default:
+		return response.Error(
+			c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "internal server occured",
+		)
+	}
+}
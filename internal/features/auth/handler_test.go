@@ -12,6 +12,7 @@ import (
 	"io"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
@@ -176,13 +177,21 @@ func TestAuthHandler_Login(t *testing.T) {
 	mockInput := auth.LoginInput{
 		Username: mockUser.Username,
 		Password: mockUser.Password,
+		IP:       "0.0.0.0", // IP ของ request ที่สร้างด้วย App.Test
 	}
 	mockRequest := auth.LoginRequest{
 		Username: mockUser.Username,
 		Password: mockUser.Password,
 	}
+	mockTokens := &auth.Tokens{
+		AccessToken:      "token",
+		RefreshToken:     "refresh",
+		RefreshExpiresAt: time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC),
+	}
 	expectedBody := fiber.Map{
-		"token": "token",
+		"token":              "token",
+		"refresh_token":      "refresh",
+		"refresh_expires_at": "2026-01-08T00:00:00Z",
 	}
 
 	tests := []struct {
@@ -198,7 +207,7 @@ func TestAuthHandler_Login(t *testing.T) {
 			path:        "/auth/login",
 			requestBody: mockRequest,
 			setup: func(hts *HandlerTestSuite) {
-				hts.MockService.On("Login", mock.Anything, mockInput).Return(mockUser, "token", nil).Once()
+				hts.MockService.On("Login", mock.Anything, mockInput).Return(mockUser, mockTokens, nil).Once()
 			},
 			expectedStatus: fiber.StatusOK,
 			expectedBody:   expectedBody,
@@ -219,7 +228,7 @@ func TestAuthHandler_Login(t *testing.T) {
 			path:        "/auth/login",
 			requestBody: mockRequest,
 			setup: func(hts *HandlerTestSuite) {
-				hts.MockService.On("Login", mock.Anything, mockInput).Return(nil, "", apperror.ErrUnauthorized).Once()
+				hts.MockService.On("Login", mock.Anything, mockInput).Return(nil, nil, apperror.ErrUnauthorized).Once()
 
 			},
 			expectedStatus: fiber.StatusUnauthorized,
@@ -229,12 +238,25 @@ func TestAuthHandler_Login(t *testing.T) {
 				"message": "invalid credentials",
 			},
 		},
+		{
+			name:        "Error_Locked",
+			path:        "/auth/login",
+			requestBody: mockRequest,
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("Login", mock.Anything, mockInput).Return(nil, nil, &auth.LockedError{RetryAfter: 90 * time.Second}).Once()
+			},
+			expectedStatus: fiber.StatusLocked,
+			expectedBody: fiber.Map{
+				"code":    "ACCOUNT_LOCKED",
+				"message": "too many failed login attempts, try again later",
+			},
+		},
 		{
 			name:        "Error_InternalServer",
 			path:        "/auth/login",
 			requestBody: mockRequest,
 			setup: func(hts *HandlerTestSuite) {
-				hts.MockService.On("Login", mock.Anything, mockInput).Return(nil, "", apperror.ErrInternalServer).Once()
+				hts.MockService.On("Login", mock.Anything, mockInput).Return(nil, nil, apperror.ErrInternalServer).Once()
 			},
 			expectedStatus: fiber.StatusInternalServerError,
 			expectedBody: fiber.Map{
@@ -290,6 +312,7 @@ func TestAuthenHandler_Logout(t *testing.T) {
 		name           string
 		path           string
 		headerValue    string
+		body           string
 		setup          func(*HandlerTestSuite)
 		expectedStatus int
 		expectedBody   interface{}
@@ -299,13 +322,52 @@ func TestAuthenHandler_Logout(t *testing.T) {
 			path:        mockPath,
 			headerValue: headerValue,
 			setup: func(hts *HandlerTestSuite) {
-				hts.MockService.On("Logout", mock.Anything, mockToken).Return(nil).Once()
+				hts.MockService.On("Logout", mock.Anything, mockToken, "").Return(nil).Once()
 			},
 			expectedStatus: fiber.StatusOK,
 			expectedBody: fiber.Map{
 				"message": "logout success",
 			},
 		},
+		{
+			name:        "Success_Logout_Revoke_Refresh",
+			path:        mockPath,
+			headerValue: headerValue,
+			body:        `{"refresh_token":"refresh-1"}`,
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("Logout", mock.Anything, mockToken, "refresh-1").Return(nil).Once()
+			},
+			expectedStatus: fiber.StatusOK,
+			expectedBody: fiber.Map{
+				"message": "logout success",
+			},
+		},
+		{
+			name:        "Success_Logout_Refresh_Only",
+			path:        mockPath,
+			headerValue: "",
+			body:        `{"refresh_token":"refresh-1"}`,
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("Logout", mock.Anything, "", "refresh-1").Return(nil).Once()
+			},
+			expectedStatus: fiber.StatusOK,
+			expectedBody: fiber.Map{
+				"message": "logout success",
+			},
+		},
+		{
+			name:        "Error_Unautherized_Invalid_Token",
+			path:        mockPath,
+			headerValue: headerValue,
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("Logout", mock.Anything, mockToken, "").Return(apperror.ErrInvalidToken).Once()
+			},
+			expectedStatus: fiber.StatusUnauthorized,
+			expectedBody: fiber.Map{
+				"code":    "UNAUTHERIZED",
+				"message": "invalid token",
+			},
+		},
 		{
 			name:           "Error_Unautherized_Missing_Token",
 			path:           mockPath,
@@ -323,7 +385,7 @@ func TestAuthenHandler_Logout(t *testing.T) {
 			path:        mockPath,
 			headerValue: headerValue,
 			setup: func(hts *HandlerTestSuite) {
-				hts.MockService.On("Logout", mock.Anything, mockToken).Return(apperror.ErrInternalServer).Once()
+				hts.MockService.On("Logout", mock.Anything, mockToken, "").Return(apperror.ErrInternalServer).Once()
 			},
 			expectedStatus: fiber.StatusInternalServerError,
 			expectedBody: fiber.Map{
@@ -344,7 +406,8 @@ func TestAuthenHandler_Logout(t *testing.T) {
 			test.setup(ts)
 
 			// Create Request
-			req := httptest.NewRequest(fiber.MethodPost, test.path, nil)
+			req := httptest.NewRequest(fiber.MethodPost, test.path, bytes.NewBufferString(test.body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
 			req.Header.Set("Authorization", test.headerValue)
 
 			// Run test
@@ -361,3 +424,134 @@ func TestAuthenHandler_Logout(t *testing.T) {
 	}
 
 }
+
+func TestAuthHandler_UnlockUser(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		setup          func(*HandlerTestSuite)
+		expectedStatus int
+	}{
+		{
+			name: "Success_Unlocked",
+			path: "/users/1/unlock",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("UnlockUser", mock.Anything, uint(1)).Return(nil).Once()
+			},
+			expectedStatus: fiber.StatusNoContent,
+		},
+		{
+			name: "Error_NotFound",
+			path: "/users/9/unlock",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("UnlockUser", mock.Anything, uint(9)).Return(apperror.ErrNotFound).Once()
+			},
+			expectedStatus: fiber.StatusNotFound,
+		},
+		{
+			name:           "Error_BadRequest_Invalid_ID",
+			path:           "/users/abc/unlock",
+			setup:          func(hts *HandlerTestSuite) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewHandlerTestSuite()
+			ts.SetupTestSuite(t)
+
+			ts.App.Post("/users/:id/unlock", ts.Handler.UnlockUser)
+			test.setup(ts)
+
+			req := httptest.NewRequest(fiber.MethodPost, test.path, nil)
+			res, _ := ts.App.Test(req, -1)
+
+			assert.Equal(t, test.expectedStatus, res.StatusCode)
+		})
+	}
+}
+
+func TestAuthHandler_Refresh(t *testing.T) {
+	mockTokens := &auth.Tokens{
+		AccessToken:      "access-2",
+		RefreshToken:     "refresh-2",
+		RefreshExpiresAt: time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		setup          func(*HandlerTestSuite)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name: "Success_Rotated",
+			body: `{"refresh_token":"refresh-1"}`,
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("Refresh", mock.Anything, "refresh-1").Return(mockTokens, nil).Once()
+			},
+			expectedStatus: fiber.StatusOK,
+			expectedBody: fiber.Map{
+				"token":              "access-2",
+				"refresh_token":      "refresh-2",
+				"refresh_expires_at": "2026-01-08T00:00:00Z",
+			},
+		},
+		{
+			name: "Error_Unautherized_Reused_Token",
+			body: `{"refresh_token":"refresh-1"}`,
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("Refresh", mock.Anything, "refresh-1").Return(nil, apperror.ErrInvalidToken).Once()
+			},
+			expectedStatus: fiber.StatusUnauthorized,
+			expectedBody: fiber.Map{
+				"code":    "UNAUTHERIZED",
+				"message": "invalid refresh token",
+			},
+		},
+		{
+			name:           "Error_BadRequest_Invalid_RequestBody",
+			body:           `{"refresh_token":`,
+			setup:          func(hts *HandlerTestSuite) {},
+			expectedStatus: fiber.StatusBadRequest,
+			expectedBody: fiber.Map{
+				"code":    "BAD_REQUEST",
+				"message": "invalid request body",
+			},
+		},
+		{
+			name: "Error_InternalServer",
+			body: `{"refresh_token":"refresh-1"}`,
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("Refresh", mock.Anything, "refresh-1").Return(nil, apperror.ErrInternalServer).Once()
+			},
+			expectedStatus: fiber.StatusInternalServerError,
+			expectedBody: fiber.Map{
+				"code":    "INTERNAL_ERROR",
+				"message": "internal server occured",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewHandlerTestSuite()
+			ts.SetupTestSuite(t)
+
+			ts.App.Post("/auth/refresh", ts.Handler.Refresh)
+			test.setup(ts)
+
+			req := httptest.NewRequest(fiber.MethodPost, "/auth/refresh", bytes.NewBufferString(test.body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			res, _ := ts.App.Test(req, -1)
+
+			assert.Equal(t, test.expectedStatus, res.StatusCode)
+
+			resBody, _ := io.ReadAll(res.Body)
+			expectedBody, _ := json.Marshal(test.expectedBody)
+			assert.JSONEq(t, string(expectedBody), string(resBody))
+		})
+	}
+}
@@ -3,16 +3,28 @@ package auth
 import (
 	"ans-spareparts-api/internal/domain"
 	"ans-spareparts-api/internal/infra/jwtx"
+	"ans-spareparts-api/internal/infra/loginguard"
 	"context"
 	"time"
 )
 
 type AuthRepository interface {
+	GetByID(ctx context.Context, id uint) (*domain.User, error)
 	GetByUsername(ctx context.Context, username string) (*domain.User, error)
 	GetByEmail(ctx context.Context, email string) (*domain.User, error)
 	Create(ctx context.Context, user *domain.User) error
 }
 
+// RefreshTokenRepository เก็บ refresh token แบบ hash พร้อม family สำหรับ rotation
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, t *domain.RefreshToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+	// Rotate ทำเครื่องหมายว่าใบเดิมถูกใช้แล้วและสร้าง next ใน family เดียวกันใน transaction เดียว
+	// ใบเดิมที่ไม่มี ใช้ไปแล้ว ถูก revoke หรือหมดอายุ คืน apperror.ErrNotFound
+	Rotate(ctx context.Context, tokenHash string, next *domain.RefreshToken, now time.Time) (*domain.RefreshToken, error)
+	RevokeFamily(ctx context.Context, familyID string, now time.Time) (int64, error)
+}
+
 // TokenIssuer interface implement by auth/jwt
 type TokenIssuer interface {
 	GenerateToken(userID uint, username, role string) (token string, jwtID string, err error)
@@ -21,4 +33,10 @@ type TokenIssuer interface {
 	BlacklistToken(ctx context.Context, jwtID string, exp time.Duration) error
 }
 
-
+// LoginGuard implement by loginguard: นับ login ที่ล้มเหลวต่อ username/IP และล็อกชั่วคราว
+type LoginGuard interface {
+	Check(ctx context.Context, username, ip string) (*loginguard.Status, error)
+	Fail(ctx context.Context, username, ip string) (*loginguard.Status, error)
+	Reset(ctx context.Context, username, ip string) error
+	Unlock(ctx context.Context, username string) error
+}
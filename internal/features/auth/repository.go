@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/pkg/apperror"
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+func (r *refreshTokenRepository) Create(ctx context.Context, t *domain.RefreshToken) error {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	if err := r.db.WithContext(ctx).Create(t).Error; err != nil {
+		m := apperror.MapDBError("repo.refresh_token.create", err)
+		log.Debug("repo.refresh_token.create.fail", zap.Error(err), zap.Duration("duration", time.Since(start)))
+		return m
+	}
+
+	log.Debug("repo.refresh_token.create.ok", zap.Uint("user_id", t.UserID), zap.Duration("duration", time.Since(start)))
+	return nil
+}
+
+func (r *refreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	var t domain.RefreshToken
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&t).Error; err != nil {
+		m := apperror.MapDBError("repo.refresh_token.getByHash", err)
+		log.Debug("repo.refresh_token.getByHash.fail", zap.Error(err), zap.Duration("duration", time.Since(start)))
+		return nil, m
+	}
+	return &t, nil
+}
+
+// Rotate ใช้ UPDATE แบบมีเงื่อนไข request ที่ส่ง token ใบเดียวกันพร้อมกันจึง rotate ได้แค่ตัวเดียว
+func (r *refreshTokenRepository) Rotate(ctx context.Context, tokenHash string, next *domain.RefreshToken, now time.Time) (*domain.RefreshToken, error) {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	var used domain.RefreshToken
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Raw(`
+			UPDATE refresh_tokens SET used_at = ?
+			WHERE token_hash = ? AND used_at IS NULL AND revoked_at IS NULL AND expires_at > ?
+			RETURNING *`,
+			now, tokenHash, now,
+		).Scan(&used)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		next.UserID = used.UserID
+		next.FamilyID = used.FamilyID
+		next.ParentID = &used.ID
+		return tx.Create(next).Error
+	})
+	if err != nil {
+		m := apperror.MapDBError("repo.refresh_token.rotate", err)
+		log.Debug("repo.refresh_token.rotate.fail", zap.Error(err), zap.Duration("duration", time.Since(start)))
+		return nil, m
+	}
+
+	log.Debug("repo.refresh_token.rotate.ok", zap.Uint("user_id", used.UserID), zap.String("family_id", used.FamilyID), zap.Duration("duration", time.Since(start)))
+	return &used, nil
+}
+
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, now time.Time) (int64, error) {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	res := r.db.WithContext(ctx).Model(&domain.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", now)
+	if res.Error != nil {
+		m := apperror.MapDBError("repo.refresh_token.revokeFamily", res.Error)
+		log.Debug("repo.refresh_token.revokeFamily.fail", zap.Error(res.Error), zap.Duration("duration", time.Since(start)))
+		return 0, m
+	}
+
+	log.Info("repo.refresh_token.revokeFamily.ok", zap.String("family_id", familyID), zap.Int64("revoked", res.RowsAffected), zap.Duration("duration", time.Since(start)))
+	return res.RowsAffected, nil
+}
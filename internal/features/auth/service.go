@@ -2,42 +2,76 @@ package auth
 
 import (
 	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/features/audit"
 	"ans-spareparts-api/internal/infra/hash"
 	"ans-spareparts-api/internal/infra/httpx/ctxlog"
 	"ans-spareparts-api/pkg/apperror"
 	"ans-spareparts-api/pkg/utils"
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 )
 
 type Service interface {
 	Register(ctx context.Context, in RegisterInput) (*domain.User, error)
-	Login(ctx context.Context, in LoginInput) (*domain.User, string, error)
-	Logout(ctx context.Context, token string) error
+	Login(ctx context.Context, in LoginInput) (*domain.User, *Tokens, error)
+	// Refresh แลก refresh token เป็นคู่ใหม่ (rotation) ใบเดิมใช้ซ้ำไม่ได้
+	Refresh(ctx context.Context, refreshToken string) (*Tokens, error)
+	// Logout blacklist access token และ revoke refresh token ทั้ง family (ถ้าส่งมา)
+	Logout(ctx context.Context, accessToken, refreshToken string) error
+	// UnlockUser ปลดล็อกบัญชีที่ถูกล็อกจากการใส่รหัสผิด (manager)
+	UnlockUser(ctx context.Context, userID uint) error
+}
+
+// LockedError บัญชีหรือ IP ถูกล็อกชั่วคราว errors.Is(err, apperror.ErrAccountLocked) เป็นจริง
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s: retry after %s", apperror.ErrAccountLocked, e.RetryAfter)
+}
+
+func (e *LockedError) Unwrap() error {
+	return apperror.ErrAccountLocked
 }
 
 type service struct {
 	authRepo AuthRepository
 	hash     hash.Hasher
 	tokens   TokenIssuer
+	auditor  audit.Recorder
+	guard    LoginGuard
+
+	refreshTokens RefreshTokenRepository
+	refreshTTL    time.Duration
 
 	DefaultRole string
 }
 
-func NewService(userRepo AuthRepository, tokens TokenIssuer, hash hash.Hasher, defaultRole string) Service {
+func NewService(userRepo AuthRepository, tokens TokenIssuer, hash hash.Hasher, defaultRole string, auditor audit.Recorder, guard LoginGuard,
+	refreshTokens RefreshTokenRepository, refreshTTL time.Duration) Service {
 	if defaultRole == "" {
 		defaultRole = "cashier"
 	}
 
 	return &service{
-		authRepo:    userRepo,
-		tokens:      tokens,
-		hash:        hash,
-		DefaultRole: defaultRole,
+		authRepo:      userRepo,
+		tokens:        tokens,
+		hash:          hash,
+		auditor:       auditor,
+		guard:         guard,
+		refreshTokens: refreshTokens,
+		refreshTTL:    refreshTTL,
+		DefaultRole:   defaultRole,
 	}
 }
 
@@ -59,6 +93,45 @@ func sanitizeLogin(in LoginInput) error {
 	return nil
 }
 
+// sleep หน่วงเวลาแต่เลิกทันทีถ้า client ยกเลิก request
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// randomToken ค่าสุ่มแบบ crypto-safe เข้ารหัส base64 URL-safe
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken เก็บเฉพาะ sha256 ของ refresh token ใน DB (token สุ่ม 256 bit ไม่ต้องใช้ bcrypt)
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// newRefreshToken คืน token จริงสำหรับส่งให้ client กับ record ที่เก็บแค่ hash
+func (i *service) newRefreshToken(now time.Time) (string, *domain.RefreshToken, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, &domain.RefreshToken{TokenHash: hashToken(token), ExpiresAt: now.Add(i.refreshTTL)}, nil
+}
+
 // --- Method ---
 
 // Register: normalized username/email -> hash -> created -> clear password -> return
@@ -131,67 +204,130 @@ func (i *service) Register(ctx context.Context, in RegisterInput) (*domain.User,
 
 	// Return user without password
 	user.Password = ""
+	i.auditor.Record(ctx, audit.Entry{EntityType: audit.EntityUser, EntityID: user.ID, Action: domain.AuditCreate, After: user})
 	log.Info("user.registered", zap.Uint("user_id", user.ID), zap.String("username", username), zap.String("role", user.Role))
 	return user, nil
 }
 
-func (i *service) Login(ctx context.Context, in LoginInput) (*domain.User, string, error) {
+// Login: ตรวจ lock -> หน่วงตามจำนวนครั้งที่ผิด -> ตรวจรหัสผ่าน -> ออก token
+// ผิดครบเกณฑ์จะถูกล็อกชั่วคราว ถ้า redis ใช้ไม่ได้ยัง login ได้ตามปกติ
+func (i *service) Login(ctx context.Context, in LoginInput) (*domain.User, *Tokens, error) {
 	log := ctxlog.From(ctx)
 
 	if err := sanitizeLogin(in); err != nil {
 		log.Warn("interactor.auth.login.validate_input", zap.Error(err))
-		return nil, "", err
+		return nil, nil, err
 	}
 	username := strings.TrimSpace(in.Username)
 
+	status, err := i.guard.Check(ctx, username, in.IP)
+	if err != nil {
+		log.Warn("interactor.auth.login.guard_check.fail", zap.Error(err))
+	}
+	if status != nil {
+		if status.Locked() {
+			log.Warn("interactor.auth.login.locked", zap.String("username", username), zap.String("ip", in.IP), zap.Duration("locked_for", status.LockedFor))
+			return nil, nil, &LockedError{RetryAfter: status.LockedFor}
+		}
+		if err := sleep(ctx, status.Delay); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	// Find user by username
 	user, err := i.authRepo.GetByUsername(ctx, username)
 	if err != nil {
-		return nil, "", err
+		if errors.Is(err, apperror.ErrNotFound) {
+			// นับ username ที่ไม่มีอยู่ด้วย กันการไล่เดาทั้ง username และรหัสผ่านจาก IP เดียว
+			i.recordFailure(ctx, username, in.IP)
+		}
+		return nil, nil, err
 	}
 
 	// Check if user is active
 	if !user.IsActive {
 		log.Warn("interactor.auth.login.GetByUsername.user_not_active", zap.Uint("user_id", user.ID))
-		return nil, "", apperror.ErrUserForbidden
+		return nil, nil, apperror.ErrUserForbidden
 	}
 
 	// Verify password
 	if err := i.hash.CompareHashAndPassword(in.Password, user.Password); err != nil {
 		log.Warn("interactor.auth.login.CompareHashAndPassword.fail", zap.Error(err))
-		return nil, "", apperror.ErrUnauthorized
+		i.recordFailure(ctx, username, in.IP)
+		return nil, nil, apperror.ErrUnauthorized
 	}
 
 	// Generate token
 	token, _, err := i.tokens.GenerateToken(user.ID, user.Username, user.Role)
 	if err != nil {
 		log.Warn("interactor.auth.login.generate_token.fail", zap.Error(err))
-		return nil, "", apperror.ErrInternalServer
+		return nil, nil, apperror.ErrInternalServer
+	}
+
+	// refresh token ใบแรกของ family ใหม่
+	now := time.Now()
+	refresh, rt, err := i.newRefreshToken(now)
+	if err == nil {
+		rt.UserID = user.ID
+		rt.FamilyID, err = randomToken(16)
+	}
+	if err != nil {
+		log.Warn("interactor.auth.login.generate_refresh_token.fail", zap.Error(err))
+		return nil, nil, apperror.ErrInternalServer
+	}
+	if err := i.refreshTokens.Create(ctx, rt); err != nil {
+		return nil, nil, err
+	}
+
+	if err := i.guard.Reset(ctx, username, in.IP); err != nil {
+		log.Warn("interactor.auth.login.guard_reset.fail", zap.Error(err))
 	}
 
 	// reset password
 	user.Password = ""
 	log.Info("user.login", zap.Uint("user_id", user.ID), zap.String("username", username))
 
-	return user, token, nil
+	return user, &Tokens{AccessToken: token, RefreshToken: refresh, RefreshExpiresAt: rt.ExpiresAt}, nil
 }
 
-func (i *service) Logout(ctx context.Context, token string) error {
+// recordFailure นับ login ที่ผิด (best-effort) และ log เมื่อครั้งนี้ทำให้ถูกล็อก
+func (i *service) recordFailure(ctx context.Context, username, ip string) {
 	log := ctxlog.From(ctx)
 
-	if strings.TrimSpace(token) == "" {
+	status, err := i.guard.Fail(ctx, username, ip)
+	if err != nil {
+		log.Warn("interactor.auth.login.guard_fail.fail", zap.Error(err))
+		return
+	}
+	if status.Locked() {
+		log.Warn("user.login.locked_out", zap.String("username", username), zap.String("ip", ip),
+			zap.Int("failures", status.Failures), zap.Duration("locked_for", status.LockedFor))
+	}
+}
+
+func (i *service) Logout(ctx context.Context, accessToken, refreshToken string) error {
+	log := ctxlog.From(ctx)
+
+	// revoke refresh token ก่อน เพราะยังต้องทำได้แม้ access token หมดอายุแล้ว
+	if strings.TrimSpace(refreshToken) != "" {
+		if err := i.revokeRefreshFamily(ctx, refreshToken); err != nil {
+			return err
+		}
+	}
+
+	if strings.TrimSpace(accessToken) == "" {
 		log.Warn("interactor.auth.logout.missing_token")
 		return nil
 	}
 
-	ttl, err := i.tokens.GetExpiry(token)
+	ttl, err := i.tokens.GetExpiry(accessToken)
 	if err != nil || ttl <= 0 {
 		// ถ้า Token ผิด format หรือ parse ไม่ได้ ก็ถือว่า logout สำเร็จไปเลย (ไม่ต้องทำไรต่อ)
 		return nil
 	}
 
 	// Validate token to get expiry time
-	claims, err := i.tokens.ValidateToken(token)
+	claims, err := i.tokens.ValidateToken(accessToken)
 	if err != nil {
 		return fmt.Errorf("invalid token: %w", apperror.ErrInvalidToken)
 	}
@@ -205,3 +341,125 @@ func (i *service) Logout(ctx context.Context, token string) error {
 	log.Info("user.logout", zap.Uint("user_id", claims.UserID), zap.String("JWTID", claims.ID))
 	return nil
 }
+
+// revokeRefreshFamily ตัดทุก refresh token ที่ rotate มาจาก login ครั้งเดียวกัน token ที่ไม่รู้จักถือว่า revoke แล้ว
+func (i *service) revokeRefreshFamily(ctx context.Context, refreshToken string) error {
+	log := ctxlog.From(ctx)
+
+	rt, err := i.refreshTokens.GetByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if _, err := i.refreshTokens.RevokeFamily(ctx, rt.FamilyID, time.Now()); err != nil {
+		return err
+	}
+
+	log.Info("user.logout.refresh_revoked", zap.Uint("user_id", rt.UserID), zap.String("family_id", rt.FamilyID))
+	return nil
+}
+
+// Refresh: rotate refresh token -> โหลด user ล่าสุด (role/สถานะอาจเปลี่ยน) -> ออก access token ใหม่
+func (i *service) Refresh(ctx context.Context, refreshToken string) (*Tokens, error) {
+	log := ctxlog.From(ctx)
+
+	if strings.TrimSpace(refreshToken) == "" {
+		return nil, apperror.ErrInvalidToken
+	}
+
+	now := time.Now()
+	refresh, next, err := i.newRefreshToken(now)
+	if err != nil {
+		log.Warn("interactor.auth.refresh.generate_refresh_token.fail", zap.Error(err))
+		return nil, apperror.ErrInternalServer
+	}
+
+	tokenHash := hashToken(refreshToken)
+	used, err := i.refreshTokens.Rotate(ctx, tokenHash, next, now)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, i.rejectRefresh(ctx, tokenHash, now)
+		}
+		return nil, err
+	}
+
+	user, err := i.authRepo.GetByID(ctx, used.UserID)
+	if err == nil && !user.IsActive {
+		err = apperror.ErrUserForbidden
+	}
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) || errors.Is(err, apperror.ErrUserForbidden) {
+			// user ถูกลบหรือปิดใช้งานหลัง login: ตัดทั้ง family
+			log.Warn("interactor.auth.refresh.user_unavailable", zap.Uint("user_id", used.UserID), zap.Error(err))
+			if _, rerr := i.refreshTokens.RevokeFamily(ctx, used.FamilyID, now); rerr != nil {
+				log.Warn("interactor.auth.refresh.revoke_family.fail", zap.Error(rerr))
+			}
+			return nil, apperror.ErrUserForbidden
+		}
+		return nil, err
+	}
+
+	token, _, err := i.tokens.GenerateToken(user.ID, user.Username, user.Role)
+	if err != nil {
+		log.Warn("interactor.auth.refresh.generate_token.fail", zap.Error(err))
+		return nil, apperror.ErrInternalServer
+	}
+
+	log.Info("user.refresh", zap.Uint("user_id", user.ID), zap.String("family_id", used.FamilyID))
+	return &Tokens{AccessToken: token, RefreshToken: refresh, RefreshExpiresAt: next.ExpiresAt}, nil
+}
+
+// rejectRefresh หาสาเหตุที่ rotate ไม่ได้ ถ้าเป็นใบที่เคยใช้ไปแล้วแปลว่า token รั่ว
+// (ทั้งเจ้าของและผู้โจมตีถือใบเดียวกัน) จึง revoke ทั้ง family ให้ต้อง login ใหม่
+func (i *service) rejectRefresh(ctx context.Context, tokenHash string, now time.Time) error {
+	log := ctxlog.From(ctx)
+
+	rt, err := i.refreshTokens.GetByHash(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			log.Warn("interactor.auth.refresh.unknown_token")
+			return apperror.ErrInvalidToken
+		}
+		return err
+	}
+
+	switch {
+	case rt.RevokedAt != nil:
+		log.Warn("interactor.auth.refresh.revoked", zap.Uint("user_id", rt.UserID), zap.String("family_id", rt.FamilyID))
+		return apperror.ErrInvalidToken
+	case rt.UsedAt != nil:
+		log.Warn("interactor.auth.refresh.reuse_detected", zap.Uint("user_id", rt.UserID), zap.String("family_id", rt.FamilyID))
+		if _, err := i.refreshTokens.RevokeFamily(ctx, rt.FamilyID, now); err != nil {
+			return err
+		}
+		return apperror.ErrInvalidToken
+	default:
+		return apperror.ErrTokenExpired
+	}
+}
+
+// UnlockUser ล้าง lock และ counter ของบัญชี (lock ของ IP ยังอยู่จนหมดเวลา)
+func (i *service) UnlockUser(ctx context.Context, userID uint) error {
+	log := ctxlog.From(ctx)
+
+	user, err := i.authRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := i.guard.Unlock(ctx, user.Username); err != nil {
+		log.Warn("interactor.auth.unlock.fail", zap.Uint("user_id", userID), zap.Error(err))
+		return apperror.ErrInternalServer
+	}
+
+	i.auditor.Record(ctx, audit.Entry{
+		EntityType: audit.EntityUser,
+		EntityID:   user.ID,
+		Action:     domain.AuditUnlock,
+		Changes:    map[string]audit.Change{"locked": {Old: true, New: false}},
+	})
+	log.Info("user.unlocked", zap.Uint("user_id", user.ID), zap.String("username", user.Username))
+	return nil
+}
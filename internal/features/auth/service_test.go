@@ -4,6 +4,7 @@ import (
 	"ans-spareparts-api/internal/domain"
 	"ans-spareparts-api/internal/features/auth"
 	"ans-spareparts-api/internal/infra/jwtx"
+	"ans-spareparts-api/internal/infra/loginguard"
 	mocks "ans-spareparts-api/internal/mock"
 	"ans-spareparts-api/pkg/apperror"
 	"ans-spareparts-api/pkg/testutil/fixtures"
@@ -21,6 +22,9 @@ type TestSuite struct {
 	MockUserRepo *mocks.UserRepository
 	MockHash     *mocks.Hasher
 	MockToken    *mocks.TokenIssuer
+	MockAudit    *mocks.AuditService
+	MockGuard    *mocks.LoginGuard
+	MockRefresh  *mocks.RefreshTokenRepository
 	Service      auth.Service
 	Ctx          context.Context
 }
@@ -33,13 +37,21 @@ func (ts *TestSuite) SetupTestSuite(t *testing.T) {
 	ts.MockHash = mocks.NewHasher()
 	ts.MockToken = mocks.NewTokenIssuer()
 	ts.MockUserRepo = mocks.NewMockUserRepository()
-	ts.Service = auth.NewService(ts.MockUserRepo, ts.MockToken, ts.MockHash, "cashier")
+	// audit เป็น best-effort: ยอมรับทุกการเรียก แล้วตรวจผ่าน MockAudit.Calls ใน test ที่สนใจ
+	ts.MockAudit = mocks.NewAuditService()
+	ts.MockAudit.On("Record", mock.Anything, mock.Anything).Maybe()
+	ts.MockGuard = mocks.NewLoginGuard()
+	ts.MockRefresh = mocks.NewMockRefreshTokenRepository()
+	ts.Service = auth.NewService(ts.MockUserRepo, ts.MockToken, ts.MockHash, "cashier", ts.MockAudit, ts.MockGuard,
+		ts.MockRefresh, 24*time.Hour)
 	ts.Ctx = context.Background()
 
 	t.Cleanup(func() {
 		ts.MockHash.AssertExpectations(t)
 		ts.MockToken.AssertExpectations(t)
 		ts.MockUserRepo.AssertExpectations(t)
+		ts.MockGuard.AssertExpectations(t)
+		ts.MockRefresh.AssertExpectations(t)
 	})
 }
 
@@ -170,42 +182,52 @@ func TestAuthService_Login(t *testing.T) {
 	input := auth.LoginInput{
 		Username: mockUser.Username,
 		Password: mockUser.Password,
+		IP:       "10.0.0.1",
 	}
 	tests := []struct {
 		name      string
 		in        auth.LoginInput
 		setup     func(ts *TestSuite)
 		assertErr func(*testing.T, error)
-		validate  func(*testing.T, *domain.User, string)
+		validate  func(*testing.T, *domain.User, *auth.Tokens)
 	}{
 		{
 			name: "success",
 			in:   input,
 			setup: func(ts *TestSuite) {
+				ts.MockGuard.On("Check", ts.Ctx, input.Username, input.IP).Return(&loginguard.Status{}, nil).Once()
 				ts.MockUserRepo.On("GetByUsername", ts.Ctx, input.Username).Return(mockUser, nil)
 				ts.MockHash.On("CompareHashAndPassword", mockUser.Password, "P@ssword1234").Return(nil)
 				ts.MockToken.On("GenerateToken", mockUser.ID, mockUser.Username, mockUser.Role).Return("token1234", "jwtID", nil)
+				ts.MockRefresh.On("Create", ts.Ctx, mock.MatchedBy(func(rt *domain.RefreshToken) bool {
+					return rt.UserID == mockUser.ID && rt.FamilyID != "" && len(rt.TokenHash) == 64
+				})).Return(nil).Once()
+				ts.MockGuard.On("Reset", ts.Ctx, input.Username, input.IP).Return(nil).Once()
 			},
 			assertErr: func(t *testing.T, err error) {
 				assert.NoError(t, err)
 			},
-			validate: func(t *testing.T, u *domain.User, token string) {
+			validate: func(t *testing.T, u *domain.User, tk *auth.Tokens) {
 				assert.NotNil(t, u)
 				assert.Equal(t, input.Username, u.Username)
-				assert.Equal(t, "token1234", token)
+				assert.Equal(t, "token1234", tk.AccessToken)
 				assert.Empty(t, u.Password)
+				assert.NotEmpty(t, tk.RefreshToken)
+				assert.WithinDuration(t, time.Now().Add(24*time.Hour), tk.RefreshExpiresAt, time.Minute)
 			},
 		},
 		{
 			name: "user not found",
 			in:   input,
 			setup: func(ts *TestSuite) {
+				ts.MockGuard.On("Check", ts.Ctx, input.Username, input.IP).Return(&loginguard.Status{}, nil).Once()
 				ts.MockUserRepo.On("GetByUsername", ts.Ctx, input.Username).Return(nil, apperror.ErrNotFound)
+				ts.MockGuard.On("Fail", ts.Ctx, input.Username, input.IP).Return(&loginguard.Status{Failures: 1}, nil).Once()
 			},
 			assertErr: func(t *testing.T, err error) {
 				assert.ErrorIs(t, err, apperror.ErrNotFound)
 			},
-			validate: func(t *testing.T, u *domain.User, s string) {
+			validate: func(t *testing.T, u *domain.User, tk *auth.Tokens) {
 				assert.Nil(t, u)
 			},
 		},
@@ -215,12 +237,13 @@ func TestAuthService_Login(t *testing.T) {
 			setup: func(ts *TestSuite) {
 				newUser := fixtures.ValidUser()
 				newUser.IsActive = false
+				ts.MockGuard.On("Check", ts.Ctx, input.Username, input.IP).Return(&loginguard.Status{}, nil).Once()
 				ts.MockUserRepo.On("GetByUsername", ts.Ctx, mockUser.Username).Return(newUser, nil)
 			},
 			assertErr: func(t *testing.T, err error) {
 				assert.ErrorIs(t, err, apperror.ErrUserForbidden)
 			},
-			validate: func(t *testing.T, u *domain.User, s string) {
+			validate: func(t *testing.T, u *domain.User, tk *auth.Tokens) {
 				assert.Nil(t, u)
 			},
 		},
@@ -228,14 +251,16 @@ func TestAuthService_Login(t *testing.T) {
 			name: "wrong password",
 			in:   input,
 			setup: func(ts *TestSuite) {
+				ts.MockGuard.On("Check", ts.Ctx, input.Username, input.IP).Return(&loginguard.Status{}, nil).Once()
 				ts.MockUserRepo.On("GetByUsername", ts.Ctx, mockUser.Username).Return(mockUser, nil)
 				ts.MockHash.On("CompareHashAndPassword", input.Password, mockUser.Password).Return(apperror.ErrInvalidInput)
+				ts.MockGuard.On("Fail", ts.Ctx, input.Username, input.IP).Return(&loginguard.Status{Failures: 5, LockedFor: 15 * time.Minute}, nil).Once()
 
 			},
 			assertErr: func(t *testing.T, err error) {
 				assert.ErrorIs(t, err, apperror.ErrUnauthorized)
 			},
-			validate: func(t *testing.T, u *domain.User, s string) {
+			validate: func(t *testing.T, u *domain.User, tk *auth.Tokens) {
 				assert.Nil(t, u)
 			},
 		},
@@ -243,6 +268,7 @@ func TestAuthService_Login(t *testing.T) {
 			name: "genterate token fails",
 			in:   input,
 			setup: func(ts *TestSuite) {
+				ts.MockGuard.On("Check", ts.Ctx, input.Username, input.IP).Return(&loginguard.Status{}, nil).Once()
 				ts.MockUserRepo.On("GetByUsername", ts.Ctx, input.Username).Return(mockUser, nil)
 				ts.MockHash.On("CompareHashAndPassword", input.Password, mockUser.Password).Return(nil)
 				ts.MockToken.On("GenerateToken", mockUser.ID, mockUser.Username, mockUser.Role).Return("", "", apperror.ErrInvalidInput)
@@ -251,10 +277,68 @@ func TestAuthService_Login(t *testing.T) {
 			assertErr: func(t *testing.T, err error) {
 				assert.ErrorIs(t, err, apperror.ErrInternalServer)
 			},
-			validate: func(t *testing.T, u *domain.User, s string) {
+			validate: func(t *testing.T, u *domain.User, tk *auth.Tokens) {
 				assert.Nil(t, u)
 			},
 		},
+		{
+			name: "locked",
+			in:   input,
+			setup: func(ts *TestSuite) {
+				ts.MockGuard.On("Check", ts.Ctx, input.Username, input.IP).Return(&loginguard.Status{Failures: 5, LockedFor: 90 * time.Second}, nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrAccountLocked)
+				var locked *auth.LockedError
+				if assert.ErrorAs(t, err, &locked) {
+					assert.Equal(t, 90*time.Second, locked.RetryAfter)
+				}
+			},
+			validate: func(t *testing.T, u *domain.User, tk *auth.Tokens) {
+				assert.Nil(t, u)
+				assert.Nil(t, tk)
+			},
+		},
+		{
+			name: "success after delay",
+			in:   input,
+			setup: func(ts *TestSuite) {
+				ts.MockGuard.On("Check", ts.Ctx, input.Username, input.IP).Return(&loginguard.Status{Failures: 1, Delay: time.Millisecond}, nil).Once()
+				ts.MockUserRepo.On("GetByUsername", ts.Ctx, input.Username).Return(fixtures.ValidUser(), nil)
+				ts.MockHash.On("CompareHashAndPassword", input.Password, mock.Anything).Return(nil)
+				ts.MockToken.On("GenerateToken", mockUser.ID, mockUser.Username, mockUser.Role).Return("token1234", "jwtID", nil)
+				ts.MockRefresh.On("Create", ts.Ctx, mock.MatchedBy(func(rt *domain.RefreshToken) bool {
+					return rt.UserID == mockUser.ID && rt.FamilyID != "" && len(rt.TokenHash) == 64
+				})).Return(nil).Once()
+				ts.MockGuard.On("Reset", ts.Ctx, input.Username, input.IP).Return(nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, u *domain.User, tk *auth.Tokens) {
+				assert.Equal(t, "token1234", tk.AccessToken)
+			},
+		},
+		{
+			name: "guard unavailable still logs in",
+			in:   input,
+			setup: func(ts *TestSuite) {
+				ts.MockGuard.On("Check", ts.Ctx, input.Username, input.IP).Return(nil, errors.New("redis down")).Once()
+				ts.MockUserRepo.On("GetByUsername", ts.Ctx, input.Username).Return(fixtures.ValidUser(), nil)
+				ts.MockHash.On("CompareHashAndPassword", input.Password, mock.Anything).Return(nil)
+				ts.MockToken.On("GenerateToken", mockUser.ID, mockUser.Username, mockUser.Role).Return("token1234", "jwtID", nil)
+				ts.MockRefresh.On("Create", ts.Ctx, mock.MatchedBy(func(rt *domain.RefreshToken) bool {
+					return rt.UserID == mockUser.ID && rt.FamilyID != "" && len(rt.TokenHash) == 64
+				})).Return(nil).Once()
+				ts.MockGuard.On("Reset", ts.Ctx, input.Username, input.IP).Return(errors.New("redis down")).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, u *domain.User, tk *auth.Tokens) {
+				assert.Equal(t, "token1234", tk.AccessToken)
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -263,10 +347,10 @@ func TestAuthService_Login(t *testing.T) {
 			ts.SetupTestSuite(t)
 
 			test.setup(ts)
-			user, token, err := ts.Service.Login(ts.Ctx, test.in)
+			user, tokens, err := ts.Service.Login(ts.Ctx, test.in)
 
 			test.assertErr(t, err)
-			test.validate(t, user, token)
+			test.validate(t, user, tokens)
 		})
 	}
 }
@@ -283,10 +367,13 @@ func TestAuthService_Logout(t *testing.T) {
 		},
 	}
 	validTTL := time.Hour
+	refreshToken := "opaque-refresh-token"
+	family := &domain.RefreshToken{ID: 3, UserID: 1, FamilyID: "fam-1"}
 
 	tests := []struct {
 		name      string
 		token     string
+		refresh   string
 		setup     func(*TestSuite)
 		assertErr func(*testing.T, error)
 	}{
@@ -326,6 +413,41 @@ func TestAuthService_Logout(t *testing.T) {
 				assert.NoError(t, err)
 			},
 		},
+		{
+			name:    "Success_Refresh_Family_Revoked",
+			token:   " ",
+			refresh: refreshToken,
+			setup: func(ts *TestSuite) {
+				ts.MockRefresh.On("GetByHash", ts.Ctx, mock.AnythingOfType("string")).Return(family, nil).Once()
+				ts.MockRefresh.On("RevokeFamily", ts.Ctx, "fam-1", mock.Anything).Return(int64(2), nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:    "Success_Unknown_Refresh_Token",
+			token:   " ",
+			refresh: refreshToken,
+			setup: func(ts *TestSuite) {
+				ts.MockRefresh.On("GetByHash", ts.Ctx, mock.AnythingOfType("string")).Return(nil, apperror.ErrNotFound).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:    "Error_Revoke_Fails",
+			token:   validToken,
+			refresh: refreshToken,
+			setup: func(ts *TestSuite) {
+				ts.MockRefresh.On("GetByHash", ts.Ctx, mock.AnythingOfType("string")).Return(family, nil).Once()
+				ts.MockRefresh.On("RevokeFamily", ts.Ctx, "fam-1", mock.Anything).Return(int64(0), apperror.ErrInternalServer).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInternalServer)
+			},
+		},
 		{
 			name: "Success_Token_Expired_Or_InvalidTTL",
 			token: validToken,
@@ -345,10 +467,182 @@ func TestAuthService_Logout(t *testing.T) {
 			ts.SetupTestSuite(t)
 
 			test.setup(ts)
-			err := ts.Service.Logout(ts.Ctx, test.token)
+			err := ts.Service.Logout(ts.Ctx, test.token, test.refresh)
+
+			test.assertErr(t, err)
+		})
+	}
+
+}
+
+// --- UnlockUser ---
+func TestAuthService_UnlockUser(t *testing.T) {
+	mockUser := fixtures.ValidUser()
+
+	tests := []struct {
+		name      string
+		setup     func(ts *TestSuite)
+		assertErr func(*testing.T, error)
+	}{
+		{
+			name: "success",
+			setup: func(ts *TestSuite) {
+				ts.MockUserRepo.On("GetByID", ts.Ctx, mockUser.ID).Return(mockUser, nil).Once()
+				ts.MockGuard.On("Unlock", ts.Ctx, mockUser.Username).Return(nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "user not found",
+			setup: func(ts *TestSuite) {
+				ts.MockUserRepo.On("GetByID", ts.Ctx, mockUser.ID).Return(nil, apperror.ErrNotFound).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrNotFound)
+			},
+		},
+		{
+			name: "guard fails",
+			setup: func(ts *TestSuite) {
+				ts.MockUserRepo.On("GetByID", ts.Ctx, mockUser.ID).Return(mockUser, nil).Once()
+				ts.MockGuard.On("Unlock", ts.Ctx, mockUser.Username).Return(errors.New("redis down")).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInternalServer)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewTestSuite()
+			ts.SetupTestSuite(t)
+
+			test.setup(ts)
+			err := ts.Service.UnlockUser(ts.Ctx, mockUser.ID)
 
 			test.assertErr(t, err)
 		})
 	}
+}
+
+// --- Refresh ---
+func TestAuthService_Refresh(t *testing.T) {
+	refreshToken := "opaque-refresh-token"
+	now := time.Now()
+	used := &domain.RefreshToken{ID: 3, UserID: 1, FamilyID: "fam-1", UsedAt: &now}
 
+	tests := []struct {
+		name      string
+		token     string
+		setup     func(ts *TestSuite)
+		assertErr func(*testing.T, error)
+		validate  func(*testing.T, *auth.Tokens)
+	}{
+		{
+			name:  "success rotated",
+			token: refreshToken,
+			setup: func(ts *TestSuite) {
+				ts.MockRefresh.On("Rotate", ts.Ctx, mock.AnythingOfType("string"), mock.MatchedBy(func(next *domain.RefreshToken) bool {
+					return len(next.TokenHash) == 64 && next.ExpiresAt.After(now)
+				}), mock.Anything).Return(used, nil).Once()
+				ts.MockUserRepo.On("GetByID", ts.Ctx, uint(1)).Return(fixtures.ValidUser(), nil).Once()
+				ts.MockToken.On("GenerateToken", uint(1), "testUser", "cashier").Return("access-2", "jwtID", nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, tk *auth.Tokens) {
+				assert.Equal(t, "access-2", tk.AccessToken)
+				assert.NotEmpty(t, tk.RefreshToken)
+				assert.NotEqual(t, refreshToken, tk.RefreshToken)
+			},
+		},
+		{
+			name:  "reuse of used token revokes family",
+			token: refreshToken,
+			setup: func(ts *TestSuite) {
+				ts.MockRefresh.On("Rotate", ts.Ctx, mock.AnythingOfType("string"), mock.Anything, mock.Anything).Return(nil, apperror.ErrNotFound).Once()
+				ts.MockRefresh.On("GetByHash", ts.Ctx, mock.AnythingOfType("string")).Return(used, nil).Once()
+				ts.MockRefresh.On("RevokeFamily", ts.Ctx, "fam-1", mock.Anything).Return(int64(2), nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidToken)
+			},
+		},
+		{
+			name:  "revoked token",
+			token: refreshToken,
+			setup: func(ts *TestSuite) {
+				ts.MockRefresh.On("Rotate", ts.Ctx, mock.AnythingOfType("string"), mock.Anything, mock.Anything).Return(nil, apperror.ErrNotFound).Once()
+				ts.MockRefresh.On("GetByHash", ts.Ctx, mock.AnythingOfType("string")).Return(&domain.RefreshToken{FamilyID: "fam-1", UsedAt: &now, RevokedAt: &now}, nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidToken)
+			},
+		},
+		{
+			name:  "expired token",
+			token: refreshToken,
+			setup: func(ts *TestSuite) {
+				ts.MockRefresh.On("Rotate", ts.Ctx, mock.AnythingOfType("string"), mock.Anything, mock.Anything).Return(nil, apperror.ErrNotFound).Once()
+				ts.MockRefresh.On("GetByHash", ts.Ctx, mock.AnythingOfType("string")).Return(&domain.RefreshToken{FamilyID: "fam-1", ExpiresAt: now.Add(-time.Hour)}, nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrTokenExpired)
+			},
+		},
+		{
+			name:  "unknown token",
+			token: refreshToken,
+			setup: func(ts *TestSuite) {
+				ts.MockRefresh.On("Rotate", ts.Ctx, mock.AnythingOfType("string"), mock.Anything, mock.Anything).Return(nil, apperror.ErrNotFound).Once()
+				ts.MockRefresh.On("GetByHash", ts.Ctx, mock.AnythingOfType("string")).Return(nil, apperror.ErrNotFound).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidToken)
+			},
+		},
+		{
+			name:  "inactive user revokes family",
+			token: refreshToken,
+			setup: func(ts *TestSuite) {
+				inactive := fixtures.ValidUser()
+				inactive.IsActive = false
+				ts.MockRefresh.On("Rotate", ts.Ctx, mock.AnythingOfType("string"), mock.Anything, mock.Anything).Return(used, nil).Once()
+				ts.MockUserRepo.On("GetByID", ts.Ctx, uint(1)).Return(inactive, nil).Once()
+				ts.MockRefresh.On("RevokeFamily", ts.Ctx, "fam-1", mock.Anything).Return(int64(2), nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrUserForbidden)
+			},
+		},
+		{
+			name:  "empty token",
+			token: " ",
+			setup: func(ts *TestSuite) {},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidToken)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewTestSuite()
+			ts.SetupTestSuite(t)
+
+			test.setup(ts)
+			tokens, err := ts.Service.Refresh(ts.Ctx, test.token)
+
+			test.assertErr(t, err)
+			if test.validate != nil {
+				test.validate(t, tokens)
+			} else {
+				assert.Nil(t, tokens)
+			}
+		})
+	}
 }
@@ -1,31 +1,75 @@
 package category
 
+import (
+	"ans-spareparts-api/pkg/cursor"
+	"ans-spareparts-api/pkg/query"
+	"time"
+)
+
+// listSchema field ที่กรอง/เรียงได้ของ GET /categories
+var listSchema = query.Schema{
+	Fields: map[string]query.Field{
+		"id":   {Column: "id", Type: query.Int, Ops: []query.Op{query.OpEq, query.OpIn}, Sortable: true},
+		"name": {Column: "name", Type: query.String, Ops: []query.Op{query.OpEq, query.OpLike}, Sortable: true},
+	},
+	DefaultSort: []query.Sort{{Column: "name"}},
+}
+
 type ListQuery struct {
-	Search string
-	Limit  int
-	Offset int
-	Sort   string
+	Search  string
+	Limit   int
+	Offset  int
+	Sort    []query.Sort
+	Filters []query.Filter
+
+	// Keyset ใช้ cursor แทน offset; After เป็น nil คือหน้าแรก
+	Keyset bool
+	After  *cursor.Cursor
 }
 
 type Item struct {
-	ID   uint
-	Name string
+	ID      uint
+	Name    string
+	Version uint
 }
 type ListOutput struct {
 	Items []*Item
 	Total int64
+	// NextCursor มีเฉพาะโหมด Keyset
+	NextCursor string
 }
 
 type CategoryRequest struct {
 	Name string `json:"name"`
+	// Version ใช้ตอนแก้ไขแทน header If-Match ได้ (สร้างใหม่ไม่ใช้)
+	Version *uint `json:"version,omitempty"`
 }
 
 type CategoryResponse struct {
-	ID   uint
-	Name string
+	ID      uint
+	Name    string
+	Version uint
 }
 
 type CategoryListResponse struct {
 	Categories []*CategoryResponse
 	Total      int64
 }
+
+// TrashItem หมวดหมู่ในถังขยะ
+type TrashItem struct {
+	ID        uint
+	Name      string
+	DeletedAt time.Time
+}
+
+type TrashOutput struct {
+	Items []*TrashItem
+	Total int64
+}
+
+type TrashCategoryResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
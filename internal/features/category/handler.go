@@ -4,9 +4,13 @@ import (
 	"ans-spareparts-api/internal/infra/httpx/ctxlog"
 	"ans-spareparts-api/internal/infra/jwtx"
 	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/httpcache"
+	"ans-spareparts-api/pkg/query"
 	"ans-spareparts-api/pkg/response"
+	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
@@ -70,8 +74,9 @@ func (h *Handler) CreateCategory(c *fiber.Ctx) error {
 	}
 
 	return response.Created(c, CategoryResponse{
-		ID:   category.ID,
-		Name: category.Name,
+		ID:      category.ID,
+		Name:    category.Name,
+		Version: category.Version,
 	})
 }
 
@@ -82,7 +87,9 @@ func (h *Handler) CreateCategory(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Category ID"
+// @Param If-None-Match header string false "ETag from a previous response"
 // @Success 200 {object} CategoryResponse
+// @Success 304 "Not Modified"
 // @Failure 400 {object} response.ErrorBody
 // @Failure 404 {object} response.ErrorBody
 // @Security BearerAuth
@@ -111,9 +118,14 @@ func (h *Handler) GetCategory(c *fiber.Ctx) error {
 
 	}
 
+	if httpcache.Fresh(c, httpcache.VersionTag(category.Version), time.Time{}) {
+		return httpcache.NotModified(c)
+	}
+
 	return response.OK(c, CategoryResponse{
-		ID: category.ID,
-		Name: category.Name,
+		ID:      category.ID,
+		Name:    category.Name,
+		Version: category.Version,
 	})
 }
 
@@ -131,26 +143,22 @@ func (h *Handler) List(c *fiber.Ctx) error {
 	ctx := c.UserContext()
 	log := ctxlog.From(ctx)
 
-	limit, err := strconv.Atoi(c.Query("limit", "10"))
-	if err != nil {
-		log.Warn("handler.category.list.invalid_input.limit", zap.Error(err))
-		return response.Error(
-			c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid limit request",
-		)
-	}
-	offset, err := strconv.Atoi(c.Query("offset", "0"))
+	p, err := query.FromCtx(c, listSchema)
 	if err != nil {
-		log.Warn("handler.category.list.invalid_input.offset", zap.Error(err))
+		log.Warn("handler.category.list.invalid_input", zap.Error(err))
 		return response.Error(
-			c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid offset request",
+			c, fiber.StatusBadRequest, "BAD_REQUEST", err.Error(),
 		)
 	}
-	search := c.Query("search", "")
 
 	out, err := h.categoryService.List(ctx, ListQuery{
-		Limit:  limit,
-		Offset: offset,
-		Search: search,
+		Limit:   p.Limit,
+		Offset:  p.Offset,
+		Search:  p.Search,
+		Sort:    p.Sort,
+		Filters: p.Filters,
+		Keyset:  p.Keyset,
+		After:   p.After,
 	})
 	if err != nil {
 		return response.Error(
@@ -161,11 +169,15 @@ func (h *Handler) List(c *fiber.Ctx) error {
 	items := make([]*CategoryResponse, len(out.Items))
 	for index, u := range out.Items {
 		items[index] = &CategoryResponse{
-			ID:   u.ID,
-			Name: u.Name,
+			ID:      u.ID,
+			Name:    u.Name,
+			Version: u.Version,
 		}
 	}
 
+	if p.Keyset {
+		return response.CursorPage(c, items, p.Limit, out.NextCursor)
+	}
 	return response.OK(c, CategoryListResponse{Categories: items, Total: out.Total})
 }
 
@@ -176,11 +188,15 @@ func (h *Handler) List(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Category ID"
-// @Param category body map[string]interface{} true "Category update data"
+// @Param category body CategoryRequest true "Category update data"
+// @Param If-Match header string false "ETag from GET (or send version in the body)"
 // @Success 200 {object} CategoryResponse
 // @Failure 400 {object} response.ErrorBody
 // @Failure 403 {object} response.ErrorBody
 // @Failure 404 {object} response.ErrorBody
+// @Failure 409 {object} response.ErrorBody
+// @Failure 412 {object} response.ErrorBody
+// @Failure 428 {object} response.ErrorBody
 // @Security BearerAuth
 // @Router /category/{id} [put]
 func (h *Handler) UpdateCategory(c *fiber.Ctx) error {
@@ -214,8 +230,21 @@ func (h *Handler) UpdateCategory(c *fiber.Ctx) error {
 		)
 	}
 
+	// ต้องบอก version ที่อ่านไป (If-Match หรือ field version) กันแก้ทับกัน
+	version, fromHeader, ok := httpcache.ExpectedVersion(c, reqBody.Version)
+	if !ok {
+		return response.Error(
+			c, fiber.StatusPreconditionRequired, "PRECONDITION_REQUIRED", "If-Match header or version is required",
+		)
+	}
+	reqBody.Version = &version
+
 	category, err := h.categoryService.UpdateCategory(ctx, uint(categoryID), reqBody)
 	if err != nil {
+		if errors.Is(err, apperror.ErrVersionConflict) {
+			status, code := httpcache.ConflictStatus(fromHeader)
+			return response.Error(c, status, code, "category was modified by another user")
+		}
 		if err == apperror.ErrNotFound {
 			return response.Error(
 				c, fiber.StatusNotFound, "NOT_FOUND", "category not found",
@@ -226,9 +255,11 @@ func (h *Handler) UpdateCategory(c *fiber.Ctx) error {
 		)
 	}
 
+	c.Set(fiber.HeaderETag, httpcache.VersionTag(category.Version))
 	return response.OK(c, CategoryResponse{
-		ID: category.ID,
-		Name: category.Name,
+		ID:      category.ID,
+		Name:    category.Name,
+		Version: category.Version,
 	})
 }
 
@@ -288,3 +319,86 @@ func (h *Handler) DeleteCategory(c *fiber.Ctx) error {
 
 	return response.NoContent(c)
 }
+
+// ListTrash godoc
+// @Summary List deleted categories
+// @Description List soft-deleted categories, most recently deleted first (manager only)
+// @Tags trash
+// @Produce json
+// @Param limit query int false "Page size"
+// @Param offset query int false "Offset"
+// @Success 200 {array} TrashCategoryResponse
+// @Failure 400 {object} response.ErrorBody
+// @Failure 403 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /trash/categories [get]
+func (h *Handler) ListTrash(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+
+	// ถังขยะไม่มี filter/sort ใช้แค่ limit/offset
+	p, err := query.FromCtx(c, query.Schema{})
+	if err != nil {
+		log.Warn("handler.category.list_trash.invalid_input", zap.Error(err))
+		return response.Error(
+			c, fiber.StatusBadRequest, "BAD_REQUEST", err.Error(),
+		)
+	}
+
+	out, err := h.categoryService.ListTrash(ctx, p.Limit, p.Offset)
+	if err != nil {
+		return response.Error(
+			c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "internal server occured",
+		)
+	}
+
+	items := make([]*TrashCategoryResponse, len(out.Items))
+	for index, it := range out.Items {
+		items[index] = &TrashCategoryResponse{
+			ID:        it.ID,
+			Name:      it.Name,
+			DeletedAt: it.DeletedAt,
+		}
+	}
+
+	return response.Page(c, items, out.Total, p.Limit, p.Offset)
+}
+
+// Restore godoc
+// @Summary Restore deleted category
+// @Description Restore a soft-deleted category (manager only)
+// @Tags trash
+// @Produce json
+// @Param id path int true "Category ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} response.ErrorBody
+// @Failure 403 {object} response.ErrorBody
+// @Failure 404 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /trash/categories/{id}/restore [post]
+func (h *Handler) Restore(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Warn("handler.category.restore.invalid_id", zap.Error(err))
+		return response.Error(
+			c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid category id",
+		)
+	}
+
+	err = h.categoryService.RestoreCategory(ctx, uint(id))
+	switch {
+	case err == nil:
+		return response.NoContent(c)
+	case errors.Is(err, apperror.ErrNotFound):
+		return response.Error(
+			c, fiber.StatusNotFound, "NOT_FOUND", "deleted category not found",
+		)
+	default:
+		return response.Error(
+			c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "internal server occured",
+		)
+	}
+}
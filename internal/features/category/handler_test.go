@@ -77,8 +77,9 @@ func TestCategoryHandler_CreateCategory(t *testing.T) {
 			},
 			expectedStatus: fiber.StatusCreated,
 			expectedBody: fiber.Map{
-				"ID":   1,
-				"Name": "Wheel",
+				"ID":      1,
+				"Name":    "Wheel",
+				"Version": 0,
 			},
 		},
 		{
@@ -193,8 +194,9 @@ func TestCategoryHandler_GetCategory(t *testing.T) {
 		Name: validCategory.Name,
 	}
 	mockResponse := fiber.Map{
-		"ID":   1,
-		"Name": "Wheel",
+		"ID":      1,
+		"Name":    "Wheel",
+		"Version": 0,
 	}
 
 	tests := []struct {
@@ -282,21 +284,26 @@ func TestCategoryHandler_GetCategory(t *testing.T) {
 func TestCategoryHandler_UpdateCategory(t *testing.T) {
 	validCategory := fixtures.ValidCategory()
 	expectedService := &category.Item{
-		ID:   validCategory.ID,
-		Name: validCategory.Name,
+		ID:      validCategory.ID,
+		Name:    validCategory.Name,
+		Version: 2,
 	}
+	version := uint(1)
 	mockRequest := category.CategoryRequest{
-		Name: "Wheel",
+		Name:    "Wheel",
+		Version: &version,
 	}
 	expectedBody := fiber.Map{
-		"ID":   1,
-		"Name": "Wheel",
+		"ID":      1,
+		"Name":    "Wheel",
+		"Version": 2,
 	}
 
 	tests := []struct {
 		name           string
 		userRole       string
 		path           string
+		ifMatch        string
 		setup          func(*HandlerTestSuite)
 		requestBody    interface{}
 		expectedStatus int
@@ -313,6 +320,59 @@ func TestCategoryHandler_UpdateCategory(t *testing.T) {
 			expectedStatus: fiber.StatusOK,
 			expectedBody:   expectedBody,
 		},
+		{
+			name:     "Success_Update_Category_IfMatch",
+			userRole: "manager",
+			path:     "/categories/1",
+			ifMatch:  `"1"`,
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("UpdateCategory", mock.Anything, uint(1), mockRequest).Return(expectedService, nil).Once()
+			},
+			requestBody:    category.CategoryRequest{Name: "Wheel"},
+			expectedStatus: fiber.StatusOK,
+			expectedBody:   expectedBody,
+		},
+		{
+			name:           "Error_PreconditionRequired_No_Version",
+			userRole:       "manager",
+			path:           "/categories/1",
+			setup:          func(hts *HandlerTestSuite) {},
+			requestBody:    category.CategoryRequest{Name: "Wheel"},
+			expectedStatus: fiber.StatusPreconditionRequired,
+			expectedBody: fiber.Map{
+				"code":    "PRECONDITION_REQUIRED",
+				"message": "If-Match header or version is required",
+			},
+		},
+		{
+			name:     "Error_PreconditionFailed_IfMatch_Stale",
+			userRole: "manager",
+			path:     "/categories/1",
+			ifMatch:  `"1"`,
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("UpdateCategory", mock.Anything, uint(1), mockRequest).Return(nil, apperror.ErrVersionConflict).Once()
+			},
+			requestBody:    category.CategoryRequest{Name: "Wheel"},
+			expectedStatus: fiber.StatusPreconditionFailed,
+			expectedBody: fiber.Map{
+				"code":    "PRECONDITION_FAILED",
+				"message": "category was modified by another user",
+			},
+		},
+		{
+			name:     "Error_Conflict_Body_Version_Stale",
+			userRole: "manager",
+			path:     "/categories/1",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("UpdateCategory", mock.Anything, uint(1), mockRequest).Return(nil, apperror.ErrVersionConflict).Once()
+			},
+			requestBody:    mockRequest,
+			expectedStatus: fiber.StatusConflict,
+			expectedBody: fiber.Map{
+				"code":    "CONFLICT",
+				"message": "category was modified by another user",
+			},
+		},
 		{
 			name:           "Error_Forbidden_By_Cashier",
 			userRole:       "cashier",
@@ -411,6 +471,9 @@ func TestCategoryHandler_UpdateCategory(t *testing.T) {
 			// สร้าง Request
 			req := httptest.NewRequest(fiber.MethodPut, test.path, bytes.NewBuffer(body))
 			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			if test.ifMatch != "" {
+				req.Header.Set(fiber.HeaderIfMatch, test.ifMatch)
+			}
 
 			// Run test
 			res, _ := ts.App.Test(req, -1)
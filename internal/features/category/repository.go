@@ -3,8 +3,13 @@ package category
 import (
 	"ans-spareparts-api/internal/domain"
 	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/internal/infra/outbox"
 	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/cursor"
+	"ans-spareparts-api/pkg/query"
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -21,6 +26,12 @@ type Repository interface {
 	List(ctx context.Context, q ListQuery) ([]*domain.Category, int64, error)
 	GetByID(ctx context.Context, id uint) (*domain.Category, error)
 	GetByName(ctx context.Context, name string) (*domain.Category, error)
+
+	// ListDeleted หมวดหมู่ในถังขยะ ลบล่าสุดก่อน
+	ListDeleted(ctx context.Context, limit, offset int) ([]*domain.Category, int64, error)
+	Restore(ctx context.Context, id uint) error
+	// PurgeDeleted ลบถาวรหมวดหมู่ที่ลบก่อน before ข้ามหมวดที่ยังมีสินค้าอ้างอิง (รวมสินค้าในถังขยะ)
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
 }
 
 type repository struct {
@@ -100,28 +111,31 @@ func (r *repository) List(ctx context.Context, q ListQuery) ([]*domain.Category,
 	// create Query Builder
 	tx := r.db.WithContext(ctx).Model(&domain.Category{})
 
-	if q.Search == "" {
-		tx = tx.Where("name =  ILIKE  ", "%"+q.Search+"%")
+	if q.Search != "" {
+		tx = tx.Where("name ILIKE ?", "%"+query.EscapeLike(q.Search)+"%")
 	}
+	tx = query.Where(tx, q.Filters)
+	sorts := listSchema.SortOrDefault(q.Sort)
 
-	// cout รวม
 	var total int64
-	if err := tx.Count(&total).Error; err != nil {
-		m := apperror.MapDBError("repo.category.list.count", err)
-		log.Debug("repo.category.list.count.fail", zap.Error(err))
-		return nil, 0, m
-	}
-
-	if q.Sort == "" {
-		tx = tx.Order(q.Sort)
+	if q.Keyset {
+		// keyset เรียงตามทุก sort + id; ดึงเกินมา 1 แถวเพื่อรู้ว่ามีหน้าถัดไปไหม
+		tx = cursor.Apply(tx, sorts, q.After).Limit(q.Limit + 1)
 	} else {
-		tx = tx.Order("created_at DESC")
-	}
-	if q.Offset != 0 {
-		tx = tx.Offset(q.Offset)
-	}
-	if q.Limit != 0 {
-		tx = tx.Limit(q.Limit)
+		// cout รวม
+		if err := tx.Count(&total).Error; err != nil {
+			m := apperror.MapDBError("repo.category.list.count", err)
+			log.Debug("repo.category.list.count.fail", zap.Error(err))
+			return nil, 0, m
+		}
+
+		tx = query.Order(tx, sorts)
+		if q.Offset != 0 {
+			tx = tx.Offset(q.Offset)
+		}
+		if q.Limit != 0 {
+			tx = tx.Limit(q.Limit)
+		}
 	}
 
 	var rows []*domain.Category
@@ -141,7 +155,18 @@ func (r *repository) Create(ctx context.Context, category *domain.Category) erro
 	start := time.Now()
 
 	// DB
-	if err := r.db.WithContext(ctx).Create(category).Error; err != nil {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(category).Error; err != nil {
+			return err
+		}
+		return outbox.Enqueue(tx, domain.CategoryChanged{
+			Type:       domain.EventCategoryCreated,
+			CategoryID: category.ID,
+			Name:       category.Name,
+			Version:    category.Version,
+		})
+	})
+	if err != nil {
 		m := apperror.MapDBError("repo.category.create", err)
 		log.Debug("repo.category.create.db_fail", zap.Error(err), zap.Duration("duration", time.Since(start)))
 		return m
@@ -157,16 +182,42 @@ func (r *repository) Create(ctx context.Context, category *domain.Category) erro
 
 }
 
+// Update บันทึกเฉพาะเมื่อ version ในฐานข้อมูลยังเท่ากับ category.Version แล้วเพิ่ม version
 func (r *repository) Update(ctx context.Context, category *domain.Category) error {
 	log := ctxlog.From(ctx)
 	start := time.Now()
 
 	// DB
-	if err := r.db.WithContext(ctx).Save(category).Error; err != nil {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&domain.Category{}).
+			Where("id = ? AND version = ?", category.ID, category.Version).
+			Updates(map[string]any{
+				"name":    category.Name,
+				"version": gorm.Expr("version + 1"),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return apperror.ErrVersionConflict
+		}
+		return outbox.Enqueue(tx, domain.CategoryChanged{
+			Type:       domain.EventCategoryUpdated,
+			CategoryID: category.ID,
+			Name:       category.Name,
+			Version:    category.Version + 1,
+		})
+	})
+	if errors.Is(err, apperror.ErrVersionConflict) {
+		log.Debug("repo.category.update.version_conflict", zap.Uint("id", category.ID), zap.Uint("version", category.Version))
+		return fmt.Errorf("repo.category.update: %w", err)
+	}
+	if err != nil {
 		m := apperror.MapDBError("repo.category.update", err)
 		log.Debug("repo.category.update.fail", zap.Error(err), zap.Duration("duration", time.Since(start)))
 		return m
 	}
+	category.Version++
 
 	// del cache
 	if err := r.cache.del(ctx, r.cache.keyID(category.ID)); err != nil {
@@ -182,7 +233,13 @@ func (r *repository) Delete(ctx context.Context, id uint) error {
 	start := time.Now()
 
 	// DB
-	if err := r.db.WithContext(ctx).Delete(domain.Category{}, id).Error; err != nil {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(domain.Category{}, id).Error; err != nil {
+			return err
+		}
+		return outbox.Enqueue(tx, domain.CategoryChanged{Type: domain.EventCategoryDeleted, CategoryID: id})
+	})
+	if err != nil {
 		m := apperror.MapDBError("repo.category.delete", err)
 		log.Debug("repo.category.delete.fail", zap.Error(err), zap.Duration("duration", time.Since(start)))
 		return m
@@ -195,3 +252,83 @@ func (r *repository) Delete(ctx context.Context, id uint) error {
 
 	return nil
 }
+
+func (r *repository) ListDeleted(ctx context.Context, limit, offset int) ([]*domain.Category, int64, error) {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	tx := r.db.WithContext(ctx).Unscoped().Model(&domain.Category{}).Where("deleted_at IS NOT NULL")
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		m := apperror.MapDBError("repo.category.listdeleted.count", err)
+		log.Debug("repo.category.listdeleted.count.fail", zap.Error(err))
+		return nil, 0, m
+	}
+
+	var categories []*domain.Category
+	if err := tx.Order("deleted_at DESC, id DESC").Limit(limit).Offset(offset).Find(&categories).Error; err != nil {
+		m := apperror.MapDBError("repo.category.listdeleted", err)
+		log.Debug("repo.category.listdeleted.fail", zap.Error(err))
+		return nil, 0, m
+	}
+
+	log.Debug("repo.category.listdeleted.ok", zap.Int("count", len(categories)), zap.Duration("duration", time.Since(start)))
+	return categories, total, nil
+}
+
+func (r *repository) Restore(ctx context.Context, id uint) error {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	var category domain.Category
+	if err := r.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL").First(&category, id).Error; err != nil {
+		m := apperror.MapDBError("repo.category.restore.get", err)
+		log.Debug("repo.category.restore.get.fail", zap.Error(err))
+		return m
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&domain.Category{}).Where("id = ?", id).
+			Updates(map[string]any{"deleted_at": nil, "version": gorm.Expr("version + 1")}).Error; err != nil {
+			return err
+		}
+		return outbox.Enqueue(tx, domain.CategoryChanged{
+			Type:       domain.EventCategoryRestored,
+			CategoryID: id,
+			Name:       category.Name,
+			Version:    category.Version + 1,
+		})
+	})
+	if err != nil {
+		m := apperror.MapDBError("repo.category.restore", err)
+		log.Debug("repo.category.restore.fail", zap.Error(err))
+		return m
+	}
+
+	// del cache
+	if err := r.cache.del(ctx, r.cache.keyID(id), r.cache.keyName(category.Name)); err != nil {
+		log.Warn("repo.category.restore.cache.fail", zap.Error(err))
+	}
+
+	log.Debug("repo.category.restore.ok", zap.Uint("id", id), zap.Duration("duration", time.Since(start)))
+	return nil
+}
+
+func (r *repository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	res := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM products p WHERE p.category_id = categories.id)").
+		Delete(&domain.Category{})
+	if res.Error != nil {
+		m := apperror.MapDBError("repo.category.purgedeleted", res.Error)
+		log.Debug("repo.category.purgedeleted.fail", zap.Error(res.Error))
+		return 0, m
+	}
+
+	log.Debug("repo.category.purgedeleted.ok", zap.Int64("purged", res.RowsAffected), zap.Duration("duration", time.Since(start)))
+	return res.RowsAffected, nil
+}
@@ -2,10 +2,13 @@ package category
 
 import (
 	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/features/audit"
 	"ans-spareparts-api/internal/infra/httpx/ctxlog"
 	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/cursor"
 	"ans-spareparts-api/pkg/utils"
 	"errors"
+	"time"
 
 	"context"
 
@@ -19,15 +22,22 @@ type Service interface {
 	UpdateCategory(ctx context.Context, id uint, req CategoryRequest) (*Item, error)
 	DeleteCategory(ctx context.Context, id uint) error
 	List(ctx context.Context, q ListQuery) (*ListOutput, error)
+
+	ListTrash(ctx context.Context, limit, offset int) (*TrashOutput, error)
+	RestoreCategory(ctx context.Context, id uint) error
+	// PurgeTrash ลบถาวรหมวดหมู่ที่อยู่ในถังขยะก่อน before
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
 }
 
 type service struct {
 	categoryRepo Repository
+	auditor      audit.Recorder
 }
 
-func NewService(categoryRepo Repository) Service {
+func NewService(categoryRepo Repository, auditor audit.Recorder) Service {
 	return &service{
 		categoryRepo: categoryRepo,
+		auditor:      auditor,
 	}
 }
 
@@ -56,10 +66,11 @@ func (i *service) CreateCategory(ctx context.Context, req CategoryRequest) (*Ite
 		return nil, err
 	}
 
+	i.auditor.Record(ctx, audit.Entry{EntityType: audit.EntityCategory, EntityID: category.ID, Action: domain.AuditCreate, After: category})
 	// Log business event
 	log.Info("category.created", zap.Uint("category_id", category.ID))
 
-	return &Item{ID: category.ID, Name: category.Name}, nil
+	return &Item{ID: category.ID, Name: category.Name, Version: category.Version}, nil
 }
 
 // GetCategoryByID retrieves a category by ID
@@ -70,7 +81,7 @@ func (i *service) GetCategoryByID(ctx context.Context, categoryID uint) (*Item,
 		return nil, err
 	}
 
-	return &Item{ID: category.ID, Name: category.Name}, nil
+	return &Item{ID: category.ID, Name: category.Name, Version: category.Version}, nil
 }
 
 // GetCategoryByName retrieves a category by name
@@ -111,15 +122,23 @@ func (i *service) UpdateCategory(ctx context.Context, categoryID uint, req Categ
 		return nil, err
 	}
 
-	// setting new data
+	// client แก้จากข้อมูลเก่า (มีคนอื่นแก้ไปก่อนแล้ว)
+	if req.Version != nil && *req.Version != category.Version {
+		log.Info("category.update.version_conflict", zap.Uint("category_id", category.ID), zap.Uint("version", category.Version))
+		return nil, apperror.ErrVersionConflict
+	}
+
+	// setting new data (เก็บค่าเดิมไว้ทำ audit diff)
+	before := *category
 	category.Name = req.Name
 	// Save changes
 	if err := i.categoryRepo.Update(ctx, category); err != nil {
 		return nil, err
 	}
 
+	i.auditor.Record(ctx, audit.Entry{EntityType: audit.EntityCategory, EntityID: category.ID, Action: domain.AuditUpdate, Before: &before, After: category})
 	log.Info("category.updated", zap.Uint("category_id", category.ID))
-	return &Item{ID: category.ID, Name: category.Name}, nil
+	return &Item{ID: category.ID, Name: category.Name, Version: category.Version}, nil
 }
 
 // DeleteCategory deletes a category if it has no associated products
@@ -137,6 +156,7 @@ func (i *service) DeleteCategory(ctx context.Context, categoryID uint) error {
 		return err
 	}
 
+	i.auditor.Record(ctx, audit.Entry{EntityType: audit.EntityCategory, EntityID: category.ID, Action: domain.AuditDelete, Before: category})
 	log.Info("category_deleted", zap.Uint("category_id", category.ID))
 
 	return nil
@@ -147,21 +167,91 @@ func (i *service) List(ctx context.Context, q ListQuery) (*ListOutput, error) {
 	limit, offset := utils.NormalizePagination(q.Limit, q.Offset)
 
 	rows, total, err := i.categoryRepo.List(ctx, ListQuery{
-		Search: q.Search,
-		Limit:  limit,
-		Offset: offset,
-		Sort:   q.Sort,
+		Search:  q.Search,
+		Limit:   limit,
+		Offset:  offset,
+		Sort:    q.Sort,
+		Filters: q.Filters,
+		Keyset:  q.Keyset,
+		After:   q.After,
 	})
 	if err != nil {
 		return nil, err
 	}
+
+	var next string
+	if q.Keyset {
+		sorts := listSchema.SortOrDefault(q.Sort)
+		rows, next = cursor.Page(rows, limit, func(c *domain.Category) cursor.Cursor {
+			return cursor.New(sorts, c.ID, func(column string) any {
+				if column == "id" {
+					return c.ID
+				}
+				return c.Name
+			})
+		})
+	}
+
 	items := make([]*Item, 0, len(rows))
 	for _, category := range rows {
 		items = append(items, &Item{
-			ID:   category.ID,
-			Name: category.Name,
+			ID:      category.ID,
+			Name:    category.Name,
+			Version: category.Version,
 		})
 	}
 
-	return &ListOutput{Items: items, Total: total}, nil
+	return &ListOutput{Items: items, Total: total, NextCursor: next}, nil
+}
+
+// ListTrash lists soft-deleted categories
+func (i *service) ListTrash(ctx context.Context, limit, offset int) (*TrashOutput, error) {
+	limit, offset = utils.NormalizePagination(limit, offset)
+
+	categories, total, err := i.categoryRepo.ListDeleted(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*TrashItem, len(categories))
+	for index, c := range categories {
+		items[index] = &TrashItem{
+			ID:        c.ID,
+			Name:      c.Name,
+			DeletedAt: c.DeletedAt.Time,
+		}
+	}
+
+	return &TrashOutput{Items: items, Total: total}, nil
+}
+
+// RestoreCategory brings a soft-deleted category back
+func (i *service) RestoreCategory(ctx context.Context, categoryID uint) error {
+	log := ctxlog.From(ctx)
+
+	if err := i.categoryRepo.Restore(ctx, categoryID); err != nil {
+		return err
+	}
+
+	i.auditor.Record(ctx, audit.Entry{
+		EntityType: audit.EntityCategory,
+		EntityID:   categoryID,
+		Action:     domain.AuditRestore,
+		Changes:    map[string]audit.Change{"deleted": {Old: true, New: false}},
+	})
+	log.Info("category_restored", zap.Uint("category_id", categoryID))
+	return nil
+}
+
+// PurgeTrash hard-deletes categories deleted before the given time
+func (i *service) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	log := ctxlog.From(ctx)
+
+	purged, err := i.categoryRepo.PurgeDeleted(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+
+	log.Info("category_trash_purged", zap.Int64("purged", purged), zap.Time("before", before))
+	return purged, nil
 }
@@ -5,9 +5,11 @@ import (
 	"ans-spareparts-api/internal/features/category"
 	mocks "ans-spareparts-api/internal/mock"
 	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/cursor"
 	"ans-spareparts-api/pkg/testutil/fixtures"
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -16,6 +18,7 @@ import (
 type ServiceTestSuite struct {
 	Service      category.Service
 	MockCategory *mocks.CategoryRepository
+	MockAudit    *mocks.AuditService
 	Ctx          context.Context
 }
 
@@ -28,7 +31,10 @@ func (ts *ServiceTestSuite) SetupTest(t *testing.T) {
 	ts.MockCategory = mocks.NewMockCategoryRepository()
 	ts.Ctx = context.Background()
 
-	ts.Service = category.NewService(ts.MockCategory)
+	// audit เป็น best-effort: ยอมรับทุกการเรียก แล้วตรวจผ่าน MockAudit.Calls ใน test ที่สนใจ
+	ts.MockAudit = mocks.NewAuditService()
+	ts.MockAudit.On("Record", mock.Anything, mock.Anything).Maybe()
+	ts.Service = category.NewService(ts.MockCategory, ts.MockAudit)
 
 	// ตั้งค่า Teardown: จะถูกเรียกเมื่อ t.Run หรือ test func จบ
 	t.Cleanup(func() {
@@ -277,6 +283,36 @@ func TestCategoryService_Delete(t *testing.T) {
 	}
 }
 
+func TestCategoryService_RestoreAndPurge(t *testing.T) {
+	t.Run("restore_successfull", func(t *testing.T) {
+		ts := newServiceTestSuite()
+		ts.SetupTest(t)
+
+		ts.MockCategory.On("Restore", ts.Ctx, uint(1)).Return(nil).Once()
+		assert.NoError(t, ts.Service.RestoreCategory(ts.Ctx, 1))
+	})
+
+	t.Run("restore_error_not_in_trash", func(t *testing.T) {
+		ts := newServiceTestSuite()
+		ts.SetupTest(t)
+
+		ts.MockCategory.On("Restore", ts.Ctx, uint(999)).Return(apperror.ErrNotFound).Once()
+		assert.ErrorIs(t, ts.Service.RestoreCategory(ts.Ctx, 999), apperror.ErrNotFound)
+	})
+
+	t.Run("purge_passes_cutoff", func(t *testing.T) {
+		ts := newServiceTestSuite()
+		ts.SetupTest(t)
+
+		before := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		ts.MockCategory.On("PurgeDeleted", ts.Ctx, before).Return(int64(3), nil).Once()
+
+		purged, err := ts.Service.PurgeTrash(ts.Ctx, before)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), purged)
+	})
+}
+
 func TestCategoryService_GetByID(t *testing.T) {
 	validCategory := fixtures.ValidCategory()
 	tests := []struct {
@@ -378,6 +414,31 @@ func TestCategoryService_List(t *testing.T) {
 				assert.Equal(t, int64(len(validCategory)), clr.Total)
 			},
 		},
+		{
+			name: "keyset_next_cursor_success",
+			input: category.ListQuery{
+				Limit:  1,
+				Keyset: true,
+			},
+			setup: func(ts *ServiceTestSuite) {
+				expectedQuery := category.ListQuery{
+					Limit:  1,
+					Keyset: true,
+				}
+				ts.MockCategory.On("List", ts.Ctx, expectedQuery).Return(validCategory, int64(0), nil).Once()
+			},
+			asserErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, clr *category.ListOutput) {
+				assert.Len(t, clr.Items, 1)
+				c, err := cursor.Decode(clr.NextCursor)
+				assert.NoError(t, err)
+				assert.Equal(t, validCategory[0].ID, c.ID)
+				assert.Equal(t, "name", c.Key)
+				assert.Equal(t, []any{validCategory[0].Name}, c.Values)
+			},
+		},
 		{
 			name: "search_dberror",
 			input: category.ListQuery{
@@ -388,7 +449,6 @@ func TestCategoryService_List(t *testing.T) {
 					Search: "",
 					Limit:  20,
 					Offset: 0,
-					Sort:   "",
 				}
 
 				ts.MockCategory.On("List", ts.Ctx, expectedQuery).Return(nil, int64(0), apperror.ErrInternalServer)
@@ -0,0 +1,27 @@
+package document
+
+import "time"
+
+// ประเภทเอกสาร (prefix ของเลขที่เอกสาร)
+const (
+	DocTypeReceipt    = "RC" // ใบเสร็จรับเงิน / ใบกำกับภาษีอย่างย่อ
+	DocTypeTaxInvoice = "TX" // ใบกำกับภาษีเต็มรูป
+	DocTypeQuotation  = "QT" // ใบเสนอราคา
+)
+
+// HeadOfficeBranch รหัสสาขาของสำนักงานใหญ่ตามรูปแบบกรมสรรพากร
+const HeadOfficeBranch = "00000"
+
+type NextNumberInput struct {
+	DocType  string
+	Branch   string
+	IssuedAt time.Time
+}
+
+type Number struct {
+	DocType string
+	Branch  string
+	Year    int // ปี พ.ศ.
+	Seq     int64
+	Code    string // เช่น TX-00000-2569-000001
+}
@@ -0,0 +1,54 @@
+package document
+
+import (
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/pkg/apperror"
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	// Next จองเลขที่ถัดไปของ sequence แบบ atomic (ถ้ายังไม่มี sequence จะสร้างให้และเริ่มที่ 1)
+	// tx ต้องเป็น transaction เดียวกับที่บันทึกเอกสาร: แถว sequence ถูกล็อกจน commit
+	// และถ้าบันทึกเอกสารไม่สำเร็จ rollback จะคืนเลขให้ ไม่เกิดเลขข้าม
+	Next(ctx context.Context, tx *gorm.DB, docType, branch string, year int) (int64, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Next(ctx context.Context, tx *gorm.DB, docType, branch string, year int) (int64, error) {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	// ใช้ upsert + RETURNING ให้ Postgres ล็อกแถวเอง ไม่ต้อง SELECT FOR UPDATE แยก
+	var lastNo int64
+	err := tx.WithContext(ctx).Raw(`
+		INSERT INTO document_sequences (doc_type, branch, year, last_no, created_at, updated_at)
+		VALUES (?, ?, ?, 1, NOW(), NOW())
+		ON CONFLICT (doc_type, branch, year)
+		DO UPDATE SET last_no = document_sequences.last_no + 1, updated_at = NOW()
+		RETURNING last_no`, docType, branch, year).Scan(&lastNo).Error
+	if err != nil {
+		m := apperror.MapDBError("repo.document.next", err)
+		log.Debug("repo.document.next.db_fail", zap.Error(err), zap.Duration("duration", time.Since(start)))
+		return 0, m
+	}
+
+	log.Debug("repo.document.next.ok",
+		zap.String("doc_type", docType),
+		zap.String("branch", branch),
+		zap.Int("year", year),
+		zap.Int64("last_no", lastNo),
+		zap.Duration("duration", time.Since(start)),
+	)
+	return lastNo, nil
+}
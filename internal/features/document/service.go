@@ -0,0 +1,92 @@
+package document
+
+import (
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/pkg/apperror"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type Service interface {
+	// NextNumber ต้องเรียกภายใน transaction ที่บันทึกเอกสาร (ดู Repository.Next)
+	NextNumber(ctx context.Context, tx *gorm.DB, in NextNumberInput) (*Number, error)
+}
+
+type service struct {
+	documentRepo Repository
+}
+
+func NewService(documentRepo Repository) Service {
+	return &service{
+		documentRepo: documentRepo,
+	}
+}
+
+// รหัสสาขา 5 หลักตามที่กรมสรรพากรกำหนด (00000 = สำนักงานใหญ่)
+var branchRegex = regexp.MustCompile(`^[0-9]{5}$`)
+
+// เลขที่เอกสารอิงเวลาประเทศไทย (ไม่พึ่ง tzdata ของเครื่อง)
+var bangkok = time.FixedZone("ICT", 7*60*60)
+
+var docTypes = map[string]bool{
+	DocTypeReceipt:    true,
+	DocTypeTaxInvoice: true,
+	DocTypeQuotation:  true,
+}
+
+// --- helper ---
+
+// buddhistYear แปลงปี ค.ศ. เป็น พ.ศ. ตามเวลาประเทศไทย
+func buddhistYear(t time.Time) int {
+	return t.In(bangkok).Year() + 543
+}
+
+func formatNumber(docType, branch string, year int, seq int64) string {
+	return fmt.Sprintf("%s-%s-%d-%06d", docType, branch, year, seq)
+}
+
+// NextNumber ออกเลขที่เอกสารถัดไปของประเภท/สาขา/ปี ที่ระบุ
+func (s *service) NextNumber(ctx context.Context, tx *gorm.DB, in NextNumberInput) (*Number, error) {
+	log := ctxlog.From(ctx)
+
+	docType := strings.ToUpper(strings.TrimSpace(in.DocType))
+	if !docTypes[docType] {
+		return nil, apperror.ErrInvalidInput
+	}
+
+	branch := strings.TrimSpace(in.Branch)
+	if branch == "" {
+		branch = HeadOfficeBranch
+	}
+	if !branchRegex.MatchString(branch) {
+		return nil, apperror.ErrInvalidInput
+	}
+
+	issuedAt := in.IssuedAt
+	if issuedAt.IsZero() {
+		issuedAt = time.Now()
+	}
+	year := buddhistYear(issuedAt)
+
+	seq, err := s.documentRepo.Next(ctx, tx, docType, branch, year)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &Number{
+		DocType: docType,
+		Branch:  branch,
+		Year:    year,
+		Seq:     seq,
+		Code:    formatNumber(docType, branch, year, seq),
+	}
+
+	log.Info("document.number.issued", zap.String("code", out.Code))
+	return out, nil
+}
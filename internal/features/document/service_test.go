@@ -0,0 +1,134 @@
+package document_test
+
+import (
+	"ans-spareparts-api/internal/features/document"
+	mocks "ans-spareparts-api/internal/mock"
+	"ans-spareparts-api/pkg/apperror"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type TestSuite struct {
+	Service          document.Service
+	MockDocumentRepo *mocks.DocumentRepository
+	Ctx              context.Context
+}
+
+func NewTestSuite() *TestSuite {
+	return &TestSuite{}
+}
+
+func (ts *TestSuite) SetupTest(t *testing.T) {
+	ts.MockDocumentRepo = mocks.NewMockDocumentRepository()
+	ts.Service = document.NewService(ts.MockDocumentRepo)
+	ts.Ctx = context.Background()
+
+	t.Cleanup(func() {
+		ts.MockDocumentRepo.AssertExpectations(t)
+	})
+}
+
+func TestDocumentService_NextNumber(t *testing.T) {
+	// 31 ธ.ค. 2025 เวลา 20:00 UTC = 1 ม.ค. 2026 เวลาไทย -> ปี พ.ศ. 2569
+	newYearInBangkok := time.Date(2025, 12, 31, 20, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		input     document.NextNumberInput
+		setup     func(ts *TestSuite)
+		assertErr func(*testing.T, error)
+		validate  func(*testing.T, *document.Number)
+	}{
+		{
+			name: "Success_Default_HeadOffice",
+			input: document.NextNumberInput{
+				DocType:  "tx",
+				IssuedAt: time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC),
+			},
+			setup: func(ts *TestSuite) {
+				ts.MockDocumentRepo.On("Next", ts.Ctx, mock.Anything, document.DocTypeTaxInvoice, document.HeadOfficeBranch, 2568).Return(int64(12), nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, n *document.Number) {
+				assert.Equal(t, "TX-00000-2568-000012", n.Code)
+				assert.Equal(t, int64(12), n.Seq)
+			},
+		},
+		{
+			name: "Success_Year_Uses_Bangkok_Time",
+			input: document.NextNumberInput{
+				DocType:  document.DocTypeReceipt,
+				Branch:   "00002",
+				IssuedAt: newYearInBangkok,
+			},
+			setup: func(ts *TestSuite) {
+				ts.MockDocumentRepo.On("Next", ts.Ctx, mock.Anything, document.DocTypeReceipt, "00002", 2569).Return(int64(1), nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, n *document.Number) {
+				assert.Equal(t, "RC-00002-2569-000001", n.Code)
+				assert.Equal(t, 2569, n.Year)
+			},
+		},
+		{
+			name:  "Error_Unknown_DocType",
+			input: document.NextNumberInput{DocType: "XX"},
+			setup: func(ts *TestSuite) {},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidInput)
+			},
+			validate: func(t *testing.T, n *document.Number) {
+				assert.Nil(t, n)
+			},
+		},
+		{
+			name:  "Error_Invalid_Branch",
+			input: document.NextNumberInput{DocType: document.DocTypeReceipt, Branch: "12"},
+			setup: func(ts *TestSuite) {},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidInput)
+			},
+			validate: func(t *testing.T, n *document.Number) {
+				assert.Nil(t, n)
+			},
+		},
+		{
+			name: "Error_Repo_Failed",
+			input: document.NextNumberInput{
+				DocType:  document.DocTypeReceipt,
+				IssuedAt: time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC),
+			},
+			setup: func(ts *TestSuite) {
+				ts.MockDocumentRepo.On("Next", ts.Ctx, mock.Anything, document.DocTypeReceipt, document.HeadOfficeBranch, 2568).Return(int64(0), apperror.ErrInternalServer).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInternalServer)
+			},
+			validate: func(t *testing.T, n *document.Number) {
+				assert.Nil(t, n)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewTestSuite()
+			ts.SetupTest(t)
+
+			test.setup(ts)
+
+			n, err := ts.Service.NextNumber(ts.Ctx, nil, test.input)
+
+			test.assertErr(t, err)
+			test.validate(t, n)
+		})
+	}
+}
@@ -30,7 +30,7 @@ func (c *cacheLayer) keyProductID(id uint) string {
 }
 
 func (c *cacheLayer) keyID(id uint) string {
-	return fmt.Sprintf("inventory:product_id:%d", id)
+	return fmt.Sprintf("inventory:id:%d", id)
 }
 
 func (c *cacheLayer) getByKey(ctx context.Context, key string) (*domain.Inventory, bool, error) {
@@ -65,10 +65,25 @@ func (c *cacheLayer) set(ctx context.Context, key string, inventory *domain.Inve
 	return c.rdb.Set(ctx, key, b, c.ttl).Err()
 }
 
-func (c *cacheLayer) del(ctx context.Context, key string) error {
+func (c *cacheLayer) del(ctx context.Context, key ...string) error {
 	if c == nil {
 		return nil
 	}
 
-	return c.rdb.Del(ctx, key).Err()
+	return c.rdb.Del(ctx, key...).Err()
+}
+
+// DeleteCache ลบ cache ของ inventory ทั้ง key ตาม id และตาม product_id
+// ให้ feature อื่นที่แก้แถว inventory เองเรียกหลัง commit แทนการเขียน key ซ้ำ (rdb เป็น nil ได้)
+func DeleteCache(ctx context.Context, rdb *redis.Client, invs ...*domain.Inventory) error {
+	if rdb == nil || len(invs) == 0 {
+		return nil
+	}
+
+	c := &cacheLayer{rdb: rdb}
+	keys := make([]string, 0, 2*len(invs))
+	for _, inv := range invs {
+		keys = append(keys, c.keyID(inv.ID), c.keyProductID(inv.ProductID))
+	}
+	return c.del(ctx, keys...)
 }
@@ -1,39 +1,105 @@
 package inventory
 
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/pkg/cursor"
+	"ans-spareparts-api/pkg/query"
+	"time"
+)
+
+// locationColumn location เป็น NULL ได้ จึงเทียบผ่าน COALESCE ไม่งั้น keyset จะข้ามแถวที่เป็น NULL
+const locationColumn = "COALESCE(location, '')"
+
+// listSchema field ที่กรอง/เรียงได้ของ GET /inventories
+var listSchema = query.Schema{
+	Fields: map[string]query.Field{
+		"id":         {Column: "id", Type: query.Int, Ops: []query.Op{query.OpEq, query.OpIn}, Sortable: true},
+		"product_id": {Column: "product_id", Type: query.Int, Ops: []query.Op{query.OpEq, query.OpIn}, Sortable: true},
+		"quantity":   {Column: "quantity", Type: query.Int, Ops: query.Comparable, Sortable: true},
+		"location":   {Column: locationColumn, Type: query.String, Ops: []query.Op{query.OpEq, query.OpLike}, Sortable: true},
+		"created_at": {Column: "created_at", Type: query.Time, Ops: query.Comparable, Sortable: true},
+		"updated_at": {Column: "updated_at", Type: query.Time, Ops: query.Comparable, Sortable: true},
+	},
+	DefaultSort: []query.Sort{{Column: "created_at", Desc: true}},
+}
+
+// sortValue ค่าของคอลัมน์ที่ใช้เรียง สำหรับสร้าง keyset cursor
+func sortValue(inv *domain.Inventory, column string) any {
+	switch column {
+	case "id":
+		return inv.ID
+	case "product_id":
+		return inv.ProductID
+	case "quantity":
+		return inv.Quantity
+	case locationColumn:
+		return inv.Location
+	case "updated_at":
+		return inv.UpdatedAt
+	}
+	return inv.CreatedAt
+}
+
 type ListQuery struct {
-	Limit  int
-	Offset int
-	Sort   string
+	Limit   int
+	Offset  int
+	Sort    []query.Sort
+	Filters []query.Filter
+
+	// Keyset ใช้ cursor แทน offset; After เป็น nil คือหน้าแรก
+	Keyset bool
+	After  *cursor.Cursor
 }
 
 type UpdateQuantityInput struct {
-	ProductID uint
-	Quantity  int
+	// Quantity จำนวนที่ปรับ (บวก = เพิ่ม, ลบ = ลด) ห้ามเป็น 0
+	Quantity int
+	// Version version ที่ client อ่านไปก่อนแก้; nil คือไม่ตรวจ (handler บังคับส่งเสมอ)
+	Version *uint
+	UserID  uint
 }
 
 type Item struct {
 	ID        uint
 	ProductID uint
 	Quantity  int
+	Version   uint
+	UpdatedAt time.Time
 }
 
 type ListOutput struct {
 	Items []*Item
 	Total int64
+	// NextCursor มีเฉพาะโหมด Keyset
+	NextCursor string
 }
 
 type UpdateQuantityRequest struct {
-	ProductID uint
-	Quantity  int
+	Quantity int
+	// Version ใช้แทน header If-Match ได้
+	Version *uint
 }
 
 type InventoryResponse struct {
 	ID        uint
 	ProductID uint
 	Quantity  int
+	Version   uint
 }
 
 type InventoryListResponse struct {
 	Inventories []*InventoryResponse
 	Total       int64
 }
+
+// ExportRow แถวสำหรับ export สต็อก รวมข้อมูลสินค้าและมูลค่าตามต้นทุนเฉลี่ย
+type ExportRow struct {
+	ProductID uint
+	SKU       string
+	Name      string
+	Location  string
+	Quantity  int
+	AvgCost   float64
+	Value     float64
+	UpdatedAt time.Time
+}
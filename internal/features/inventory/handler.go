@@ -4,8 +4,16 @@ import (
 	"ans-spareparts-api/internal/infra/httpx/ctxlog"
 	"ans-spareparts-api/internal/infra/jwtx"
 	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/export"
+	"ans-spareparts-api/pkg/httpcache"
+	"ans-spareparts-api/pkg/query"
 	"ans-spareparts-api/pkg/response"
+	"bufio"
+	"errors"
+	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
@@ -28,7 +36,10 @@ func NewHandler(service Service) *Handler {
 // @Accept json
 // @Produce json
 // @Param id path int true "inventory ID"
+// @Param If-None-Match header string false "ETag from a previous response"
+// @Param If-Modified-Since header string false "Last-Modified from a previous response"
 // @Success 200 {object} InventoryListResponse
+// @Success 304 "Not Modified"
 // @Failure 400 {object} response.ErrorBody
 // @Failure 404 {object} response.ErrorBody
 // @Failure 500 {object} response.ErrorBody
@@ -58,10 +69,15 @@ func (h *Handler) GetInventoryByID(c *fiber.Ctx) error {
 		)
 	}
 
+	if httpcache.Fresh(c, httpcache.VersionTag(item.Version), item.UpdatedAt) {
+		return httpcache.NotModified(c)
+	}
+
 	return response.OK(c, InventoryResponse{
 		ID:        item.ID,
 		ProductID: item.ProductID,
 		Quantity:  item.Quantity,
+		Version:   item.Version,
 	})
 }
 
@@ -101,20 +117,26 @@ func (h *Handler) GetInventoryByProductID(c *fiber.Ctx) error {
 		ID:        inventory.ID,
 		ProductID: inventory.ProductID,
 		Quantity:  inventory.Quantity,
+		Version:   inventory.Version,
 	})
 }
 
 // UpdateQuantity godoc
 // @Summary Update quantity inventory
-// @Description Update inventory stock (admin/manager only)
+// @Description Adjust inventory stock by a signed quantity, recorded as an adjust stock movement at the current average cost (admin/manager only)
 // @Tags inventory
 // @Accept json
+// @Param id path int true "Inventory ID"
 // @Param inventory body UpdateQuantityRequest true "Inventory update request"
+// @Param If-Match header string false "ETag from GET /inventories/{id} (or send Version in the body)"
 // @Success 200 {object} InventoryResponse
 // @Failure 400 {object} response.ErrorBody
 // @Failure 403 {object} response.ErrorBody
+// @Failure 409 {object} response.ErrorBody
+// @Failure 412 {object} response.ErrorBody
+// @Failure 428 {object} response.ErrorBody
 // @Security BearerAuth
-// @Router /inventory [patch]
+// @Router /inventories/{id} [patch]
 func (h *Handler) UpdateQuantity(c *fiber.Ctx) error {
 	ctx := c.UserContext()
 	log := ctxlog.From(ctx)
@@ -146,18 +168,36 @@ func (h *Handler) UpdateQuantity(c *fiber.Ctx) error {
 		)
 	}
 
+	// ต้องบอก version ที่อ่านไป (If-Match หรือ field version) กันปรับยอดทับกัน
+	version, fromHeader, ok := httpcache.ExpectedVersion(c, req.Version)
+	if !ok {
+		return response.Error(
+			c, fiber.StatusPreconditionRequired, "PRECONDITION_REQUIRED", "If-Match header or version is required",
+		)
+	}
+
 	inventory, err := h.service.UpdateQuantity(ctx, uint(invID), UpdateQuantityInput{
-		ProductID: req.ProductID,
-		Quantity:  req.Quantity,
+		Quantity: req.Quantity,
+		Version:  &version,
+		UserID:   userClaims.UserID,
 	})
 
 	if err != nil {
-		if err == apperror.ErrInsufficientStock {
+		if errors.Is(err, apperror.ErrInvalidInput) {
+			return response.Error(
+				c, fiber.StatusBadRequest, "BAD_REQUEST", "quantity must not be zero",
+			)
+		}
+		if errors.Is(err, apperror.ErrVersionConflict) {
+			status, code := httpcache.ConflictStatus(fromHeader)
+			return response.Error(c, status, code, "inventory was modified by another user")
+		}
+		if errors.Is(err, apperror.ErrInsufficientStock) {
 			return response.Error(
 				c, fiber.StatusUnprocessableEntity, "UNPROCESSABLE", "Insufficient Stock",
 			)
 		}
-		if err == apperror.ErrNotFound {
+		if errors.Is(err, apperror.ErrNotFound) {
 			return response.Error(
 				c, fiber.StatusNotFound, "NOT_FOUND", "inventory for product not found",
 			)
@@ -167,10 +207,12 @@ func (h *Handler) UpdateQuantity(c *fiber.Ctx) error {
 		)
 	}
 
+	c.Set(fiber.HeaderETag, httpcache.VersionTag(inventory.Version))
 	return response.OK(c, InventoryResponse{
 		ID:        inventory.ID,
 		ProductID: inventory.ProductID,
 		Quantity:  inventory.Quantity,
+		Version:   inventory.Version,
 	})
 }
 
@@ -182,7 +224,12 @@ func (h *Handler) UpdateQuantity(c *fiber.Ctx) error {
 // @Produce json
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
+// @Param cursor query string false "Keyset cursor (send empty for the first page; response meta.next_cursor for the next)"
+// @Param sort query string false "Comma separated, '-' prefix for DESC: id,product_id,quantity,location,created_at,updated_at"
+// @Param filter query string false "field=value or field[op]=value (eq,ne,gt,gte,lt,lte,like,in) on id,product_id,quantity,location,created_at,updated_at"
+// @Param If-None-Match header string false "ETag from a previous response"
 // @Success 200 {array} InventoryListResponse
+// @Success 304 "Not Modified"
 // @Failure 403 {object} response.ErrorBody
 // @Failure 500 {object} response.ErrorBody
 // @Security BearerAuth
@@ -203,26 +250,21 @@ func (h *Handler) List(c *fiber.Ctx) error {
 		)
 	}
 
-	limit, err := strconv.Atoi(c.Query("limit", "10"))
+	p, err := query.FromCtx(c, listSchema)
 	if err != nil {
-		log.Warn("handler.inventory.list.invalid_input.limit", zap.Error(err))
+		log.Warn("handler.inventory.list.invalid_input", zap.Error(err))
 		return response.Error(
-			c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid limit request",
+			c, fiber.StatusBadRequest, "BAD_REQUEST", err.Error(),
 		)
 	}
-	offset, err := strconv.Atoi(c.Query("offset", "0"))
-	if err != nil {
-		log.Warn("handler.inventory.list.invalid_input.offset", zap.Error(err))
-		return response.Error(
-			c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid offset request",
-		)
-	}
-	sort := c.Query("sort", "ASC")
 
 	inventories, err := h.service.List(ctx, ListQuery{
-		Limit:  limit,
-		Offset: offset,
-		Sort:   sort,
+		Limit:   p.Limit,
+		Offset:  p.Offset,
+		Sort:    p.Sort,
+		Filters: p.Filters,
+		Keyset:  p.Keyset,
+		After:   p.After,
 	})
 	if err != nil {
 		return response.Error(
@@ -235,10 +277,14 @@ func (h *Handler) List(c *fiber.Ctx) error {
 			ID:        inv.ID,
 			ProductID: inv.ProductID,
 			Quantity:  inv.Quantity,
+			Version:   inv.Version,
 		}
 
 	}
 
+	if p.Keyset {
+		return response.CursorPage(c, res, p.Limit, inventories.NextCursor)
+	}
 	return response.OK(
 		c, InventoryListResponse{
 			Inventories: res,
@@ -246,3 +292,57 @@ func (h *Handler) List(c *fiber.Ctx) error {
 		},
 	)
 }
+
+// ExportInventories godoc
+// @Summary Export inventories
+// @Description Stream stock levels with average cost and value as CSV or XLSX
+// @Tags inventory
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "csv | xlsx" default(csv)
+// @Param sort query string false "Sort fields, same as List (e.g. -quantity,location)"
+// @Success 200 {file} file
+// @Failure 400 {object} response.ErrorBody
+// @Failure 403 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /inventories/export [get]
+func (h *Handler) ExportInventories(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+	userClaims := c.Locals("user").(*jwtx.Claims)
+
+	// Check permissions
+	if userClaims.Role != "admin" && userClaims.Role != "manager" {
+		log.Warn("handler.inventory.export.permission.not_allow",
+			zap.Uint("user_id", userClaims.UserID),
+			zap.String("role", userClaims.Role),
+		)
+		return response.Error(
+			c, fiber.StatusForbidden, "FORBIDDEN", "insufficient permission",
+		)
+	}
+
+	format := strings.ToLower(c.Query("format", export.FormatCSV))
+	if !export.Supported(format) {
+		log.Warn("handler.inventory.export.invalid_format", zap.String("format", format))
+		return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid format request")
+	}
+	p, err := query.FromCtx(c, listSchema)
+	if err != nil {
+		log.Warn("handler.inventory.export.invalid_input", zap.Error(err))
+		return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", err.Error())
+	}
+	q := ListQuery{Sort: p.Sort, Filters: p.Filters}
+
+	c.Set(fiber.HeaderContentType, export.ContentType(format))
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="inventories-%s.%s"`, time.Now().Format("20060102"), format))
+
+	// header ถูกส่งไปแล้ว ถ้าล้มกลางทาง service จะเขียนแถว #ERROR ปิดท้ายไฟล์
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := h.service.ExportInventories(ctx, q, format, w); err != nil {
+			log.Error("handler.inventory.export.stream_failed", zap.Error(err))
+		}
+		_ = w.Flush()
+	})
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"ans-spareparts-api/internal/infra/jwtx"
 	mocks "ans-spareparts-api/internal/mock"
 	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/query"
 	"ans-spareparts-api/pkg/response"
 	"ans-spareparts-api/pkg/testutil/fixtures"
 	"bytes"
@@ -13,6 +14,7 @@ import (
 	"io"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
@@ -145,6 +147,25 @@ func TestInventoryHandler_GetInventoryByID(t *testing.T) {
 	}
 }
 
+func TestInventoryHandler_GetInventoryByID_NotModified(t *testing.T) {
+	ts := NewHandlerTestSuite()
+	ts.SetUpHandlerTestSuite(t)
+	ts.App.Get("/inventories/:id", ts.Handler.GetInventoryByID)
+
+	item := &inventory.Item{ID: 1, ProductID: 1, Quantity: 5, Version: 4, UpdatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}
+	ts.MockService.On("GetInventoryByID", mock.Anything, uint(1)).Return(item, nil).Twice()
+
+	res, _ := ts.App.Test(httptest.NewRequest(fiber.MethodGet, "/inventories/1", nil), -1)
+	assert.Equal(t, fiber.StatusOK, res.StatusCode)
+	etag := res.Header.Get(fiber.HeaderETag)
+	assert.Equal(t, `"4"`, etag)
+
+	req := httptest.NewRequest(fiber.MethodGet, "/inventories/1", nil)
+	req.Header.Set(fiber.HeaderIfNoneMatch, etag)
+	res, _ = ts.App.Test(req, -1)
+	assert.Equal(t, fiber.StatusNotModified, res.StatusCode)
+}
+
 func TestInventoryHandler_GetInventoryByProductID(t *testing.T) {
 	mockInv := fixtures.ValidInventory()
 	mockItem := &inventory.Item{
@@ -247,29 +268,34 @@ func TestInventoryHandler_GetInventoryByProductID(t *testing.T) {
 
 func TestInventoryHandler_UpdateQuantity(t *testing.T) {
 	mockInv := fixtures.ValidInventory()
+	version := uint(1)
 	mockInput := inventory.UpdateQuantityInput{
-		ProductID: mockInv.ID,
-		Quantity:  1,
+		Quantity: 1,
+		Version:  &version,
+		UserID:   1,
 	}
 	mockItem := &inventory.Item{
 		ID:        mockInv.ID,
 		ProductID: mockInv.ProductID,
 		Quantity:  2,
+		Version:   2,
 	}
 	mockRequest := &inventory.UpdateQuantityRequest{
-		ProductID: mockInv.ID,
-		Quantity:  1,
+		Quantity: 1,
+		Version:  &version,
 	}
 	mockResponse := &inventory.InventoryResponse{
 		ID:        mockInv.ID,
-		ProductID: mockInput.ProductID,
+		ProductID: mockInv.ProductID,
 		Quantity:  2,
+		Version:   2,
 	}
 
 	tests := []struct {
 		name               string
 		path               string
 		userRole           string
+		ifMatch            string
 		requestBody        interface{}
 		setup              func(*HandlerTestSuite)
 		expectedStatusCode int
@@ -291,26 +317,82 @@ func TestInventoryHandler_UpdateQuantity(t *testing.T) {
 			userRole: "manager",
 			path:     "/inventories/1",
 			requestBody: inventory.UpdateQuantityInput{
-				ProductID: 1,
-				Quantity:  -1,
+				Quantity: -1,
+				Version:  &version,
 			},
 			setup: func(hts *HandlerTestSuite) {
 				input := inventory.UpdateQuantityInput{
-					ProductID: 1,
-					Quantity:  -1,
+					Quantity: -1,
+					Version:  &version,
+					UserID:   1,
 				}
 				item := &inventory.Item{
 					ID:        1,
 					ProductID: 1,
 					Quantity:  0,
+					Version:   2,
 				}
 				hts.MockService.On("UpdateQuantity", mock.Anything, uint(1), input).Return(item, nil).Once()
 			},
 			expectedStatusCode: fiber.StatusOK,
 			expectedBody: &inventory.InventoryResponse{
 				ID:        mockInv.ID,
-				ProductID: mockInput.ProductID,
+				ProductID: mockInv.ProductID,
 				Quantity:  0,
+				Version:   2,
+			},
+		},
+		{
+			name:        "Success_IfMatch_Header",
+			userRole:    "manager",
+			path:        "/inventories/1",
+			ifMatch:     `"1"`,
+			requestBody: inventory.UpdateQuantityRequest{Quantity: 1},
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("UpdateQuantity", mock.Anything, uint(1), mockInput).Return(mockItem, nil).Once()
+			},
+			expectedStatusCode: fiber.StatusOK,
+			expectedBody:       mockResponse,
+		},
+		{
+			name:               "Error_PreconditionRequired_No_Version",
+			userRole:           "manager",
+			path:               "/inventories/1",
+			requestBody:        inventory.UpdateQuantityRequest{Quantity: 1},
+			setup:              func(hts *HandlerTestSuite) {},
+			expectedStatusCode: fiber.StatusPreconditionRequired,
+			expectedBody: fiber.Map{
+				"code":    "PRECONDITION_REQUIRED",
+				"message": "If-Match header or version is required",
+			},
+		},
+		{
+			name:        "Error_PreconditionFailed_IfMatch_Stale",
+			userRole:    "manager",
+			path:        "/inventories/1",
+			ifMatch:     `"1"`,
+			requestBody: inventory.UpdateQuantityRequest{Quantity: 1},
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("UpdateQuantity", mock.Anything, uint(1), mockInput).Return(nil, apperror.ErrVersionConflict).Once()
+			},
+			expectedStatusCode: fiber.StatusPreconditionFailed,
+			expectedBody: fiber.Map{
+				"code":    "PRECONDITION_FAILED",
+				"message": "inventory was modified by another user",
+			},
+		},
+		{
+			name:        "Error_Conflict_Body_Version_Stale",
+			userRole:    "manager",
+			path:        "/inventories/1",
+			requestBody: mockRequest,
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("UpdateQuantity", mock.Anything, uint(1), mockInput).Return(nil, apperror.ErrVersionConflict).Once()
+			},
+			expectedStatusCode: fiber.StatusConflict,
+			expectedBody: fiber.Map{
+				"code":    "CONFLICT",
+				"message": "inventory was modified by another user",
 			},
 		},
 		{
@@ -318,13 +400,14 @@ func TestInventoryHandler_UpdateQuantity(t *testing.T) {
 			userRole: "manager",
 			path:     "/inventories/1",
 			requestBody: inventory.UpdateQuantityInput{
-				ProductID: 1,
-				Quantity:  -2,
+				Quantity: -2,
+				Version:  &version,
 			},
 			setup: func(hts *HandlerTestSuite) {
 				input := inventory.UpdateQuantityInput{
-					ProductID: 1,
-					Quantity:  -2,
+					Quantity: -2,
+					Version:  &version,
+					UserID:   1,
 				}
 
 				hts.MockService.On("UpdateQuantity", mock.Anything, uint(1), input).Return(nil, apperror.ErrInsufficientStock).Once()
@@ -336,6 +419,29 @@ func TestInventoryHandler_UpdateQuantity(t *testing.T) {
 				"message": "Insufficient Stock",
 			},
 		},
+		{
+			name:     "Error_BadRequest_Zero_Quantity",
+			userRole: "manager",
+			path:     "/inventories/1",
+			requestBody: inventory.UpdateQuantityRequest{
+				Quantity: 0,
+				Version:  &version,
+			},
+			setup: func(hts *HandlerTestSuite) {
+				input := inventory.UpdateQuantityInput{
+					Quantity: 0,
+					Version:  &version,
+					UserID:   1,
+				}
+
+				hts.MockService.On("UpdateQuantity", mock.Anything, uint(1), input).Return(nil, apperror.ErrInvalidInput).Once()
+			},
+			expectedStatusCode: fiber.StatusBadRequest,
+			expectedBody: fiber.Map{
+				"code":    "BAD_REQUEST",
+				"message": "quantity must not be zero",
+			},
+		},
 		{
 			name:        "Error_Forbidden_UpdateQuantity_With_Cashier",
 			userRole:    "cashier",
@@ -437,6 +543,9 @@ func TestInventoryHandler_UpdateQuantity(t *testing.T) {
 			// Create Request
 			req := httptest.NewRequest(fiber.MethodPatch, test.path, bytes.NewBuffer(body))
 			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			if test.ifMatch != "" {
+				req.Header.Set(fiber.HeaderIfMatch, test.ifMatch)
+			}
 
 			// Run test
 			res, _ := ts.App.Test(req, -1)
@@ -458,7 +567,6 @@ func TestInventoryHandler_List(t *testing.T) {
 	mockQuery := inventory.ListQuery{
 		Limit:  10,
 		Offset: 0,
-		Sort:   "ASC",
 	}
 	mockOutput := createListOutput(mockList)
 	mockResponse := createListResponse(mockOutput.Items)
@@ -474,13 +582,52 @@ func TestInventoryHandler_List(t *testing.T) {
 		{
 			name:     "Success_Get_All_Inventory",
 			userRole: "manager",
-			path:     "/inventories?limit=10&offset=0&sort=ASC",
+			path:     "/inventories?limit=10&offset=0",
 			setup: func(hts *HandlerTestSuite) {
 				hts.MockService.On("List", mock.Anything, mockQuery).Return(mockOutput, nil).Once()
 			},
 			expectedStatusCode: fiber.StatusOK,
 			expectedBody:       mockResponse,
 		},
+		{
+			name:     "Success_Sort_And_Filter",
+			userRole: "manager",
+			path:     "/inventories?sort=-quantity,id&quantity[lte]=5&location=A-01",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("List", mock.Anything, inventory.ListQuery{
+					Limit: 10,
+					Sort:  []query.Sort{{Column: "quantity", Desc: true}, {Column: "id"}},
+					Filters: []query.Filter{
+						{Column: "COALESCE(location, '')", Op: query.OpEq, Value: "A-01"},
+						{Column: "quantity", Op: query.OpLte, Value: int64(5)},
+					},
+				}).Return(mockOutput, nil).Once()
+			},
+			expectedStatusCode: fiber.StatusOK,
+			expectedBody:       mockResponse,
+		},
+		{
+			name:               "Error_BadRequest_Invalid_Sort",
+			userRole:           "manager",
+			path:               "/inventories?sort=ASC",
+			setup:              func(hts *HandlerTestSuite) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+			expectedBody: fiber.Map{
+				"code":    "BAD_REQUEST",
+				"message": "invalid sort request",
+			},
+		},
+		{
+			name:               "Error_BadRequest_Unknown_Filter_Op",
+			userRole:           "manager",
+			path:               "/inventories?quantity[like]=1",
+			setup:              func(hts *HandlerTestSuite) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+			expectedBody: fiber.Map{
+				"code":    "BAD_REQUEST",
+				"message": "invalid filter quantity[like] request",
+			},
+		},
 		{
 			name:               "Error_Forbidden_By_Cashier",
 			userRole:           "cashier",
@@ -4,6 +4,8 @@ import (
 	"ans-spareparts-api/internal/domain"
 	"ans-spareparts-api/internal/infra/httpx/ctxlog"
 	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/cursor"
+	"ans-spareparts-api/pkg/query"
 	"context"
 	"time"
 
@@ -17,7 +19,8 @@ type Repository interface {
 	GetByID(ctx context.Context, invID uint) (*domain.Inventory, error)
 	GetByProductID(ctx context.Context, productID uint) (*domain.Inventory, error)
 	List(ctx context.Context, q ListQuery) ([]*domain.Inventory, int64, error)
-	UpdateQuantity(ctx context.Context, productID uint, quantity int) error
+	// Export เรียก fn ทีละแถวจาก cursor ไม่โหลดทั้งหมดเข้าหน่วยความจำ (ใช้ Filters/Sort เดียวกับ List)
+	Export(ctx context.Context, q ListQuery, fn func(*ExportRow) error) error
 
 	// ใช้ที่ Product Interactor เมื่อสร้าง Product หรือ ลบ Products
 	Create(ctx context.Context, inventory *domain.Inventory) (*domain.Inventory, error)
@@ -87,7 +90,7 @@ func (r *repository) GetByProductID(ctx context.Context, pID uint) (*domain.Inve
 	}
 
 	// set cache
-	if err := r.cache.set(ctx, r.cache.keyID(pID), &inventory); err != nil {
+	if err := r.cache.set(ctx, r.cache.keyProductID(pID), &inventory); err != nil {
 		log.Warn("repo.inventory.getByProductID.set_cache.fail", zap.Error(err))
 	}
 
@@ -100,26 +103,29 @@ func (r *repository) List(ctx context.Context, q ListQuery) ([]*domain.Inventory
 	start := time.Now()
 
 	// Query Builder Session
-	tx := r.db.WithContext(ctx).Find(&domain.Inventory{})
+	tx := r.db.WithContext(ctx).Model(&domain.Inventory{})
+	tx = query.Where(tx, q.Filters)
+	sorts := listSchema.SortOrDefault(q.Sort)
 
-	// Count รวท
 	var total int64
-	if err := tx.Count(&total).Error; err != nil {
-		m := apperror.MapDBError("repo.inventory.list", err)
-		log.Warn("repo.inventory.list.count_err", zap.Error(err))
-		return nil, 0, m
-	}
-
-	if q.Sort != "" {
-		tx = tx.Order(q.Sort)
+	if q.Keyset {
+		// keyset: ไม่ count และดึงเกินมา 1 แถวเพื่อรู้ว่ามีหน้าถัดไปไหม
+		tx = cursor.Apply(tx, sorts, q.After).Limit(q.Limit + 1)
 	} else {
-		tx = tx.Order("created_at DESC")
-	}
-	if q.Offset > 0 {
-		tx = tx.Offset(q.Offset)
-	}
-	if q.Limit > 0 {
-		tx = tx.Limit(q.Limit)
+		// Count รวท
+		if err := tx.Count(&total).Error; err != nil {
+			m := apperror.MapDBError("repo.inventory.list", err)
+			log.Warn("repo.inventory.list.count_err", zap.Error(err))
+			return nil, 0, m
+		}
+
+		tx = query.Order(tx, sorts)
+		if q.Offset > 0 {
+			tx = tx.Offset(q.Offset)
+		}
+		if q.Limit > 0 {
+			tx = tx.Limit(q.Limit)
+		}
 	}
 
 	// Find with Query Builder Session
@@ -144,7 +150,7 @@ func (r *repository) Create(ctx context.Context, inventory *domain.Inventory) (*
 		return nil, m
 	}
 
-	if err := r.cache.del(ctx, r.cache.keyID(inventory.ProductID)); err != nil {
+	if err := r.cache.del(ctx, r.cache.keyID(inventory.ID), r.cache.keyProductID(inventory.ProductID)); err != nil {
 		log.Warn("repo.inventory.create.cache.del_error", zap.Error(err))
 	}
 
@@ -152,52 +158,73 @@ func (r *repository) Create(ctx context.Context, inventory *domain.Inventory) (*
 	return inventory, nil
 }
 
-// delta สามารถเป็นค่า + หรือ - ได้
-func (r *repository) UpdateQuantity(ctx context.Context, pID uint, delta int) error {
-	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		log := ctxlog.From(ctx)
-		start := time.Now()
-
-		var inventory domain.Inventory
-
-		// SELECT FOR UPDATE ล็อกแถวที่ถูกเลือกเพื่อป้องกันไม่ให้ข้อมูลถูกลบหร่ือแก้ไข
-		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
-			Where("product_id", pID).
-			First(&inventory).Error; err != nil {
-
-			m := apperror.MapDBError("repo.inventory.updateQuantity", err)
-			log.Debug("repo.inventory.updatequantity.findinventory.db_error", zap.Error(err), zap.Duration("duration", time.Since(start)))
-			return m
-		}
-
-		if err := tx.Model(&inventory).Where("product_id = ?", pID).
-			Update("quantity", gorm.Expr("quantity + ?", delta)).Error; err != nil {
-
-			m := apperror.MapDBError("repo.inventory.updatequantity", err)
-			log.Debug("repo.inventory.updatequantity.db_error", zap.Error(err), zap.Duration("duration", time.Since(start)))
-			return m
-		}
-
-		log.Debug("repo.inventory.updatequantity.ok", zap.Uint("product_id", pID), zap.Duration("duration", time.Since(start)))
-		return nil
-	})
-}
-
 func (r *repository) Delete(ctx context.Context, pID uint) error {
 	log := ctxlog.From(ctx)
 	start := time.Now()
 
-	if err := r.db.WithContext(ctx).Where("product_id = ?", pID).Delete(&domain.Inventory{}).Error; err != nil {
+	// RETURNING id เพื่อลบ cache ตาม inventory id ด้วย
+	var deleted []domain.Inventory
+	if err := r.db.WithContext(ctx).Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).
+		Where("product_id = ?", pID).Delete(&deleted).Error; err != nil {
 		m := apperror.MapDBError("repo.inventory.delete", err)
 		log.Debug("repo.inventory.delete.db_error", zap.Error(err), zap.Duration("duration", time.Since(start)))
 		return m
 	}
 
 	// del cache
-	if err := r.cache.del(ctx, r.cache.keyID(pID)); err != nil {
+	keys := []string{r.cache.keyProductID(pID)}
+	for _, inv := range deleted {
+		keys = append(keys, r.cache.keyID(inv.ID))
+	}
+	if err := r.cache.del(ctx, keys...); err != nil {
 		log.Warn("repo.inventory.delete.cache_err", zap.Error(err))
 	}
 
 	log.Debug("repo.inventory.delete.ok", zap.Uint("product_id", pID), zap.Duration("duration", time.Since(start)))
 	return nil
 }
+
+func (r *repository) Export(ctx context.Context, q ListQuery, fn func(*ExportRow) error) error {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	// คอลัมน์ใน listSchema ไม่ระบุตาราง จึงห่อ join เป็น subquery ให้ชื่อคอลัมน์ไม่ชนกับ products
+	joined := r.db.WithContext(ctx).Table("inventories i").
+		Select("i.id, i.product_id, p.sku, p.name, i.location, i.quantity, i.avg_cost, ROUND((i.quantity * i.avg_cost)::numeric, 2) AS value, i.created_at, i.updated_at").
+		Joins("JOIN products p ON p.id = i.product_id AND p.deleted_at IS NULL").
+		Where("i.deleted_at IS NULL")
+
+	tx := r.db.WithContext(ctx).Table("(?) AS inventories", joined)
+	tx = query.Where(tx, q.Filters)
+	tx = query.Order(tx, listSchema.SortOrDefault(q.Sort))
+
+	rows, err := tx.Rows()
+	if err != nil {
+		m := apperror.MapDBError("repo.inventory.export", err)
+		log.Debug("repo.inventory.export.query_fail", zap.Error(err))
+		return m
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		var row ExportRow
+		if err := tx.ScanRows(rows, &row); err != nil {
+			m := apperror.MapDBError("repo.inventory.export.scan", err)
+			log.Debug("repo.inventory.export.scan_fail", zap.Error(err))
+			return m
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		m := apperror.MapDBError("repo.inventory.export.rows", err)
+		log.Debug("repo.inventory.export.rows_fail", zap.Error(err))
+		return m
+	}
+
+	log.Debug("repo.inventory.export.ok", zap.Int("rows", n), zap.Duration("duration", time.Since(start)))
+	return nil
+}
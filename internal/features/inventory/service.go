@@ -1,11 +1,17 @@
 package inventory
 
 import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/features/audit"
 	"ans-spareparts-api/internal/infra/httpx/ctxlog"
 	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/cursor"
+	"ans-spareparts-api/pkg/export"
 	"ans-spareparts-api/pkg/utils"
 
 	"context"
+	"errors"
+	"io"
 
 	"go.uber.org/zap"
 )
@@ -15,15 +21,27 @@ type Service interface {
 	GetInventoryByProductID(ctx context.Context, productID uint) (*Item, error)
 	List(ctx context.Context, q ListQuery) (*ListOutput, error)
 	UpdateQuantity(ctx context.Context, id uint, input UpdateQuantityInput) (*Item, error)
+	// ExportInventories เขียนสต็อกตามเงื่อนไขของ List ลง out ทีละแถวในรูปแบบ csv/xlsx
+	ExportInventories(ctx context.Context, q ListQuery, format string, out io.Writer) error
+}
+
+// Ledger ปรับยอดผ่าน stock ledger (stock.Repository) ให้การปรับยอดมือมี movement และ cost layer
+// เหมือนการรับเข้า/ตัดออก ไม่งั้นรายงานมูลค่าสต็อกไม่ตรงกับยอดใน inventories
+type Ledger interface {
+	Adjust(ctx context.Context, m *domain.StockMovement, inventoryID uint, version *uint) (*domain.Inventory, error)
 }
 
 type service struct {
 	inventoryRepo Repository
+	ledger        Ledger
+	auditor       audit.Recorder
 }
 
-func NewService(inventoryRepo Repository) Service {
+func NewService(inventoryRepo Repository, ledger Ledger, auditor audit.Recorder) Service {
 	return &service{
 		inventoryRepo: inventoryRepo,
+		ledger:        ledger,
+		auditor:       auditor,
 	}
 }
 
@@ -32,7 +50,7 @@ func (i *service) GetInventoryByID(ctx context.Context, ivnID uint) (*Item, erro
 	if err != nil {
 		return nil, err
 	}
-	return &Item{ID: inventory.ID, ProductID: inventory.ProductID, Quantity: inventory.Quantity}, nil
+	return &Item{ID: inventory.ID, ProductID: inventory.ProductID, Quantity: inventory.Quantity, Version: inventory.Version, UpdatedAt: inventory.UpdatedAt}, nil
 }
 
 func (i *service) GetInventoryByProductID(ctx context.Context, productID uint) (*Item, error) {
@@ -40,57 +58,113 @@ func (i *service) GetInventoryByProductID(ctx context.Context, productID uint) (
 	if err != nil {
 		return nil, err
 	}
-	return &Item{ID: inventory.ID, ProductID: inventory.ProductID, Quantity: inventory.Quantity}, nil
+	return &Item{ID: inventory.ID, ProductID: inventory.ProductID, Quantity: inventory.Quantity, Version: inventory.Version, UpdatedAt: inventory.UpdatedAt}, nil
 }
 
 func (i *service) List(ctx context.Context, q ListQuery) (*ListOutput, error) {
 	limit, offset := utils.NormalizePagination(q.Limit, q.Offset)
 	rows, total, err := i.inventoryRepo.List(ctx, ListQuery{
-		Limit:  limit,
-		Offset: offset,
-		Sort:   q.Sort,
+		Limit:   limit,
+		Offset:  offset,
+		Sort:    q.Sort,
+		Filters: q.Filters,
+		Keyset:  q.Keyset,
+		After:   q.After,
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	var next string
+	if q.Keyset {
+		sorts := listSchema.SortOrDefault(q.Sort)
+		rows, next = cursor.Page(rows, limit, func(inv *domain.Inventory) cursor.Cursor {
+			return cursor.New(sorts, inv.ID, func(column string) any { return sortValue(inv, column) })
+		})
+	}
+
 	items := make([]*Item,  len(rows))
 	for i, inv := range rows {
 		items[i] = &Item{
 			ID:        inv.ID,
 			ProductID: inv.ProductID,
 			Quantity:  inv.Quantity,
+			Version:   inv.Version,
 		}
 	}
-	return &ListOutput{Items: items, Total: total}, nil
+	return &ListOutput{Items: items, Total: total, NextCursor: next}, nil
 }
 
 func (i *service) UpdateQuantity(ctx context.Context, id uint, input UpdateQuantityInput) (*Item, error) {
 	log := ctxlog.From(ctx)
 
-	// check inventory exist
-	inventory, err := i.inventoryRepo.GetByID(ctx, id)
-	if inventory == nil {
-		return nil, apperror.ErrNotFound
-	} else if err != nil {
-		return nil, err
-	}
-
-	// check validquantity quantity สามารถติดลบได้
-	// เงื่อนไขจำนวนที่ลดต้องไม่มากกว่า ค่า b เพราะหลังจากลดค่าไปแล้วจะติดลบ
-	if input.Quantity < 0 && input.Quantity*-1 > inventory.Quantity {
-		return nil, apperror.ErrInsufficientStock
+	if input.Quantity == 0 {
+		return nil, apperror.ErrInvalidInput
 	}
 
-	// Update stock
-	if err := i.inventoryRepo.UpdateQuantity(ctx, input.ProductID, input.Quantity); err != nil {
+	// ไม่อ่านก่อนจาก GetByID (อาจเป็นค่าจาก cache) ให้ ledger ล็อกแถวของ id จาก path
+	// แล้วตรวจ version และยอดคงเหลือกับแถวนั้นเอง
+	updated, err := i.ledger.Adjust(ctx, &domain.StockMovement{
+		Quantity:  input.Quantity,
+		CreatedBy: input.UserID,
+	}, id, input.Version)
+	if err != nil {
+		if errors.Is(err, apperror.ErrVersionConflict) {
+			log.Info("inventory.quantity.version_conflict", zap.Uint("id", id))
+		}
 		return nil, err
 	}
 
-	log.Info("inventory.quantity.updated", zap.Uint("product_id", input.ProductID))
+	// แถวถูกล็อกระหว่างปรับ ค่าเดิมจึงคำนวณย้อนจากแถวที่ commit ได้ตรง
+	i.auditor.Record(ctx, audit.Entry{
+		EntityType: audit.EntityInventory,
+		EntityID:   updated.ID,
+		Action:     domain.AuditUpdate,
+		Changes: map[string]audit.Change{
+			"quantity": {Old: updated.Quantity - input.Quantity, New: updated.Quantity},
+			"version":  {Old: updated.Version - 1, New: updated.Version},
+		},
+	})
+	log.Info("inventory.quantity.updated", zap.Uint("inventory_id", updated.ID), zap.Uint("product_id", updated.ProductID))
 	return &Item{
-		ID:        inventory.ID,
-		ProductID: inventory.ProductID,
-		Quantity:  inventory.Quantity + input.Quantity,
+		ID:        updated.ID,
+		ProductID: updated.ProductID,
+		Quantity:  updated.Quantity,
+		Version:   updated.Version,
+		UpdatedAt: updated.UpdatedAt,
 	}, nil
 }
+
+var exportHeader = []string{"product_id", "sku", "name", "location", "quantity", "avg_cost", "value", "updated_at"}
+
+// exportErrorRow แถวท้ายไฟล์เมื่อ export ล้มกลางทาง: status 200 ถูกส่งไปแล้ว จึงบอกผู้ใช้ว่าไฟล์ไม่ครบด้วยแถวนี้
+var exportErrorRow = []any{"#ERROR", "export incomplete"}
+
+func (i *service) ExportInventories(ctx context.Context, q ListQuery, format string, out io.Writer) error {
+	log := ctxlog.From(ctx)
+
+	w, err := export.NewWriter(out, format, exportHeader)
+	if err != nil {
+		return err
+	}
+
+	n := 0
+	err = i.inventoryRepo.Export(ctx, ListQuery{Sort: q.Sort, Filters: q.Filters}, func(r *ExportRow) error {
+		n++
+		return w.Write([]any{r.ProductID, r.SKU, r.Name, r.Location, r.Quantity, r.AvgCost, r.Value, r.UpdatedAt})
+	})
+	if err != nil {
+		log.Error("inventory.export.failed", zap.Int("rows", n), zap.Error(err))
+		if werr := w.Write(exportErrorRow); werr == nil {
+			_ = w.Close()
+		}
+		return err
+	}
+	if err := w.Close(); err != nil {
+		log.Error("inventory.export.flush_failed", zap.Error(err))
+		return err
+	}
+
+	log.Info("inventory.exported", zap.String("format", format), zap.Int("rows", n))
+	return nil
+}
@@ -1,19 +1,28 @@
 package inventory_test
 
 import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/features/audit"
 	"ans-spareparts-api/internal/features/inventory"
 	mocks "ans-spareparts-api/internal/mock"
 	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/export"
+	"ans-spareparts-api/pkg/query"
 	"ans-spareparts-api/pkg/testutil/fixtures"
+	"bytes"
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 type TestSuite struct {
 	Service       inventory.Service
 	MockInventory *mocks.InventoryRepository
+	MockStock     *mocks.StockRepository
+	MockAudit     *mocks.AuditService
 	Ctx           context.Context
 }
 
@@ -23,11 +32,16 @@ func NewTestSuite() *TestSuite {
 
 func (ts *TestSuite) SetupTest(t *testing.T) {
 	ts.MockInventory = mocks.NewMockInventoryRepository()
-	ts.Service = inventory.NewService(ts.MockInventory)
+	ts.MockStock = mocks.NewMockStockRepository()
+	// audit เป็น best-effort: ยอมรับทุกการเรียก แล้วตรวจผ่าน MockAudit.Calls ใน test ที่สนใจ
+	ts.MockAudit = mocks.NewAuditService()
+	ts.MockAudit.On("Record", mock.Anything, mock.Anything).Maybe()
+	ts.Service = inventory.NewService(ts.MockInventory, ts.MockStock, ts.MockAudit)
 	ts.Ctx = context.Background()
 
 	t.Cleanup(func() {
 		ts.MockInventory.AssertExpectations(t)
+		ts.MockStock.AssertExpectations(t)
 	})
 }
 func TestInventoryService_GetByID(t *testing.T) {
@@ -255,7 +269,7 @@ func TestInventoryService_List(t *testing.T) {
 }
 
 func TestInventoryService_UpdateQuantity(t *testing.T) {
-	mockinv := fixtures.ValidInventory()
+	version := func(v uint) *uint { return &v }
 
 	tests := []struct {
 		name      string
@@ -269,20 +283,16 @@ func TestInventoryService_UpdateQuantity(t *testing.T) {
 			name: "updatequantity_positivevalue_successfull",
 			id:   uint(1),
 			input: inventory.UpdateQuantityInput{
-				ProductID: 1,
-				Quantity:  1,
+				Quantity: 1,
+				Version:  version(0),
 			},
 			setup: func(ts *TestSuite) {
-				mockinv := fixtures.ValidInventory()
-				ts.MockInventory.On("GetByID", ts.Ctx, uint(1)).Return(mockinv, nil).Once()
-				ts.MockInventory.On("UpdateQuantity", ts.Ctx, uint(1), int(1)).Return(nil)
+				ts.MockStock.On("Adjust", ts.Ctx, &domain.StockMovement{Quantity: 1}, uint(1), version(0)).Return(&domain.Inventory{ID: 1, ProductID: 1, Quantity: 2, Version: 1}, nil).Once()
 			},
 			assertErr: func(t *testing.T, err error) {
 				assert.NoError(t, err)
 			},
 			validate: func(t *testing.T, i *inventory.Item) {
-				mockinv := fixtures.ValidInventory()
-				mockinv.Quantity++
 				assert.NotNil(t, i)
 				assert.Equal(t, uint(1), i.ID)
 				assert.Equal(t, 2, i.Quantity)
@@ -292,39 +302,64 @@ func TestInventoryService_UpdateQuantity(t *testing.T) {
 			name: "updatequantity_negativevalue_successfull",
 			id:   uint(1),
 			input: inventory.UpdateQuantityInput{
-				ProductID: 1,
-				Quantity:  -1,
+				Quantity: -1,
 			},
 			setup: func(ts *TestSuite) {
-				mockinv := fixtures.ValidInventory()
-				ts.MockInventory.On("GetByID", ts.Ctx, uint(1)).Return(mockinv, nil).Once()
-				ts.MockInventory.On("UpdateQuantity", ts.Ctx, uint(1), int(-1)).Return(nil)
+				ts.MockStock.On("Adjust", ts.Ctx, &domain.StockMovement{Quantity: -1}, uint(1), (*uint)(nil)).Return(&domain.Inventory{ID: 1, ProductID: 1, Quantity: 0, Version: 1}, nil).Once()
 			},
 			assertErr: func(t *testing.T, err error) {
-				assert.Nil(t, err)
 				assert.NoError(t, err)
 			},
 			validate: func(t *testing.T, i *inventory.Item) {
-				mockinv.Quantity--
 				assert.NotNil(t, i)
-				assert.Equal(t, mockinv.ID, i.ID)
-				assert.Equal(t, mockinv.Quantity, i.Quantity)
+				assert.Equal(t, uint(1), i.ID)
+				assert.Equal(t, 0, i.Quantity)
 			},
 		},
 		{
 			name: "updatequantity_negativevalue_error_outofstock",
 			id:   1,
 			input: inventory.UpdateQuantityInput{
-				ProductID: 1,
-				Quantity:  -2,
+				Quantity: -2,
 			},
 			setup: func(ts *TestSuite) {
-				mockinv := fixtures.ValidInventory()
-				ts.MockInventory.On("GetByID", ts.Ctx, uint(1)).Return(mockinv, nil).Once()
+				ts.MockStock.On("Adjust", ts.Ctx, &domain.StockMovement{Quantity: -2}, uint(1), (*uint)(nil)).Return(nil, apperror.ErrInsufficientStock).Once()
 			},
 			assertErr: func(t *testing.T, err error) {
-				assert.NotNil(t, err)
-				assert.ErrorIs(t, apperror.ErrInsufficientStock, err)
+				assert.ErrorIs(t, err, apperror.ErrInsufficientStock)
+			},
+			validate: func(t *testing.T, i *inventory.Item) {
+				assert.Nil(t, i)
+			},
+		},
+		{
+			name: "updatequantity_error_version_conflict",
+			id:   uint(1),
+			input: inventory.UpdateQuantityInput{
+				Quantity: 1,
+				Version:  version(3),
+			},
+			setup: func(ts *TestSuite) {
+				ts.MockStock.On("Adjust", ts.Ctx, &domain.StockMovement{Quantity: 1}, uint(1), version(3)).Return(nil, apperror.ErrVersionConflict).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrVersionConflict)
+			},
+			validate: func(t *testing.T, i *inventory.Item) {
+				assert.Nil(t, i)
+			},
+		},
+		{
+			name: "updatequantity_error_notfound",
+			id:   uint(99),
+			input: inventory.UpdateQuantityInput{
+				Quantity: 1,
+			},
+			setup: func(ts *TestSuite) {
+				ts.MockStock.On("Adjust", ts.Ctx, &domain.StockMovement{Quantity: 1}, uint(99), (*uint)(nil)).Return(nil, apperror.ErrNotFound).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrNotFound)
 			},
 			validate: func(t *testing.T, i *inventory.Item) {
 				assert.Nil(t, i)
@@ -334,17 +369,28 @@ func TestInventoryService_UpdateQuantity(t *testing.T) {
 			name: "updatequantity_dberror",
 			id:   uint(1),
 			input: inventory.UpdateQuantityInput{
-				ProductID: 1,
-				Quantity:  1,
+				Quantity: 1,
 			},
 			setup: func(ts *TestSuite) {
-				mockinv := fixtures.ValidInventory()
-				ts.MockInventory.On("GetByID", ts.Ctx, uint(1)).Return(mockinv, nil).Once()
-				ts.MockInventory.On("UpdateQuantity", ts.Ctx, uint(1), int(1)).Return(apperror.ErrInternalServer)
+				ts.MockStock.On("Adjust", ts.Ctx, &domain.StockMovement{Quantity: 1}, uint(1), (*uint)(nil)).Return(nil, apperror.ErrInternalServer).Once()
 			},
 			assertErr: func(t *testing.T, err error) {
-				assert.NotNil(t, err)
-				assert.ErrorIs(t, apperror.ErrInternalServer, err)
+				assert.ErrorIs(t, err, apperror.ErrInternalServer)
+			},
+			validate: func(t *testing.T, i *inventory.Item) {
+				assert.Nil(t, i)
+			},
+		},
+		{
+			name: "updatequantity_zero_invalidinput",
+			id:   uint(1),
+			input: inventory.UpdateQuantityInput{
+				Quantity: 0,
+				Version:  version(1),
+			},
+			setup: func(ts *TestSuite) {},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidInput)
 			},
 			validate: func(t *testing.T, i *inventory.Item) {
 				assert.Nil(t, i)
@@ -366,3 +412,144 @@ func TestInventoryService_UpdateQuantity(t *testing.T) {
 		})
 	}
 }
+
+// GetByID อาจคืนแถวอื่นจาก cache (เช่น key ชนกัน) การปรับยอดต้องไม่ใช้ id/version ของแถวนั้น
+func TestInventoryService_UpdateQuantity_Targets_Path_ID_Not_Cached_Row(t *testing.T) {
+	ts := NewTestSuite()
+	ts.SetupTest(t)
+
+	other := fixtures.ValidInventory()
+	other.ID = 5
+	other.ProductID = 2
+	other.Version = 9
+	ts.MockInventory.On("GetByID", ts.Ctx, uint(2)).Return(other, nil).Maybe()
+
+	v := uint(4)
+	ts.MockStock.On("Adjust", ts.Ctx, &domain.StockMovement{Quantity: 3}, uint(2), &v).Return(&domain.Inventory{ID: 2, ProductID: 7, Quantity: 8, Version: 5}, nil).Once()
+
+	item, err := ts.Service.UpdateQuantity(ts.Ctx, 2, inventory.UpdateQuantityInput{Quantity: 3, Version: &v})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), item.ID)
+	assert.Equal(t, uint(7), item.ProductID)
+	assert.Equal(t, uint(5), item.Version)
+	ts.MockInventory.AssertNotCalled(t, "GetByID", ts.Ctx, uint(2))
+
+	entry := ts.MockAudit.Calls[0].Arguments.Get(1).(audit.Entry)
+	assert.Equal(t, uint(2), entry.EntityID)
+}
+
+func TestInventoryService_UpdateQuantity_AuditsCommittedRow(t *testing.T) {
+	ts := NewTestSuite()
+	ts.SetupTest(t)
+
+	v := uint(2)
+	// ค่าที่ commit จริงต้องมาจาก ledger (แถวที่ล็อกไว้) ไม่ใช่ค่าที่อ่านไว้ก่อน
+	ts.MockStock.On("Adjust", ts.Ctx, &domain.StockMovement{Quantity: -3, CreatedBy: 4}, uint(1), &v).Return(&domain.Inventory{ID: 1, ProductID: 1, Quantity: 7, Version: 3}, nil).Once()
+
+	item, err := ts.Service.UpdateQuantity(ts.Ctx, 1, inventory.UpdateQuantityInput{Quantity: -3, Version: &v, UserID: 4})
+	assert.NoError(t, err)
+	assert.Equal(t, 7, item.Quantity)
+	assert.Equal(t, uint(3), item.Version)
+
+	ts.MockAudit.AssertNumberOfCalls(t, "Record", 1)
+	entry := ts.MockAudit.Calls[0].Arguments.Get(1).(audit.Entry)
+	assert.Equal(t, audit.EntityInventory, entry.EntityType)
+	assert.Equal(t, map[string]audit.Change{
+		"quantity": {Old: 10, New: 7},
+		"version":  {Old: uint(2), New: uint(3)},
+	}, entry.Changes)
+}
+
+func TestInventoryService_ExportInventories(t *testing.T) {
+	updated := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := []*inventory.ExportRow{
+		{ProductID: 1, SKU: "BRK-001", Name: "Brake pad", Location: "A-01", Quantity: 10, AvgCost: 300.25, Value: 3002.5, UpdatedAt: updated},
+	}
+	listQuery := inventory.ListQuery{
+		Sort:    []query.Sort{{Column: "quantity", Desc: true}},
+		Filters: []query.Filter{{Column: "COALESCE(location, '')", Op: query.OpEq, Value: "A-01"}},
+	}
+
+	tests := []struct {
+		name      string
+		format    string
+		setup     func(*TestSuite)
+		assertErr func(*testing.T, error)
+		validate  func(*testing.T, string)
+	}{
+		{
+			name:   "export_csv_successfull",
+			format: export.FormatCSV,
+			setup: func(ts *TestSuite) {
+				ts.MockInventory.On("Export", ts.Ctx, listQuery, mock.Anything).Return(rows, nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, out string) {
+				assert.Equal(t,
+					"product_id,sku,name,location,quantity,avg_cost,value,updated_at\n"+
+						"1,BRK-001,Brake pad,A-01,10,300.25,3002.5,2025-01-02T03:04:05Z\n",
+					out)
+			},
+		},
+		{
+			name:   "export_fail_unsupported_format",
+			format: "json",
+			setup:  func(ts *TestSuite) {},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, export.ErrUnsupportedFormat)
+			},
+			validate: func(t *testing.T, out string) {
+				assert.Empty(t, out)
+			},
+		},
+		{
+			name:   "export_fail_repo_error",
+			format: export.FormatCSV,
+			setup: func(ts *TestSuite) {
+				ts.MockInventory.On("Export", ts.Ctx, listQuery, mock.Anything).Return(nil, apperror.ErrInternalServer).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInternalServer)
+			},
+			validate: func(t *testing.T, out string) {
+				assert.Equal(t,
+					"product_id,sku,name,location,quantity,avg_cost,value,updated_at\n"+
+						"#ERROR,export incomplete\n",
+					out)
+			},
+		},
+		{
+			name:   "export_fail_mid_stream_marks_trailer",
+			format: export.FormatCSV,
+			setup: func(ts *TestSuite) {
+				ts.MockInventory.On("Export", ts.Ctx, listQuery, mock.Anything).Return(rows, apperror.ErrInternalServer).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInternalServer)
+			},
+			validate: func(t *testing.T, out string) {
+				assert.Equal(t,
+					"product_id,sku,name,location,quantity,avg_cost,value,updated_at\n"+
+						"1,BRK-001,Brake pad,A-01,10,300.25,3002.5,2025-01-02T03:04:05Z\n"+
+						"#ERROR,export incomplete\n",
+					out)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewTestSuite()
+			ts.SetupTest(t)
+
+			test.setup(ts)
+			out := &bytes.Buffer{}
+			err := ts.Service.ExportInventories(ts.Ctx, listQuery, test.format, out)
+
+			test.assertErr(t, err)
+			test.validate(t, out.String())
+		})
+	}
+}
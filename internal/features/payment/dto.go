@@ -0,0 +1,58 @@
+package payment
+
+type Tender struct {
+	Method    string
+	Amount    float64
+	Reference string
+}
+
+type TenderInput struct {
+	Due     float64
+	Tenders []Tender
+}
+
+// TenderResult ผลการรับชำระ: ยอดรับรวม และเงินทอน (ทอนจากเงินสดเท่านั้น)
+type TenderResult struct {
+	Due         float64
+	Paid        float64
+	ChangeGiven float64
+	Tenders     []Tender
+}
+
+type QRItem struct {
+	Amount  float64
+	Payload string
+}
+
+type PromptPayQRRequest struct {
+	Amount float64 `json:"amount"`
+}
+
+type PromptPayQRResponse struct {
+	Amount  float64 `json:"amount"`
+	Payload string  `json:"payload"`
+}
+
+type TenderRequest struct {
+	Due      float64             `json:"due"`
+	Payments []TenderLineRequest `json:"payments"`
+}
+
+type TenderLineRequest struct {
+	Method    string  `json:"method"`
+	Amount    float64 `json:"amount"`
+	Reference string  `json:"reference"`
+}
+
+type TenderResponse struct {
+	Due         float64              `json:"due"`
+	Paid        float64              `json:"paid"`
+	ChangeGiven float64              `json:"change_given"`
+	Payments    []TenderLineResponse `json:"payments"`
+}
+
+type TenderLineResponse struct {
+	Method    string  `json:"method"`
+	Amount    float64 `json:"amount"`
+	Reference string  `json:"reference,omitempty"`
+}
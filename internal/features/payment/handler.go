@@ -0,0 +1,110 @@
+package payment
+
+import (
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/response"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// mapError แปลง error จาก service เป็น response มาตรฐาน
+func mapError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, apperror.ErrInvalidInput):
+		return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid input")
+	case errors.Is(err, ErrPromptPayNotConfigured):
+		return response.Error(c, fiber.StatusServiceUnavailable, "PROMPTPAY_NOT_CONFIGURED", "promptpay is not configured")
+	}
+	return response.Error(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "internal server occured")
+}
+
+// PromptPayQR godoc
+// @Summary Generate PromptPay QR payload
+// @Description Generate an EMVCo PromptPay dynamic QR payload for the due amount
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param request body PromptPayQRRequest true "Due amount"
+// @Success 200 {object} PromptPayQRResponse
+// @Failure 400 {object} response.ErrorBody
+// @Failure 503 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /payments/promptpay-qr [post]
+func (h *Handler) PromptPayQR(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+
+	var req PromptPayQRRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Warn("handler.payment.promptpay.invalid_body", zap.Error(err))
+		return response.Error(
+			c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid request body",
+		)
+	}
+
+	qr, err := h.service.PromptPayQR(ctx, req.Amount)
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	return response.OK(c, PromptPayQRResponse(*qr))
+}
+
+// Tender godoc
+// @Summary Split payment tender
+// @Description Validate payments split across methods and compute change given
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param request body TenderRequest true "Tender request"
+// @Success 200 {object} TenderResponse
+// @Failure 400 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /payments/tender [post]
+func (h *Handler) Tender(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+
+	var req TenderRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Warn("handler.payment.tender.invalid_body", zap.Error(err))
+		return response.Error(
+			c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid request body",
+		)
+	}
+
+	tenders := make([]Tender, len(req.Payments))
+	for i, p := range req.Payments {
+		tenders[i] = Tender(p)
+	}
+
+	res, err := h.service.Tender(ctx, TenderInput{Due: req.Due, Tenders: tenders})
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	payments := make([]TenderLineResponse, len(res.Tenders))
+	for i, t := range res.Tenders {
+		payments[i] = TenderLineResponse(t)
+	}
+
+	return response.OK(c, TenderResponse{
+		Due:         res.Due,
+		Paid:        res.Paid,
+		ChangeGiven: res.ChangeGiven,
+		Payments:    payments,
+	})
+}
@@ -0,0 +1,139 @@
+package payment_test
+
+import (
+	"ans-spareparts-api/internal/features/payment"
+	mocks "ans-spareparts-api/internal/mock"
+	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/response"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type HandlerTestSuite struct {
+	App         *fiber.App
+	MockService *mocks.PaymentService
+	Handler     *payment.Handler
+}
+
+func NewHandlerTestSuite() *HandlerTestSuite {
+	return &HandlerTestSuite{}
+}
+
+func (ts *HandlerTestSuite) SetUpHandlerTestSuite(t *testing.T) {
+	ts.MockService = mocks.NewPaymentService()
+	ts.Handler = payment.NewHandler(ts.MockService)
+	ts.App = fiber.New(fiber.Config{
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			return response.Error(c, fiber.StatusBadRequest, "INVALID_REQUEST", "invalid request body: "+err.Error())
+		},
+	})
+
+	t.Cleanup(func() {
+		ts.MockService.AssertExpectations(t)
+	})
+}
+
+func TestPaymentHandler_PromptPayQR(t *testing.T) {
+	tests := []struct {
+		name           string
+		setup          func(*HandlerTestSuite)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name: "Success_Payload",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("PromptPayQR", mock.Anything, 150.0).Return(&payment.QRItem{Amount: 150, Payload: "000201..."}, nil).Once()
+			},
+			expectedStatus: fiber.StatusOK,
+			expectedBody: fiber.Map{
+				"amount":  150,
+				"payload": "000201...",
+			},
+		},
+		{
+			name: "Error_Not_Configured",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("PromptPayQR", mock.Anything, 150.0).Return(nil, payment.ErrPromptPayNotConfigured).Once()
+			},
+			expectedStatus: fiber.StatusServiceUnavailable,
+			expectedBody: fiber.Map{
+				"code":    "PROMPTPAY_NOT_CONFIGURED",
+				"message": "promptpay is not configured",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewHandlerTestSuite()
+			ts.SetUpHandlerTestSuite(t)
+
+			ts.App.Post("/payments/promptpay-qr", ts.Handler.PromptPayQR)
+			test.setup(ts)
+
+			body, _ := json.Marshal(payment.PromptPayQRRequest{Amount: 150})
+			req := httptest.NewRequest(fiber.MethodPost, "/payments/promptpay-qr", bytes.NewBuffer(body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+			res, _ := ts.App.Test(req, -1)
+
+			assert.Equal(t, test.expectedStatus, res.StatusCode)
+			if test.expectedBody != nil {
+				resBody, _ := io.ReadAll(res.Body)
+				expectedBody, _ := json.Marshal(test.expectedBody)
+				assert.JSONEq(t, string(expectedBody), string(resBody))
+			}
+		})
+	}
+}
+
+func TestPaymentHandler_Tender(t *testing.T) {
+	mockInput := payment.TenderInput{Due: 100, Tenders: []payment.Tender{{Method: "cash", Amount: 120}}}
+
+	tests := []struct {
+		name           string
+		setup          func(*HandlerTestSuite)
+		expectedStatus int
+	}{
+		{
+			name: "Success_With_Change",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("Tender", mock.Anything, mockInput).Return(&payment.TenderResult{Due: 100, Paid: 120, ChangeGiven: 20, Tenders: mockInput.Tenders}, nil).Once()
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name: "Error_Invalid_Input",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("Tender", mock.Anything, mockInput).Return(nil, apperror.ErrInvalidInput).Once()
+			},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewHandlerTestSuite()
+			ts.SetUpHandlerTestSuite(t)
+
+			ts.App.Post("/payments/tender", ts.Handler.Tender)
+			test.setup(ts)
+
+			body, _ := json.Marshal(payment.TenderRequest{Due: 100, Payments: []payment.TenderLineRequest{{Method: "cash", Amount: 120}}})
+			req := httptest.NewRequest(fiber.MethodPost, "/payments/tender", bytes.NewBuffer(body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+			res, _ := ts.App.Test(req, -1)
+
+			assert.Equal(t, test.expectedStatus, res.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package payment
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/promptpay"
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// ErrPromptPayNotConfigured ยังไม่ได้ตั้งค่า PROMPTPAY_ID ของร้าน
+var ErrPromptPayNotConfigured = errors.New("promptpay id is not configured")
+
+type Service interface {
+	// PromptPayQR สร้าง payload ของ PromptPay QR แบบ dynamic สำหรับยอดที่ต้องชำระ
+	PromptPayQR(ctx context.Context, amount float64) (*QRItem, error)
+	// Tender ตรวจสอบการแบ่งชำระหลายช่องทาง และคำนวณเงินทอน
+	Tender(ctx context.Context, in TenderInput) (*TenderResult, error)
+}
+
+type service struct {
+	promptPayID string
+}
+
+func NewService(promptPayID string) Service {
+	return &service{
+		promptPayID: strings.TrimSpace(promptPayID),
+	}
+}
+
+// --- helper ---
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func (s *service) PromptPayQR(ctx context.Context, amount float64) (*QRItem, error) {
+	log := ctxlog.From(ctx)
+
+	if s.promptPayID == "" {
+		log.Error("service.payment.promptpay.not_configured")
+		return nil, ErrPromptPayNotConfigured
+	}
+
+	amount = roundMoney(amount)
+	if amount <= 0 {
+		log.Warn("service.payment.promptpay.invalid_amount", zap.Float64("amount", amount))
+		return nil, apperror.ErrInvalidInput
+	}
+
+	payload, err := promptpay.Payload(s.promptPayID, amount)
+	if err != nil {
+		log.Error("service.payment.promptpay.payload_failed", zap.Error(err))
+		return nil, err
+	}
+
+	return &QRItem{
+		Amount:  amount,
+		Payload: payload,
+	}, nil
+}
+
+func (s *service) Tender(ctx context.Context, in TenderInput) (*TenderResult, error) {
+	log := ctxlog.From(ctx)
+
+	due := roundMoney(in.Due)
+	if due <= 0 || len(in.Tenders) == 0 {
+		log.Warn("service.payment.tender.invalid_input", zap.Float64("due", due), zap.Int("tenders", len(in.Tenders)))
+		return nil, apperror.ErrInvalidInput
+	}
+
+	var paid, cash float64
+	tenders := make([]Tender, 0, len(in.Tenders))
+	for _, t := range in.Tenders {
+		method := strings.ToLower(strings.TrimSpace(t.Method))
+		amount := roundMoney(t.Amount)
+		if !slices.Contains(domain.PaymentMethods, method) || amount <= 0 {
+			log.Warn("service.payment.tender.invalid_line", zap.String("method", t.Method), zap.Float64("amount", t.Amount))
+			return nil, apperror.ErrInvalidInput
+		}
+		if method == domain.PaymentMethodCash {
+			cash += amount
+		}
+		paid += amount
+		tenders = append(tenders, Tender{
+			Method:    method,
+			Amount:    amount,
+			Reference: strings.TrimSpace(t.Reference),
+		})
+	}
+	paid = roundMoney(paid)
+
+	if paid < due {
+		log.Warn("service.payment.tender.underpaid", zap.Float64("due", due), zap.Float64("paid", paid))
+		return nil, apperror.ErrInvalidInput
+	}
+
+	// เงินทอนต้องมาจากเงินสดเท่านั้น: ช่องทางอื่นรวมกันห้ามเกินยอดที่ต้องชำระ
+	change := roundMoney(paid - due)
+	if change > roundMoney(cash) {
+		log.Warn("service.payment.tender.overpaid_non_cash", zap.Float64("due", due), zap.Float64("paid", paid), zap.Float64("cash", cash))
+		return nil, apperror.ErrInvalidInput
+	}
+
+	return &TenderResult{
+		Due:         due,
+		Paid:        paid,
+		ChangeGiven: change,
+		Tenders:     tenders,
+	}, nil
+}
@@ -0,0 +1,142 @@
+package payment_test
+
+import (
+	"ans-spareparts-api/internal/features/payment"
+	"ans-spareparts-api/pkg/apperror"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentService_PromptPayQR(t *testing.T) {
+	tests := []struct {
+		name        string
+		promptPayID string
+		amount      float64
+		assertErr   func(*testing.T, error)
+		validate    func(*testing.T, *payment.QRItem)
+	}{
+		{
+			name:        "Success_Dynamic_QR",
+			promptPayID: "0812345678",
+			amount:      1250.505,
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, qr *payment.QRItem) {
+				assert.Equal(t, 1250.51, qr.Amount)
+				assert.True(t, strings.HasPrefix(qr.Payload, "000201010212"))
+				assert.Contains(t, qr.Payload, "54071250.51")
+			},
+		},
+		{
+			name:        "Error_Not_Configured",
+			promptPayID: "  ",
+			amount:      100,
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, payment.ErrPromptPayNotConfigured)
+			},
+		},
+		{
+			name:        "Error_Zero_Amount",
+			promptPayID: "0812345678",
+			amount:      0,
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidInput)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svc := payment.NewService(test.promptPayID)
+
+			qr, err := svc.PromptPayQR(context.Background(), test.amount)
+
+			test.assertErr(t, err)
+			if test.validate != nil {
+				test.validate(t, qr)
+			}
+		})
+	}
+}
+
+func TestPaymentService_Tender(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     payment.TenderInput
+		assertErr func(*testing.T, error)
+		validate  func(*testing.T, *payment.TenderResult)
+	}{
+		{
+			name: "Success_Split_Cash_And_Card_With_Change",
+			input: payment.TenderInput{Due: 1500, Tenders: []payment.Tender{
+				{Method: "card", Amount: 1000, Reference: " APPR-123 "},
+				{Method: "CASH", Amount: 1000},
+			}},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, res *payment.TenderResult) {
+				assert.Equal(t, 2000.0, res.Paid)
+				assert.Equal(t, 500.0, res.ChangeGiven)
+				assert.Equal(t, "APPR-123", res.Tenders[0].Reference)
+				assert.Equal(t, "cash", res.Tenders[1].Method)
+			},
+		},
+		{
+			name: "Success_Exact_PromptPay",
+			input: payment.TenderInput{Due: 99.5, Tenders: []payment.Tender{
+				{Method: "promptpay", Amount: 99.5},
+			}},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, res *payment.TenderResult) {
+				assert.Equal(t, 0.0, res.ChangeGiven)
+			},
+		},
+		{
+			name: "Error_Underpaid",
+			input: payment.TenderInput{Due: 500, Tenders: []payment.Tender{
+				{Method: "cash", Amount: 400},
+			}},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidInput)
+			},
+		},
+		{
+			name: "Error_Change_From_Non_Cash",
+			input: payment.TenderInput{Due: 500, Tenders: []payment.Tender{
+				{Method: "bank_transfer", Amount: 600},
+			}},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidInput)
+			},
+		},
+		{
+			name: "Error_Unknown_Method",
+			input: payment.TenderInput{Due: 100, Tenders: []payment.Tender{
+				{Method: "cheque", Amount: 100},
+			}},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidInput)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svc := payment.NewService("0812345678")
+
+			res, err := svc.Tender(context.Background(), test.input)
+
+			test.assertErr(t, err)
+			if test.validate != nil {
+				test.validate(t, res)
+			}
+		})
+	}
+}
@@ -22,6 +22,14 @@ func newCache(rdb *redis.Client, ttl time.Duration) *cacheLayer {
 	return &cacheLayer{rdb: rdb, ttl: ttl}
 }
 
+// client redis ที่ใช้ร่วมกับ cache ของ feature อื่น (nil เมื่อปิด cache)
+func (c *cacheLayer) client() *redis.Client {
+	if c == nil {
+		return nil
+	}
+	return c.rdb
+}
+
 func (c *cacheLayer) keyByID(id uint) string {
 	return fmt.Sprintf("product:id:%d", id)
 }
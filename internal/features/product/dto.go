@@ -1,10 +1,48 @@
 package product
 
 import (
+	"ans-spareparts-api/internal/domain"
 	"ans-spareparts-api/internal/features/category"
 	"ans-spareparts-api/internal/features/inventory"
+	"ans-spareparts-api/pkg/cursor"
+	"ans-spareparts-api/pkg/query"
+	"io"
+	"time"
 )
 
+// listSchema field ที่กรอง/เรียงได้ของ GET /products (และ export)
+var listSchema = query.Schema{
+	Fields: map[string]query.Field{
+		"id":          {Column: "products.id", Type: query.Int, Ops: []query.Op{query.OpEq, query.OpIn}, Sortable: true},
+		"sku":         {Column: "products.sku", Type: query.String, Ops: []query.Op{query.OpEq, query.OpLike, query.OpIn}, Sortable: true},
+		"name":        {Column: "products.name", Type: query.String, Ops: []query.Op{query.OpEq, query.OpLike}, Sortable: true},
+		"price":       {Column: "products.price", Type: query.Float, Ops: query.Comparable, Sortable: true},
+		"category_id": {Column: "products.category_id", Type: query.Int, Ops: []query.Op{query.OpEq, query.OpNe, query.OpIn}},
+		"is_active":   {Column: "products.is_active", Type: query.Bool, Ops: []query.Op{query.OpEq}},
+		"created_at":  {Column: "products.created_at", Type: query.Time, Ops: query.Comparable, Sortable: true},
+		"updated_at":  {Column: "products.updated_at", Type: query.Time, Ops: query.Comparable, Sortable: true},
+		"in_stock":    {Column: inStockColumn, Type: query.Bool, Ops: []query.Op{query.OpEq}},
+	},
+	DefaultSort: []query.Sort{{Column: "products.created_at", Desc: true}},
+}
+
+// sortValue ค่าของคอลัมน์ที่ใช้เรียง สำหรับสร้าง keyset cursor
+func sortValue(p *domain.Product, column string) any {
+	switch column {
+	case "products.id":
+		return p.ID
+	case "products.sku":
+		return p.SKU
+	case "products.name":
+		return p.Name
+	case "products.price":
+		return p.Price
+	case "products.updated_at":
+		return p.UpdatedAt
+	}
+	return p.CreatedAt
+}
+
 type CreateInput struct {
 	Name        string
 	Description string
@@ -19,13 +57,22 @@ type UpdateInput struct {
 	SKU         *string
 	Price       *float64
 	CategoryID  *uint
+	// Version version ที่ client อ่านไปก่อนแก้; nil คือไม่ตรวจ (handler บังคับส่งเสมอ)
+	Version *uint
 }
 
 type ListQuery struct {
-	Search string
-	Limit  int
-	Offset int
-	Sort   string
+	Search  string
+	Limit   int
+	Offset  int
+	Sort    []query.Sort
+	Filters []query.Filter
+
+	// Keyset ใช้ cursor แทน offset; After เป็น nil คือหน้าแรก
+	Keyset bool
+	After  *cursor.Cursor
+	// Facets คำนวณจำนวนแยกตามหมวด/ช่วงราคา/สต็อก ของผลค้นหาทั้งหมด (ไม่ใช่แค่หน้านี้)
+	Facets bool
 }
 
 type Item struct {
@@ -38,6 +85,9 @@ type Item struct {
 	CategoryID  uint
 	Category    category.CategoryResponse
 	Inventory   inventory.InventoryResponse
+	Version     uint
+	// UpdatedAt เวลาแก้ไขล่าสุดของ product หรือ inventory (ใช้ทำ ETag / Last-Modified)
+	UpdatedAt time.Time
 }
 
 type ItemLite struct {
@@ -50,8 +100,11 @@ type ItemLite struct {
 }
 
 type ListOutput struct {
-	Items []*ItemLite
-	Total int64
+	Items  []*ItemLite
+	Total  int64
+	Facets *Facets
+	// NextCursor มีเฉพาะโหมด Keyset
+	NextCursor string
 }
 
 type CreateProductRequest struct {
@@ -68,6 +121,8 @@ type UpdateProductRequest struct {
 	SKU         *string
 	Price       *float64
 	CategoryID  *uint
+	// Version ใช้แทน header If-Match ได้
+	Version *uint
 }
 
 type ProductDetailResponse struct {
@@ -79,6 +134,7 @@ type ProductDetailResponse struct {
 	CategoryID  uint
 	Category    category.CategoryResponse
 	Inventory   inventory.InventoryResponse
+	Version     uint
 }
 
 type LiteProductResponse struct {
@@ -92,4 +148,125 @@ type LiteProductResponse struct {
 type ProductListResponse struct {
 	Products []*LiteProductResponse
 	Total    int64
+	Facets   *FacetsResponse `json:"Facets,omitempty"`
+}
+
+type CategoryFacet struct {
+	ID    uint
+	Name  string
+	Count int64
+}
+
+// PriceBucket ช่วงราคา [Min, Max); Max เป็น nil คือไม่มีเพดาน
+type PriceBucket struct {
+	Min   float64
+	Max   *float64
+	Count int64
+}
+
+type Facets struct {
+	Categories   []CategoryFacet
+	PriceBuckets []PriceBucket
+	InStock      int64
+	OutOfStock   int64
+}
+
+type CategoryFacetResponse struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+type PriceBucketResponse struct {
+	Min   float64  `json:"min"`
+	Max   *float64 `json:"max"`
+	Count int64    `json:"count"`
+}
+
+type StockFacetResponse struct {
+	InStock    int64 `json:"in_stock"`
+	OutOfStock int64 `json:"out_of_stock"`
+}
+
+type FacetsResponse struct {
+	Categories []CategoryFacetResponse `json:"categories"`
+	Price      []PriceBucketResponse   `json:"price"`
+	Stock      StockFacetResponse      `json:"stock"`
+}
+
+// TrashItem สินค้าในถังขยะ
+type TrashItem struct {
+	ID        uint
+	SKU       string
+	Name      string
+	DeletedAt time.Time
+}
+
+type TrashOutput struct {
+	Items []*TrashItem
+	Total int64
+}
+
+type TrashProductResponse struct {
+	ID        uint      `json:"id" example:"1"`
+	SKU       string    `json:"sku" example:"BRK-001"`
+	Name      string    `json:"name" example:"Brake pad"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// ExportRow หนึ่งแถวของไฟล์ export สินค้า
+type ExportRow struct {
+	ID          uint
+	SKU         string
+	Name        string
+	Description string
+	Category    string
+	Price       float64
+	Quantity    int
+	IsActive    bool
+}
+
+type ImportInput struct {
+	File    io.Reader
+	Format  string
+	Mapping map[string]string // field ในระบบ -> ชื่อหัวคอลัมน์ในไฟล์
+	DryRun  bool
+}
+
+// ImportRecord สินค้าหนึ่งรายการที่จะ upsert พร้อมจำนวนตั้งต้น (ใช้เฉพาะสินค้าใหม่)
+type ImportRecord struct {
+	Product  *domain.Product
+	Quantity int
+}
+
+type ImportRowError struct {
+	Row     int
+	SKU     string
+	Message string
+}
+
+type ImportReport struct {
+	DryRun  bool
+	Total   int
+	Valid   int
+	Created int
+	Updated int
+	Failed  int
+	Errors  []ImportRowError
+}
+
+type ImportRowErrorResponse struct {
+	Row     int    `json:"row"`
+	SKU     string `json:"sku,omitempty"`
+	Message string `json:"message"`
+}
+
+type ImportResponse struct {
+	DryRun  bool                     `json:"dry_run"`
+	Total   int                      `json:"total"`
+	Valid   int                      `json:"valid"`
+	Created int                      `json:"created"`
+	Updated int                      `json:"updated"`
+	Failed  int                      `json:"failed"`
+	Errors  []ImportRowErrorResponse `json:"errors"`
 }
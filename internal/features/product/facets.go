@@ -0,0 +1,68 @@
+package product
+
+import (
+	"ans-spareparts-api/pkg/query"
+	"fmt"
+	"strings"
+)
+
+// priceBands ขอบของช่วงราคา (บาท) ที่ใช้ทำ facet: <100, 100-500, 500-1000, 1000-5000, 5000+
+var priceBands = []float64{100, 500, 1000, 5000}
+
+// inStockColumn ใช้เป็นทั้ง filter in_stock และเงื่อนไขของ facet สต็อก
+const inStockColumn = "EXISTS (SELECT 1 FROM inventories inv WHERE inv.product_id = products.id AND inv.deleted_at IS NULL AND inv.quantity > 0)"
+
+// priceBucketSQL expression ของ width_bucket: 0 คือต่ำกว่าขอบแรก, len(priceBands) คือตั้งแต่ขอบสุดท้ายขึ้นไป
+func priceBucketSQL() string {
+	bands := make([]string, len(priceBands))
+	for i, b := range priceBands {
+		bands[i] = fmt.Sprintf("%g", b)
+	}
+	return fmt.Sprintf("width_bucket(products.price, ARRAY[%s]::numeric[])", strings.Join(bands, ","))
+}
+
+// priceBuckets แปลงจำนวนต่อ bucket index เป็นช่วงราคา คืนครบทุกช่วงแม้จำนวนเป็น 0
+func priceBuckets(counts map[int]int64) []PriceBucket {
+	out := make([]PriceBucket, len(priceBands)+1)
+	for i := range out {
+		if i > 0 {
+			out[i].Min = priceBands[i-1]
+		}
+		if i < len(priceBands) {
+			max := priceBands[i]
+			out[i].Max = &max
+		}
+		out[i].Count = counts[i]
+	}
+	return out
+}
+
+// withoutColumn ตัด filter ของคอลัมน์ที่กำลังทำ facet ออก
+// เพื่อให้ sidebar ยังเห็นตัวเลือกอื่นของ facet เดียวกันหลังเลือกไปแล้ว
+func withoutColumn(filters []query.Filter, column string) []query.Filter {
+	out := make([]query.Filter, 0, len(filters))
+	for _, f := range filters {
+		if f.Column != column {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func toFacetsResponse(f *Facets) *FacetsResponse {
+	if f == nil {
+		return nil
+	}
+	res := &FacetsResponse{
+		Categories: make([]CategoryFacetResponse, len(f.Categories)),
+		Price:      make([]PriceBucketResponse, len(f.PriceBuckets)),
+		Stock:      StockFacetResponse{InStock: f.InStock, OutOfStock: f.OutOfStock},
+	}
+	for i, c := range f.Categories {
+		res.Categories[i] = CategoryFacetResponse(c)
+	}
+	for i, b := range f.PriceBuckets {
+		res.Price[i] = PriceBucketResponse(b)
+	}
+	return res
+}
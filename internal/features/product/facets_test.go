@@ -0,0 +1,37 @@
+package product
+
+import (
+	"ans-spareparts-api/pkg/query"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriceBuckets(t *testing.T) {
+	got := priceBuckets(map[int]int64{0: 3, 2: 5, 4: 1})
+
+	assert.Len(t, got, len(priceBands)+1)
+	assert.Equal(t, float64(0), got[0].Min)
+	assert.Equal(t, float64(100), *got[0].Max)
+	assert.Equal(t, int64(3), got[0].Count)
+	assert.Equal(t, int64(0), got[1].Count)
+	assert.Equal(t, float64(500), got[2].Min)
+	assert.Equal(t, float64(1000), *got[2].Max)
+	assert.Equal(t, float64(5000), got[4].Min)
+	assert.Nil(t, got[4].Max)
+	assert.Equal(t, int64(1), got[4].Count)
+}
+
+func TestPriceBucketSQL(t *testing.T) {
+	assert.Equal(t, "width_bucket(products.price, ARRAY[100,500,1000,5000]::numeric[])", priceBucketSQL())
+}
+
+func TestWithoutColumn(t *testing.T) {
+	filters := []query.Filter{
+		{Column: "products.category_id", Op: query.OpEq, Value: int64(3)},
+		{Column: "products.price", Op: query.OpGte, Value: float64(100)},
+	}
+
+	assert.Equal(t, filters[1:], withoutColumn(filters, "products.category_id"))
+	assert.Equal(t, filters, withoutColumn(filters, inStockColumn))
+}
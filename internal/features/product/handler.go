@@ -4,10 +4,18 @@ import (
 	"ans-spareparts-api/internal/infra/httpx/ctxlog"
 	"ans-spareparts-api/internal/infra/jwtx"
 	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/export"
+	"ans-spareparts-api/pkg/httpcache"
+	"ans-spareparts-api/pkg/query"
 	"ans-spareparts-api/pkg/response"
-	"ans-spareparts-api/pkg/utils"
+	"bufio"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
@@ -94,6 +102,7 @@ func (h *Handler) CreateProduct(c *fiber.Ctx) error {
 		CategoryID:  product.CategoryID,
 		Category:    product.Category,
 		Inventory:   product.Inventory,
+		Version:     product.Version,
 	})
 }
 
@@ -104,7 +113,10 @@ func (h *Handler) CreateProduct(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Product ID"
+// @Param If-None-Match header string false "ETag from a previous response"
+// @Param If-Modified-Since header string false "Last-Modified from a previous response"
 // @Success 200 {object} ProductDetailResponse
+// @Success 304 "Not Modified"
 // @Failure 400 {object} response.ErrorBody
 // @Failure 404 {object} response.ErrorBody
 // @Security BearerAuth
@@ -132,6 +144,11 @@ func (h *Handler) GetProductDetail(c *fiber.Ctx) error {
 		)
 	}
 
+	// หน้าจอหน้าร้าน poll ถี่ ถ้าไม่มีอะไรเปลี่ยนตอบ 304 ไม่ต้องส่ง body
+	if httpcache.Fresh(c, httpcache.VersionTag(product.Version, product.Inventory.Version), product.UpdatedAt) {
+		return httpcache.NotModified(c)
+	}
+
 	return response.OK(c, ProductDetailResponse{
 		ID:          product.ID,
 		Name:        product.Name,
@@ -141,6 +158,7 @@ func (h *Handler) GetProductDetail(c *fiber.Ctx) error {
 		CategoryID:  product.CategoryID,
 		Category:    product.Category,
 		Inventory:   product.Inventory,
+		Version:     product.Version,
 	})
 }
 
@@ -152,7 +170,15 @@ func (h *Handler) GetProductDetail(c *fiber.Ctx) error {
 // @Product json
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
+// @Param cursor query string false "Keyset cursor (send empty for the first page; response meta.next_cursor for the next)"
+// @Param sort query string false "Comma separated, '-' prefix for DESC: id,sku,name,price,created_at,updated_at"
+// @Param search query string false "Search name, SKU or description"
+// @Param filter query string false "field=value or field[op]=value (eq,ne,gt,gte,lt,lte,like,in) on id,sku,name,price,category_id,is_active,in_stock,created_at,updated_at"
+// @Param facets query bool false "Include category, price band and stock facet counts"
+// @Param If-None-Match header string false "ETag from a previous response"
 // @Success 200 {array} ProductListResponse
+// @Success 304 "Not Modified"
+// @Failure 400 {object} response.ErrorBody
 // @Failure 500 {object} response.ErrorBody
 // @Security BearerAuth
 // @Router /products [get]
@@ -160,32 +186,23 @@ func (h *Handler) List(c *fiber.Ctx) error {
 	ctx := c.UserContext()
 	log := ctxlog.From(ctx)
 
-	// init query
-	limit, err := strconv.Atoi(c.Query("limit", "10"))
-	if err != nil {
-		log.Warn("handler.product.list.invalid_input.limit", zap.Error(err))
-		return response.Error(
-			c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid limit request",
-		)
-	}
-	offset, err := strconv.Atoi(c.Query("offset", "0"))
+	p, err := query.FromCtx(c, listSchema)
 	if err != nil {
-		log.Warn("handler.product.list.invalid_input.offset", zap.Error(err))
+		log.Warn("handler.product.list.invalid_input", zap.Error(err))
 		return response.Error(
-			c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid offset request",
+			c, fiber.StatusBadRequest, "BAD_REQUEST", err.Error(),
 		)
 	}
-	sort := c.Query("sort", "ASC")
-	search := c.Query("search", "")
-
-	// Validate pagination parameters
-	limit, offset = utils.NormalizePagination(limit, offset)
 
 	products, err := h.service.List(ctx, ListQuery{
-		Limit:  limit,
-		Offset: offset,
-		Sort:   sort,
-		Search: search,
+		Limit:   p.Limit,
+		Offset:  p.Offset,
+		Sort:    p.Sort,
+		Filters: p.Filters,
+		Search:  p.Search,
+		Keyset:  p.Keyset,
+		After:   p.After,
+		Facets:  c.QueryBool("facets"),
 	})
 	if err != nil {
 		return response.Error(
@@ -205,8 +222,14 @@ func (h *Handler) List(c *fiber.Ctx) error {
 		}
 	}
 
+	if p.Keyset {
+		if products.Facets != nil {
+			return response.CursorPageWith(c, res, p.Limit, products.NextCursor, fiber.Map{"facets": toFacetsResponse(products.Facets)})
+		}
+		return response.CursorPage(c, res, p.Limit, products.NextCursor)
+	}
 	// Return response with metadata
-	return response.OK(c, ProductListResponse{Products: res, Total: products.Total})
+	return response.OK(c, ProductListResponse{Products: res, Total: products.Total, Facets: toFacetsResponse(products.Facets)})
 }
 
 // UpdateProduct godoc
@@ -217,11 +240,14 @@ func (h *Handler) List(c *fiber.Ctx) error {
 // @Produce json
 // @Param id path int true "Product ID"
 // @Param product body UpdateProductRequest true "Product update data"
+// @Param If-Match header string false "ETag from GET /products/{id} (or send Version in the body)"
 // @Success 200 {object} ProductDetailResponse
 // @Failure 400 {object} response.ErrorBody
 // @Failure 403 {object} response.ErrorBody
 // @Failure 404 {object} response.ErrorBody
 // @Failure 409 {object} response.ErrorBody
+// @Failure 412 {object} response.ErrorBody
+// @Failure 428 {object} response.ErrorBody
 // @Security BearerAuth
 // @Router /products/{id} [put]
 func (h *Handler) UpdateProduct(c *fiber.Ctx) error {
@@ -254,14 +280,28 @@ func (h *Handler) UpdateProduct(c *fiber.Ctx) error {
 		)
 	}
 
+	// ต้องบอก version ที่อ่านไป (If-Match หรือ field version) กันผู้จัดการสองคนแก้ทับกัน
+	version, fromHeader, ok := httpcache.ExpectedVersion(c, req.Version)
+	if !ok {
+		return response.Error(
+			c, fiber.StatusPreconditionRequired, "PRECONDITION_REQUIRED", "If-Match header or version is required",
+		)
+	}
+
 	p, err := h.service.UpdateProduct(ctx, uint(productID), UpdateInput{
 		Name:        req.Name,
 		Description: req.Description,
 		SKU:         req.SKU,
 		Price:       req.Price,
 		CategoryID:  req.CategoryID,
+		Version:     &version,
 	})
 	if err != nil {
+		if errors.Is(err, apperror.ErrVersionConflict) {
+			log.Info("handler.product.update.version_conflict", zap.Uint64("id", productID), zap.Uint("version", version))
+			status, code := httpcache.ConflictStatus(fromHeader)
+			return response.Error(c, status, code, "product was modified by another user")
+		}
 		if err == apperror.ErrNotFound {
 			return response.Error(
 				c, fiber.StatusNotFound, "NOT_FOUND", "product not found",
@@ -279,6 +319,7 @@ func (h *Handler) UpdateProduct(c *fiber.Ctx) error {
 		)
 	}
 
+	c.Set(fiber.HeaderETag, httpcache.VersionTag(p.Version, p.Inventory.Version))
 	return response.OK(c, ProductDetailResponse{
 		ID:          p.ID,
 		Name:        p.Name,
@@ -288,6 +329,7 @@ func (h *Handler) UpdateProduct(c *fiber.Ctx) error {
 		CategoryID:  p.CategoryID,
 		Category:    p.Category,
 		Inventory:   p.Inventory,
+		Version:     p.Version,
 	})
 
 }
@@ -340,3 +382,233 @@ func (h *Handler) DeleteProduct(c *fiber.Ctx) error {
 
 	return response.NoContent(c)
 }
+
+// importFormat เลือกรูปแบบจากค่า format ที่ส่งมา หรือจากนามสกุลไฟล์
+func importFormat(explicit, filename string) string {
+	if f := strings.ToLower(strings.TrimSpace(explicit)); f != "" {
+		return f
+	}
+	return strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+}
+
+// ImportProducts godoc
+// @Summary Bulk import products
+// @Description Import products from CSV or XLSX, upsert by SKU and create initial inventories (admin/manager only)
+// @Tags products
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV or XLSX file"
+// @Param format formData string false "csv | xlsx (default from file extension)"
+// @Param dry_run formData bool false "Validate only, do not write"
+// @Param mapping formData string false "JSON object: field -> column header, e.g. {\"sku\":\"Part No\"}"
+// @Success 200 {object} ImportResponse
+// @Failure 400 {object} response.ErrorBody
+// @Failure 403 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /products/import [post]
+func (h *Handler) ImportProducts(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+	userClaims := c.Locals("user").(*jwtx.Claims)
+
+	// Check permissions
+	if userClaims.Role != "admin" && userClaims.Role != "manager" {
+		log.Warn("handler.product.import.permission.not_allow",
+			zap.Uint("user_id", userClaims.UserID),
+			zap.String("role", userClaims.Role),
+		)
+		return response.Error(
+			c, fiber.StatusForbidden, "FORBIDDEN", "insufficient permission",
+		)
+	}
+
+	fh, err := c.FormFile("file")
+	if err != nil {
+		log.Warn("handler.product.import.missing_file", zap.Error(err))
+		return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", "file is required")
+	}
+
+	dryRun := false
+	if v := c.FormValue("dry_run"); v != "" {
+		dryRun, err = strconv.ParseBool(v)
+		if err != nil {
+			log.Warn("handler.product.import.invalid_dry_run", zap.Error(err))
+			return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid dry_run request")
+		}
+	}
+
+	var mapping map[string]string
+	if v := c.FormValue("mapping"); v != "" {
+		if err := json.Unmarshal([]byte(v), &mapping); err != nil {
+			log.Warn("handler.product.import.invalid_mapping", zap.Error(err))
+			return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid mapping request")
+		}
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		log.Error("handler.product.import.open_file", zap.Error(err))
+		return response.Error(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "internal server occured")
+	}
+	defer f.Close()
+
+	report, err := h.service.ImportProducts(ctx, ImportInput{
+		File:    f,
+		Format:  importFormat(c.FormValue("format"), fh.Filename),
+		Mapping: mapping,
+		DryRun:  dryRun,
+	})
+	if err != nil {
+		if errors.Is(err, apperror.ErrInvalidInput) {
+			return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", err.Error())
+		}
+		return response.Error(
+			c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "internal server occured",
+		)
+	}
+
+	rowErrors := make([]ImportRowErrorResponse, len(report.Errors))
+	for i, e := range report.Errors {
+		rowErrors[i] = ImportRowErrorResponse(e)
+	}
+	return response.OK(c, ImportResponse{
+		DryRun:  report.DryRun,
+		Total:   report.Total,
+		Valid:   report.Valid,
+		Created: report.Created,
+		Updated: report.Updated,
+		Failed:  report.Failed,
+		Errors:  rowErrors,
+	})
+}
+
+// ExportProducts godoc
+// @Summary Export products
+// @Description Stream all products matching the List search filter as CSV or XLSX
+// @Tags products
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "csv | xlsx" default(csv)
+// @Param search query string false "Search name or SKU"
+// @Param sort query string false "Sort fields, same as List (e.g. -price,name)"
+// @Success 200 {file} file
+// @Failure 400 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /products/export [get]
+func (h *Handler) ExportProducts(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+
+	format := strings.ToLower(c.Query("format", export.FormatCSV))
+	if !export.Supported(format) {
+		log.Warn("handler.product.export.invalid_format", zap.String("format", format))
+		return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid format request")
+	}
+	p, err := query.FromCtx(c, listSchema)
+	if err != nil {
+		log.Warn("handler.product.export.invalid_input", zap.Error(err))
+		return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", err.Error())
+	}
+	q := ListQuery{Search: p.Search, Sort: p.Sort, Filters: p.Filters}
+
+	c.Set(fiber.HeaderContentType, export.ContentType(format))
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="products-%s.%s"`, time.Now().Format("20060102"), format))
+
+	// เขียนตรงลง connection ทีละแถว; header ถูกส่งไปแล้ว ถ้าล้มกลางทางทำได้แค่ log
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := h.service.ExportProducts(ctx, q, format, w); err != nil {
+			log.Error("handler.product.export.stream_failed", zap.Error(err))
+		}
+		_ = w.Flush()
+	})
+	return nil
+}
+
+// ListTrash godoc
+// @Summary List deleted products
+// @Description List soft-deleted products, most recently deleted first (manager only)
+// @Tags trash
+// @Produce json
+// @Param limit query int false "Page size"
+// @Param offset query int false "Offset"
+// @Success 200 {array} TrashProductResponse
+// @Failure 400 {object} response.ErrorBody
+// @Failure 403 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /trash/products [get]
+func (h *Handler) ListTrash(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+
+	// ถังขยะไม่มี filter/sort ใช้แค่ limit/offset
+	p, err := query.FromCtx(c, query.Schema{})
+	if err != nil {
+		log.Warn("handler.product.list_trash.invalid_input", zap.Error(err))
+		return response.Error(
+			c, fiber.StatusBadRequest, "BAD_REQUEST", err.Error(),
+		)
+	}
+
+	out, err := h.service.ListTrash(ctx, p.Limit, p.Offset)
+	if err != nil {
+		return response.Error(
+			c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "internal server occured",
+		)
+	}
+
+	items := make([]*TrashProductResponse, len(out.Items))
+	for index, it := range out.Items {
+		items[index] = &TrashProductResponse{
+			ID:        it.ID,
+			SKU:       it.SKU,
+			Name:      it.Name,
+			DeletedAt: it.DeletedAt,
+		}
+	}
+
+	return response.Page(c, items, out.Total, p.Limit, p.Offset)
+}
+
+// Restore godoc
+// @Summary Restore deleted product
+// @Description Restore a soft-deleted product together with its inventory (manager only)
+// @Tags trash
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} response.ErrorBody
+// @Failure 403 {object} response.ErrorBody
+// @Failure 404 {object} response.ErrorBody
+// @Failure 409 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /trash/products/{id}/restore [post]
+func (h *Handler) Restore(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Warn("handler.product.restore.invalid_id", zap.Error(err))
+		return response.Error(
+			c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid product id",
+		)
+	}
+
+	err = h.service.RestoreProduct(ctx, uint(id))
+	switch {
+	case err == nil:
+		return response.NoContent(c)
+	case errors.Is(err, apperror.ErrNotFound):
+		return response.Error(
+			c, fiber.StatusNotFound, "NOT_FOUND", "deleted product not found",
+		)
+	case errors.Is(err, apperror.ErrInvalidState):
+		return response.Error(
+			c, fiber.StatusConflict, "CONFLICT", "restore the product's category first",
+		)
+	default:
+		return response.Error(
+			c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "internal server occured",
+		)
+	}
+}
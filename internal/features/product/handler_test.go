@@ -6,16 +6,21 @@ import (
 	"ans-spareparts-api/internal/features/inventory"
 	"ans-spareparts-api/internal/features/product"
 	"ans-spareparts-api/internal/infra/jwtx"
+	"ans-spareparts-api/internal/middleware"
 	mocks "ans-spareparts-api/internal/mock"
 	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/query"
 	"ans-spareparts-api/pkg/response"
 	"ans-spareparts-api/pkg/testutil"
 	"ans-spareparts-api/pkg/testutil/fixtures"
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
@@ -324,6 +329,71 @@ func TestProductHandler_GetProductDetail(t *testing.T) {
 	}
 }
 
+func TestProductHandler_GetProductDetail_Conditional(t *testing.T) {
+	updatedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	item := *mockItem
+	item.UpdatedAt = updatedAt
+	item.Version = 3
+	item.Inventory.Version = 7
+	etag := `"3.7"`
+
+	tests := []struct {
+		name               string
+		headers            map[string]string
+		expectedStatusCode int
+	}{
+		{name: "Success_No_Validator", expectedStatusCode: fiber.StatusOK},
+		{name: "NotModified_IfNoneMatch", headers: map[string]string{fiber.HeaderIfNoneMatch: etag}, expectedStatusCode: fiber.StatusNotModified},
+		{name: "Success_IfNoneMatch_Stale", headers: map[string]string{fiber.HeaderIfNoneMatch: `"stale"`}, expectedStatusCode: fiber.StatusOK},
+		{name: "NotModified_IfModifiedSince", headers: map[string]string{fiber.HeaderIfModifiedSince: "Thu, 02 Jan 2025 03:04:05 GMT"}, expectedStatusCode: fiber.StatusNotModified},
+		{name: "Success_IfModifiedSince_Older", headers: map[string]string{fiber.HeaderIfModifiedSince: "Thu, 02 Jan 2025 03:04:04 GMT"}, expectedStatusCode: fiber.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewHandlerTestSuite()
+			ts.SetUpHandlerTestSuite(t)
+			ts.App.Get("/products/:id", ts.Handler.GetProductDetail)
+			ts.MockService.On("GetProductDetail", mock.Anything, uint(1)).Return(&item, nil).Once()
+
+			req := httptest.NewRequest(fiber.MethodGet, "/products/1", nil)
+			for k, v := range test.headers {
+				req.Header.Set(k, v)
+			}
+			res, _ := ts.App.Test(req, -1)
+
+			assert.Equal(t, test.expectedStatusCode, res.StatusCode)
+			assert.Equal(t, etag, res.Header.Get(fiber.HeaderETag))
+			assert.Equal(t, "Thu, 02 Jan 2025 03:04:05 GMT", res.Header.Get(fiber.HeaderLastModified))
+			resBody, _ := io.ReadAll(res.Body)
+			if test.expectedStatusCode == fiber.StatusNotModified {
+				assert.Empty(t, resBody)
+			} else {
+				assert.NotEmpty(t, resBody)
+			}
+		})
+	}
+}
+
+func TestProductHandler_List_ETag(t *testing.T) {
+	ts := NewHandlerTestSuite()
+	ts.SetUpHandlerTestSuite(t)
+	ts.App.Get("/products", middleware.ETag(), ts.Handler.List)
+	ts.MockService.On("List", mock.Anything, product.ListQuery{Limit: 10}).Return(createListOutput(fixtures.ValidListProduct()), nil).Twice()
+
+	res, _ := ts.App.Test(httptest.NewRequest(fiber.MethodGet, "/products", nil), -1)
+	assert.Equal(t, fiber.StatusOK, res.StatusCode)
+	etag := res.Header.Get(fiber.HeaderETag)
+	assert.NotEmpty(t, etag)
+
+	// หน้าเดิม ETag เดิม -> 304
+	req := httptest.NewRequest(fiber.MethodGet, "/products", nil)
+	req.Header.Set(fiber.HeaderIfNoneMatch, etag)
+	res, _ = ts.App.Test(req, -1)
+	assert.Equal(t, fiber.StatusNotModified, res.StatusCode)
+	assert.Equal(t, etag, res.Header.Get(fiber.HeaderETag))
+}
+
 func TestProductHandler_UpdateProduct(t *testing.T) {
 	mockRequest := &product.UpdateProductRequest{
 		Name:        testutil.PTRHelper(mockProduct.Name),
@@ -331,6 +401,7 @@ func TestProductHandler_UpdateProduct(t *testing.T) {
 		SKU:         testutil.PTRHelper(mockProduct.SKU),
 		Price:       testutil.PTRHelper(mockProduct.Price),
 		CategoryID:  testutil.PTRHelper(mockProduct.CategoryID),
+		Version:     testutil.PTRHelper(uint(1)),
 	}
 	mockInput := product.UpdateInput{
 		Name:        mockRequest.Name,
@@ -338,12 +409,14 @@ func TestProductHandler_UpdateProduct(t *testing.T) {
 		SKU:         mockRequest.SKU,
 		Price:       mockRequest.Price,
 		CategoryID:  mockRequest.CategoryID,
+		Version:     mockRequest.Version,
 	}
 
 	tests := []struct {
 		name               string
 		userRole           string
 		path               string
+		ifMatch            string
 		requestBody        interface{}
 		setup              func(*HandlerTestSuite)
 		expectedStatusCode int
@@ -364,18 +437,63 @@ func TestProductHandler_UpdateProduct(t *testing.T) {
 			name:     "Success_Update_FieldName",
 			userRole: "manager",
 			path:     "/products/1",
+			ifMatch:  `"1.4"`,
 			requestBody: product.UpdateInput{
 				Name: testutil.PTRHelper(mockProduct.Name),
 			},
 			setup: func(hts *HandlerTestSuite) {
 				input := product.UpdateInput{
-					Name: testutil.PTRHelper(mockProduct.Name),
+					Name:    testutil.PTRHelper(mockProduct.Name),
+					Version: testutil.PTRHelper(uint(1)),
 				}
 				hts.MockService.On("UpdateProduct", mock.Anything, uint(1), input).Return(mockItem, nil).Once()
 			},
 			expectedStatusCode: fiber.StatusOK,
 			expectedBody:       mockResponse,
 		},
+		{
+			name:     "Error_PreconditionRequired_No_Version",
+			userRole: "manager",
+			path:     "/products/1",
+			requestBody: product.UpdateProductRequest{
+				Name: testutil.PTRHelper(mockProduct.Name),
+			},
+			setup:              func(hts *HandlerTestSuite) {},
+			expectedStatusCode: fiber.StatusPreconditionRequired,
+			expectedBody: fiber.Map{
+				"code":    "PRECONDITION_REQUIRED",
+				"message": "If-Match header or version is required",
+			},
+		},
+		{
+			name:        "Error_PreconditionFailed_IfMatch_Stale",
+			userRole:    "manager",
+			path:        "/products/1",
+			ifMatch:     `"1"`,
+			requestBody: mockRequest,
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("UpdateProduct", mock.Anything, uint(1), mockInput).Return(nil, apperror.ErrVersionConflict).Once()
+			},
+			expectedStatusCode: fiber.StatusPreconditionFailed,
+			expectedBody: fiber.Map{
+				"code":    "PRECONDITION_FAILED",
+				"message": "product was modified by another user",
+			},
+		},
+		{
+			name:        "Error_Conflict_Body_Version_Stale",
+			userRole:    "manager",
+			path:        "/products/1",
+			requestBody: mockRequest,
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("UpdateProduct", mock.Anything, uint(1), mockInput).Return(nil, apperror.ErrVersionConflict).Once()
+			},
+			expectedStatusCode: fiber.StatusConflict,
+			expectedBody: fiber.Map{
+				"code":    "CONFLICT",
+				"message": "product was modified by another user",
+			},
+		},
 		{
 			name:               "Error_Forbridden_Update_With_Cashier",
 			userRole:           "cashier",
@@ -495,6 +613,9 @@ func TestProductHandler_UpdateProduct(t *testing.T) {
 			// create request
 			req := httptest.NewRequest(fiber.MethodPatch, test.path, bytes.NewBuffer(body))
 			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			if test.ifMatch != "" {
+				req.Header.Set(fiber.HeaderIfMatch, test.ifMatch)
+			}
 
 			// run test
 			res, _ := ts.App.Test(req, -1)
@@ -623,13 +744,110 @@ func TestProductHandler_DeleteProduct(t *testing.T) {
 	}
 }
 
+func TestProductHandler_Restore(t *testing.T) {
+	tests := []struct {
+		name               string
+		path               string
+		setup              func(*HandlerTestSuite)
+		expectedStatusCode int
+		expectedBody       interface{}
+	}{
+		{
+			name: "Success_Restore",
+			path: "/trash/products/1/restore",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("RestoreProduct", mock.Anything, uint(1)).Return(nil).Once()
+			},
+			expectedStatusCode: fiber.StatusNoContent,
+		},
+		{
+			name:               "Error_BadRequest_Invalid_ID",
+			path:               "/trash/products/one/restore",
+			setup:              func(hts *HandlerTestSuite) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+			expectedBody:       fiber.Map{"code": "BAD_REQUEST", "message": "invalid product id"},
+		},
+		{
+			name: "Error_Not_In_Trash",
+			path: "/trash/products/99/restore",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("RestoreProduct", mock.Anything, uint(99)).
+					Return(fmt.Errorf("repo.product.restore: %w", apperror.ErrNotFound)).Once()
+			},
+			expectedStatusCode: fiber.StatusNotFound,
+			expectedBody:       fiber.Map{"code": "NOT_FOUND", "message": "deleted product not found"},
+		},
+		{
+			name: "Error_Category_In_Trash",
+			path: "/trash/products/1/restore",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("RestoreProduct", mock.Anything, uint(1)).Return(apperror.ErrInvalidState).Once()
+			},
+			expectedStatusCode: fiber.StatusConflict,
+			expectedBody:       fiber.Map{"code": "CONFLICT", "message": "restore the product's category first"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewHandlerTestSuite()
+			ts.SetUpHandlerTestSuite(t)
+
+			ts.App.Post("/trash/products/:id/restore", ts.Handler.Restore)
+			test.setup(ts)
+
+			req := httptest.NewRequest(fiber.MethodPost, test.path, nil)
+			res, _ := ts.App.Test(req, -1)
+
+			assert.Equal(t, test.expectedStatusCode, res.StatusCode)
+			if test.expectedBody != nil {
+				expectedBody, _ := json.Marshal(test.expectedBody)
+				resBody, _ := io.ReadAll(res.Body)
+				assert.JSONEq(t, string(expectedBody), string(resBody))
+			}
+		})
+	}
+}
+
+func TestProductHandler_ListTrash(t *testing.T) {
+	deletedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("Success_Page", func(t *testing.T) {
+		ts := NewHandlerTestSuite()
+		ts.SetUpHandlerTestSuite(t)
+		ts.App.Get("/trash/products", ts.Handler.ListTrash)
+
+		ts.MockService.On("ListTrash", mock.Anything, 5, 10).Return(&product.TrashOutput{
+			Items: []*product.TrashItem{{ID: 7, SKU: "BRK-007", Name: "Brake pad", DeletedAt: deletedAt}},
+			Total: 11,
+		}, nil).Once()
+
+		res, _ := ts.App.Test(httptest.NewRequest(fiber.MethodGet, "/trash/products?limit=5&offset=10", nil), -1)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		resBody, _ := io.ReadAll(res.Body)
+		assert.JSONEq(t, `{
+			"data": [{"id": 7, "sku": "BRK-007", "name": "Brake pad", "deleted_at": "2025-01-02T03:04:05Z"}],
+			"meta": {"total": 11, "limit": 5, "offset": 10}
+		}`, string(resBody))
+	})
+
+	t.Run("Error_Invalid_Limit", func(t *testing.T) {
+		ts := NewHandlerTestSuite()
+		ts.SetUpHandlerTestSuite(t)
+		ts.App.Get("/trash/products", ts.Handler.ListTrash)
+
+		res, _ := ts.App.Test(httptest.NewRequest(fiber.MethodGet, "/trash/products?limit=abc", nil), -1)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+	})
+}
+
 func TestProductHandler_List(t *testing.T) {
 	mockProducts := fixtures.ValidListProduct()
 	mockQuery := product.ListQuery{
 		Search: "",
 		Limit:  10,
 		Offset: 0,
-		Sort:   "ASC",
 	}
 	mockOutput := createListOutput(mockProducts)
 	mockResponse := createListResponse(mockOutput.Items)
@@ -672,6 +890,59 @@ func TestProductHandler_List(t *testing.T) {
 				"message": "invalid offset request",
 			},
 		},
+		{
+			name: "Success_Keyset_FirstPage",
+			path: "/products?limit=2&cursor=",
+			setup: func(hts *HandlerTestSuite) {
+				out := createListOutput(mockProducts)
+				out.NextCursor = "next"
+				hts.MockService.On("List", mock.Anything, product.ListQuery{Limit: 2, Keyset: true}).Return(out, nil).Once()
+			},
+			expectedStatusCode: fiber.StatusOK,
+			expectedBody: fiber.Map{
+				"data": mockResponse.Products,
+				"meta": fiber.Map{"limit": 2, "next_cursor": "next"},
+			},
+		},
+		{
+			name: "Success_With_Facets",
+			path: "/products?search=filter&category_id=1&facets=true",
+			setup: func(hts *HandlerTestSuite) {
+				out := createListOutput(mockProducts)
+				max := float64(100)
+				out.Facets = &product.Facets{
+					Categories:   []product.CategoryFacet{{ID: 1, Name: "Filters", Count: 2}},
+					PriceBuckets: []product.PriceBucket{{Min: 0, Max: &max, Count: 2}},
+					InStock:      2,
+				}
+				hts.MockService.On("List", mock.Anything, product.ListQuery{
+					Search:  "filter",
+					Limit:   10,
+					Filters: []query.Filter{{Column: "products.category_id", Op: query.OpEq, Value: int64(1)}},
+					Facets:  true,
+				}).Return(out, nil).Once()
+			},
+			expectedStatusCode: fiber.StatusOK,
+			expectedBody: fiber.Map{
+				"Products": mockResponse.Products,
+				"Total":    mockResponse.Total,
+				"Facets": fiber.Map{
+					"categories": []fiber.Map{{"id": 1, "name": "Filters", "count": 2}},
+					"price":      []fiber.Map{{"min": 0, "max": 100, "count": 2}},
+					"stock":      fiber.Map{"in_stock": 2, "out_of_stock": 0},
+				},
+			},
+		},
+		{
+			name:               "Error_BadRequest_InvalidCursor",
+			path:               "/products?cursor=not-a-cursor",
+			setup:              func(hts *HandlerTestSuite) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+			expectedBody: fiber.Map{
+				"code":    "BAD_REQUEST",
+				"message": "invalid cursor request",
+			},
+		},
 		{
 			name: "Error_InternalServer",
 			path: "/products?limit=10&offset=0&search",
@@ -750,3 +1021,188 @@ func createListResponse(item []*product.ItemLite) *product.ProductListResponse {
 		Total:    int64(len(items)),
 	}
 }
+
+func TestProductHandler_ImportProducts(t *testing.T) {
+	csvBody := "sku,name,price,category_id\nBRK-001,Brake pad,450,1\n"
+
+	// multipartBody สร้าง body แบบ multipart/form-data พร้อมไฟล์และฟิลด์
+	multipartBody := func(filename string, fields map[string]string) (*bytes.Buffer, string) {
+		body := &bytes.Buffer{}
+		w := multipart.NewWriter(body)
+		if filename != "" {
+			part, _ := w.CreateFormFile("file", filename)
+			_, _ = part.Write([]byte(csvBody))
+		}
+		for k, v := range fields {
+			_ = w.WriteField(k, v)
+		}
+		_ = w.Close()
+		return body, w.FormDataContentType()
+	}
+
+	tests := []struct {
+		name               string
+		userRole           string
+		filename           string
+		fields             map[string]string
+		setup              func(*HandlerTestSuite)
+		expectedStatusCode int
+		expectedBody       interface{}
+	}{
+		{
+			name:     "Success_DryRun_With_Mapping",
+			userRole: "manager",
+			filename: "catalogue.CSV",
+			fields:   map[string]string{"dry_run": "true", "mapping": `{"sku":"sku"}`},
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("ImportProducts", mock.Anything, mock.MatchedBy(func(in product.ImportInput) bool {
+					return in.DryRun && in.Format == product.ImportFormatCSV && in.Mapping["sku"] == "sku"
+				})).Return(&product.ImportReport{DryRun: true, Total: 1, Valid: 1}, nil).Once()
+			},
+			expectedStatusCode: fiber.StatusOK,
+			expectedBody: fiber.Map{
+				"dry_run": true,
+				"total":   1,
+				"valid":   1,
+				"created": 0,
+				"updated": 0,
+				"failed":  0,
+				"errors":  []any{},
+			},
+		},
+		{
+			name:               "Error_Forbidden_With_Cashier",
+			userRole:           "cashier",
+			filename:           "catalogue.csv",
+			setup:              func(hts *HandlerTestSuite) {},
+			expectedStatusCode: fiber.StatusForbidden,
+		},
+		{
+			name:               "Error_Missing_File",
+			userRole:           "manager",
+			setup:              func(hts *HandlerTestSuite) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+			expectedBody: fiber.Map{
+				"code":    "BAD_REQUEST",
+				"message": "file is required",
+			},
+		},
+		{
+			name:               "Error_Invalid_Mapping",
+			userRole:           "manager",
+			filename:           "catalogue.csv",
+			fields:             map[string]string{"mapping": "sku=Part No"},
+			setup:              func(hts *HandlerTestSuite) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:     "Error_Invalid_File",
+			userRole: "manager",
+			filename: "catalogue.csv",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("ImportProducts", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("%w: missing column for \"price\"", apperror.ErrInvalidInput)).Once()
+			},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewHandlerTestSuite()
+			ts.SetUpHandlerTestSuite(t)
+
+			// Mock jwt cliams
+			ts.App.Use(func(c *fiber.Ctx) error {
+				c.Locals("user", &jwtx.Claims{UserID: 1, Username: "Test", Role: test.userRole})
+				return c.Next()
+			})
+
+			ts.App.Post("/products/import", ts.Handler.ImportProducts)
+			test.setup(ts)
+
+			body, contentType := multipartBody(test.filename, test.fields)
+			req := httptest.NewRequest(fiber.MethodPost, "/products/import", body)
+			req.Header.Set(fiber.HeaderContentType, contentType)
+
+			res, _ := ts.App.Test(req, -1)
+
+			assert.Equal(t, test.expectedStatusCode, res.StatusCode)
+			if test.expectedBody != nil {
+				expectedBody, _ := json.Marshal(test.expectedBody)
+				resBody, _ := io.ReadAll(res.Body)
+
+				assert.JSONEq(t, string(expectedBody), string(resBody))
+			}
+		})
+	}
+}
+
+func TestProductHandler_ExportProducts(t *testing.T) {
+	tests := []struct {
+		name               string
+		query              string
+		setup              func(*HandlerTestSuite)
+		expectedStatusCode int
+		expectedType       string
+		expectedBody       string
+	}{
+		{
+			name:  "Success_Default_CSV",
+			query: "?search=brk",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("ExportProducts", mock.Anything, product.ListQuery{Search: "brk"}, "csv", mock.Anything).Return("id,sku\n1,BRK-001\n", nil).Once()
+			},
+			expectedStatusCode: fiber.StatusOK,
+			expectedType:       "text/csv; charset=utf-8",
+			expectedBody:       "id,sku\n1,BRK-001\n",
+		},
+		{
+			name:  "Success_XLSX",
+			query: "?format=XLSX",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("ExportProducts", mock.Anything, product.ListQuery{}, "xlsx", mock.Anything).Return(nil, nil).Once()
+			},
+			expectedStatusCode: fiber.StatusOK,
+			expectedType:       "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		},
+		{
+			name:  "Success_Sort_Passed_Through",
+			query: "?sort=-price,name",
+			setup: func(hts *HandlerTestSuite) {
+				sorts := []query.Sort{{Column: "products.price", Desc: true}, {Column: "products.name"}}
+				hts.MockService.On("ExportProducts", mock.Anything, product.ListQuery{Sort: sorts}, "csv", mock.Anything).Return("id,sku\n", nil).Once()
+			},
+			expectedStatusCode: fiber.StatusOK,
+			expectedType:       "text/csv; charset=utf-8",
+		},
+		{
+			name:               "Error_Invalid_Format",
+			query:              "?format=pdf",
+			setup:              func(hts *HandlerTestSuite) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewHandlerTestSuite()
+			ts.SetUpHandlerTestSuite(t)
+
+			ts.App.Get("/products/export", ts.Handler.ExportProducts)
+			test.setup(ts)
+
+			req := httptest.NewRequest(fiber.MethodGet, "/products/export"+test.query, nil)
+			res, _ := ts.App.Test(req, -1)
+
+			assert.Equal(t, test.expectedStatusCode, res.StatusCode)
+			if test.expectedType != "" {
+				assert.Equal(t, test.expectedType, res.Header.Get(fiber.HeaderContentType))
+				assert.Contains(t, res.Header.Get(fiber.HeaderContentDisposition), "attachment")
+			}
+			if test.expectedBody != "" {
+				resBody, _ := io.ReadAll(res.Body)
+				assert.Equal(t, test.expectedBody, string(resBody))
+			}
+		})
+	}
+}
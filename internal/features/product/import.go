@@ -0,0 +1,212 @@
+package product
+
+import (
+	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/utils"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// รูปแบบไฟล์นำเข้าสินค้า
+const (
+	ImportFormatCSV  = "csv"
+	ImportFormatXLSX = "xlsx"
+)
+
+// importBatchSize จำนวนแถวต่อหนึ่ง transaction ตอน upsert
+const importBatchSize = 500
+
+// คอลัมน์ที่รองรับ (ชื่อ field ฝั่งระบบ) ผู้ใช้ map หัวคอลัมน์ในไฟล์มาที่ชื่อเหล่านี้ได้
+const (
+	importFieldSKU         = "sku"
+	importFieldName        = "name"
+	importFieldDescription = "description"
+	importFieldPrice       = "price"
+	importFieldCategoryID  = "category_id"
+	importFieldQuantity    = "quantity"
+)
+
+var importFields = []string{
+	importFieldSKU,
+	importFieldName,
+	importFieldDescription,
+	importFieldPrice,
+	importFieldCategoryID,
+	importFieldQuantity,
+}
+
+var requiredImportFields = []string{
+	importFieldSKU,
+	importFieldName,
+	importFieldPrice,
+	importFieldCategoryID,
+}
+
+// importRow แถวข้อมูลที่ parse แล้ว (Line นับแบบเดียวกับที่ผู้ใช้เห็นในไฟล์ หัวตารางคือแถว 1)
+type importRow struct {
+	Line     int
+	Input    CreateInput
+	Quantity int
+	Err      string
+}
+
+// readImportRecords อ่านไฟล์ทั้งหมดเป็นตาราง string (แถวแรกคือหัวคอลัมน์)
+func readImportRecords(r io.Reader, format string) ([][]string, error) {
+	switch format {
+	case ImportFormatCSV:
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		cr.TrimLeadingSpace = true
+		records, err := cr.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", apperror.ErrInvalidInput, err)
+		}
+		return records, nil
+	case ImportFormatXLSX:
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", apperror.ErrInvalidInput, err)
+		}
+		defer f.Close()
+		// ใช้ sheet แรกเสมอ
+		records, err := f.GetRows(f.GetSheetName(0))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", apperror.ErrInvalidInput, err)
+		}
+		return records, nil
+	}
+	return nil, fmt.Errorf("%w: unsupported format %q", apperror.ErrInvalidInput, format)
+}
+
+func normalizeHeader(h string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+}
+
+// columnIndex หา index ของแต่ละ field จากหัวคอลัมน์ โดยใช้ mapping (field -> ชื่อหัวคอลัมน์) ถ้ามี
+func columnIndex(header []string, mapping map[string]string) (map[string]int, error) {
+	pos := make(map[string]int, len(header))
+	for i, h := range header {
+		pos[normalizeHeader(h)] = i
+	}
+
+	idx := make(map[string]int, len(importFields))
+	for _, f := range importFields {
+		name := f
+		if m, ok := mapping[f]; ok && strings.TrimSpace(m) != "" {
+			name = m
+		}
+		if i, ok := pos[normalizeHeader(name)]; ok {
+			idx[f] = i
+		}
+	}
+
+	for _, f := range requiredImportFields {
+		if _, ok := idx[f]; !ok {
+			return nil, fmt.Errorf("%w: missing column for %q", apperror.ErrInvalidInput, f)
+		}
+	}
+	return idx, nil
+}
+
+func cell(record []string, idx map[string]int, field string) string {
+	i, ok := idx[field]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+func isBlank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseImportRow แปลงหนึ่งแถวเป็น CreateInput และตรวจด้วยกฎเดียวกับ CreateProduct
+func parseImportRow(line int, record []string, idx map[string]int) importRow {
+	row := importRow{Line: line}
+
+	price, err := strconv.ParseFloat(cell(record, idx, importFieldPrice), 64)
+	if err != nil {
+		row.Err = "price must be a number"
+		return row
+	}
+	categoryID, err := strconv.ParseUint(cell(record, idx, importFieldCategoryID), 10, 32)
+	if err != nil {
+		row.Err = "category_id must be a positive integer"
+		return row
+	}
+	if q := cell(record, idx, importFieldQuantity); q != "" {
+		row.Quantity, err = strconv.Atoi(q)
+		if err != nil || row.Quantity < 0 {
+			row.Err = "quantity must be a non-negative integer"
+			return row
+		}
+	}
+
+	row.Input = CreateInput{
+		Name:        cell(record, idx, importFieldName),
+		Description: cell(record, idx, importFieldDescription),
+		Price:       price,
+		SKU:         cell(record, idx, importFieldSKU),
+		CategoryID:  uint(categoryID),
+	}
+	if err := sanitizeCreate(row.Input); err != nil {
+		row.Err = "name and sku are required, price must be >= 0"
+		return row
+	}
+
+	sku, err := utils.ValidateAndNormalizeSKU(row.Input.SKU)
+	if err != nil {
+		row.Err = "invalid sku"
+		return row
+	}
+	row.Input.SKU = sku
+	row.Input.Name = utils.SanitizeString(row.Input.Name)
+	row.Input.Description = utils.SanitizeString(row.Input.Description)
+
+	return row
+}
+
+// parseImport อ่านไฟล์และตรวจทุกแถว คืนแถวทั้งหมด (แถวที่ผิดมี Err)
+// error คืนเฉพาะกรณีที่อ่านไฟล์ไม่ได้หรือหัวคอลัมน์ไม่ครบ
+func parseImport(r io.Reader, format string, mapping map[string]string) ([]importRow, error) {
+	records, err := readImportRecords(r, format)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: empty file", apperror.ErrInvalidInput)
+	}
+
+	idx, err := columnIndex(records[0], mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]importRow, 0, len(records)-1)
+	seen := make(map[string]int)
+	for i, record := range records[1:] {
+		if isBlank(record) {
+			continue
+		}
+		row := parseImportRow(i+2, record, idx)
+		if row.Err == "" {
+			if first, dup := seen[row.Input.SKU]; dup {
+				row.Err = fmt.Sprintf("duplicate sku in file (first at row %d)", first)
+			} else {
+				seen[row.Input.SKU] = row.Line
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -2,14 +2,21 @@ package product
 
 import (
 	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/features/inventory"
 	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/internal/infra/outbox"
 	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/cursor"
+	"ans-spareparts-api/pkg/query"
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Repository interface {
@@ -20,17 +27,40 @@ type Repository interface {
 	Create(ctx context.Context, p *domain.Product) error
 	Update(ctx context.Context, p *domain.Product) error
 	Delete(ctx context.Context, id uint) error
+
+	// UpsertBySKU เพิ่ม/อัปเดตสินค้าตาม SKU และสร้างแถว inventories ของสินค้าใหม่ใน transaction เดียว
+	// คืนจำนวนที่สร้างใหม่และที่อัปเดต
+	UpsertBySKU(ctx context.Context, records []ImportRecord) (int, int, error)
+	// DeletedSKUs คืน SKU ที่อยู่ในถังขยะ (soft delete) ซึ่ง import ทับไม่ได้ ต้อง restore ก่อน
+	DeletedSKUs(ctx context.Context, skus []string) ([]string, error)
+	// Export อ่านสินค้าตามเงื่อนไขเดียวกับ List (ไม่แบ่งหน้า) ทีละแถวผ่าน cursor แล้วส่งให้ fn
+	Export(ctx context.Context, q ListQuery, fn func(*ExportRow) error) error
+	// Facets นับจำนวนสินค้าตามหมวด ช่วงราคา และสถานะสต็อก ภายใต้ search/filter เดียวกับ List
+	Facets(ctx context.Context, q ListQuery) (*Facets, error)
+
+	// ListDeleted สินค้าในถังขยะ (soft delete แล้ว) ลบล่าสุดก่อน
+	ListDeleted(ctx context.Context, limit, offset int) ([]*domain.Product, int64, error)
+	// Restore กู้สินค้าพร้อมแถว inventories ที่ผูกกันใน transaction เดียว
+	Restore(ctx context.Context, id uint) error
+	// PurgeDeleted ลบถาวรสินค้าที่ลบก่อน before ข้ามสินค้าที่ยังถูกอ้างอิงโดยใบเสนอราคาหรือ stock movement
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
 }
 
 type repository struct {
-	db    *gorm.DB
-	cache *cacheLayer
+	db       *gorm.DB
+	cache    *cacheLayer
+	searcher Searcher
 }
 
-func NewRepository(db *gorm.DB, rdb *redis.Client, cacheExp time.Duration) Repository {
+// NewRepository searcher เป็น nil ได้ จะใช้ ILIKE แทน
+func NewRepository(db *gorm.DB, rdb *redis.Client, cacheExp time.Duration, searcher Searcher) Repository {
+	if searcher == nil {
+		searcher = likeSearcher{}
+	}
 	return &repository{
-		db:    db,
-		cache: newCache(rdb, cacheExp),
+		db:       db,
+		cache:    newCache(rdb, cacheExp),
+		searcher: searcher,
 	}
 }
 
@@ -63,7 +93,6 @@ func (r *repository) GetByID(ctx context.Context, id uint) (*domain.Product, err
 	return &p, nil
 }
 
-
 func (r *repository) GetBySKU(ctx context.Context, sku string) (*domain.Product, error) {
 	log := ctxlog.From(ctx)
 	start := time.Now()
@@ -101,29 +130,34 @@ func (r *repository) List(ctx context.Context, q ListQuery) ([]*domain.Product,
 	// Create Query Builder Session
 	tx := r.db.WithContext(ctx).Model(&domain.Product{})
 
-	if q.Search != "" {
-		tx = tx.Where("name ILIKE ? OR sku ILIKE ?", "%"+q.Search+"%", "%"+q.Search+"%")
-	}
+	tx = r.searcher.Filter(tx, "products", q.Search)
+	tx = query.Where(tx, q.Filters)
+	sorts := listSchema.SortOrDefault(q.Sort)
 
-	// count รวม
+	// keyset: ไม่ count และดึงเกินมา 1 แถวเพื่อรู้ว่ามีหน้าถัดไปไหม
+	// เรียงตามทุก sort + id (ไม่จัดอันดับความเกี่ยวข้อง เพราะคะแนนใช้เป็น cursor ไม่ได้)
 	var total int64
-	if err := tx.Count(&total).Error; err != nil {
-		m := apperror.MapDBError("repo.product.list.count", err)
-		log.Debug("repo.product.list.count_fail", zap.Error(err))
-		return nil, 0, m
-	}
-
-	// sort + page
-	if q.Sort != "" {
-		tx = tx.Order(q.Sort)
+	if q.Keyset {
+		tx = cursor.Apply(tx, sorts, q.After).Limit(q.Limit + 1)
 	} else {
-		tx = tx.Order("created_at DESC")
-	}
-	if q.Limit > 0 {
-		tx = tx.Limit(q.Limit)
-	}
-	if q.Offset > 0 {
-		tx = tx.Offset(q.Offset)
+		// count รวม
+		if err := tx.Count(&total).Error; err != nil {
+			m := apperror.MapDBError("repo.product.list.count", err)
+			log.Debug("repo.product.list.count_fail", zap.Error(err))
+			return nil, 0, m
+		}
+
+		// sort + page: มีคำค้นและผู้ใช้ไม่ได้ระบุ sort ให้เรียงตามความเกี่ยวข้องก่อน
+		if len(q.Sort) == 0 {
+			tx = r.searcher.Rank(tx, "products", q.Search)
+		}
+		tx = query.Order(tx, sorts)
+		if q.Limit > 0 {
+			tx = tx.Limit(q.Limit)
+		}
+		if q.Offset > 0 {
+			tx = tx.Offset(q.Offset)
+		}
 	}
 
 	// Find with Query Builder Session
@@ -142,7 +176,13 @@ func (r *repository) Create(ctx context.Context, p *domain.Product) error {
 	log := ctxlog.From(ctx)
 	start := time.Now()
 
-	if err := r.db.WithContext(ctx).Create(p).Error; err != nil {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(p).Error; err != nil {
+			return err
+		}
+		return outbox.Enqueue(tx, domain.NewProductChanged(domain.EventProductCreated, p))
+	})
+	if err != nil {
 		m := apperror.MapDBError("repo.product.create", err)
 		log.Debug("repo.product.create.fail", zap.Error(err))
 		return m
@@ -157,15 +197,48 @@ func (r *repository) Create(ctx context.Context, p *domain.Product) error {
 	return nil
 }
 
+// Update บันทึกเฉพาะเมื่อ version ในฐานข้อมูลยังเท่ากับ p.Version แล้วเพิ่ม version
+// ถ้ามีคนแก้ไปก่อนจะได้ ErrVersionConflict
 func (r *repository) Update(ctx context.Context, p *domain.Product) error {
 	log := ctxlog.From(ctx)
 	start := time.Now()
 
-	if err := r.db.WithContext(ctx).Save(p).Error; err != nil {
+	now := time.Now()
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&domain.Product{}).
+			Where("id = ? AND version = ?", p.ID, p.Version).
+			Updates(map[string]any{
+				"name":        p.Name,
+				"description": p.Description,
+				"sku":         p.SKU,
+				"price":       p.Price,
+				"category_id": p.CategoryID,
+				"is_active":   p.IsActive,
+				"updated_at":  now,
+				"version":     gorm.Expr("version + 1"),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return apperror.ErrVersionConflict
+		}
+
+		changed := domain.NewProductChanged(domain.EventProductUpdated, p)
+		changed.Version = p.Version + 1
+		return outbox.Enqueue(tx, changed)
+	})
+	if errors.Is(err, apperror.ErrVersionConflict) {
+		log.Debug("repo.product.update.version_conflict", zap.Uint("id", p.ID), zap.Uint("version", p.Version))
+		return fmt.Errorf("repo.product.update: %w", err)
+	}
+	if err != nil {
 		m := apperror.MapDBError("repo.product.update", err)
 		log.Debug("repo.product.update.fail", zap.Error(err))
 		return m
 	}
+	p.Version++
+	p.UpdatedAt = now
 
 	if err := r.cache.del(ctx, r.cache.keyByID(p.ID), r.cache.keyBySKU(p.SKU)); err != nil {
 		log.Warn("repo.product.update.cache_del_fail", zap.Error(err))
@@ -187,7 +260,13 @@ func (r *repository) Delete(ctx context.Context, id uint) error {
 		return m
 	}
 
-	if err := r.db.WithContext(ctx).Delete(&domain.Product{}, id).Error; err != nil {
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&domain.Product{}, id).Error; err != nil {
+			return err
+		}
+		return outbox.Enqueue(tx, domain.ProductRemoved{Type: domain.EventProductDeleted, ProductID: id, SKU: p.SKU})
+	})
+	if err != nil {
 		m := apperror.MapDBError("repo.product.delete", err)
 		log.Debug("repo.product.delete.fail", zap.Error(err))
 		return m
@@ -200,3 +279,349 @@ func (r *repository) Delete(ctx context.Context, id uint) error {
 	log.Debug("repo.product.delete.ok", zap.Uint("id", id), zap.Duration("duration", time.Since(start)))
 	return nil
 }
+
+func (r *repository) UpsertBySKU(ctx context.Context, records []ImportRecord) (int, int, error) {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	if len(records) == 0 {
+		return 0, 0, nil
+	}
+
+	skus := make([]string, len(records))
+	products := make([]*domain.Product, len(records))
+	for i, rec := range records {
+		skus[i] = rec.Product.SKU
+		products[i] = rec.Product
+	}
+
+	var existing []string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// unique index ของ sku รวมแถวที่ soft delete ด้วย จึงอ่านแบบ Unscoped และล็อกไว้กันลบพร้อมกัน
+		var found []struct {
+			SKU     string
+			Deleted bool
+		}
+		if err := tx.Unscoped().Model(&domain.Product{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("sku, deleted_at IS NOT NULL AS deleted").
+			Where("sku IN ?", skus).
+			Scan(&found).Error; err != nil {
+			return err
+		}
+		for _, f := range found {
+			// ON CONFLICT จะอัปเดตแถวที่ถูกลบโดยไม่กู้คืน -> service กรองออกไว้แล้ว ที่เหลือคือถูกลบระหว่างทาง
+			if f.Deleted {
+				return fmt.Errorf("sku %s is deleted: %w", f.SKU, apperror.ErrInvalidState)
+			}
+			existing = append(existing, f.SKU)
+		}
+
+		if err := tx.Omit(clause.Associations).
+			Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "sku"}},
+				DoUpdates: append(
+					clause.AssignmentColumns([]string{"name", "description", "price", "category_id", "updated_at"}),
+					clause.Assignment{Column: clause.Column{Name: "version"}, Value: gorm.Expr("products.version + 1")},
+				),
+			}).
+			Create(&products).Error; err != nil {
+			return err
+		}
+
+		// สินค้าที่มีอยู่แล้วมีแถว inventories อยู่แล้ว -> DO NOTHING ไม่ทับยอดเดิม
+		inventories := make([]domain.Inventory, len(records))
+		for i, rec := range records {
+			inventories[i] = domain.Inventory{ProductID: rec.Product.ID, Quantity: rec.Quantity}
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "product_id"}},
+			DoNothing: true,
+		}).Create(&inventories).Error; err != nil {
+			return err
+		}
+
+		// upsert ไม่ได้อ่าน version กลับมา จึงไม่ใส่ version ใน event
+		known := make(map[string]bool, len(existing))
+		for _, sku := range existing {
+			known[sku] = true
+		}
+		events := make([]domain.Event, len(products))
+		for i, p := range products {
+			eventType := domain.EventProductCreated
+			if known[p.SKU] {
+				eventType = domain.EventProductUpdated
+			}
+			changed := domain.NewProductChanged(eventType, p)
+			changed.Version = 0
+			events[i] = changed
+		}
+		return outbox.Enqueue(tx, events...)
+	})
+	if errors.Is(err, apperror.ErrInvalidState) {
+		log.Debug("repo.product.upsertBySKU.deleted_sku", zap.Error(err))
+		return 0, 0, fmt.Errorf("repo.product.upsertBySKU: %w", err)
+	}
+	if err != nil {
+		m := apperror.MapDBError("repo.product.upsertBySKU", err)
+		log.Debug("repo.product.upsertBySKU.fail", zap.Error(err), zap.Duration("duration", time.Since(start)))
+		return 0, 0, m
+	}
+
+	keys := make([]string, 0, len(products)*2)
+	for _, p := range products {
+		keys = append(keys, r.cache.keyByID(p.ID), r.cache.keyBySKU(p.SKU))
+	}
+	if err := r.cache.del(ctx, keys...); err != nil {
+		log.Warn("repo.product.upsertBySKU.cache_del_fail", zap.Error(err))
+	}
+
+	updated := len(existing)
+	created := len(records) - updated
+	log.Info("repo.product.upsertBySKU.ok", zap.Int("created", created), zap.Int("updated", updated), zap.Duration("duration", time.Since(start)))
+	return created, updated, nil
+}
+
+func (r *repository) DeletedSKUs(ctx context.Context, skus []string) ([]string, error) {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	if len(skus) == 0 {
+		return nil, nil
+	}
+
+	var deleted []string
+	if err := r.db.WithContext(ctx).Unscoped().Model(&domain.Product{}).
+		Where("sku IN ? AND deleted_at IS NOT NULL", skus).
+		Pluck("sku", &deleted).Error; err != nil {
+		m := apperror.MapDBError("repo.product.deletedSKUs", err)
+		log.Debug("repo.product.deletedSKUs.fail", zap.Error(err))
+		return nil, m
+	}
+
+	log.Debug("repo.product.deletedSKUs.ok", zap.Int("n", len(deleted)), zap.Duration("duration", time.Since(start)))
+	return deleted, nil
+}
+
+func (r *repository) Export(ctx context.Context, q ListQuery, fn func(*ExportRow) error) error {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	// ไม่ใช้ alias ของ products เพราะคอลัมน์ใน listSchema อ้างด้วยชื่อตารางเต็ม
+	tx := r.db.WithContext(ctx).Table("products").
+		Select("products.id, products.sku, products.name, products.description, COALESCE(c.name, '') AS category, products.price, COALESCE(i.quantity, 0) AS quantity, products.is_active").
+		Joins("LEFT JOIN categories c ON c.id = products.category_id").
+		Joins("LEFT JOIN inventories i ON i.product_id = products.id AND i.deleted_at IS NULL").
+		Where("products.deleted_at IS NULL")
+	tx = r.searcher.Filter(tx, "products", q.Search)
+	tx = query.Where(tx, q.Filters)
+
+	// join กับ categories/inventories แล้ว id ต้องระบุตาราง
+	tx = query.OrderBy(tx, listSchema.SortOrDefault(q.Sort), "products.id")
+
+	rows, err := tx.Rows()
+	if err != nil {
+		m := apperror.MapDBError("repo.product.export", err)
+		log.Debug("repo.product.export.query_fail", zap.Error(err))
+		return m
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		var row ExportRow
+		if err := tx.ScanRows(rows, &row); err != nil {
+			m := apperror.MapDBError("repo.product.export.scan", err)
+			log.Debug("repo.product.export.scan_fail", zap.Error(err))
+			return m
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		m := apperror.MapDBError("repo.product.export.rows", err)
+		log.Debug("repo.product.export.rows_fail", zap.Error(err))
+		return m
+	}
+
+	log.Debug("repo.product.export.ok", zap.Int("rows", n), zap.Duration("duration", time.Since(start)))
+	return nil
+}
+
+func (r *repository) Facets(ctx context.Context, q ListQuery) (*Facets, error) {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	// base ชุดผลลัพธ์เดียวกับ List แต่ไม่กรองคอลัมน์ของ facet ที่กำลังนับ
+	base := func(skip string) *gorm.DB {
+		tx := r.db.WithContext(ctx).Table("products").Where("products.deleted_at IS NULL")
+		tx = r.searcher.Filter(tx, "products", q.Search)
+		return query.Where(tx, withoutColumn(q.Filters, skip))
+	}
+
+	out := &Facets{}
+	if err := base("products.category_id").
+		Select("products.category_id AS id, COALESCE(c.name, '') AS name, COUNT(*) AS count").
+		Joins("LEFT JOIN categories c ON c.id = products.category_id").
+		Group("products.category_id, c.name").
+		Order("count DESC, name").
+		Scan(&out.Categories).Error; err != nil {
+		m := apperror.MapDBError("repo.product.facets.category", err)
+		log.Debug("repo.product.facets.category_fail", zap.Error(err))
+		return nil, m
+	}
+
+	var buckets []struct {
+		Bucket int
+		Count  int64
+	}
+	if err := base("products.price").
+		Select(priceBucketSQL() + " AS bucket, COUNT(*) AS count").
+		Group("bucket").
+		Scan(&buckets).Error; err != nil {
+		m := apperror.MapDBError("repo.product.facets.price", err)
+		log.Debug("repo.product.facets.price_fail", zap.Error(err))
+		return nil, m
+	}
+	counts := make(map[int]int64, len(buckets))
+	for _, b := range buckets {
+		counts[b.Bucket] = b.Count
+	}
+	out.PriceBuckets = priceBuckets(counts)
+
+	var stock struct {
+		InStock    int64
+		OutOfStock int64
+	}
+	if err := base(inStockColumn).
+		Select("COUNT(*) FILTER (WHERE " + inStockColumn + ") AS in_stock, COUNT(*) FILTER (WHERE NOT " + inStockColumn + ") AS out_of_stock").
+		Scan(&stock).Error; err != nil {
+		m := apperror.MapDBError("repo.product.facets.stock", err)
+		log.Debug("repo.product.facets.stock_fail", zap.Error(err))
+		return nil, m
+	}
+	out.InStock, out.OutOfStock = stock.InStock, stock.OutOfStock
+
+	log.Debug("repo.product.facets.ok", zap.Int("categories", len(out.Categories)), zap.Duration("duration", time.Since(start)))
+	return out, nil
+}
+
+func (r *repository) ListDeleted(ctx context.Context, limit, offset int) ([]*domain.Product, int64, error) {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	tx := r.db.WithContext(ctx).Unscoped().Model(&domain.Product{}).Where("deleted_at IS NOT NULL")
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		m := apperror.MapDBError("repo.product.listdeleted.count", err)
+		log.Debug("repo.product.listdeleted.count.fail", zap.Error(err))
+		return nil, 0, m
+	}
+
+	var products []*domain.Product
+	if err := tx.Order("deleted_at DESC, id DESC").Limit(limit).Offset(offset).Find(&products).Error; err != nil {
+		m := apperror.MapDBError("repo.product.listdeleted", err)
+		log.Debug("repo.product.listdeleted.fail", zap.Error(err))
+		return nil, 0, m
+	}
+
+	log.Debug("repo.product.listdeleted.ok", zap.Int("count", len(products)), zap.Duration("duration", time.Since(start)))
+	return products, total, nil
+}
+
+func (r *repository) Restore(ctx context.Context, id uint) error {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	var p domain.Product
+	var restored []*domain.Inventory
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("deleted_at IS NOT NULL").First(&p, id).Error; err != nil {
+			return err
+		}
+
+		// หมวดหมู่ต้องไม่อยู่ในถังขยะ ไม่งั้นสินค้าจะชี้ไปหมวดที่มองไม่เห็น
+		var active int64
+		if err := tx.Model(&domain.Category{}).Where("id = ?", p.CategoryID).Count(&active).Error; err != nil {
+			return err
+		}
+		if active == 0 {
+			return apperror.ErrInvalidState
+		}
+
+		if err := tx.Unscoped().Model(&domain.Product{}).Where("id = ?", id).
+			Updates(map[string]any{"deleted_at": nil, "version": gorm.Expr("version + 1")}).Error; err != nil {
+			return err
+		}
+
+		// inventory ถูก soft delete พร้อมสินค้าใน DeleteProduct จึงต้องกู้คืนด้วย (RETURNING ไว้ลบ cache ตาม id)
+		if err := tx.Unscoped().Model(&restored).Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}, {Name: "product_id"}}}).
+			Where("product_id = ? AND deleted_at IS NOT NULL", id).
+			Updates(map[string]any{"deleted_at": nil, "version": gorm.Expr("version + 1")}).Error; err != nil {
+			return err
+		}
+		return outbox.Enqueue(tx, domain.ProductRemoved{Type: domain.EventProductRestored, ProductID: id, SKU: p.SKU})
+	})
+	if errors.Is(err, apperror.ErrInvalidState) {
+		log.Debug("repo.product.restore.category_deleted", zap.Uint("id", id), zap.Uint("category_id", p.CategoryID))
+		return fmt.Errorf("repo.product.restore: %w", err)
+	}
+	if err != nil {
+		m := apperror.MapDBError("repo.product.restore", err)
+		log.Debug("repo.product.restore.fail", zap.Error(err))
+		return m
+	}
+
+	if err := r.cache.del(ctx, r.cache.keyByID(id), r.cache.keyBySKU(p.SKU)); err != nil {
+		log.Warn("repo.product.restore.cache_del.fail", zap.Error(err))
+	}
+	// inventory ที่กู้คืนอาจยังมี cache ค้างตอนถูกลบ (ทั้ง key ตาม id และตาม product_id)
+	if err := inventory.DeleteCache(ctx, r.cache.client(), restored...); err != nil {
+		log.Warn("repo.product.restore.inventory_cache_del.fail", zap.Error(err))
+	}
+
+	log.Debug("repo.product.restore.ok", zap.Uint("id", id), zap.Duration("duration", time.Since(start)))
+	return nil
+}
+
+func (r *repository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	var purged int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// สินค้าที่มีประวัติในใบเสนอราคา/ledger ต้องเก็บไว้ ไม่งั้น FK จะพังและรายงานย้อนหลังจะหาย
+		var ids []uint
+		if err := tx.Unscoped().Model(&domain.Product{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+			Where("NOT EXISTS (SELECT 1 FROM quotation_lines ql WHERE ql.product_id = products.id)").
+			Where("NOT EXISTS (SELECT 1 FROM stock_movements sm WHERE sm.product_id = products.id)").
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		if err := tx.Unscoped().Where("product_id IN ?", ids).Delete(&domain.Inventory{}).Error; err != nil {
+			return err
+		}
+		res := tx.Unscoped().Where("id IN ?", ids).Delete(&domain.Product{})
+		if res.Error != nil {
+			return res.Error
+		}
+		purged = res.RowsAffected
+		return nil
+	})
+	if err != nil {
+		m := apperror.MapDBError("repo.product.purgedeleted", err)
+		log.Debug("repo.product.purgedeleted.fail", zap.Error(err))
+		return 0, m
+	}
+
+	log.Debug("repo.product.purgedeleted.ok", zap.Int64("purged", purged), zap.Duration("duration", time.Since(start)))
+	return purged, nil
+}
@@ -0,0 +1,91 @@
+package product
+
+import (
+	"ans-spareparts-api/pkg/query"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	SearchBackendPostgres = "postgres"
+	SearchBackendLike     = "like"
+)
+
+// Searcher กำหนดวิธีกรองและจัดอันดับผลค้นหาสินค้า
+// table คือชื่อหรือ alias ของตาราง products ใน query นั้น
+type Searcher interface {
+	Filter(tx *gorm.DB, table, term string) *gorm.DB
+	Rank(tx *gorm.DB, table, term string) *gorm.DB
+}
+
+// NewSearcher เลือก backend ตามค่า config; ค่าที่ไม่รู้จักใช้ ILIKE
+func NewSearcher(backend string) Searcher {
+	if strings.EqualFold(backend, SearchBackendPostgres) {
+		return pgSearcher{}
+	}
+	return likeSearcher{}
+}
+
+// normalizeTerm ตัดช่องว่างซ้ำ เพราะผู้ใช้มักเว้นวรรคคำไทยไม่สม่ำเสมอ
+func normalizeTerm(term string) string {
+	return strings.Join(strings.Fields(term), " ")
+}
+
+// likeSearcher ILIKE แบบเดิม ใช้ได้ทุกฐานข้อมูลและใน test
+type likeSearcher struct{}
+
+func (likeSearcher) Filter(tx *gorm.DB, table, term string) *gorm.DB {
+	term = normalizeTerm(term)
+	if term == "" {
+		return tx
+	}
+	pattern := "%" + query.EscapeLike(term) + "%"
+	return tx.Where(
+		fmt.Sprintf("(%[1]s.name ILIKE ? OR %[1]s.sku ILIKE ? OR %[1]s.description ILIKE ?)", table),
+		pattern, pattern, pattern,
+	)
+}
+
+func (likeSearcher) Rank(tx *gorm.DB, _, _ string) *gorm.DB {
+	return tx
+}
+
+// pgSearcher ใช้ tsvector (search_vector) คู่กับ pg_trgm จาก migration 000006
+//
+// ภาษาไทยไม่เว้นวรรคระหว่างคำ tsvector จึงตัดคำไม่ได้ ส่วนนี้อาศัย ILIKE ที่มี trigram index
+// (ค้นกลางคำได้) และ word_similarity สำหรับคำที่สะกดผิดเล็กน้อย
+type pgSearcher struct{}
+
+// searchDocument ต้องตรงกับ expression ของ idx_products_search_trgm ไม่งั้น index จะไม่ถูกใช้
+func searchDocument(table string) string {
+	return fmt.Sprintf("(%[1]s.sku || ' ' || %[1]s.name || ' ' || COALESCE(%[1]s.description, ''))", table)
+}
+
+func (pgSearcher) Filter(tx *gorm.DB, table, term string) *gorm.DB {
+	term = normalizeTerm(term)
+	if term == "" {
+		return tx
+	}
+	doc := searchDocument(table)
+	return tx.Where(
+		fmt.Sprintf("(%s.search_vector @@ plainto_tsquery('simple', ?) OR %s ILIKE ? OR ? <%% %s)", table, doc, doc),
+		term, "%"+query.EscapeLike(term)+"%", term,
+	)
+}
+
+func (pgSearcher) Rank(tx *gorm.DB, table, term string) *gorm.DB {
+	term = normalizeTerm(term)
+	if term == "" {
+		return tx
+	}
+	// SKU ตรงตัวขึ้นก่อนเสมอ แล้วตามด้วยคะแนน full-text + ความคล้ายของ trigram
+	return tx.Clauses(clause.OrderBy{Expression: clause.Expr{
+		SQL: fmt.Sprintf("(%[1]s.sku ILIKE ?) DESC, ts_rank(%[1]s.search_vector, plainto_tsquery('simple', ?)) + word_similarity(?, %[2]s) DESC",
+			table, searchDocument(table)),
+		Vars:               []any{query.EscapeLike(term), term, term},
+		WithoutParentheses: true,
+	}})
+}
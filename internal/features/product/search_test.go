@@ -0,0 +1,62 @@
+package product
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunDB สร้าง gorm แบบ DryRun ไม่ต่อฐานข้อมูลจริง ใช้ดู SQL ที่ได้
+func dryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	require.NoError(t, err)
+	return db
+}
+
+func searchSQL(t *testing.T, s Searcher, term string) (string, []any) {
+	var rows []map[string]any
+	tx := dryRunDB(t).Table("products")
+	tx = s.Filter(tx, "products", term)
+	tx = s.Rank(tx, "products", term)
+	stmt := tx.Find(&rows).Statement
+	return stmt.SQL.String(), stmt.Vars
+}
+
+func TestNewSearcher(t *testing.T) {
+	assert.IsType(t, pgSearcher{}, NewSearcher("Postgres"))
+	assert.IsType(t, likeSearcher{}, NewSearcher("like"))
+	assert.IsType(t, likeSearcher{}, NewSearcher(""))
+}
+
+func TestLikeSearcher(t *testing.T) {
+	sql, vars := searchSQL(t, likeSearcher{}, "  ผ้า   เบรก 10%_ ")
+
+	assert.Contains(t, sql, "products.name ILIKE $1 OR products.sku ILIKE $2 OR products.description ILIKE $3")
+	assert.NotContains(t, sql, "ORDER BY")
+	assert.Equal(t, []any{`%ผ้า เบรก 10\%\_%`, `%ผ้า เบรก 10\%\_%`, `%ผ้า เบรก 10\%\_%`}, vars)
+}
+
+func TestPGSearcher(t *testing.T) {
+	sql, vars := searchSQL(t, pgSearcher{}, "brake  pad")
+
+	assert.Contains(t, sql, "products.search_vector @@ plainto_tsquery('simple', $1)")
+	assert.Contains(t, sql, "(products.sku || ' ' || products.name || ' ' || COALESCE(products.description, '')) ILIKE $2")
+	assert.Contains(t, sql, "$3 <% (products.sku")
+	assert.Contains(t, sql, "ORDER BY (products.sku ILIKE $4) DESC, ts_rank(products.search_vector, plainto_tsquery('simple', $5)) + word_similarity($6,")
+	assert.Equal(t, []any{"brake pad", "%brake pad%", "brake pad", "brake pad", "brake pad", "brake pad"}, vars)
+}
+
+func TestSearcher_EmptyTerm(t *testing.T) {
+	for _, s := range []Searcher{likeSearcher{}, pgSearcher{}} {
+		sql, vars := searchSQL(t, s, "   ")
+		assert.NotContains(t, sql, "WHERE")
+		assert.NotContains(t, sql, "ORDER BY")
+		assert.Empty(t, vars)
+	}
+}
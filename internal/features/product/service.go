@@ -2,6 +2,7 @@ package product
 
 import (
 	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/features/audit"
 	"ans-spareparts-api/internal/features/category"
 	"ans-spareparts-api/internal/features/inventory"
 	"ans-spareparts-api/internal/infra/httpx/ctxlog"
@@ -9,8 +10,12 @@ import (
 	"fmt"
 
 	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/cursor"
+	"ans-spareparts-api/pkg/export"
 	"ans-spareparts-api/pkg/utils"
+	"io"
 	"strings"
+	"time"
 
 	"context"
 
@@ -23,23 +28,37 @@ type Service interface {
 	UpdateProduct(ctx context.Context, productID uint, update UpdateInput) (*Item, error)
 	DeleteProduct(ctx context.Context, productID uint) error
 	List(ctx context.Context, q ListQuery) (*ListOutput, error)
+	// ImportProducts นำเข้าสินค้าจาก CSV/XLSX: ตรวจทุกแถว แล้ว upsert ตาม SKU เป็นชุด (ไม่เขียนถ้า DryRun)
+	ImportProducts(ctx context.Context, in ImportInput) (*ImportReport, error)
+	// ExportProducts เขียนสินค้าทั้งหมดตามเงื่อนไขลง out ทีละแถวในรูปแบบ csv/xlsx
+	ExportProducts(ctx context.Context, q ListQuery, format string, out io.Writer) error
+
+	// ListTrash สินค้าที่ถูกลบ (soft delete) สำหรับหน้าถังขยะ
+	ListTrash(ctx context.Context, limit, offset int) (*TrashOutput, error)
+	// RestoreProduct กู้สินค้าพร้อม inventory คืน ErrInvalidState ถ้าหมวดหมู่ของสินค้ายังอยู่ในถังขยะ
+	RestoreProduct(ctx context.Context, productID uint) error
+	// PurgeTrash ลบถาวรสินค้าที่อยู่ในถังขยะก่อน before
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
 }
 
 type service struct {
 	productRepo   Repository
 	categoryRepo  category.Repository
 	inventoryRepo inventory.Repository
+	auditor       audit.Recorder
 }
 
 func NewService(
 	productRepo Repository,
 	categoryRepo category.Repository,
 	inventoryRepo inventory.Repository,
+	auditor audit.Recorder,
 ) Service {
 	return &service{
 		productRepo:   productRepo,
 		categoryRepo:  categoryRepo,
 		inventoryRepo: inventoryRepo,
+		auditor:       auditor,
 	}
 }
 
@@ -73,15 +92,23 @@ func toItem(p *domain.Product, c *domain.Category, inv *domain.Inventory) *Item
 		Description: p.Description,
 		Price:       p.Price,
 		IsActive:    p.IsActive,
+		Version:     p.Version,
+		UpdatedAt:   p.UpdatedAt,
 	}
 	if c != nil {
 		out.Category.ID = c.ID
 		out.Category.Name = c.Name
+		out.Category.Version = c.Version
 	}
 	if inv != nil {
 		out.Inventory.ID = inv.ID
 		out.Inventory.ProductID = inv.ProductID
 		out.Inventory.Quantity = inv.Quantity
+		out.Inventory.Version = inv.Version
+		// สต็อกเปลี่ยนก็ถือว่ารายละเอียดสินค้าเปลี่ยน
+		if inv.UpdatedAt.After(out.UpdatedAt) {
+			out.UpdatedAt = inv.UpdatedAt
+		}
 	}
 
 	return out
@@ -165,6 +192,7 @@ func (i *service) CreateProduct(ctx context.Context, in CreateInput) (*Item, err
 		return nil, err
 	}
 
+	i.auditor.Record(ctx, audit.Entry{EntityType: audit.EntityProduct, EntityID: product.ID, Action: domain.AuditCreate, After: product})
 	log.Info("product.created", zap.Uint("id", product.ID), zap.String("sku", product.SKU))
 
 	return toItem(product, category, inv), nil
@@ -181,11 +209,20 @@ func (i *service) UpdateProduct(ctx context.Context, productID uint, in UpdateIn
 		return nil, err
 	}
 
+	// client แก้จากข้อมูลเก่า (มีคนอื่นแก้ไปก่อนแล้ว)
+	if in.Version != nil && *in.Version != product.Version {
+		log.Info("product.update.version_conflict", zap.Uint("id", productID), zap.Uint("version", product.Version))
+		return nil, apperror.ErrVersionConflict
+	}
+
 	// data validator
 	if err := sanitizeUpdate(in); err != nil {
 		return nil, err
 	}
 
+	// เก็บค่าเดิมไว้ทำ audit diff ก่อนแก้ product
+	before := *product
+
 	// Apply updates
 	if in.Name != nil && in.Name != &product.Name {
 		product.Name = utils.SanitizeString(*in.Name)
@@ -237,6 +274,7 @@ func (i *service) UpdateProduct(ctx context.Context, productID uint, in UpdateIn
 		return nil, err
 	}
 
+	i.auditor.Record(ctx, audit.Entry{EntityType: audit.EntityProduct, EntityID: productID, Action: domain.AuditUpdate, Before: &before, After: product})
 	log.Info("product.updated", zap.Uint("id", productID))
 	return toItem(product, &product.Category, inventory), nil
 }
@@ -244,7 +282,7 @@ func (i *service) UpdateProduct(ctx context.Context, productID uint, in UpdateIn
 func (i *service) DeleteProduct(ctx context.Context, productID uint) error {
 	log := ctxlog.From(ctx)
 
-	_, err := i.productRepo.GetByID(ctx, productID)
+	product, err := i.productRepo.GetByID(ctx, productID)
 	if err != nil {
 		return err
 	}
@@ -259,6 +297,7 @@ func (i *service) DeleteProduct(ctx context.Context, productID uint) error {
 		return err
 	}
 
+	i.auditor.Record(ctx, audit.Entry{EntityType: audit.EntityProduct, EntityID: productID, Action: domain.AuditDelete, Before: product})
 	log.Info("product.Deledted", zap.Uint("productID", productID))
 	return nil
 }
@@ -266,16 +305,35 @@ func (i *service) DeleteProduct(ctx context.Context, productID uint) error {
 func (i *service) List(ctx context.Context, q ListQuery) (*ListOutput, error) {
 	// map query
 	query := ListQuery{
-		Search: q.Search,
-		Limit:  q.Limit,
-		Offset: q.Offset,
-		Sort:   q.Sort,
+		Search:  q.Search,
+		Limit:   q.Limit,
+		Offset:  q.Offset,
+		Sort:    q.Sort,
+		Filters: q.Filters,
+		Keyset:  q.Keyset,
+		After:   q.After,
 	}
 	products, total, err := i.productRepo.List(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 
+	// facet นับจากผลค้นหาทั้งหมด จึงไม่ขึ้นกับ limit/offset/cursor
+	var facets *Facets
+	if q.Facets {
+		if facets, err = i.productRepo.Facets(ctx, ListQuery{Search: q.Search, Filters: q.Filters}); err != nil {
+			return nil, err
+		}
+	}
+
+	var next string
+	if q.Keyset {
+		sorts := listSchema.SortOrDefault(q.Sort)
+		products, next = cursor.Page(products, q.Limit, func(p *domain.Product) cursor.Cursor {
+			return cursor.New(sorts, p.ID, func(column string) any { return sortValue(p, column) })
+		})
+	}
+
 	items := make([]*ItemLite, 0, len(products))
 	for _, p := range products {
 		items = append(items, &ItemLite{
@@ -287,5 +345,182 @@ func (i *service) List(ctx context.Context, q ListQuery) (*ListOutput, error) {
 		})
 	}
 
-	return &ListOutput{Items: items, Total: total}, nil
+	return &ListOutput{Items: items, Total: total, NextCursor: next, Facets: facets}, nil
+}
+
+func (i *service) ImportProducts(ctx context.Context, in ImportInput) (*ImportReport, error) {
+	log := ctxlog.From(ctx)
+
+	rows, err := parseImport(in.File, in.Format, in.Mapping)
+	if err != nil {
+		log.Warn("product.import.parse_failed", zap.Error(err))
+		return nil, err
+	}
+
+	report := &ImportReport{DryRun: in.DryRun, Total: len(rows)}
+
+	// SKU ที่อยู่ในถังขยะ ON CONFLICT จะอัปเดตแถวที่มองไม่เห็น จึงรายงานเป็น error ของแถวแทน
+	skus := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if row.Err == "" {
+			skus = append(skus, row.Input.SKU)
+		}
+	}
+	deletedSKUs, err := i.productRepo.DeletedSKUs(ctx, skus)
+	if err != nil {
+		return nil, err
+	}
+	deleted := make(map[string]bool, len(deletedSKUs))
+	for _, sku := range deletedSKUs {
+		deleted[sku] = true
+	}
+
+	// ตรวจ category ครั้งเดียวต่อ id
+	categories := make(map[uint]bool)
+	valid := make([]ImportRecord, 0, len(rows))
+	for _, row := range rows {
+		if row.Err == "" {
+			exists, checked := categories[row.Input.CategoryID]
+			if !checked {
+				_, err := i.categoryRepo.GetByID(ctx, row.Input.CategoryID)
+				if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+					return nil, err
+				}
+				exists = err == nil
+				categories[row.Input.CategoryID] = exists
+			}
+			if !exists {
+				row.Err = "category not found"
+			} else if deleted[row.Input.SKU] {
+				row.Err = "product is deleted, restore it before importing"
+			}
+		}
+
+		if row.Err != "" {
+			report.Errors = append(report.Errors, ImportRowError{Row: row.Line, SKU: row.Input.SKU, Message: row.Err})
+			continue
+		}
+
+		valid = append(valid, ImportRecord{
+			Product: &domain.Product{
+				Name:        row.Input.Name,
+				Description: row.Input.Description,
+				Price:       row.Input.Price,
+				SKU:         row.Input.SKU,
+				CategoryID:  row.Input.CategoryID,
+				IsActive:    true,
+			},
+			Quantity: row.Quantity,
+		})
+	}
+	report.Valid = len(valid)
+	report.Failed = len(report.Errors)
+
+	if in.DryRun {
+		log.Info("product.import.dry_run", zap.Int("total", report.Total), zap.Int("valid", report.Valid), zap.Int("failed", report.Failed))
+		return report, nil
+	}
+
+	// แต่ละชุดเป็น transaction ของตัวเอง ชุดที่ผ่านแล้วไม่ถูก rollback ถ้าชุดถัดไปล้ม
+	for start := 0; start < len(valid); start += importBatchSize {
+		end := min(start+importBatchSize, len(valid))
+		created, updated, err := i.productRepo.UpsertBySKU(ctx, valid[start:end])
+		if err != nil {
+			log.Error("product.import.batch_failed", zap.Int("from_row", start), zap.Int("created", report.Created), zap.Int("updated", report.Updated), zap.Error(err))
+			return nil, err
+		}
+		report.Created += created
+		report.Updated += updated
+	}
+
+	// import เป็นชุด จึงบันทึกเป็นสรุปหนึ่งแถวด้วย entity type ของการ import เอง
+	// ไม่ปนกับประวัติของสินค้ารายตัว (entity_type=product)
+	i.auditor.Record(ctx, audit.Entry{
+		EntityType: audit.EntityProductImport,
+		Action:     domain.AuditImport,
+		Changes: map[string]audit.Change{
+			"created": {New: report.Created},
+			"updated": {New: report.Updated},
+		},
+	})
+	log.Info("product.imported", zap.Int("total", report.Total), zap.Int("created", report.Created), zap.Int("updated", report.Updated), zap.Int("failed", report.Failed))
+	return report, nil
+}
+
+var exportHeader = []string{"id", "sku", "name", "description", "category", "price", "quantity", "is_active"}
+
+func (i *service) ExportProducts(ctx context.Context, q ListQuery, format string, out io.Writer) error {
+	log := ctxlog.From(ctx)
+
+	w, err := export.NewWriter(out, format, exportHeader)
+	if err != nil {
+		return err
+	}
+
+	n := 0
+	err = i.productRepo.Export(ctx, ListQuery{Search: q.Search, Sort: q.Sort, Filters: q.Filters}, func(r *ExportRow) error {
+		n++
+		return w.Write([]any{r.ID, r.SKU, r.Name, r.Description, r.Category, r.Price, r.Quantity, r.IsActive})
+	})
+	if err != nil {
+		log.Error("product.export.failed", zap.Int("rows", n), zap.Error(err))
+		return err
+	}
+	if err := w.Close(); err != nil {
+		log.Error("product.export.flush_failed", zap.Error(err))
+		return err
+	}
+
+	log.Info("product.exported", zap.String("format", format), zap.Int("rows", n))
+	return nil
+}
+
+func (i *service) ListTrash(ctx context.Context, limit, offset int) (*TrashOutput, error) {
+	limit, offset = utils.NormalizePagination(limit, offset)
+
+	products, total, err := i.productRepo.ListDeleted(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*TrashItem, len(products))
+	for index, p := range products {
+		items[index] = &TrashItem{
+			ID:        p.ID,
+			SKU:       p.SKU,
+			Name:      p.Name,
+			DeletedAt: p.DeletedAt.Time,
+		}
+	}
+
+	return &TrashOutput{Items: items, Total: total}, nil
+}
+
+func (i *service) RestoreProduct(ctx context.Context, productID uint) error {
+	log := ctxlog.From(ctx)
+
+	if err := i.productRepo.Restore(ctx, productID); err != nil {
+		return err
+	}
+
+	i.auditor.Record(ctx, audit.Entry{
+		EntityType: audit.EntityProduct,
+		EntityID:   productID,
+		Action:     domain.AuditRestore,
+		Changes:    map[string]audit.Change{"deleted": {Old: true, New: false}},
+	})
+	log.Info("product.restored", zap.Uint("productID", productID))
+	return nil
+}
+
+func (i *service) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	log := ctxlog.From(ctx)
+
+	purged, err := i.productRepo.PurgeDeleted(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+
+	log.Info("product.trash.purged", zap.Int64("purged", purged), zap.Time("before", before))
+	return purged, nil
 }
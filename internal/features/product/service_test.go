@@ -2,19 +2,28 @@ package product_test
 
 import (
 	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/features/audit"
 	"ans-spareparts-api/internal/features/category"
 	"ans-spareparts-api/internal/features/inventory"
 	"ans-spareparts-api/internal/features/product"
 	mocks "ans-spareparts-api/internal/mock"
 	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/cursor"
+	"ans-spareparts-api/pkg/export"
+	"ans-spareparts-api/pkg/query"
 	"ans-spareparts-api/pkg/testutil"
 	"ans-spareparts-api/pkg/testutil/fixtures"
+	"bytes"
 	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
 )
 
 // TestSuite struct: เก้บตัวแปรที่ใช้ร่วมกัน
@@ -23,6 +32,7 @@ type TestSuite struct {
 	MockProductRepo   *mocks.ProductRepository
 	MockCategoryRepo  *mocks.CategoryRepository
 	MockInventoryRepo *mocks.InventoryRepository
+	MockAudit         *mocks.AuditService
 	Ctx               context.Context
 }
 
@@ -38,11 +48,16 @@ func (ts *TestSuite) SetupTest(t *testing.T) {
 	ts.MockInventoryRepo = mocks.NewMockInventoryRepository()
 	ts.Ctx = context.Background()
 
+	// audit เป็น best-effort: ยอมรับทุกการเรียก แล้วตรวจผ่าน MockAudit.Calls ใน test ที่สนใจ
+	ts.MockAudit = mocks.NewAuditService()
+	ts.MockAudit.On("Record", mock.Anything, mock.Anything).Maybe()
+
 	// สร้าง service Instance โดยใช้ข้่อมูล mock
 	ts.Service = product.NewService(
 		ts.MockProductRepo,
 		ts.MockCategoryRepo,
 		ts.MockInventoryRepo,
+		ts.MockAudit,
 	)
 
 	// ตั้งค่า Teardown: จะถูกเรียกเมื่อ t.Run หรือ test func จบ
@@ -510,6 +525,47 @@ func TestProductService_UpdateProduct(t *testing.T) {
 				assert.Equal(t, 99.99, i.Price)
 			},
 		},
+		{
+			name: "Error_Version_Stale",
+			ID:   uint(1),
+			input: product.UpdateInput{
+				Name:    testutil.PTRHelper("New Name"),
+				Version: testutil.PTRHelper(uint(1)),
+			},
+			setup: func(ts *TestSuite) {
+				current := fixtures.ValidProductLite()
+				current.Version = 2
+				ts.MockProductRepo.On("GetByID", ts.Ctx, uint(1)).Return(current, nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrVersionConflict)
+			},
+			validate: func(t *testing.T, i *product.Item) {
+				assert.Nil(t, i)
+			},
+		},
+		{
+			name: "Error_Version_Changed_Concurrently",
+			ID:   uint(1),
+			input: product.UpdateInput{
+				Name:    testutil.PTRHelper("New Name"),
+				Version: testutil.PTRHelper(uint(2)),
+			},
+			setup: func(ts *TestSuite) {
+				current := fixtures.ValidProductLite()
+				current.Version = 2
+				ts.MockProductRepo.On("GetByID", ts.Ctx, uint(1)).Return(current, nil).Once()
+				ts.MockProductRepo.On("Update", ts.Ctx, mock.MatchedBy(func(p *domain.Product) bool {
+					return p.Version == 2
+				})).Return(apperror.ErrVersionConflict).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrVersionConflict)
+			},
+			validate: func(t *testing.T, i *product.Item) {
+				assert.Nil(t, i)
+			},
+		},
 		{
 			name: "Error_Product_NotFound",
 			ID:   uint(999),
@@ -656,6 +712,28 @@ func TestProductService_UpdateProduct(t *testing.T) {
 	}
 }
 
+func TestProductService_UpdateProduct_RecordsAudit(t *testing.T) {
+	ts := NewTestSuite()
+	ts.SetupTest(t)
+
+	ts.MockProductRepo.On("GetByID", ts.Ctx, uint(1)).Return(fixtures.ValidProductLite(), nil).Once()
+	ts.MockProductRepo.On("Update", ts.Ctx, mock.Anything).Return(nil).Once()
+	ts.MockInventoryRepo.On("GetByProductID", ts.Ctx, uint(1)).Return(fixtures.ValidInventory(), nil).Once()
+
+	_, err := ts.Service.UpdateProduct(ts.Ctx, 1, product.UpdateInput{Price: testutil.PTRHelper(2.5)})
+	assert.NoError(t, err)
+
+	ts.MockAudit.AssertNumberOfCalls(t, "Record", 1)
+	entry := ts.MockAudit.Calls[0].Arguments.Get(1).(audit.Entry)
+	assert.Equal(t, audit.EntityProduct, entry.EntityType)
+	assert.Equal(t, domain.AuditUpdate, entry.Action)
+
+	// before ต้องเป็นค่าก่อนแก้ ไม่ใช่ pointer ตัวเดียวกับ after
+	changes, err := audit.Diff(entry.Before, entry.After)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]audit.Change{"price": {Old: float64(1), New: 2.5}}, changes)
+}
+
 func TestProductService_DeleteProduct(t *testing.T) {
 	validProduct := fixtures.ValidListProduct()
 	productID := uint(1)
@@ -726,13 +804,91 @@ func TestProductService_DeleteProduct(t *testing.T) {
 	}
 }
 
+func TestProductService_ListTrash(t *testing.T) {
+	deletedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	trashed := &domain.Product{ID: 7, SKU: "BRK-007", Name: "Brake pad", DeletedAt: gorm.DeletedAt{Time: deletedAt, Valid: true}}
+
+	tests := []struct {
+		name      string
+		limit     int
+		offset    int
+		setup     func(ts *TestSuite)
+		assertOut func(*testing.T, *product.TrashOutput, error)
+	}{
+		{
+			name:  "Success_Normalizes_Pagination",
+			limit: 0,
+			setup: func(ts *TestSuite) {
+				ts.MockProductRepo.On("ListDeleted", ts.Ctx, 10, 0).Return([]*domain.Product{trashed}, int64(1), nil).Once()
+			},
+			assertOut: func(t *testing.T, out *product.TrashOutput, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, int64(1), out.Total)
+				assert.Equal(t, &product.TrashItem{ID: 7, SKU: "BRK-007", Name: "Brake pad", DeletedAt: deletedAt}, out.Items[0])
+			},
+		},
+		{
+			name:   "Error_DBError",
+			limit:  20,
+			offset: 40,
+			setup: func(ts *TestSuite) {
+				ts.MockProductRepo.On("ListDeleted", ts.Ctx, 20, 40).Return(nil, int64(0), apperror.ErrInternalServer).Once()
+			},
+			assertOut: func(t *testing.T, out *product.TrashOutput, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInternalServer)
+				assert.Nil(t, out)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewTestSuite()
+			ts.SetupTest(t)
+
+			test.setup(ts)
+			out, err := ts.Service.ListTrash(ts.Ctx, test.limit, test.offset)
+
+			test.assertOut(t, out, err)
+		})
+	}
+}
+
+func TestProductService_RestoreProduct(t *testing.T) {
+	tests := []struct {
+		name    string
+		restore error
+		wantErr error
+	}{
+		{name: "Success_Restored"},
+		{name: "Error_Not_In_Trash", restore: apperror.ErrNotFound, wantErr: apperror.ErrNotFound},
+		{name: "Error_Category_In_Trash", restore: apperror.ErrInvalidState, wantErr: apperror.ErrInvalidState},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewTestSuite()
+			ts.SetupTest(t)
+
+			ts.MockProductRepo.On("Restore", ts.Ctx, uint(1)).Return(test.restore).Once()
+			err := ts.Service.RestoreProduct(ts.Ctx, 1)
+
+			if test.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, test.wantErr)
+		})
+	}
+}
+
 func TestProductService_List(t *testing.T) {
 	validateProduct := fixtures.ValidListProduct()
 	inputQuery := product.ListQuery{
 		Search: "product",
 		Limit:  10,
 		Offset: 20,
-		Sort:   "name desc",
+		Sort:   []query.Sort{{Column: "products.name", Desc: true}},
 	}
 
 	tests := []struct {
@@ -750,7 +906,7 @@ func TestProductService_List(t *testing.T) {
 					assert.Equal(t, "product", q.Search)
 					assert.Equal(t, 10, q.Limit)
 					assert.Equal(t, 20, q.Offset)
-					assert.Equal(t, "name desc", q.Sort)
+					assert.Equal(t, []query.Sort{{Column: "products.name", Desc: true}}, q.Sort)
 					return true
 				})).Return(validateProduct, int64(2), nil).Once()
 			},
@@ -772,7 +928,7 @@ func TestProductService_List(t *testing.T) {
 					assert.Equal(t, "product", q.Search)
 					assert.Equal(t, 10, q.Limit)
 					assert.Equal(t, 20, q.Offset)
-					assert.Equal(t, "name desc", q.Sort)
+					assert.Equal(t, []query.Sort{{Column: "products.name", Desc: true}}, q.Sort)
 					return true
 				})).Return([]*domain.Product{}, int64(0), nil).Once()
 			},
@@ -784,6 +940,72 @@ func TestProductService_List(t *testing.T) {
 
 			},
 		},
+		{
+			name:  "Success_Keyset_Returns_NextCursor",
+			input: product.ListQuery{Limit: 1, Keyset: true},
+			setup: func(ts *TestSuite) {
+				ts.MockProductRepo.On("List", ts.Ctx, product.ListQuery{Limit: 1, Keyset: true}).Return(validateProduct, int64(0), nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, lo *product.ListOutput) {
+				assert.Len(t, lo.Items, 1)
+				c, err := cursor.Decode(lo.NextCursor)
+				assert.NoError(t, err)
+				assert.Equal(t, validateProduct[0].ID, c.ID)
+			},
+		},
+		{
+			name:  "Success_With_Facets",
+			input: product.ListQuery{Search: "filter", Limit: 10, Facets: true},
+			setup: func(ts *TestSuite) {
+				ts.MockProductRepo.On("List", ts.Ctx, product.ListQuery{Search: "filter", Limit: 10}).Return(validateProduct, int64(2), nil).Once()
+				ts.MockProductRepo.On("Facets", ts.Ctx, product.ListQuery{Search: "filter"}).Return(&product.Facets{
+					Categories: []product.CategoryFacet{{ID: 1, Name: "Filters", Count: 2}},
+					InStock:    1,
+					OutOfStock: 1,
+				}, nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, lo *product.ListOutput) {
+				assert.Len(t, lo.Items, 2)
+				assert.Equal(t, int64(2), lo.Facets.Categories[0].Count)
+				assert.Equal(t, int64(1), lo.Facets.InStock)
+			},
+		},
+		{
+			name:  "Error_Facets_DBError",
+			input: product.ListQuery{Limit: 10, Facets: true},
+			setup: func(ts *TestSuite) {
+				ts.MockProductRepo.On("List", ts.Ctx, product.ListQuery{Limit: 10}).Return(validateProduct, int64(2), nil).Once()
+				ts.MockProductRepo.On("Facets", ts.Ctx, product.ListQuery{}).Return(nil, apperror.ErrInternalServer).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInternalServer)
+			},
+			validate: func(t *testing.T, lo *product.ListOutput) {
+				assert.Nil(t, lo)
+			},
+		},
+		{
+			name:  "Success_Keyset_LastPage",
+			input: product.ListQuery{Limit: 10, Keyset: true, After: &cursor.Cursor{Key: "-products.created_at", Values: []any{"2025-01-01T00:00:00Z"}, ID: 9}},
+			setup: func(ts *TestSuite) {
+				ts.MockProductRepo.On("List", ts.Ctx, mock.MatchedBy(func(q product.ListQuery) bool {
+					return q.Keyset && q.After != nil && q.After.ID == 9
+				})).Return(validateProduct, int64(0), nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, lo *product.ListOutput) {
+				assert.Len(t, lo.Items, len(validateProduct))
+				assert.Empty(t, lo.NextCursor)
+			},
+		},
 		{
 			name:  "Error_Retrieves_DBError",
 			input: inputQuery,
@@ -792,7 +1014,7 @@ func TestProductService_List(t *testing.T) {
 					assert.Equal(t, "product", q.Search)
 					assert.Equal(t, 10, q.Limit)
 					assert.Equal(t, 20, q.Offset)
-					assert.Equal(t, "name desc", q.Sort)
+					assert.Equal(t, []query.Sort{{Column: "products.name", Desc: true}}, q.Sort)
 					return true
 				})).Return(nil, int64(0), apperror.ErrInternalServer).Once()
 			},
@@ -818,3 +1040,270 @@ func TestProductService_List(t *testing.T) {
 		})
 	}
 }
+
+func TestProductService_ImportProducts(t *testing.T) {
+	csvFile := "Part No,Name,Description,Price,Category,Qty\n" +
+		"brk-001,Brake  pad,Front,450.50,1,10\n" +
+		"FLT-001,Oil filter,,120,1,\n" +
+		",No sku,,10,1,1\n" +
+		"BRK-001,Duplicate,,1,1,1\n" +
+		"OIL-001,Engine oil,,abc,1,1\n" +
+		"BLT-001,Belt,,300,9,2\n" +
+		",,,,,\n"
+	mapping := map[string]string{"sku": "Part No", "category_id": "Category", "quantity": "Qty"}
+
+	xlsxFile := func() *bytes.Buffer {
+		f := excelize.NewFile()
+		sheet := f.GetSheetName(0)
+		_ = f.SetSheetRow(sheet, "A1", &[]any{"sku", "name", "price", "category_id", "quantity"})
+		_ = f.SetSheetRow(sheet, "A2", &[]any{"WHL-001", "Wheel", 1500, 1, 4})
+		buf, _ := f.WriteToBuffer()
+		return buf
+	}
+
+	tests := []struct {
+		name      string
+		input     func() product.ImportInput
+		setup     func(ts *TestSuite)
+		assertErr func(*testing.T, error)
+		validate  func(*testing.T, *product.ImportReport)
+	}{
+		{
+			name: "Success_DryRun_Reports_Row_Errors",
+			input: func() product.ImportInput {
+				return product.ImportInput{File: strings.NewReader(csvFile), Format: product.ImportFormatCSV, Mapping: mapping, DryRun: true}
+			},
+			setup: func(ts *TestSuite) {
+				ts.MockCategoryRepo.On("GetByID", ts.Ctx, uint(1)).Return(fixtures.ValidCategory(), nil).Once()
+				ts.MockCategoryRepo.On("GetByID", ts.Ctx, uint(9)).Return(nil, apperror.ErrNotFound).Once()
+				ts.MockProductRepo.On("DeletedSKUs", ts.Ctx, []string{"BRK-001", "FLT-001", "BLT-001"}).Return(nil, nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, r *product.ImportReport) {
+				assert.True(t, r.DryRun)
+				assert.Equal(t, 6, r.Total)
+				assert.Equal(t, 2, r.Valid)
+				assert.Equal(t, 4, r.Failed)
+				assert.Equal(t, 0, r.Created)
+				rows := []int{}
+				for _, e := range r.Errors {
+					rows = append(rows, e.Row)
+				}
+				assert.Equal(t, []int{4, 5, 6, 7}, rows)
+				assert.Equal(t, "duplicate sku in file (first at row 2)", r.Errors[1].Message)
+				assert.Equal(t, "category not found", r.Errors[3].Message)
+			},
+		},
+		{
+			name: "Success_Upserts_Valid_Rows",
+			input: func() product.ImportInput {
+				return product.ImportInput{File: strings.NewReader(csvFile), Format: product.ImportFormatCSV, Mapping: mapping}
+			},
+			setup: func(ts *TestSuite) {
+				ts.MockCategoryRepo.On("GetByID", ts.Ctx, uint(1)).Return(fixtures.ValidCategory(), nil).Once()
+				ts.MockCategoryRepo.On("GetByID", ts.Ctx, uint(9)).Return(nil, apperror.ErrNotFound).Once()
+				ts.MockProductRepo.On("DeletedSKUs", ts.Ctx, []string{"BRK-001", "FLT-001", "BLT-001"}).Return(nil, nil).Once()
+				ts.MockProductRepo.On("UpsertBySKU", ts.Ctx, mock.MatchedBy(func(recs []product.ImportRecord) bool {
+					return len(recs) == 2 &&
+						recs[0].Product.SKU == "BRK-001" && recs[0].Product.Name == "Brake pad" && recs[0].Quantity == 10 &&
+						recs[1].Product.SKU == "FLT-001" && recs[1].Quantity == 0
+				})).Return(1, 1, nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, r *product.ImportReport) {
+				assert.Equal(t, 1, r.Created)
+				assert.Equal(t, 1, r.Updated)
+				assert.Equal(t, 4, r.Failed)
+			},
+		},
+		{
+			name: "Success_XLSX",
+			input: func() product.ImportInput {
+				return product.ImportInput{File: xlsxFile(), Format: product.ImportFormatXLSX}
+			},
+			setup: func(ts *TestSuite) {
+				ts.MockCategoryRepo.On("GetByID", ts.Ctx, uint(1)).Return(fixtures.ValidCategory(), nil).Once()
+				ts.MockProductRepo.On("DeletedSKUs", ts.Ctx, []string{"WHL-001"}).Return(nil, nil).Once()
+				ts.MockProductRepo.On("UpsertBySKU", ts.Ctx, mock.MatchedBy(func(recs []product.ImportRecord) bool {
+					return len(recs) == 1 && recs[0].Product.SKU == "WHL-001" && recs[0].Product.Price == 1500 && recs[0].Quantity == 4
+				})).Return(1, 0, nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, r *product.ImportReport) {
+				assert.Equal(t, 1, r.Created)
+				assert.Empty(t, r.Errors)
+			},
+		},
+		{
+			name: "Success_Deleted_SKU_Reported_As_Row_Error",
+			input: func() product.ImportInput {
+				return product.ImportInput{File: strings.NewReader("sku,name,price,category_id\nABC-1,x,1,1\nABC-2,y,2,1\n"), Format: product.ImportFormatCSV}
+			},
+			setup: func(ts *TestSuite) {
+				ts.MockCategoryRepo.On("GetByID", ts.Ctx, uint(1)).Return(fixtures.ValidCategory(), nil).Once()
+				ts.MockProductRepo.On("DeletedSKUs", ts.Ctx, []string{"ABC-1", "ABC-2"}).Return([]string{"ABC-1"}, nil).Once()
+				ts.MockProductRepo.On("UpsertBySKU", ts.Ctx, mock.MatchedBy(func(recs []product.ImportRecord) bool {
+					return len(recs) == 1 && recs[0].Product.SKU == "ABC-2"
+				})).Return(1, 0, nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, r *product.ImportReport) {
+				assert.Equal(t, 1, r.Created)
+				assert.Equal(t, 1, r.Failed)
+				assert.Equal(t, []product.ImportRowError{{Row: 2, SKU: "ABC-1", Message: "product is deleted, restore it before importing"}}, r.Errors)
+			},
+		},
+		{
+			name: "Error_Missing_Required_Column",
+			input: func() product.ImportInput {
+				return product.ImportInput{File: strings.NewReader("sku,name\nA-1,x\n"), Format: product.ImportFormatCSV}
+			},
+			setup: func(ts *TestSuite) {},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidInput)
+			},
+			validate: func(t *testing.T, r *product.ImportReport) {
+				assert.Nil(t, r)
+			},
+		},
+		{
+			name: "Error_Unsupported_Format",
+			input: func() product.ImportInput {
+				return product.ImportInput{File: strings.NewReader(""), Format: "ods"}
+			},
+			setup: func(ts *TestSuite) {},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidInput)
+			},
+			validate: func(t *testing.T, r *product.ImportReport) {
+				assert.Nil(t, r)
+			},
+		},
+		{
+			name: "Error_Upsert_DB",
+			input: func() product.ImportInput {
+				return product.ImportInput{File: strings.NewReader("sku,name,price,category_id\nABC-1,x,1,1\n"), Format: product.ImportFormatCSV}
+			},
+			setup: func(ts *TestSuite) {
+				ts.MockCategoryRepo.On("GetByID", ts.Ctx, uint(1)).Return(fixtures.ValidCategory(), nil).Once()
+				ts.MockProductRepo.On("DeletedSKUs", ts.Ctx, mock.Anything).Return(nil, nil).Once()
+				ts.MockProductRepo.On("UpsertBySKU", ts.Ctx, mock.Anything).Return(0, 0, apperror.ErrInternalServer).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInternalServer)
+			},
+			validate: func(t *testing.T, r *product.ImportReport) {
+				assert.Nil(t, r)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewTestSuite()
+			ts.SetupTest(t)
+
+			test.setup(ts)
+			report, err := ts.Service.ImportProducts(ts.Ctx, test.input())
+
+			test.assertErr(t, err)
+			test.validate(t, report)
+		})
+	}
+}
+
+func TestProductService_ExportProducts(t *testing.T) {
+	rows := []*product.ExportRow{
+		{ID: 1, SKU: "BRK-001", Name: "Brake pad", Category: "Brakes", Price: 450.5, Quantity: 10, IsActive: true},
+		{ID: 2, SKU: "FLT-001", Name: "=cmd()", Category: "Filters", Price: 120, Quantity: 0, IsActive: true},
+	}
+	// sort ต้องส่งต่อถึง repo เหมือน List ส่วน limit ไม่ใช้กับ export
+	sorts := []query.Sort{{Column: "products.price", Desc: true}}
+
+	tests := []struct {
+		name      string
+		format    string
+		setup     func(ts *TestSuite)
+		assertErr func(*testing.T, error)
+		validate  func(*testing.T, *bytes.Buffer)
+	}{
+		{
+			name:   "Success_CSV_Escapes_Formula",
+			format: export.FormatCSV,
+			setup: func(ts *TestSuite) {
+				ts.MockProductRepo.On("Export", ts.Ctx, product.ListQuery{Search: "brk", Sort: sorts}, mock.Anything).Return(rows, nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, out *bytes.Buffer) {
+				lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+				assert.Len(t, lines, 3)
+				assert.Equal(t, "id,sku,name,description,category,price,quantity,is_active", lines[0])
+				assert.Equal(t, "1,BRK-001,Brake pad,,Brakes,450.5,10,true", lines[1])
+				assert.Contains(t, lines[2], "'=cmd()")
+			},
+		},
+		{
+			name:   "Success_XLSX",
+			format: export.FormatXLSX,
+			setup: func(ts *TestSuite) {
+				ts.MockProductRepo.On("Export", ts.Ctx, product.ListQuery{Search: "brk", Sort: sorts}, mock.Anything).Return(rows, nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, out *bytes.Buffer) {
+				f, err := excelize.OpenReader(out)
+				assert.NoError(t, err)
+				got, _ := f.GetRows(f.GetSheetName(0))
+				assert.Len(t, got, 3)
+				assert.Equal(t, "BRK-001", got[1][1])
+			},
+		},
+		{
+			name:   "Error_Unsupported_Format",
+			format: "pdf",
+			setup:  func(ts *TestSuite) {},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, export.ErrUnsupportedFormat)
+			},
+			validate: func(t *testing.T, out *bytes.Buffer) {
+				assert.Zero(t, out.Len())
+			},
+		},
+		{
+			name:   "Error_Repo_Failed",
+			format: export.FormatCSV,
+			setup: func(ts *TestSuite) {
+				ts.MockProductRepo.On("Export", ts.Ctx, product.ListQuery{Search: "brk", Sort: sorts}, mock.Anything).Return(nil, apperror.ErrInternalServer).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInternalServer)
+			},
+			validate: func(t *testing.T, out *bytes.Buffer) {},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewTestSuite()
+			ts.SetupTest(t)
+
+			test.setup(ts)
+			out := &bytes.Buffer{}
+			err := ts.Service.ExportProducts(ts.Ctx, product.ListQuery{Search: "brk", Sort: sorts, Limit: 10}, test.format, out)
+
+			test.assertErr(t, err)
+			test.validate(t, out)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package quotation
+
+import (
+	"ans-spareparts-api/pkg/query"
+	"time"
+)
+
+type LineInput struct {
+	ProductID uint
+	Quantity  int
+}
+
+type CreateInput struct {
+	CustomerName  string
+	CustomerPhone string
+	CustomerTaxID string
+	ValidUntil    *time.Time
+	Note          string
+	CreatedBy     uint
+	Lines         []LineInput
+}
+
+// listSchema field ที่กรอง/เรียงได้ของ GET /quotations
+var listSchema = query.Schema{
+	Fields: map[string]query.Field{
+		"status":        {Column: "status", Type: query.String, Ops: []query.Op{query.OpEq, query.OpIn}},
+		"number":        {Column: "number", Type: query.String, Ops: []query.Op{query.OpEq, query.OpLike}, Sortable: true},
+		"customer_name": {Column: "customer_name", Type: query.String, Ops: []query.Op{query.OpEq, query.OpLike}, Sortable: true},
+		"total":         {Column: "total", Type: query.Float, Ops: query.Comparable, Sortable: true},
+		"valid_until":   {Column: "valid_until", Type: query.Time, Ops: query.Comparable, Sortable: true},
+		"created_at":    {Column: "created_at", Type: query.Time, Ops: query.Comparable, Sortable: true},
+	},
+	DefaultSort: []query.Sort{{Column: "created_at", Desc: true}},
+}
+
+type ListQuery struct {
+	Limit   int
+	Offset  int
+	Sort    []query.Sort
+	Filters []query.Filter
+}
+
+type LineItem struct {
+	ProductID uint
+	SKU       string
+	Name      string
+	Quantity  int
+	UnitPrice float64
+	LineTotal float64
+}
+
+type Item struct {
+	ID            uint
+	Number        string
+	CustomerName  string
+	CustomerPhone string
+	CustomerTaxID string
+	Status        string
+	ValidUntil    time.Time
+	Total         float64
+	Note          string
+	CreatedBy     uint
+	CreatedAt     time.Time
+	Lines         []LineItem
+}
+
+type ListOutput struct {
+	Items []*Item
+	Total int64
+}
+
+type LineRequest struct {
+	ProductID uint `json:"product_id"`
+	Quantity  int  `json:"quantity"`
+}
+
+type CreateQuotationRequest struct {
+	CustomerName  string        `json:"customer_name"`
+	CustomerPhone string        `json:"customer_phone"`
+	CustomerTaxID string        `json:"customer_tax_id"`
+	ValidUntil    *time.Time    `json:"valid_until"`
+	Note          string        `json:"note"`
+	Lines         []LineRequest `json:"lines"`
+}
+
+type UpdateStatusRequest struct {
+	Status string `json:"status"`
+}
+
+type LineResponse struct {
+	ProductID uint    `json:"product_id"`
+	SKU       string  `json:"sku"`
+	Name      string  `json:"name"`
+	Quantity  int     `json:"quantity"`
+	UnitPrice float64 `json:"unit_price"`
+	LineTotal float64 `json:"line_total"`
+}
+
+type QuotationResponse struct {
+	ID            uint           `json:"id"`
+	Number        string         `json:"number"`
+	CustomerName  string         `json:"customer_name"`
+	CustomerPhone string         `json:"customer_phone"`
+	CustomerTaxID string         `json:"customer_tax_id"`
+	Status        string         `json:"status"`
+	ValidUntil    time.Time      `json:"valid_until"`
+	Total         float64        `json:"total"`
+	Note          string         `json:"note"`
+	CreatedAt     time.Time      `json:"created_at"`
+	Lines         []LineResponse `json:"lines"`
+}
+
+type QuotationListResponse struct {
+	Quotations []*QuotationResponse `json:"quotations"`
+	Total      int64                `json:"total"`
+}
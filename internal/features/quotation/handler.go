@@ -0,0 +1,286 @@
+package quotation
+
+import (
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/internal/infra/jwtx"
+	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/query"
+	"ans-spareparts-api/pkg/response"
+	"bytes"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+func toResponse(it *Item) *QuotationResponse {
+	out := &QuotationResponse{
+		ID:            it.ID,
+		Number:        it.Number,
+		CustomerName:  it.CustomerName,
+		CustomerPhone: it.CustomerPhone,
+		CustomerTaxID: it.CustomerTaxID,
+		Status:        it.Status,
+		ValidUntil:    it.ValidUntil,
+		Total:         it.Total,
+		Note:          it.Note,
+		CreatedAt:     it.CreatedAt,
+		Lines:         make([]LineResponse, len(it.Lines)),
+	}
+	for i, l := range it.Lines {
+		out.Lines[i] = LineResponse(l)
+	}
+	return out
+}
+
+// CreateQuotation godoc
+// @Summary Create a quotation
+// @Description Create a quotation with prices resolved at quote time
+// @Tags quotations
+// @Accept json
+// @Produce json
+// @Param quotation body CreateQuotationRequest true "Quotation creation request"
+// @Success 201 {object} QuotationResponse
+// @Failure 400 {object} response.ErrorBody
+// @Failure 404 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /quotations [post]
+func (h *Handler) CreateQuotation(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+	userClaims := c.Locals("user").(*jwtx.Claims)
+
+	var req CreateQuotationRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Warn("handler.quotation.create.invalid_body", zap.Error(err))
+		return response.Error(
+			c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid request body",
+		)
+	}
+
+	lines := make([]LineInput, len(req.Lines))
+	for i, l := range req.Lines {
+		lines[i] = LineInput(l)
+	}
+
+	q, err := h.service.CreateQuotation(ctx, CreateInput{
+		CustomerName:  req.CustomerName,
+		CustomerPhone: req.CustomerPhone,
+		CustomerTaxID: req.CustomerTaxID,
+		ValidUntil:    req.ValidUntil,
+		Note:          req.Note,
+		CreatedBy:     userClaims.UserID,
+		Lines:         lines,
+	})
+	if err != nil {
+		if errors.Is(err, apperror.ErrInvalidInput) {
+			return response.Error(
+				c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid input",
+			)
+		}
+		if errors.Is(err, apperror.ErrNotFound) {
+			return response.Error(
+				c, fiber.StatusNotFound, "NOT_FOUND", "product not found",
+			)
+		}
+		return response.Error(
+			c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "internal server occured",
+		)
+	}
+
+	return response.Created(c, toResponse(q))
+}
+
+// GetQuotation godoc
+// @Summary Get quotation by ID
+// @Description Get quotation with its lines
+// @Tags quotations
+// @Produce json
+// @Param id path int true "Quotation ID"
+// @Success 200 {object} QuotationResponse
+// @Failure 400 {object} response.ErrorBody
+// @Failure 404 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /quotations/{id} [get]
+func (h *Handler) GetQuotation(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Warn("handler.quotation.get.invalid_id", zap.Error(err))
+		return response.Error(
+			c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid quotation id",
+		)
+	}
+
+	q, err := h.service.GetQuotation(ctx, uint(id))
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return response.Error(
+				c, fiber.StatusNotFound, "NOT_FOUND", "quotation not found",
+			)
+		}
+		return response.Error(
+			c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "internal server occured",
+		)
+	}
+
+	return response.OK(c, toResponse(q))
+}
+
+// RenderQuotation godoc
+// @Summary Render quotation
+// @Description Render quotation as a printable HTML page
+// @Tags quotations
+// @Produce html
+// @Param id path int true "Quotation ID"
+// @Success 200 {string} string "HTML document"
+// @Failure 400 {object} response.ErrorBody
+// @Failure 404 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /quotations/{id}/render [get]
+func (h *Handler) RenderQuotation(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Warn("handler.quotation.render.invalid_id", zap.Error(err))
+		return response.Error(
+			c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid quotation id",
+		)
+	}
+
+	q, err := h.service.GetQuotation(ctx, uint(id))
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return response.Error(
+				c, fiber.StatusNotFound, "NOT_FOUND", "quotation not found",
+			)
+		}
+		return response.Error(
+			c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "internal server occured",
+		)
+	}
+
+	var buf bytes.Buffer
+	if err := Render(&buf, q); err != nil {
+		log.Error("handler.quotation.render.template_fail", zap.Error(err))
+		return response.Error(
+			c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "internal server occured",
+		)
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.Status(fiber.StatusOK).Send(buf.Bytes())
+}
+
+// List godoc
+// @Summary List quotations
+// @Description List quotations with optional status filter
+// @Tags quotations
+// @Produce json
+// @Param status query string false "draft|sent|accepted|expired (status[in]=draft,sent for several)"
+// @Param sort query string false "Comma separated, '-' prefix for DESC: number,customer_name,total,valid_until,created_at"
+// @Param limit query int false "Limit" default(10)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} QuotationListResponse
+// @Failure 400 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /quotations [get]
+func (h *Handler) List(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+
+	p, err := query.FromCtx(c, listSchema)
+	if err != nil {
+		log.Warn("handler.quotation.list.invalid_input", zap.Error(err))
+		return response.Error(
+			c, fiber.StatusBadRequest, "BAD_REQUEST", err.Error(),
+		)
+	}
+
+	out, err := h.service.List(ctx, ListQuery{
+		Limit:   p.Limit,
+		Offset:  p.Offset,
+		Sort:    p.Sort,
+		Filters: p.Filters,
+	})
+	if err != nil {
+		return response.Error(
+			c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "internal server occured",
+		)
+	}
+
+	res := make([]*QuotationResponse, len(out.Items))
+	for i, it := range out.Items {
+		res[i] = toResponse(it)
+	}
+	return response.OK(c, QuotationListResponse{Quotations: res, Total: out.Total})
+}
+
+// UpdateStatus godoc
+// @Summary Update quotation status
+// @Description Move a quotation to sent or accepted
+// @Tags quotations
+// @Accept json
+// @Produce json
+// @Param id path int true "Quotation ID"
+// @Param status body UpdateStatusRequest true "New status"
+// @Success 200 {object} QuotationResponse
+// @Failure 400 {object} response.ErrorBody
+// @Failure 404 {object} response.ErrorBody
+// @Failure 409 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /quotations/{id}/status [patch]
+func (h *Handler) UpdateStatus(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Warn("handler.quotation.update_status.invalid_id", zap.Error(err))
+		return response.Error(
+			c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid quotation id",
+		)
+	}
+
+	var req UpdateStatusRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Warn("handler.quotation.update_status.invalid_body", zap.Error(err))
+		return response.Error(
+			c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid request body",
+		)
+	}
+
+	q, err := h.service.UpdateStatus(ctx, uint(id), req.Status)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return response.Error(
+				c, fiber.StatusNotFound, "NOT_FOUND", "quotation not found",
+			)
+		}
+		if errors.Is(err, apperror.ErrInvalidState) {
+			return response.Error(
+				c, fiber.StatusConflict, "CONFLICT", "status change not allowed",
+			)
+		}
+		return response.Error(
+			c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "internal server occured",
+		)
+	}
+
+	return response.OK(c, toResponse(q))
+}
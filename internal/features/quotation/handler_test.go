@@ -0,0 +1,192 @@
+package quotation_test
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/features/quotation"
+	"ans-spareparts-api/internal/infra/jwtx"
+	mocks "ans-spareparts-api/internal/mock"
+	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/response"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type HandlerTestSuite struct {
+	App         *fiber.App
+	MockService *mocks.QuotationService
+	Handler     *quotation.Handler
+}
+
+func NewHandlerTestSuite() *HandlerTestSuite {
+	return &HandlerTestSuite{}
+}
+
+func (ts *HandlerTestSuite) SetUpHandlerTestSuite(t *testing.T) {
+	ts.MockService = mocks.NewQuotationService()
+	ts.Handler = quotation.NewHandler(ts.MockService)
+	ts.App = fiber.New(fiber.Config{
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			return response.Error(c, fiber.StatusBadRequest, "INVALID_REQUEST", "invalid request body: "+err.Error())
+		},
+	})
+
+	t.Cleanup(func() {
+		ts.MockService.AssertExpectations(t)
+	})
+}
+
+var mockQuotationItem = &quotation.Item{
+	ID:           1,
+	Number:       "QT-00000-2569-000001",
+	CustomerName: "อู่ช่างสมชาย",
+	Status:       domain.QuotationStatusDraft,
+	ValidUntil:   time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+	Total:        1250,
+	Lines: []quotation.LineItem{
+		{ProductID: 1, SKU: "BRK-001", Name: "ผ้าเบรกหน้า", Quantity: 1, UnitPrice: 1250, LineTotal: 1250},
+	},
+}
+
+func TestQuotationHandler_CreateQuotation(t *testing.T) {
+	mockRequest := quotation.CreateQuotationRequest{
+		CustomerName: "อู่ช่างสมชาย",
+		Lines:        []quotation.LineRequest{{ProductID: 1, Quantity: 1}},
+	}
+
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		setup          func(*HandlerTestSuite)
+		expectedStatus int
+	}{
+		{
+			name:        "Success_Created",
+			requestBody: mockRequest,
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("CreateQuotation", mock.Anything, mock.MatchedBy(func(in quotation.CreateInput) bool {
+					return in.CreatedBy == 7 && len(in.Lines) == 1
+				})).Return(mockQuotationItem, nil).Once()
+			},
+			expectedStatus: fiber.StatusCreated,
+		},
+		{
+			name:           "Error_BadRequest_Invalid_Body",
+			requestBody:    `{"customer_name":`,
+			setup:          func(hts *HandlerTestSuite) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:        "Error_Product_NotFound",
+			requestBody: mockRequest,
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("CreateQuotation", mock.Anything, mock.Anything).Return(nil, apperror.ErrNotFound).Once()
+			},
+			expectedStatus: fiber.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewHandlerTestSuite()
+			ts.SetUpHandlerTestSuite(t)
+
+			ts.App.Post("/quotations", func(c *fiber.Ctx) error {
+				c.Locals("user", &jwtx.Claims{UserID: 7, Username: "Test", Role: "cashier"})
+				return c.Next()
+			}, ts.Handler.CreateQuotation)
+			test.setup(ts)
+
+			var body []byte
+			if value, ok := test.requestBody.(string); ok {
+				body = []byte(value)
+			} else {
+				body, _ = json.Marshal(test.requestBody)
+			}
+
+			req := httptest.NewRequest(fiber.MethodPost, "/quotations", bytes.NewBuffer(body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+			res, _ := ts.App.Test(req, -1)
+
+			assert.Equal(t, test.expectedStatus, res.StatusCode)
+		})
+	}
+}
+
+func TestQuotationHandler_RenderQuotation(t *testing.T) {
+	ts := NewHandlerTestSuite()
+	ts.SetUpHandlerTestSuite(t)
+
+	ts.App.Get("/quotations/:id/render", ts.Handler.RenderQuotation)
+	ts.MockService.On("GetQuotation", mock.Anything, uint(1)).Return(mockQuotationItem, nil).Once()
+
+	req := httptest.NewRequest(fiber.MethodGet, "/quotations/1/render", nil)
+	res, _ := ts.App.Test(req, -1)
+
+	assert.Equal(t, fiber.StatusOK, res.StatusCode)
+	assert.Contains(t, res.Header.Get(fiber.HeaderContentType), "text/html")
+
+	body, _ := io.ReadAll(res.Body)
+	assert.Contains(t, string(body), "QT-00000-2569-000001")
+	assert.Contains(t, string(body), "ผ้าเบรกหน้า")
+	assert.Contains(t, string(body), "1,250.00")
+}
+
+func TestQuotationHandler_UpdateStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		setup          func(*HandlerTestSuite)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name: "Success_Sent",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("UpdateStatus", mock.Anything, uint(1), domain.QuotationStatusSent).Return(mockQuotationItem, nil).Once()
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name: "Error_Conflict_InvalidState",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("UpdateStatus", mock.Anything, uint(1), domain.QuotationStatusSent).Return(nil, apperror.ErrInvalidState).Once()
+			},
+			expectedStatus: fiber.StatusConflict,
+			expectedBody: fiber.Map{
+				"code":    "CONFLICT",
+				"message": "status change not allowed",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewHandlerTestSuite()
+			ts.SetUpHandlerTestSuite(t)
+
+			ts.App.Patch("/quotations/:id/status", ts.Handler.UpdateStatus)
+			test.setup(ts)
+
+			body, _ := json.Marshal(quotation.UpdateStatusRequest{Status: domain.QuotationStatusSent})
+			req := httptest.NewRequest(fiber.MethodPatch, "/quotations/1/status", bytes.NewBuffer(body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+			res, _ := ts.App.Test(req, -1)
+
+			assert.Equal(t, test.expectedStatus, res.StatusCode)
+			if test.expectedBody != nil {
+				resBody, _ := io.ReadAll(res.Body)
+				expectedBody, _ := json.Marshal(test.expectedBody)
+				assert.JSONEq(t, string(expectedBody), string(resBody))
+			}
+		})
+	}
+}
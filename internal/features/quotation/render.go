@@ -0,0 +1,71 @@
+package quotation
+
+import (
+	"html/template"
+	"io"
+	"strconv"
+	"time"
+)
+
+// หน้าใบเสนอราคาแบบ HTML สำหรับแสดง/สั่งพิมพ์จาก browser
+var quotationTmpl = template.Must(template.New("quotation").Funcs(template.FuncMap{
+	"money": func(v float64) string { return formatMoney(v) },
+	"date":  func(t time.Time) string { return t.Format("02/01/2006") },
+	"inc":   func(i int) int { return i + 1 },
+}).Parse(`<!DOCTYPE html>
+<html lang="th">
+<head>
+<meta charset="utf-8">
+<title>ใบเสนอราคา {{.Number}}</title>
+<style>
+body { font-family: "Sarabun", "Tahoma", sans-serif; margin: 24px; }
+table { width: 100%; border-collapse: collapse; }
+th, td { border: 1px solid #999; padding: 4px 8px; }
+td.num { text-align: right; }
+</style>
+</head>
+<body>
+<h1>ใบเสนอราคา / Quotation</h1>
+<p>เลขที่: {{.Number}}<br>
+วันที่: {{date .CreatedAt}}<br>
+ยืนราคาถึง: {{date .ValidUntil}}</p>
+<p>ลูกค้า: {{.CustomerName}}{{if .CustomerPhone}}<br>โทร: {{.CustomerPhone}}{{end}}{{if .CustomerTaxID}}<br>เลขประจำตัวผู้เสียภาษี: {{.CustomerTaxID}}{{end}}</p>
+<table>
+<thead>
+<tr><th>#</th><th>รหัสสินค้า</th><th>รายการ</th><th>จำนวน</th><th>ราคา/หน่วย</th><th>จำนวนเงิน</th></tr>
+</thead>
+<tbody>
+{{range $i, $l := .Lines}}<tr><td>{{inc $i}}</td><td>{{$l.SKU}}</td><td>{{$l.Name}}</td><td class="num">{{$l.Quantity}}</td><td class="num">{{money $l.UnitPrice}}</td><td class="num">{{money $l.LineTotal}}</td></tr>
+{{end}}</tbody>
+<tfoot>
+<tr><td colspan="5" class="num">รวมทั้งสิ้น</td><td class="num">{{money .Total}}</td></tr>
+</tfoot>
+</table>
+{{if .Note}}<p>หมายเหตุ: {{.Note}}</p>{{end}}
+</body>
+</html>
+`))
+
+// formatMoney แสดงเงินแบบมีคอมมาคั่นหลักพัน เช่น 12,345.50
+func formatMoney(v float64) string {
+	s := []byte(strconv.FormatFloat(v, 'f', 2, 64))
+	dot := len(s) - 3
+	out := make([]byte, 0, len(s)+len(s)/3)
+	start := 0
+	if s[0] == '-' {
+		out = append(out, '-')
+		start = 1
+	}
+	for i := start; i < dot; i++ {
+		if i > start && (dot-i)%3 == 0 {
+			out = append(out, ',')
+		}
+		out = append(out, s[i])
+	}
+	return string(append(out, s[dot:]...))
+}
+
+// Render เขียนใบเสนอราคาเป็น HTML ลงใน w
+func Render(w io.Writer, it *Item) error {
+	return quotationTmpl.Execute(w, it)
+}
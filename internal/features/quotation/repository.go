@@ -0,0 +1,146 @@
+package quotation
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/query"
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	GetByID(ctx context.Context, id uint) (*domain.Quotation, error)
+	List(ctx context.Context, q ListQuery) ([]*domain.Quotation, int64, error)
+
+	// Create บันทึกหัวใบเสนอราคาพร้อมรายการสินค้าใน transaction เดียวกัน
+	// number ถูกเรียกใน transaction นั้นเพื่อออกเลขที่เอกสาร (rollback แล้วเลขไม่ถูกใช้)
+	Create(ctx context.Context, q *domain.Quotation, number NumberFunc) error
+	// UpdateStatus เปลี่ยนสถานะเฉพาะเมื่อสถานะปัจจุบันยังเป็น from (กันชนกับ sweeper/คำขอพร้อมกัน)
+	UpdateStatus(ctx context.Context, id uint, from, to string) error
+	// ExpireBefore เปลี่ยนสถานะใบเสนอราคา draft/sent ที่เลยวันหมดอายุเป็น expired
+	ExpireBefore(ctx context.Context, t time.Time) (int64, error)
+}
+
+// NumberFunc ออกเลขที่เอกสารภายใน transaction ที่บันทึกใบเสนอราคา
+type NumberFunc func(tx *gorm.DB) (string, error)
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) GetByID(ctx context.Context, id uint) (*domain.Quotation, error) {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	var q domain.Quotation
+	if err := r.db.WithContext(ctx).Preload("Lines").First(&q, id).Error; err != nil {
+		m := apperror.MapDBError("repo.quotation.getByID", err)
+		log.Debug("repo.quotation.getByID.db_fail", zap.Error(err), zap.Duration("duration", time.Since(start)))
+		return nil, m
+	}
+
+	log.Debug("repo.quotation.getByID.ok", zap.Uint("id", id), zap.Duration("duration", time.Since(start)))
+	return &q, nil
+}
+
+func (r *repository) List(ctx context.Context, q ListQuery) ([]*domain.Quotation, int64, error) {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	tx := r.db.WithContext(ctx).Model(&domain.Quotation{})
+	tx = query.Where(tx, q.Filters)
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		m := apperror.MapDBError("repo.quotation.list.count", err)
+		log.Debug("repo.quotation.list.count_fail", zap.Error(err))
+		return nil, 0, m
+	}
+
+	tx = query.Order(tx, listSchema.SortOrDefault(q.Sort))
+	if q.Limit > 0 {
+		tx = tx.Limit(q.Limit)
+	}
+	if q.Offset > 0 {
+		tx = tx.Offset(q.Offset)
+	}
+
+	var rows []*domain.Quotation
+	if err := tx.Preload("Lines").Find(&rows).Error; err != nil {
+		m := apperror.MapDBError("repo.quotation.list.find", err)
+		log.Debug("repo.quotation.list.find_fail", zap.Error(err))
+		return nil, 0, m
+	}
+
+	log.Debug("repo.quotation.list.ok", zap.Int("n", len(rows)), zap.Int64("total", total), zap.Duration("duration", time.Since(start)))
+	return rows, total, nil
+}
+
+func (r *repository) Create(ctx context.Context, q *domain.Quotation, number NumberFunc) error {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	// GORM จะ insert Lines ให้อัตโนมัติ (association) ภายใน transaction เดียวกัน
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		code, err := number(tx)
+		if err != nil {
+			return err
+		}
+		q.Number = code
+		return tx.Create(q).Error
+	})
+	if err != nil {
+		m := apperror.MapDBError("repo.quotation.create", err)
+		log.Debug("repo.quotation.create.fail", zap.Error(err), zap.Duration("duration", time.Since(start)))
+		return m
+	}
+
+	log.Info("repo.quotation.create.ok", zap.Uint("id", q.ID), zap.Duration("duration", time.Since(start)))
+	return nil
+}
+
+func (r *repository) UpdateStatus(ctx context.Context, id uint, from, to string) error {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	res := r.db.WithContext(ctx).Model(&domain.Quotation{}).
+		Where("id = ? AND status = ?", id, from).
+		Update("status", to)
+	if res.Error != nil {
+		m := apperror.MapDBError("repo.quotation.updateStatus", res.Error)
+		log.Debug("repo.quotation.updateStatus.fail", zap.Error(res.Error))
+		return m
+	}
+	// สถานะถูกเปลี่ยนไปก่อนแล้ว (หรือไม่มีแถว ซึ่ง service ตรวจไว้ก่อนหน้าแล้ว)
+	if res.RowsAffected == 0 {
+		return apperror.ErrInvalidState
+	}
+
+	log.Debug("repo.quotation.updateStatus.ok", zap.Uint("id", id), zap.String("from", from), zap.String("to", to), zap.Duration("duration", time.Since(start)))
+	return nil
+}
+
+func (r *repository) ExpireBefore(ctx context.Context, t time.Time) (int64, error) {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	res := r.db.WithContext(ctx).Model(&domain.Quotation{}).
+		Where("status IN ? AND valid_until < ?", []string{domain.QuotationStatusDraft, domain.QuotationStatusSent}, t).
+		Update("status", domain.QuotationStatusExpired)
+	if res.Error != nil {
+		m := apperror.MapDBError("repo.quotation.expireBefore", res.Error)
+		log.Debug("repo.quotation.expireBefore.fail", zap.Error(res.Error))
+		return 0, m
+	}
+
+	log.Debug("repo.quotation.expireBefore.ok", zap.Int64("n", res.RowsAffected), zap.Duration("duration", time.Since(start)))
+	return res.RowsAffected, nil
+}
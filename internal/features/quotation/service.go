@@ -0,0 +1,247 @@
+package quotation
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/features/document"
+	"ans-spareparts-api/internal/features/product"
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/utils"
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type Service interface {
+	CreateQuotation(ctx context.Context, in CreateInput) (*Item, error)
+	GetQuotation(ctx context.Context, id uint) (*Item, error)
+	List(ctx context.Context, q ListQuery) (*ListOutput, error)
+	UpdateStatus(ctx context.Context, id uint, status string) (*Item, error)
+	// ExpireStale ใช้โดย sweeper เพื่อปิดใบเสนอราคาที่หมดอายุ
+	ExpireStale(ctx context.Context) (int64, error)
+}
+
+type service struct {
+	quotationRepo Repository
+	productRepo   product.Repository
+	documentSvc   document.Service
+	validity      time.Duration
+}
+
+// ค่าเริ่มต้นของอายุใบเสนอราคา เมื่อไม่ได้ระบุ valid_until
+const defaultValidity = 30 * 24 * time.Hour
+
+func NewService(
+	quotationRepo Repository,
+	productRepo product.Repository,
+	documentSvc document.Service,
+	validity time.Duration,
+) Service {
+	if validity <= 0 {
+		validity = defaultValidity
+	}
+	return &service{
+		quotationRepo: quotationRepo,
+		productRepo:   productRepo,
+		documentSvc:   documentSvc,
+		validity:      validity,
+	}
+}
+
+// สถานะที่เปลี่ยนไปได้ (from -> to)
+var transitions = map[string]map[string]bool{
+	domain.QuotationStatusDraft: {
+		domain.QuotationStatusSent:     true,
+		domain.QuotationStatusAccepted: true,
+	},
+	domain.QuotationStatusSent: {
+		domain.QuotationStatusAccepted: true,
+	},
+}
+
+// --- Validators ---
+func sanitizeCreate(in CreateInput) error {
+	if strings.TrimSpace(in.CustomerName) == "" || len(in.Lines) == 0 {
+		return apperror.ErrInvalidInput
+	}
+	for _, l := range in.Lines {
+		if l.ProductID == 0 || l.Quantity <= 0 {
+			return apperror.ErrInvalidInput
+		}
+	}
+	return nil
+}
+
+// --- Mappers ---
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func toItem(q *domain.Quotation) *Item {
+	out := &Item{
+		ID:            q.ID,
+		Number:        q.Number,
+		CustomerName:  q.CustomerName,
+		CustomerPhone: q.CustomerPhone,
+		CustomerTaxID: q.CustomerTaxID,
+		Status:        q.Status,
+		ValidUntil:    q.ValidUntil,
+		Total:         q.Total,
+		Note:          q.Note,
+		CreatedBy:     q.CreatedBy,
+		CreatedAt:     q.CreatedAt,
+		Lines:         make([]LineItem, len(q.Lines)),
+	}
+	for i, l := range q.Lines {
+		out.Lines[i] = LineItem{
+			ProductID: l.ProductID,
+			SKU:       l.SKU,
+			Name:      l.Name,
+			Quantity:  l.Quantity,
+			UnitPrice: l.UnitPrice,
+			LineTotal: l.LineTotal,
+		}
+	}
+	return out
+}
+
+// CreateQuotation ดึงราคาสินค้าปัจจุบันมาล็อกไว้ในใบเสนอราคา แล้วออกเลขที่เอกสาร
+func (s *service) CreateQuotation(ctx context.Context, in CreateInput) (*Item, error) {
+	log := ctxlog.From(ctx)
+
+	if err := sanitizeCreate(in); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	validUntil := now.Add(s.validity)
+	if in.ValidUntil != nil {
+		if !in.ValidUntil.After(now) {
+			return nil, apperror.ErrInvalidInput
+		}
+		validUntil = *in.ValidUntil
+	}
+
+	// resolve ราคา ณ เวลาที่เสนอ
+	lines := make([]domain.QuotationLine, 0, len(in.Lines))
+	var total float64
+	for _, l := range in.Lines {
+		p, err := s.productRepo.GetByID(ctx, l.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if !p.IsActive {
+			return nil, apperror.ErrInvalidInput
+		}
+
+		lineTotal := roundMoney(p.Price * float64(l.Quantity))
+		lines = append(lines, domain.QuotationLine{
+			ProductID: p.ID,
+			SKU:       p.SKU,
+			Name:      p.Name,
+			Quantity:  l.Quantity,
+			UnitPrice: p.Price,
+			LineTotal: lineTotal,
+		})
+		total += lineTotal
+	}
+
+	q := &domain.Quotation{
+		CustomerName:  utils.SanitizeString(in.CustomerName),
+		CustomerPhone: utils.SanitizeString(in.CustomerPhone),
+		CustomerTaxID: utils.SanitizeString(in.CustomerTaxID),
+		Status:        domain.QuotationStatusDraft,
+		ValidUntil:    validUntil,
+		Total:         roundMoney(total),
+		Note:          utils.SanitizeString(in.Note),
+		CreatedBy:     in.CreatedBy,
+		Lines:         lines,
+	}
+	// ออกเลขใน transaction เดียวกับการบันทึก เพื่อให้เลขเรียงต่อเนื่องไม่ข้าม
+	number := func(tx *gorm.DB) (string, error) {
+		n, err := s.documentSvc.NextNumber(ctx, tx, document.NextNumberInput{
+			DocType:  document.DocTypeQuotation,
+			IssuedAt: now,
+		})
+		if err != nil {
+			return "", err
+		}
+		return n.Code, nil
+	}
+	if err := s.quotationRepo.Create(ctx, q, number); err != nil {
+		return nil, err
+	}
+
+	log.Info("quotation.created", zap.Uint("id", q.ID), zap.String("number", q.Number))
+	return toItem(q), nil
+}
+
+func (s *service) GetQuotation(ctx context.Context, id uint) (*Item, error) {
+	q, err := s.quotationRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toItem(q), nil
+}
+
+func (s *service) List(ctx context.Context, q ListQuery) (*ListOutput, error) {
+	limit, offset := utils.NormalizePagination(q.Limit, q.Offset)
+	rows, total, err := s.quotationRepo.List(ctx, ListQuery{
+		Limit:   limit,
+		Offset:  offset,
+		Sort:    q.Sort,
+		Filters: q.Filters,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*Item, 0, len(rows))
+	for _, r := range rows {
+		items = append(items, toItem(r))
+	}
+	return &ListOutput{Items: items, Total: total}, nil
+}
+
+// UpdateStatus เปลี่ยนสถานะตาม transitions ที่อนุญาต (expired ทำได้โดย sweeper เท่านั้น)
+func (s *service) UpdateStatus(ctx context.Context, id uint, status string) (*Item, error) {
+	log := ctxlog.From(ctx)
+
+	q, err := s.quotationRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !transitions[q.Status][status] {
+		return nil, apperror.ErrInvalidState
+	}
+	// ใบเสนอราคาที่เลยกำหนดแล้วแต่ sweeper ยังไม่ทันปิด ห้าม accept
+	if status == domain.QuotationStatusAccepted && time.Now().After(q.ValidUntil) {
+		return nil, apperror.ErrInvalidState
+	}
+
+	if err := s.quotationRepo.UpdateStatus(ctx, id, q.Status, status); err != nil {
+		return nil, err
+	}
+	q.Status = status
+
+	log.Info("quotation.status.updated", zap.Uint("id", id), zap.String("status", status))
+	return toItem(q), nil
+}
+
+func (s *service) ExpireStale(ctx context.Context) (int64, error) {
+	log := ctxlog.From(ctx)
+
+	n, err := s.quotationRepo.ExpireBefore(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	if n > 0 {
+		log.Info("quotation.expired", zap.Int64("count", n))
+	}
+	return n, nil
+}
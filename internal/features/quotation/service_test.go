@@ -0,0 +1,285 @@
+package quotation_test
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/features/document"
+	"ans-spareparts-api/internal/features/quotation"
+	mocks "ans-spareparts-api/internal/mock"
+	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/testutil/fixtures"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type TestSuite struct {
+	Service           quotation.Service
+	MockQuotationRepo *mocks.QuotationRepository
+	MockProductRepo   *mocks.ProductRepository
+	MockDocumentSvc   *mocks.DocumentService
+	Ctx               context.Context
+}
+
+func NewTestSuite() *TestSuite {
+	return &TestSuite{}
+}
+
+func (ts *TestSuite) SetupTest(t *testing.T) {
+	ts.MockQuotationRepo = mocks.NewMockQuotationRepository()
+	ts.MockProductRepo = mocks.NewMockProductRepository()
+	ts.MockDocumentSvc = mocks.NewDocumentService()
+	ts.Ctx = context.Background()
+
+	ts.Service = quotation.NewService(ts.MockQuotationRepo, ts.MockProductRepo, ts.MockDocumentSvc, 7*24*time.Hour)
+
+	t.Cleanup(func() {
+		ts.MockQuotationRepo.AssertExpectations(t)
+		ts.MockProductRepo.AssertExpectations(t)
+		ts.MockDocumentSvc.AssertExpectations(t)
+	})
+}
+
+func TestQuotationService_CreateQuotation(t *testing.T) {
+	validProduct := fixtures.ValidProduct()
+	validProduct.Price = 125.50
+
+	inactiveProduct := fixtures.ValidProduct()
+	inactiveProduct.ID = 2
+	inactiveProduct.IsActive = false
+
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		input     quotation.CreateInput
+		setup     func(ts *TestSuite)
+		assertErr func(*testing.T, error)
+		validate  func(*testing.T, *quotation.Item)
+	}{
+		{
+			name: "Success_Prices_Resolved_At_Quote_Time",
+			input: quotation.CreateInput{
+				CustomerName: "  Somchai   Garage ",
+				CreatedBy:    9,
+				Lines:        []quotation.LineInput{{ProductID: 1, Quantity: 3}},
+			},
+			setup: func(ts *TestSuite) {
+				ts.MockProductRepo.On("GetByID", ts.Ctx, uint(1)).Return(validProduct, nil).Once()
+				ts.MockDocumentSvc.On("NextNumber", ts.Ctx, mock.Anything, mock.MatchedBy(func(in document.NextNumberInput) bool {
+					return in.DocType == document.DocTypeQuotation
+				})).Return(&document.Number{Code: "QT-00000-2569-000001"}, nil).Once()
+				ts.MockQuotationRepo.On("Create", ts.Ctx, mock.MatchedBy(func(q *domain.Quotation) bool {
+					q.ID = 1
+					return q.Status == domain.QuotationStatusDraft && len(q.Lines) == 1
+				})).Return(nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, it *quotation.Item) {
+				assert.Equal(t, "QT-00000-2569-000001", it.Number)
+				assert.Equal(t, "Somchai Garage", it.CustomerName)
+				assert.Equal(t, 376.50, it.Total)
+				assert.Equal(t, 125.50, it.Lines[0].UnitPrice)
+				assert.Equal(t, validProduct.SKU, it.Lines[0].SKU)
+				assert.WithinDuration(t, time.Now().Add(7*24*time.Hour), it.ValidUntil, time.Minute)
+			},
+		},
+		{
+			name: "Error_Number_Not_Issued",
+			input: quotation.CreateInput{
+				CustomerName: "Somchai",
+				Lines:        []quotation.LineInput{{ProductID: 1, Quantity: 1}},
+			},
+			setup: func(ts *TestSuite) {
+				ts.MockProductRepo.On("GetByID", ts.Ctx, uint(1)).Return(validProduct, nil).Once()
+				ts.MockDocumentSvc.On("NextNumber", ts.Ctx, mock.Anything, mock.Anything).Return(nil, apperror.ErrInternalServer).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInternalServer)
+			},
+			validate: func(t *testing.T, it *quotation.Item) {
+				assert.Nil(t, it)
+			},
+		},
+		{
+			name:  "Error_No_Lines",
+			input: quotation.CreateInput{CustomerName: "Somchai"},
+			setup: func(ts *TestSuite) {},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidInput)
+			},
+			validate: func(t *testing.T, it *quotation.Item) {
+				assert.Nil(t, it)
+			},
+		},
+		{
+			name: "Error_ValidUntil_In_Past",
+			input: quotation.CreateInput{
+				CustomerName: "Somchai",
+				ValidUntil:   &past,
+				Lines:        []quotation.LineInput{{ProductID: 1, Quantity: 1}},
+			},
+			setup: func(ts *TestSuite) {},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidInput)
+			},
+			validate: func(t *testing.T, it *quotation.Item) {
+				assert.Nil(t, it)
+			},
+		},
+		{
+			name: "Error_Inactive_Product",
+			input: quotation.CreateInput{
+				CustomerName: "Somchai",
+				Lines:        []quotation.LineInput{{ProductID: 2, Quantity: 1}},
+			},
+			setup: func(ts *TestSuite) {
+				ts.MockProductRepo.On("GetByID", ts.Ctx, uint(2)).Return(inactiveProduct, nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidInput)
+			},
+			validate: func(t *testing.T, it *quotation.Item) {
+				assert.Nil(t, it)
+			},
+		},
+		{
+			name: "Error_Product_NotFound",
+			input: quotation.CreateInput{
+				CustomerName: "Somchai",
+				Lines:        []quotation.LineInput{{ProductID: 3, Quantity: 1}},
+			},
+			setup: func(ts *TestSuite) {
+				ts.MockProductRepo.On("GetByID", ts.Ctx, uint(3)).Return(nil, apperror.ErrNotFound).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrNotFound)
+			},
+			validate: func(t *testing.T, it *quotation.Item) {
+				assert.Nil(t, it)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewTestSuite()
+			ts.SetupTest(t)
+
+			test.setup(ts)
+
+			it, err := ts.Service.CreateQuotation(ts.Ctx, test.input)
+
+			test.assertErr(t, err)
+			test.validate(t, it)
+		})
+	}
+}
+
+func TestQuotationService_UpdateStatus(t *testing.T) {
+	quote := func(status string, validUntil time.Time) *domain.Quotation {
+		return &domain.Quotation{ID: 1, Number: "QT-00000-2569-000001", Status: status, ValidUntil: validUntil}
+	}
+	future := time.Now().Add(24 * time.Hour)
+	past := time.Now().Add(-24 * time.Hour)
+
+	tests := []struct {
+		name      string
+		status    string
+		setup     func(ts *TestSuite)
+		assertErr func(*testing.T, error)
+	}{
+		{
+			name:   "Success_Draft_To_Sent",
+			status: domain.QuotationStatusSent,
+			setup: func(ts *TestSuite) {
+				ts.MockQuotationRepo.On("GetByID", ts.Ctx, uint(1)).Return(quote(domain.QuotationStatusDraft, future), nil).Once()
+				ts.MockQuotationRepo.On("UpdateStatus", ts.Ctx, uint(1), domain.QuotationStatusDraft, domain.QuotationStatusSent).Return(nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:   "Success_Sent_To_Accepted",
+			status: domain.QuotationStatusAccepted,
+			setup: func(ts *TestSuite) {
+				ts.MockQuotationRepo.On("GetByID", ts.Ctx, uint(1)).Return(quote(domain.QuotationStatusSent, future), nil).Once()
+				ts.MockQuotationRepo.On("UpdateStatus", ts.Ctx, uint(1), domain.QuotationStatusSent, domain.QuotationStatusAccepted).Return(nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:   "Error_Status_Changed_Concurrently",
+			status: domain.QuotationStatusAccepted,
+			setup: func(ts *TestSuite) {
+				ts.MockQuotationRepo.On("GetByID", ts.Ctx, uint(1)).Return(quote(domain.QuotationStatusSent, future), nil).Once()
+				ts.MockQuotationRepo.On("UpdateStatus", ts.Ctx, uint(1), domain.QuotationStatusSent, domain.QuotationStatusAccepted).Return(apperror.ErrInvalidState).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidState)
+			},
+		},
+		{
+			name:   "Error_Accept_After_ValidUntil",
+			status: domain.QuotationStatusAccepted,
+			setup: func(ts *TestSuite) {
+				ts.MockQuotationRepo.On("GetByID", ts.Ctx, uint(1)).Return(quote(domain.QuotationStatusSent, past), nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidState)
+			},
+		},
+		{
+			name:   "Error_Manual_Expire_Not_Allowed",
+			status: domain.QuotationStatusExpired,
+			setup: func(ts *TestSuite) {
+				ts.MockQuotationRepo.On("GetByID", ts.Ctx, uint(1)).Return(quote(domain.QuotationStatusDraft, future), nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidState)
+			},
+		},
+		{
+			name:   "Error_Accepted_Is_Final",
+			status: domain.QuotationStatusSent,
+			setup: func(ts *TestSuite) {
+				ts.MockQuotationRepo.On("GetByID", ts.Ctx, uint(1)).Return(quote(domain.QuotationStatusAccepted, future), nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidState)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewTestSuite()
+			ts.SetupTest(t)
+
+			test.setup(ts)
+
+			_, err := ts.Service.UpdateStatus(ts.Ctx, 1, test.status)
+
+			test.assertErr(t, err)
+		})
+	}
+}
+
+func TestQuotationService_ExpireStale(t *testing.T) {
+	ts := NewTestSuite()
+	ts.SetupTest(t)
+
+	ts.MockQuotationRepo.On("ExpireBefore", ts.Ctx, mock.AnythingOfType("time.Time")).Return(int64(3), nil).Once()
+
+	n, err := ts.Service.ExpireStale(ts.Ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+}
@@ -0,0 +1,31 @@
+package quotation
+
+import (
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// RunExpirySweeper ปิดใบเสนอราคาที่หมดอายุทุกๆ interval จนกว่า ctx จะถูก cancel
+// (รันเป็น goroutine จาก main)
+func RunExpirySweeper(ctx context.Context, svc Service, interval time.Duration) {
+	log := ctxlog.From(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := svc.ExpireStale(ctx); err != nil {
+			log.Warn("quotation.sweeper.expire_fail", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info("quotation.sweeper.stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// cacheLayer เก็บผลรายงานที่ aggregate แล้วไว้ช่วงสั้นๆ (TTL สั้น เพราะข้อมูลเปลี่ยนตลอดวัน)
+type cacheLayer struct {
+	rdb *redis.Client
+	ttl time.Duration
+}
+
+func newCache(rdb *redis.Client, ttl time.Duration) *cacheLayer {
+	if rdb == nil || ttl <= 0 {
+		return nil
+	}
+	return &cacheLayer{
+		rdb: rdb,
+		ttl: ttl,
+	}
+}
+
+func (c *cacheLayer) keyABC(from, to time.Time) string {
+	return fmt.Sprintf("report:abc:%d:%d", from.Unix(), to.Unix())
+}
+
+func (c *cacheLayer) keyDeadStock(cutoff time.Time) string {
+	return fmt.Sprintf("report:dead_stock:%d", cutoff.Unix())
+}
+
+// get คืน ok=false เมื่อไม่มีใน cache (ไม่ถือเป็น error)
+func (c *cacheLayer) get(ctx context.Context, key string, dest any) (bool, error) {
+	if c == nil {
+		return false, nil
+	}
+
+	b, err := c.rdb.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := json.Unmarshal(b, dest); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *cacheLayer) set(ctx context.Context, key string, v any) error {
+	if c == nil {
+		return nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return c.rdb.Set(ctx, key, b, c.ttl).Err()
+}
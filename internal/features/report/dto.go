@@ -0,0 +1,115 @@
+package report
+
+import "time"
+
+// ระดับของ ABC analysis
+const (
+	ClassA = "A"
+	ClassB = "B"
+	ClassC = "C"
+)
+
+type ABCQuery struct {
+	From time.Time
+	To   time.Time
+}
+
+type DeadStockQuery struct {
+	Days int
+}
+
+// ProductValueRow ยอดตัดออกต่อสินค้าในช่วงเวลา (aggregate จาก stock_movements)
+type ProductValueRow struct {
+	ProductID uint
+	SKU       string
+	Name      string
+	Quantity  int64
+	Value     float64
+}
+
+// DeadStockRow สินค้าคงเหลือที่ไม่มีการตัดออกตั้งแต่ cutoff
+type DeadStockRow struct {
+	ProductID    uint
+	SKU          string
+	Name         string
+	Location     string
+	OnHand       int
+	AvgCost      float64
+	LastIssuedAt *time.Time
+}
+
+type ABCItem struct {
+	ProductID       uint
+	SKU             string
+	Name            string
+	Quantity        int64
+	Value           float64
+	Share           float64 // สัดส่วนของมูลค่ารวม (%)
+	CumulativeShare float64 // สัดส่วนสะสม (%)
+	Class           string
+}
+
+type ABCReport struct {
+	From       time.Time
+	To         time.Time
+	TotalValue float64
+	Counts     map[string]int
+	Items      []ABCItem
+}
+
+type DeadStockItem struct {
+	ProductID    uint
+	SKU          string
+	Name         string
+	Location     string
+	OnHand       int
+	Value        float64
+	LastIssuedAt *time.Time
+	IdleDays     int
+	Clearance    bool
+}
+
+type DeadStockReport struct {
+	Days       int
+	Cutoff     time.Time
+	TotalValue float64
+	Items      []DeadStockItem
+}
+
+type ABCItemResponse struct {
+	ProductID       uint    `json:"product_id"`
+	SKU             string  `json:"sku"`
+	Name            string  `json:"name"`
+	Quantity        int64   `json:"quantity"`
+	Value           float64 `json:"value"`
+	Share           float64 `json:"share"`
+	CumulativeShare float64 `json:"cumulative_share"`
+	Class           string  `json:"class"`
+}
+
+type ABCResponse struct {
+	From       time.Time         `json:"from"`
+	To         time.Time         `json:"to"`
+	TotalValue float64           `json:"total_value"`
+	Counts     map[string]int    `json:"counts"`
+	Items      []ABCItemResponse `json:"items"`
+}
+
+type DeadStockItemResponse struct {
+	ProductID    uint       `json:"product_id"`
+	SKU          string     `json:"sku"`
+	Name         string     `json:"name"`
+	Location     string     `json:"location"`
+	OnHand       int        `json:"on_hand"`
+	Value        float64    `json:"value"`
+	LastIssuedAt *time.Time `json:"last_issued_at"`
+	IdleDays     int        `json:"idle_days"`
+	Clearance    bool       `json:"clearance"`
+}
+
+type DeadStockResponse struct {
+	Days       int                     `json:"days"`
+	Cutoff     time.Time               `json:"cutoff"`
+	TotalValue float64                 `json:"total_value"`
+	Items      []DeadStockItemResponse `json:"items"`
+}
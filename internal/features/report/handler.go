@@ -0,0 +1,124 @@
+package report
+
+import (
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/response"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// mapError แปลง error จาก service เป็น response มาตรฐาน
+func mapError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, apperror.ErrInvalidInput) {
+		return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid input")
+	}
+	return response.Error(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "internal server occured")
+}
+
+// parseDate แปลง YYYY-MM-DD (ค่าว่างคืน zero time)
+func parseDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.ParseInLocation(time.DateOnly, s, time.Local)
+}
+
+// ABC godoc
+// @Summary ABC analysis
+// @Description Classify products A/B/C by outbound value (quantity x price) over a period (manager only)
+// @Tags reports
+// @Produce json
+// @Param from query string false "Start date (YYYY-MM-DD), default 1 year before to"
+// @Param to query string false "End date inclusive (YYYY-MM-DD), default today"
+// @Success 200 {object} ABCResponse
+// @Failure 400 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /reports/abc [get]
+func (h *Handler) ABC(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+
+	from, err := parseDate(c.Query("from"))
+	if err != nil {
+		log.Warn("handler.report.abc.invalid_from", zap.Error(err))
+		return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid from request")
+	}
+	to, err := parseDate(c.Query("to"))
+	if err != nil {
+		log.Warn("handler.report.abc.invalid_to", zap.Error(err))
+		return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid to request")
+	}
+	// to เป็นวันที่แบบรวมวันนั้น -> ขอบบนคือต้นวันถัดไป
+	if !to.IsZero() {
+		to = to.AddDate(0, 0, 1)
+	}
+
+	rep, err := h.service.ABC(ctx, ABCQuery{From: from, To: to})
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	items := make([]ABCItemResponse, len(rep.Items))
+	for i, it := range rep.Items {
+		items[i] = ABCItemResponse(it)
+	}
+	return response.OK(c, ABCResponse{
+		From:       rep.From,
+		To:         rep.To,
+		TotalValue: rep.TotalValue,
+		Counts:     rep.Counts,
+		Items:      items,
+	})
+}
+
+// DeadStock godoc
+// @Summary Dead-stock report
+// @Description Products on hand with no outbound movement for N days, with clearance suggestions (manager only)
+// @Tags reports
+// @Produce json
+// @Param days query int false "Idle days" default(365)
+// @Success 200 {object} DeadStockResponse
+// @Failure 400 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /reports/dead-stock [get]
+func (h *Handler) DeadStock(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+
+	days, err := strconv.Atoi(c.Query("days", "0"))
+	if err != nil {
+		log.Warn("handler.report.deadstock.invalid_days", zap.Error(err))
+		return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid days request")
+	}
+
+	rep, err := h.service.DeadStock(ctx, DeadStockQuery{Days: days})
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	items := make([]DeadStockItemResponse, len(rep.Items))
+	for i, it := range rep.Items {
+		items[i] = DeadStockItemResponse(it)
+	}
+	return response.OK(c, DeadStockResponse{
+		Days:       rep.Days,
+		Cutoff:     rep.Cutoff,
+		TotalValue: rep.TotalValue,
+		Items:      items,
+	})
+}
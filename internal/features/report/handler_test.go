@@ -0,0 +1,127 @@
+package report_test
+
+import (
+	"ans-spareparts-api/internal/features/report"
+	mocks "ans-spareparts-api/internal/mock"
+	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/response"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type HandlerTestSuite struct {
+	App         *fiber.App
+	MockService *mocks.ReportService
+	Handler     *report.Handler
+}
+
+func NewHandlerTestSuite() *HandlerTestSuite {
+	return &HandlerTestSuite{}
+}
+
+func (ts *HandlerTestSuite) SetUpHandlerTestSuite(t *testing.T) {
+	ts.MockService = mocks.NewReportService()
+	ts.Handler = report.NewHandler(ts.MockService)
+	ts.App = fiber.New(fiber.Config{
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			return response.Error(c, fiber.StatusBadRequest, "INVALID_REQUEST", "invalid request body: "+err.Error())
+		},
+	})
+
+	t.Cleanup(func() {
+		ts.MockService.AssertExpectations(t)
+	})
+}
+
+func TestReportHandler_ABC(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		setup          func(*HandlerTestSuite)
+		expectedStatus int
+	}{
+		{
+			name: "Success_To_Is_Inclusive",
+			path: "/reports/abc?from=2026-01-01&to=2026-06-30",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("ABC", mock.Anything, mock.MatchedBy(func(q report.ABCQuery) bool {
+					return q.From.Format(time.DateOnly) == "2026-01-01" && q.To.Format(time.DateOnly) == "2026-07-01"
+				})).Return(&report.ABCReport{}, nil).Once()
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "Error_Invalid_Date",
+			path:           "/reports/abc?from=01/01/2026",
+			setup:          func(hts *HandlerTestSuite) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "Error_Invalid_Range",
+			path: "/reports/abc?from=2026-06-30&to=2026-01-01",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("ABC", mock.Anything, mock.Anything).Return(nil, apperror.ErrInvalidInput).Once()
+			},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewHandlerTestSuite()
+			ts.SetUpHandlerTestSuite(t)
+
+			ts.App.Get("/reports/abc", ts.Handler.ABC)
+			test.setup(ts)
+
+			req := httptest.NewRequest(fiber.MethodGet, test.path, nil)
+			res, _ := ts.App.Test(req, -1)
+
+			assert.Equal(t, test.expectedStatus, res.StatusCode)
+		})
+	}
+}
+
+func TestReportHandler_DeadStock(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		setup          func(*HandlerTestSuite)
+		expectedStatus int
+	}{
+		{
+			name: "Success_Days",
+			path: "/reports/dead-stock?days=180",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("DeadStock", mock.Anything, report.DeadStockQuery{Days: 180}).Return(&report.DeadStockReport{Days: 180}, nil).Once()
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "Error_Invalid_Days",
+			path:           "/reports/dead-stock?days=abc",
+			setup:          func(hts *HandlerTestSuite) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewHandlerTestSuite()
+			ts.SetUpHandlerTestSuite(t)
+
+			ts.App.Get("/reports/dead-stock", ts.Handler.DeadStock)
+			test.setup(ts)
+
+			req := httptest.NewRequest(fiber.MethodGet, test.path, nil)
+			res, _ := ts.App.Test(req, -1)
+
+			assert.Equal(t, test.expectedStatus, res.StatusCode)
+		})
+	}
+}
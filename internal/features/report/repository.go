@@ -0,0 +1,118 @@
+package report
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/pkg/apperror"
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	// OutboundValueByProduct มูลค่าการตัดออก (จำนวน x ราคาขาย) ต่อสินค้าในช่วง [from, to)
+	// สินค้าที่ไม่มีการเคลื่อนไหวจะได้ค่า 0
+	OutboundValueByProduct(ctx context.Context, from, to time.Time) ([]ProductValueRow, error)
+	// DeadStock สินค้าที่ยังมีของแต่ไม่มีการตัดออกตั้งแต่ cutoff
+	DeadStock(ctx context.Context, cutoff time.Time) ([]DeadStockRow, error)
+}
+
+type repository struct {
+	db    *gorm.DB
+	cache *cacheLayer
+}
+
+func NewRepository(db *gorm.DB, rdb *redis.Client, cacheExp time.Duration) Repository {
+	return &repository{
+		db:    db,
+		cache: newCache(rdb, cacheExp),
+	}
+}
+
+func (r *repository) OutboundValueByProduct(ctx context.Context, from, to time.Time) ([]ProductValueRow, error) {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	key := r.cache.keyABC(from, to)
+	var rows []ProductValueRow
+	if ok, err := r.cache.get(ctx, key, &rows); err == nil && ok {
+		log.Debug("repo.report.outboundValue.cache_hit", zap.Duration("duration", time.Since(start)))
+		return rows, nil
+	} else if err != nil {
+		log.Warn("repo.report.outboundValue.cache_err", zap.Error(err))
+	}
+
+	query := `
+SELECT p.id AS product_id, p.sku, p.name,
+    COALESCE(SUM(m.quantity), 0) AS quantity,
+    COALESCE(SUM(m.quantity * p.price), 0) AS value
+FROM products p
+LEFT JOIN stock_movements m
+    ON m.product_id = p.id AND m.type = ? AND m.created_at >= ? AND m.created_at < ?
+WHERE p.deleted_at IS NULL
+GROUP BY p.id, p.sku, p.name
+ORDER BY value DESC, p.id`
+
+	if err := r.db.WithContext(ctx).Raw(query, domain.StockMoveIssue, from, to).Scan(&rows).Error; err != nil {
+		m := apperror.MapDBError("repo.report.outboundValue", err)
+		log.Debug("repo.report.outboundValue.db_fail", zap.Error(err), zap.Duration("duration", time.Since(start)))
+		return nil, m
+	}
+
+	if err := r.cache.set(ctx, key, rows); err != nil {
+		log.Warn("repo.report.outboundValue.set_cache.fail", zap.Error(err))
+	}
+
+	log.Debug("repo.report.outboundValue.ok", zap.Int("rows", len(rows)), zap.Duration("duration", time.Since(start)))
+	return rows, nil
+}
+
+func (r *repository) DeadStock(ctx context.Context, cutoff time.Time) ([]DeadStockRow, error) {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	key := r.cache.keyDeadStock(cutoff)
+	var rows []DeadStockRow
+	if ok, err := r.cache.get(ctx, key, &rows); err == nil && ok {
+		log.Debug("repo.report.deadStock.cache_hit", zap.Duration("duration", time.Since(start)))
+		return rows, nil
+	} else if err != nil {
+		log.Warn("repo.report.deadStock.cache_err", zap.Error(err))
+	}
+
+	query := `
+SELECT p.id AS product_id, p.sku, p.name,
+    COALESCE(i.location, '') AS location,
+    i.quantity AS on_hand,
+    i.avg_cost,
+    last.last_issued_at
+FROM inventories i
+JOIN products p ON p.id = i.product_id
+LEFT JOIN (
+    SELECT product_id, MAX(created_at) AS last_issued_at
+    FROM stock_movements
+    WHERE type = ?
+    GROUP BY product_id
+) last ON last.product_id = i.product_id
+WHERE i.quantity > 0
+    AND i.deleted_at IS NULL
+    AND p.deleted_at IS NULL
+    AND (last.last_issued_at IS NULL OR last.last_issued_at < ?)
+ORDER BY i.quantity * i.avg_cost DESC, p.id`
+
+	if err := r.db.WithContext(ctx).Raw(query, domain.StockMoveIssue, cutoff).Scan(&rows).Error; err != nil {
+		m := apperror.MapDBError("repo.report.deadStock", err)
+		log.Debug("repo.report.deadStock.db_fail", zap.Error(err), zap.Duration("duration", time.Since(start)))
+		return nil, m
+	}
+
+	if err := r.cache.set(ctx, key, rows); err != nil {
+		log.Warn("repo.report.deadStock.set_cache.fail", zap.Error(err))
+	}
+
+	log.Debug("repo.report.deadStock.ok", zap.Int("rows", len(rows)), zap.Duration("duration", time.Since(start)))
+	return rows, nil
+}
@@ -0,0 +1,167 @@
+package report
+
+import (
+	"ans-spareparts-api/pkg/apperror"
+	"context"
+	"math"
+	"time"
+)
+
+// เกณฑ์ ABC ตามสัดส่วนมูลค่าสะสม: A = 80% แรก, B = ถึง 95%, ที่เหลือ C
+const (
+	classAThreshold = 80.0
+	classBThreshold = 95.0
+)
+
+const (
+	defaultABCPeriod     = 365 * 24 * time.Hour
+	defaultDeadStockDays = 365
+)
+
+type Service interface {
+	// ABC จัดกลุ่มสินค้า A/B/C ตามมูลค่าการตัดออกในช่วงเวลา
+	ABC(ctx context.Context, q ABCQuery) (*ABCReport, error)
+	// DeadStock สินค้าที่ไม่มีการตัดออกเกิน N วัน พร้อมคำแนะนำสินค้าที่ควรเคลียร์
+	DeadStock(ctx context.Context, q DeadStockQuery) (*DeadStockReport, error)
+}
+
+type service struct {
+	reportRepo Repository
+}
+
+func NewService(reportRepo Repository) Service {
+	return &service{
+		reportRepo: reportRepo,
+	}
+}
+
+// --- helper ---
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// startOfDay ปัดเวลาลงเป็นต้นวัน เพื่อให้ key ของ cache คงที่ทั้งวัน
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// classify จัดระดับจากรายการที่เรียงมูลค่ามากไปน้อยแล้ว
+// สินค้าที่ไม่มีมูลค่าเลยเป็น C เสมอ
+func classify(rows []ProductValueRow) ([]ABCItem, float64) {
+	var total float64
+	for _, r := range rows {
+		total += r.Value
+	}
+
+	items := make([]ABCItem, len(rows))
+	var cumulative float64
+	for i, r := range rows {
+		it := ABCItem{
+			ProductID: r.ProductID,
+			SKU:       r.SKU,
+			Name:      r.Name,
+			Quantity:  r.Quantity,
+			Value:     roundMoney(r.Value),
+			Class:     ClassC,
+		}
+		if total > 0 && r.Value > 0 {
+			// ใช้สัดส่วนสะสม "ก่อน" รายการนี้ เพื่อให้รายการที่ข้ามเกณฑ์ยังอยู่ในระดับบน
+			prev := cumulative / total * 100
+			cumulative += r.Value
+			it.Share = roundMoney(r.Value / total * 100)
+			it.CumulativeShare = roundMoney(cumulative / total * 100)
+			switch {
+			case prev < classAThreshold:
+				it.Class = ClassA
+			case prev < classBThreshold:
+				it.Class = ClassB
+			}
+		} else if total > 0 {
+			it.CumulativeShare = roundMoney(cumulative / total * 100)
+		}
+		items[i] = it
+	}
+
+	return items, roundMoney(total)
+}
+
+func (s *service) ABC(ctx context.Context, q ABCQuery) (*ABCReport, error) {
+	to := q.To
+	if to.IsZero() {
+		to = startOfDay(time.Now()).AddDate(0, 0, 1)
+	}
+	from := q.From
+	if from.IsZero() {
+		from = to.Add(-defaultABCPeriod)
+	}
+	if !from.Before(to) {
+		return nil, apperror.ErrInvalidInput
+	}
+
+	rows, err := s.reportRepo.OutboundValueByProduct(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	items, total := classify(rows)
+	counts := map[string]int{ClassA: 0, ClassB: 0, ClassC: 0}
+	for _, it := range items {
+		counts[it.Class]++
+	}
+
+	return &ABCReport{
+		From:       from,
+		To:         to,
+		TotalValue: total,
+		Counts:     counts,
+		Items:      items,
+	}, nil
+}
+
+func (s *service) DeadStock(ctx context.Context, q DeadStockQuery) (*DeadStockReport, error) {
+	days := q.Days
+	if days == 0 {
+		days = defaultDeadStockDays
+	}
+	if days < 0 {
+		return nil, apperror.ErrInvalidInput
+	}
+
+	now := time.Now()
+	cutoff := startOfDay(now).AddDate(0, 0, -days)
+
+	rows, err := s.reportRepo.DeadStock(ctx, cutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &DeadStockReport{
+		Days:   days,
+		Cutoff: cutoff,
+		Items:  make([]DeadStockItem, len(rows)),
+	}
+	for i, r := range rows {
+		it := DeadStockItem{
+			ProductID:    r.ProductID,
+			SKU:          r.SKU,
+			Name:         r.Name,
+			Location:     r.Location,
+			OnHand:       r.OnHand,
+			Value:        roundMoney(float64(r.OnHand) * r.AvgCost),
+			LastIssuedAt: r.LastIssuedAt,
+		}
+		// แนะนำเคลียร์: ไม่เคยตัดออกเลย หรือค้างนานเกินสองเท่าของเกณฑ์
+		if r.LastIssuedAt == nil {
+			it.Clearance = true
+		} else {
+			it.IdleDays = int(now.Sub(*r.LastIssuedAt).Hours() / 24)
+			it.Clearance = it.IdleDays >= 2*days
+		}
+		out.Items[i] = it
+		out.TotalValue += it.Value
+	}
+	out.TotalValue = roundMoney(out.TotalValue)
+
+	return out, nil
+}
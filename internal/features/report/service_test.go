@@ -0,0 +1,186 @@
+package report_test
+
+import (
+	"ans-spareparts-api/internal/features/report"
+	mocks "ans-spareparts-api/internal/mock"
+	"ans-spareparts-api/pkg/apperror"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type TestSuite struct {
+	Service        report.Service
+	MockReportRepo *mocks.ReportRepository
+	Ctx            context.Context
+}
+
+func NewTestSuite() *TestSuite {
+	return &TestSuite{}
+}
+
+func (ts *TestSuite) SetupTest(t *testing.T) {
+	ts.MockReportRepo = mocks.NewMockReportRepository()
+	ts.Service = report.NewService(ts.MockReportRepo)
+	ts.Ctx = context.Background()
+
+	t.Cleanup(func() {
+		ts.MockReportRepo.AssertExpectations(t)
+	})
+}
+
+func TestReportService_ABC(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	rows := []report.ProductValueRow{
+		{ProductID: 1, SKU: "BRK-001", Value: 7000},
+		{ProductID: 2, SKU: "FLT-001", Value: 2000},
+		{ProductID: 3, SKU: "OIL-001", Value: 600},
+		{ProductID: 4, SKU: "BLT-001", Value: 400},
+		{ProductID: 5, SKU: "NUT-001", Value: 0},
+	}
+
+	tests := []struct {
+		name      string
+		query     report.ABCQuery
+		setup     func(ts *TestSuite)
+		assertErr func(*testing.T, error)
+		validate  func(*testing.T, *report.ABCReport)
+	}{
+		{
+			name:  "Success_Classified",
+			query: report.ABCQuery{From: from, To: to},
+			setup: func(ts *TestSuite) {
+				ts.MockReportRepo.On("OutboundValueByProduct", ts.Ctx, from, to).Return(rows, nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, rep *report.ABCReport) {
+				assert.Equal(t, 10000.0, rep.TotalValue)
+				// 70% -> A, 70..90% -> A (ยังไม่ถึง 80 ก่อนรายการนี้), 90..96 -> B, 96..100 -> C
+				classes := []string{}
+				for _, it := range rep.Items {
+					classes = append(classes, it.Class)
+				}
+				assert.Equal(t, []string{"A", "A", "B", "C", "C"}, classes)
+				assert.Equal(t, map[string]int{"A": 2, "B": 1, "C": 2}, rep.Counts)
+				assert.Equal(t, 70.0, rep.Items[0].Share)
+				assert.Equal(t, 96.0, rep.Items[2].CumulativeShare)
+			},
+		},
+		{
+			name:  "Success_No_Movement_All_C",
+			query: report.ABCQuery{From: from, To: to},
+			setup: func(ts *TestSuite) {
+				ts.MockReportRepo.On("OutboundValueByProduct", ts.Ctx, from, to).Return([]report.ProductValueRow{{ProductID: 1}}, nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, rep *report.ABCReport) {
+				assert.Equal(t, report.ClassC, rep.Items[0].Class)
+			},
+		},
+		{
+			name:  "Success_Default_Period_One_Year",
+			query: report.ABCQuery{},
+			setup: func(ts *TestSuite) {
+				ts.MockReportRepo.On("OutboundValueByProduct", ts.Ctx, mock.Anything, mock.MatchedBy(func(t time.Time) bool {
+					return t.After(time.Now())
+				})).Return(nil, nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, rep *report.ABCReport) {
+				assert.Equal(t, 365*24*time.Hour, rep.To.Sub(rep.From))
+			},
+		},
+		{
+			name:  "Error_From_After_To",
+			query: report.ABCQuery{From: to, To: from},
+			setup: func(ts *TestSuite) {},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidInput)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewTestSuite()
+			ts.SetupTest(t)
+			test.setup(ts)
+
+			rep, err := ts.Service.ABC(ts.Ctx, test.query)
+
+			test.assertErr(t, err)
+			if test.validate != nil {
+				test.validate(t, rep)
+			}
+		})
+	}
+}
+
+func TestReportService_DeadStock(t *testing.T) {
+	longAgo := time.Now().AddDate(0, 0, -400)
+	veryLongAgo := time.Now().AddDate(0, 0, -800)
+
+	tests := []struct {
+		name      string
+		query     report.DeadStockQuery
+		setup     func(ts *TestSuite)
+		assertErr func(*testing.T, error)
+		validate  func(*testing.T, *report.DeadStockReport)
+	}{
+		{
+			name:  "Success_With_Clearance_Candidates",
+			query: report.DeadStockQuery{},
+			setup: func(ts *TestSuite) {
+				ts.MockReportRepo.On("DeadStock", ts.Ctx, mock.AnythingOfType("time.Time")).Return([]report.DeadStockRow{
+					{ProductID: 1, OnHand: 10, AvgCost: 250, LastIssuedAt: &veryLongAgo},
+					{ProductID: 2, OnHand: 4, AvgCost: 99.995, LastIssuedAt: &longAgo},
+					{ProductID: 3, OnHand: 2, AvgCost: 50},
+				}, nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, rep *report.DeadStockReport) {
+				assert.Equal(t, 365, rep.Days)
+				assert.True(t, rep.Items[0].Clearance)
+				assert.False(t, rep.Items[1].Clearance)
+				assert.Equal(t, 400, rep.Items[1].IdleDays)
+				assert.True(t, rep.Items[2].Clearance)
+				assert.Equal(t, 2500+399.98+100, rep.TotalValue)
+			},
+		},
+		{
+			name:  "Error_Negative_Days",
+			query: report.DeadStockQuery{Days: -1},
+			setup: func(ts *TestSuite) {},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidInput)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewTestSuite()
+			ts.SetupTest(t)
+			test.setup(ts)
+
+			rep, err := ts.Service.DeadStock(ts.Ctx, test.query)
+
+			test.assertErr(t, err)
+			if test.validate != nil {
+				test.validate(t, rep)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package shift
+
+import "time"
+
+// ประเภทรายงาน: X = ระหว่างกะ (ไม่ปิดกะ), Z = ปิดกะ
+const (
+	ReportX = "X"
+	ReportZ = "Z"
+)
+
+type OpenInput struct {
+	UserID       uint
+	Terminal     string
+	OpeningFloat float64
+}
+
+// ReportInput ผู้ขอดูรายงาน: เจ้าของกะ หรือ manager/admin (CanViewAll)
+type ReportInput struct {
+	ShiftID    uint
+	UserID     uint
+	CanViewAll bool
+}
+
+type CloseInput struct {
+	ShiftID uint
+	UserID  uint
+	Counted map[string]float64 // method -> ยอดที่นับได้
+	Note    string
+}
+
+type Item struct {
+	ID           uint
+	UserID       uint
+	Terminal     string
+	Status       string
+	OpeningFloat float64
+	OpenedAt     time.Time
+	ClosedAt     *time.Time
+}
+
+type ReportLine struct {
+	Method   string
+	Expected float64
+	Counted  float64
+	Variance float64
+}
+
+type Report struct {
+	Kind          string
+	ShiftID       uint
+	UserID        uint
+	Terminal      string
+	OpeningFloat  float64
+	OpenedAt      time.Time
+	ClosedAt      *time.Time
+	Lines         []ReportLine
+	TotalExpected float64
+	TotalCounted  float64
+	TotalVariance float64
+}
+
+type OpenShiftRequest struct {
+	Terminal     string  `json:"terminal"`
+	OpeningFloat float64 `json:"opening_float"`
+}
+
+type CloseShiftRequest struct {
+	Counted map[string]float64 `json:"counted"`
+	Note    string             `json:"note"`
+}
+
+type ShiftResponse struct {
+	ID           uint       `json:"id"`
+	UserID       uint       `json:"user_id"`
+	Terminal     string     `json:"terminal"`
+	Status       string     `json:"status"`
+	OpeningFloat float64    `json:"opening_float"`
+	OpenedAt     time.Time  `json:"opened_at"`
+	ClosedAt     *time.Time `json:"closed_at"`
+}
+
+type ReportLineResponse struct {
+	Method   string  `json:"method"`
+	Expected float64 `json:"expected"`
+	Counted  float64 `json:"counted"`
+	Variance float64 `json:"variance"`
+}
+
+type ReportResponse struct {
+	Kind          string               `json:"kind"`
+	ShiftID       uint                 `json:"shift_id"`
+	UserID        uint                 `json:"user_id"`
+	Terminal      string               `json:"terminal"`
+	OpeningFloat  float64              `json:"opening_float"`
+	OpenedAt      time.Time            `json:"opened_at"`
+	ClosedAt      *time.Time           `json:"closed_at"`
+	Lines         []ReportLineResponse `json:"lines"`
+	TotalExpected float64              `json:"total_expected"`
+	TotalCounted  float64              `json:"total_counted"`
+	TotalVariance float64              `json:"total_variance"`
+}
@@ -0,0 +1,252 @@
+package shift
+
+import (
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/internal/infra/jwtx"
+	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/response"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+func toShiftResponse(it *Item) ShiftResponse {
+	return ShiftResponse(*it)
+}
+
+func toReportResponse(r *Report) ReportResponse {
+	lines := make([]ReportLineResponse, len(r.Lines))
+	for i, l := range r.Lines {
+		lines[i] = ReportLineResponse(l)
+	}
+	return ReportResponse{
+		Kind:          r.Kind,
+		ShiftID:       r.ShiftID,
+		UserID:        r.UserID,
+		Terminal:      r.Terminal,
+		OpeningFloat:  r.OpeningFloat,
+		OpenedAt:      r.OpenedAt,
+		ClosedAt:      r.ClosedAt,
+		Lines:         lines,
+		TotalExpected: r.TotalExpected,
+		TotalCounted:  r.TotalCounted,
+		TotalVariance: r.TotalVariance,
+	}
+}
+
+// mapError แปลง error จาก service เป็น response มาตรฐาน
+func mapError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, apperror.ErrInvalidInput):
+		return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid input")
+	case errors.Is(err, apperror.ErrNotFound):
+		return response.Error(c, fiber.StatusNotFound, "NOT_FOUND", "shift not found")
+	case errors.Is(err, apperror.ErrConflict):
+		return response.Error(c, fiber.StatusConflict, "CONFLICT", "shift already open for user or terminal")
+	case errors.Is(err, apperror.ErrInvalidState):
+		return response.Error(c, fiber.StatusConflict, "CONFLICT", "shift is not in a valid state for this action")
+	case errors.Is(err, apperror.ErrUserForbidden):
+		return response.Error(c, fiber.StatusForbidden, "FORBIDDEN", "shift belongs to another user")
+	}
+	return response.Error(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "internal server occured")
+}
+
+// reportInput รายงานกะดูได้เฉพาะเจ้าของกะ หรือ manager/admin
+func reportInput(c *fiber.Ctx, shiftID uint) ReportInput {
+	userClaims := c.Locals("user").(*jwtx.Claims)
+	return ReportInput{
+		ShiftID:    shiftID,
+		UserID:     userClaims.UserID,
+		CanViewAll: userClaims.Role == "admin" || userClaims.Role == "manager",
+	}
+}
+
+func parseShiftID(c *fiber.Ctx) (uint, error) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	return uint(id), err
+}
+
+// OpenShift godoc
+// @Summary Open a cash drawer shift
+// @Description Open a shift for the current user on a terminal with an opening float
+// @Tags shifts
+// @Accept json
+// @Produce json
+// @Param shift body OpenShiftRequest true "Open shift request"
+// @Success 201 {object} ShiftResponse
+// @Failure 400 {object} response.ErrorBody
+// @Failure 409 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /shifts/open [post]
+func (h *Handler) OpenShift(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+	userClaims := c.Locals("user").(*jwtx.Claims)
+
+	var req OpenShiftRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Warn("handler.shift.open.invalid_body", zap.Error(err))
+		return response.Error(
+			c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid request body",
+		)
+	}
+
+	s, err := h.service.OpenShift(ctx, OpenInput{
+		UserID:       userClaims.UserID,
+		Terminal:     req.Terminal,
+		OpeningFloat: req.OpeningFloat,
+	})
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	return response.Created(c, toShiftResponse(s))
+}
+
+// CurrentShift godoc
+// @Summary Get current open shift
+// @Description Get the open shift of the current user
+// @Tags shifts
+// @Produce json
+// @Success 200 {object} ShiftResponse
+// @Failure 404 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /shifts/current [get]
+func (h *Handler) CurrentShift(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	userClaims := c.Locals("user").(*jwtx.Claims)
+
+	s, err := h.service.CurrentShift(ctx, userClaims.UserID)
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	return response.OK(c, toShiftResponse(s))
+}
+
+// XReport godoc
+// @Summary Mid-shift X-report
+// @Description Expected totals per payment method for an open shift
+// @Tags shifts
+// @Produce json
+// @Param id path int true "Shift ID"
+// @Success 200 {object} ReportResponse
+// @Failure 400 {object} response.ErrorBody
+// @Failure 403 {object} response.ErrorBody
+// @Failure 404 {object} response.ErrorBody
+// @Failure 409 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /shifts/{id}/x-report [get]
+func (h *Handler) XReport(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+
+	id, err := parseShiftID(c)
+	if err != nil {
+		log.Warn("handler.shift.xreport.invalid_id", zap.Error(err))
+		return response.Error(
+			c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid shift id",
+		)
+	}
+
+	r, err := h.service.XReport(ctx, reportInput(c, id))
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	return response.OK(c, toReportResponse(r))
+}
+
+// CloseShift godoc
+// @Summary Close a shift
+// @Description Close the shift with counted totals per payment method and return the Z-report
+// @Tags shifts
+// @Accept json
+// @Produce json
+// @Param id path int true "Shift ID"
+// @Param counted body CloseShiftRequest true "Counted totals"
+// @Success 200 {object} ReportResponse
+// @Failure 400 {object} response.ErrorBody
+// @Failure 403 {object} response.ErrorBody
+// @Failure 404 {object} response.ErrorBody
+// @Failure 409 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /shifts/{id}/close [post]
+func (h *Handler) CloseShift(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+	userClaims := c.Locals("user").(*jwtx.Claims)
+
+	id, err := parseShiftID(c)
+	if err != nil {
+		log.Warn("handler.shift.close.invalid_id", zap.Error(err))
+		return response.Error(
+			c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid shift id",
+		)
+	}
+
+	var req CloseShiftRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Warn("handler.shift.close.invalid_body", zap.Error(err))
+		return response.Error(
+			c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid request body",
+		)
+	}
+
+	r, err := h.service.CloseShift(ctx, CloseInput{
+		ShiftID: id,
+		UserID:  userClaims.UserID,
+		Counted: req.Counted,
+		Note:    req.Note,
+	})
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	return response.OK(c, toReportResponse(r))
+}
+
+// ZReport godoc
+// @Summary Close-of-shift Z-report
+// @Description Expected, counted and variance per payment method for a closed shift
+// @Tags shifts
+// @Produce json
+// @Param id path int true "Shift ID"
+// @Success 200 {object} ReportResponse
+// @Failure 400 {object} response.ErrorBody
+// @Failure 403 {object} response.ErrorBody
+// @Failure 404 {object} response.ErrorBody
+// @Failure 409 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /shifts/{id}/z-report [get]
+func (h *Handler) ZReport(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+
+	id, err := parseShiftID(c)
+	if err != nil {
+		log.Warn("handler.shift.zreport.invalid_id", zap.Error(err))
+		return response.Error(
+			c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid shift id",
+		)
+	}
+
+	r, err := h.service.ZReport(ctx, reportInput(c, id))
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	return response.OK(c, toReportResponse(r))
+}
@@ -0,0 +1,152 @@
+package shift_test
+
+import (
+	"ans-spareparts-api/internal/features/shift"
+	"ans-spareparts-api/internal/infra/jwtx"
+	mocks "ans-spareparts-api/internal/mock"
+	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/response"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type HandlerTestSuite struct {
+	App         *fiber.App
+	MockService *mocks.ShiftService
+	Handler     *shift.Handler
+}
+
+func NewHandlerTestSuite() *HandlerTestSuite {
+	return &HandlerTestSuite{}
+}
+
+func (ts *HandlerTestSuite) SetUpHandlerTestSuite(t *testing.T) {
+	ts.MockService = mocks.NewShiftService()
+	ts.Handler = shift.NewHandler(ts.MockService)
+	ts.App = fiber.New(fiber.Config{
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			return response.Error(c, fiber.StatusBadRequest, "INVALID_REQUEST", "invalid request body: "+err.Error())
+		},
+	})
+
+	t.Cleanup(func() {
+		ts.MockService.AssertExpectations(t)
+	})
+}
+
+func withUser(c *fiber.Ctx) error {
+	c.Locals("user", &jwtx.Claims{UserID: 5, Username: "Test", Role: "cashier"})
+	return c.Next()
+}
+
+func TestShiftHandler_OpenShift(t *testing.T) {
+	mockInput := shift.OpenInput{UserID: 5, Terminal: "POS-01", OpeningFloat: 2000}
+
+	tests := []struct {
+		name           string
+		setup          func(*HandlerTestSuite)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name: "Success_Opened",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("OpenShift", mock.Anything, mockInput).Return(&shift.Item{ID: 1, UserID: 5, Terminal: "POS-01", Status: "open", OpeningFloat: 2000}, nil).Once()
+			},
+			expectedStatus: fiber.StatusCreated,
+		},
+		{
+			name: "Error_Conflict_Already_Open",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("OpenShift", mock.Anything, mockInput).Return(nil, apperror.ErrConflict).Once()
+			},
+			expectedStatus: fiber.StatusConflict,
+			expectedBody: fiber.Map{
+				"code":    "CONFLICT",
+				"message": "shift already open for user or terminal",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewHandlerTestSuite()
+			ts.SetUpHandlerTestSuite(t)
+
+			ts.App.Post("/shifts/open", withUser, ts.Handler.OpenShift)
+			test.setup(ts)
+
+			body, _ := json.Marshal(shift.OpenShiftRequest{Terminal: "POS-01", OpeningFloat: 2000})
+			req := httptest.NewRequest(fiber.MethodPost, "/shifts/open", bytes.NewBuffer(body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+			res, _ := ts.App.Test(req, -1)
+
+			assert.Equal(t, test.expectedStatus, res.StatusCode)
+			if test.expectedBody != nil {
+				resBody, _ := io.ReadAll(res.Body)
+				expectedBody, _ := json.Marshal(test.expectedBody)
+				assert.JSONEq(t, string(expectedBody), string(resBody))
+			}
+		})
+	}
+}
+
+func TestShiftHandler_CloseShift(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		setup          func(*HandlerTestSuite)
+		expectedStatus int
+	}{
+		{
+			name: "Success_Closed",
+			path: "/shifts/1/close",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("CloseShift", mock.Anything, mock.MatchedBy(func(in shift.CloseInput) bool {
+					return in.ShiftID == 1 && in.UserID == 5 && in.Counted["cash"] == 1950
+				})).Return(&shift.Report{Kind: shift.ReportZ, ShiftID: 1}, nil).Once()
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name: "Error_Forbidden_Other_User",
+			path: "/shifts/1/close",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("CloseShift", mock.Anything, mock.Anything).Return(nil, apperror.ErrUserForbidden).Once()
+			},
+			expectedStatus: fiber.StatusForbidden,
+		},
+		{
+			name:           "Error_BadRequest_Invalid_ID",
+			path:           "/shifts/abc/close",
+			setup:          func(hts *HandlerTestSuite) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewHandlerTestSuite()
+			ts.SetUpHandlerTestSuite(t)
+
+			ts.App.Post("/shifts/:id/close", withUser, ts.Handler.CloseShift)
+			test.setup(ts)
+
+			body, _ := json.Marshal(shift.CloseShiftRequest{Counted: map[string]float64{"cash": 1950}})
+			req := httptest.NewRequest(fiber.MethodPost, test.path, bytes.NewBuffer(body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+			res, _ := ts.App.Test(req, -1)
+
+			assert.Equal(t, test.expectedStatus, res.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package shift
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/pkg/apperror"
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	GetByID(ctx context.Context, id uint) (*domain.Shift, error)
+	// GetOpenByUser คืนกะที่ยังเปิดอยู่ของผู้ใช้ (ErrNotFound ถ้าไม่มี)
+	GetOpenByUser(ctx context.Context, userID uint) (*domain.Shift, error)
+
+	Create(ctx context.Context, s *domain.Shift) error
+	// Close ปิดกะและบันทึกยอดต่อช่องทางชำระเงินใน transaction เดียว
+	Close(ctx context.Context, s *domain.Shift) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) GetByID(ctx context.Context, id uint) (*domain.Shift, error) {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	var s domain.Shift
+	if err := r.db.WithContext(ctx).Preload("Totals").First(&s, id).Error; err != nil {
+		m := apperror.MapDBError("repo.shift.getByID", err)
+		log.Debug("repo.shift.getByID.db_fail", zap.Error(err), zap.Duration("duration", time.Since(start)))
+		return nil, m
+	}
+
+	log.Debug("repo.shift.getByID.ok", zap.Uint("id", id), zap.Duration("duration", time.Since(start)))
+	return &s, nil
+}
+
+func (r *repository) GetOpenByUser(ctx context.Context, userID uint) (*domain.Shift, error) {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	var s domain.Shift
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND status = ?", userID, domain.ShiftStatusOpen).
+		First(&s).Error; err != nil {
+		m := apperror.MapDBError("repo.shift.getOpenByUser", err)
+		log.Debug("repo.shift.getOpenByUser.db_fail", zap.Error(err), zap.Duration("duration", time.Since(start)))
+		return nil, m
+	}
+
+	log.Debug("repo.shift.getOpenByUser.ok", zap.Uint("user_id", userID), zap.Duration("duration", time.Since(start)))
+	return &s, nil
+}
+
+func (r *repository) Create(ctx context.Context, s *domain.Shift) error {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	// unique partial index กันเปิดกะซ้อน -> 23505 -> ErrConflict
+	if err := r.db.WithContext(ctx).Create(s).Error; err != nil {
+		m := apperror.MapDBError("repo.shift.create", err)
+		log.Debug("repo.shift.create.fail", zap.Error(err), zap.Duration("duration", time.Since(start)))
+		return m
+	}
+
+	log.Info("repo.shift.create.ok", zap.Uint("id", s.ID), zap.Duration("duration", time.Since(start)))
+	return nil
+}
+
+func (r *repository) Close(ctx context.Context, s *domain.Shift) error {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// อัปเดตเฉพาะกะที่ยังเปิดอยู่ กันการปิดซ้ำจากสอง request พร้อมกัน
+		res := tx.Model(&domain.Shift{}).
+			Where("id = ? AND status = ?", s.ID, domain.ShiftStatusOpen).
+			Updates(map[string]any{
+				"status":       domain.ShiftStatusClosed,
+				"closed_at":    s.ClosedAt,
+				"closing_note": s.ClosingNote,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return apperror.ErrInvalidState
+		}
+
+		for i := range s.Totals {
+			s.Totals[i].ShiftID = s.ID
+		}
+		if len(s.Totals) > 0 {
+			if err := tx.Create(&s.Totals).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if err == apperror.ErrInvalidState {
+			return err
+		}
+		m := apperror.MapDBError("repo.shift.close", err)
+		log.Debug("repo.shift.close.fail", zap.Error(err), zap.Duration("duration", time.Since(start)))
+		return m
+	}
+
+	log.Info("repo.shift.close.ok", zap.Uint("id", s.ID), zap.Duration("duration", time.Since(start)))
+	return nil
+}
@@ -0,0 +1,232 @@
+package shift
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/utils"
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type Service interface {
+	OpenShift(ctx context.Context, in OpenInput) (*Item, error)
+	CurrentShift(ctx context.Context, userID uint) (*Item, error)
+	// XReport สรุปยอดระหว่างกะ โดยไม่ปิดกะ
+	XReport(ctx context.Context, in ReportInput) (*Report, error)
+	// CloseShift ปิดกะด้วยยอดที่นับได้ และคืน Z-report
+	CloseShift(ctx context.Context, in CloseInput) (*Report, error)
+	// ZReport ดู Z-report ของกะที่ปิดแล้ว
+	ZReport(ctx context.Context, in ReportInput) (*Report, error)
+}
+
+type service struct {
+	shiftRepo Repository
+}
+
+func NewService(shiftRepo Repository) Service {
+	return &service{
+		shiftRepo: shiftRepo,
+	}
+}
+
+var paymentMethods = map[string]bool{
+	domain.PaymentMethodCash:         true,
+	domain.PaymentMethodCard:         true,
+	domain.PaymentMethodBankTransfer: true,
+	domain.PaymentMethodPromptPay:    true,
+}
+
+// --- helper ---
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// expectedTotals ยอดที่ควรมีต่อช่องทางชำระเงิน
+// ตอนนี้ยังไม่มีข้อมูลการขาย จึงมีเพียงเงินทอนตั้งต้น (float) ในช่องเงินสด
+func expectedTotals(s *domain.Shift) map[string]float64 {
+	return map[string]float64{
+		domain.PaymentMethodCash: s.OpeningFloat,
+	}
+}
+
+func toItem(s *domain.Shift) *Item {
+	return &Item{
+		ID:           s.ID,
+		UserID:       s.UserID,
+		Terminal:     s.Terminal,
+		Status:       s.Status,
+		OpeningFloat: s.OpeningFloat,
+		OpenedAt:     s.OpenedAt,
+		ClosedAt:     s.ClosedAt,
+	}
+}
+
+func buildReport(kind string, s *domain.Shift, lines []ReportLine) *Report {
+	out := &Report{
+		Kind:         kind,
+		ShiftID:      s.ID,
+		UserID:       s.UserID,
+		Terminal:     s.Terminal,
+		OpeningFloat: s.OpeningFloat,
+		OpenedAt:     s.OpenedAt,
+		ClosedAt:     s.ClosedAt,
+		Lines:        lines,
+	}
+	for _, l := range lines {
+		out.TotalExpected += l.Expected
+		out.TotalCounted += l.Counted
+		out.TotalVariance += l.Variance
+	}
+	out.TotalExpected = roundMoney(out.TotalExpected)
+	out.TotalCounted = roundMoney(out.TotalCounted)
+	out.TotalVariance = roundMoney(out.TotalVariance)
+	return out
+}
+
+func (i *service) OpenShift(ctx context.Context, in OpenInput) (*Item, error) {
+	log := ctxlog.From(ctx)
+
+	terminal := utils.SanitizeString(in.Terminal)
+	if in.UserID == 0 || terminal == "" || in.OpeningFloat < 0 {
+		return nil, apperror.ErrInvalidInput
+	}
+
+	// ผู้ใช้เปิดกะค้างอยู่แล้ว
+	open, err := i.shiftRepo.GetOpenByUser(ctx, in.UserID)
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, err
+	}
+	if open != nil {
+		return nil, apperror.ErrConflict
+	}
+
+	s := &domain.Shift{
+		UserID:       in.UserID,
+		Terminal:     terminal,
+		Status:       domain.ShiftStatusOpen,
+		OpeningFloat: roundMoney(in.OpeningFloat),
+		OpenedAt:     time.Now(),
+	}
+	// เครื่องเดียวกันมีกะเปิดอยู่ -> unique index -> ErrConflict
+	if err := i.shiftRepo.Create(ctx, s); err != nil {
+		return nil, err
+	}
+
+	log.Info("shift.opened", zap.Uint("shift_id", s.ID), zap.Uint("user_id", s.UserID), zap.String("terminal", s.Terminal))
+	return toItem(s), nil
+}
+
+func (i *service) CurrentShift(ctx context.Context, userID uint) (*Item, error) {
+	s, err := i.shiftRepo.GetOpenByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return toItem(s), nil
+}
+
+// getForReport โหลดกะและตรวจสิทธิ์ดูรายงาน (เจ้าของกะ หรือ manager/admin)
+func (i *service) getForReport(ctx context.Context, in ReportInput) (*domain.Shift, error) {
+	s, err := i.shiftRepo.GetByID(ctx, in.ShiftID)
+	if err != nil {
+		return nil, err
+	}
+	if !in.CanViewAll && s.UserID != in.UserID {
+		return nil, apperror.ErrUserForbidden
+	}
+	return s, nil
+}
+
+func (i *service) XReport(ctx context.Context, in ReportInput) (*Report, error) {
+	s, err := i.getForReport(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	if s.Status != domain.ShiftStatusOpen {
+		return nil, apperror.ErrInvalidState
+	}
+
+	expected := expectedTotals(s)
+	lines := make([]ReportLine, 0, len(domain.PaymentMethods))
+	for _, m := range domain.PaymentMethods {
+		lines = append(lines, ReportLine{Method: m, Expected: roundMoney(expected[m])})
+	}
+	return buildReport(ReportX, s, lines), nil
+}
+
+func (i *service) CloseShift(ctx context.Context, in CloseInput) (*Report, error) {
+	log := ctxlog.From(ctx)
+
+	for m, v := range in.Counted {
+		if !paymentMethods[m] || v < 0 {
+			return nil, apperror.ErrInvalidInput
+		}
+	}
+
+	s, err := i.shiftRepo.GetByID(ctx, in.ShiftID)
+	if err != nil {
+		return nil, err
+	}
+	// ปิดได้เฉพาะกะของตัวเอง
+	if s.UserID != in.UserID {
+		return nil, apperror.ErrUserForbidden
+	}
+	if s.Status != domain.ShiftStatusOpen {
+		return nil, apperror.ErrInvalidState
+	}
+
+	expected := expectedTotals(s)
+	totals := make([]domain.ShiftTotal, 0, len(domain.PaymentMethods))
+	lines := make([]ReportLine, 0, len(domain.PaymentMethods))
+	for _, m := range domain.PaymentMethods {
+		e := roundMoney(expected[m])
+		c := roundMoney(in.Counted[m])
+		v := roundMoney(c - e)
+		totals = append(totals, domain.ShiftTotal{Method: m, Expected: e, Counted: c, Variance: v})
+		lines = append(lines, ReportLine{Method: m, Expected: e, Counted: c, Variance: v})
+	}
+
+	now := time.Now()
+	s.Status = domain.ShiftStatusClosed
+	s.ClosedAt = &now
+	s.ClosingNote = strings.TrimSpace(in.Note)
+	s.Totals = totals
+	if err := i.shiftRepo.Close(ctx, s); err != nil {
+		return nil, err
+	}
+
+	report := buildReport(ReportZ, s, lines)
+	log.Info("shift.closed",
+		zap.Uint("shift_id", s.ID),
+		zap.Float64("expected", report.TotalExpected),
+		zap.Float64("counted", report.TotalCounted),
+		zap.Float64("variance", report.TotalVariance),
+	)
+	return report, nil
+}
+
+func (i *service) ZReport(ctx context.Context, in ReportInput) (*Report, error) {
+	s, err := i.getForReport(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	if s.Status != domain.ShiftStatusClosed {
+		return nil, apperror.ErrInvalidState
+	}
+
+	byMethod := make(map[string]domain.ShiftTotal, len(s.Totals))
+	for _, t := range s.Totals {
+		byMethod[t.Method] = t
+	}
+	lines := make([]ReportLine, 0, len(domain.PaymentMethods))
+	for _, m := range domain.PaymentMethods {
+		t := byMethod[m]
+		lines = append(lines, ReportLine{Method: m, Expected: t.Expected, Counted: t.Counted, Variance: t.Variance})
+	}
+	return buildReport(ReportZ, s, lines), nil
+}
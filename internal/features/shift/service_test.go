@@ -0,0 +1,245 @@
+package shift_test
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/features/shift"
+	mocks "ans-spareparts-api/internal/mock"
+	"ans-spareparts-api/pkg/apperror"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type TestSuite struct {
+	Service       shift.Service
+	MockShiftRepo *mocks.ShiftRepository
+	Ctx           context.Context
+}
+
+func NewTestSuite() *TestSuite {
+	return &TestSuite{}
+}
+
+func (ts *TestSuite) SetupTest(t *testing.T) {
+	ts.MockShiftRepo = mocks.NewMockShiftRepository()
+	ts.Service = shift.NewService(ts.MockShiftRepo)
+	ts.Ctx = context.Background()
+
+	t.Cleanup(func() {
+		ts.MockShiftRepo.AssertExpectations(t)
+	})
+}
+
+func openShift() *domain.Shift {
+	return &domain.Shift{
+		ID:           1,
+		UserID:       5,
+		Terminal:     "POS-01",
+		Status:       domain.ShiftStatusOpen,
+		OpeningFloat: 2000,
+		OpenedAt:     time.Now().Add(-8 * time.Hour),
+	}
+}
+
+func TestShiftService_OpenShift(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     shift.OpenInput
+		setup     func(ts *TestSuite)
+		assertErr func(*testing.T, error)
+	}{
+		{
+			name:  "Success_Opened",
+			input: shift.OpenInput{UserID: 5, Terminal: " POS-01 ", OpeningFloat: 2000},
+			setup: func(ts *TestSuite) {
+				ts.MockShiftRepo.On("GetOpenByUser", ts.Ctx, uint(5)).Return(nil, apperror.ErrNotFound).Once()
+				ts.MockShiftRepo.On("Create", ts.Ctx, mock.MatchedBy(func(s *domain.Shift) bool {
+					s.ID = 1
+					return s.Terminal == "POS-01" && s.Status == domain.ShiftStatusOpen
+				})).Return(nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:  "Error_User_Already_Has_Open_Shift",
+			input: shift.OpenInput{UserID: 5, Terminal: "POS-02", OpeningFloat: 0},
+			setup: func(ts *TestSuite) {
+				ts.MockShiftRepo.On("GetOpenByUser", ts.Ctx, uint(5)).Return(openShift(), nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrConflict)
+			},
+		},
+		{
+			name:  "Error_Negative_Float",
+			input: shift.OpenInput{UserID: 5, Terminal: "POS-01", OpeningFloat: -1},
+			setup: func(ts *TestSuite) {},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidInput)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewTestSuite()
+			ts.SetupTest(t)
+
+			test.setup(ts)
+
+			_, err := ts.Service.OpenShift(ts.Ctx, test.input)
+
+			test.assertErr(t, err)
+		})
+	}
+}
+
+func TestShiftService_CloseShift(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     shift.CloseInput
+		setup     func(ts *TestSuite)
+		assertErr func(*testing.T, error)
+		validate  func(*testing.T, *shift.Report)
+	}{
+		{
+			name: "Success_Cash_Short",
+			input: shift.CloseInput{
+				ShiftID: 1,
+				UserID:  5,
+				Counted: map[string]float64{domain.PaymentMethodCash: 1950.25},
+			},
+			setup: func(ts *TestSuite) {
+				ts.MockShiftRepo.On("GetByID", ts.Ctx, uint(1)).Return(openShift(), nil).Once()
+				ts.MockShiftRepo.On("Close", ts.Ctx, mock.MatchedBy(func(s *domain.Shift) bool {
+					return s.Status == domain.ShiftStatusClosed && s.ClosedAt != nil && len(s.Totals) == len(domain.PaymentMethods)
+				})).Return(nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, r *shift.Report) {
+				assert.Equal(t, shift.ReportZ, r.Kind)
+				assert.Equal(t, domain.PaymentMethodCash, r.Lines[0].Method)
+				assert.Equal(t, 2000.0, r.Lines[0].Expected)
+				assert.Equal(t, -49.75, r.Lines[0].Variance)
+				assert.Equal(t, -49.75, r.TotalVariance)
+			},
+		},
+		{
+			name:  "Error_Unknown_Method",
+			input: shift.CloseInput{ShiftID: 1, UserID: 5, Counted: map[string]float64{"cheque": 10}},
+			setup: func(ts *TestSuite) {},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidInput)
+			},
+			validate: func(t *testing.T, r *shift.Report) {
+				assert.Nil(t, r)
+			},
+		},
+		{
+			name:  "Error_Other_Users_Shift",
+			input: shift.CloseInput{ShiftID: 1, UserID: 6},
+			setup: func(ts *TestSuite) {
+				ts.MockShiftRepo.On("GetByID", ts.Ctx, uint(1)).Return(openShift(), nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrUserForbidden)
+			},
+			validate: func(t *testing.T, r *shift.Report) {
+				assert.Nil(t, r)
+			},
+		},
+		{
+			name:  "Error_Already_Closed",
+			input: shift.CloseInput{ShiftID: 1, UserID: 5},
+			setup: func(ts *TestSuite) {
+				s := openShift()
+				s.Status = domain.ShiftStatusClosed
+				ts.MockShiftRepo.On("GetByID", ts.Ctx, uint(1)).Return(s, nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidState)
+			},
+			validate: func(t *testing.T, r *shift.Report) {
+				assert.Nil(t, r)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewTestSuite()
+			ts.SetupTest(t)
+
+			test.setup(ts)
+
+			r, err := ts.Service.CloseShift(ts.Ctx, test.input)
+
+			test.assertErr(t, err)
+			test.validate(t, r)
+		})
+	}
+}
+
+func TestShiftService_XReport(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     shift.ReportInput
+		assertErr func(*testing.T, error)
+		validate  func(*testing.T, *shift.Report)
+	}{
+		{
+			name:  "Success_Owner",
+			input: shift.ReportInput{ShiftID: 1, UserID: 5},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, r *shift.Report) {
+				assert.Equal(t, shift.ReportX, r.Kind)
+				assert.Len(t, r.Lines, len(domain.PaymentMethods))
+				assert.Equal(t, 2000.0, r.TotalExpected)
+				assert.Zero(t, r.TotalCounted)
+			},
+		},
+		{
+			name:  "Success_Manager_Views_Other_Shift",
+			input: shift.ReportInput{ShiftID: 1, UserID: 9, CanViewAll: true},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, r *shift.Report) {
+				assert.Equal(t, uint(5), r.UserID)
+			},
+		},
+		{
+			name:  "Error_Not_Owner",
+			input: shift.ReportInput{ShiftID: 1, UserID: 9},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrUserForbidden)
+			},
+			validate: func(t *testing.T, r *shift.Report) {
+				assert.Nil(t, r)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewTestSuite()
+			ts.SetupTest(t)
+
+			ts.MockShiftRepo.On("GetByID", ts.Ctx, uint(1)).Return(openShift(), nil).Once()
+
+			r, err := ts.Service.XReport(ts.Ctx, test.input)
+
+			test.assertErr(t, err)
+			test.validate(t, r)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package stock
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"math"
+)
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// roundCost ต้นทุนต่อหน่วยเก็บละเอียด 4 ตำแหน่ง (ตรงกับ NUMERIC(14,4))
+func roundCost(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
+
+// movingAverage ต้นทุนถัวเฉลี่ยใหม่หลังรับสินค้าเข้า
+// ถ้าสต็อกเดิมเป็นศูนย์ (หรือติดลบจากการปรับยอดมือ) ให้ใช้ราคารับเข้าครั้งนี้
+func movingAverage(qty int, avg float64, inQty int, inCost float64) float64 {
+	if qty <= 0 {
+		return roundCost(inCost)
+	}
+	total := float64(qty + inQty)
+	return roundCost((float64(qty)*avg + float64(inQty)*inCost) / total)
+}
+
+type layerTake struct {
+	LayerID uint
+	Qty     int
+}
+
+// consumeFIFO ตัด cost layer จากเก่าไปใหม่ (layers ต้องเรียงตาม id แล้ว)
+// คืนรายการที่ตัด ต้นทุนรวมจาก layer และจำนวนที่ไม่มี layer รองรับ
+// (เช่น สต็อกตั้งต้นที่สร้างพร้อมสินค้าโดยไม่มีต้นทุน)
+func consumeFIFO(layers []domain.StockCostLayer, qty int) ([]layerTake, float64, int) {
+	var takes []layerTake
+	var cost float64
+	remaining := qty
+
+	for _, l := range layers {
+		if remaining == 0 {
+			break
+		}
+		if l.QtyRemaining <= 0 {
+			continue
+		}
+		take := min(l.QtyRemaining, remaining)
+		takes = append(takes, layerTake{LayerID: l.ID, Qty: take})
+		cost += float64(take) * l.UnitCost
+		remaining -= take
+	}
+
+	return takes, roundMoney(cost), remaining
+}
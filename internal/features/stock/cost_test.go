@@ -0,0 +1,76 @@
+package stock
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMovingAverage(t *testing.T) {
+	tests := []struct {
+		name     string
+		qty      int
+		avg      float64
+		inQty    int
+		inCost   float64
+		expected float64
+	}{
+		{name: "Empty_Stock_Takes_Receipt_Cost", qty: 0, avg: 0, inQty: 10, inCost: 120, expected: 120},
+		{name: "Weighted_Average", qty: 10, avg: 100, inQty: 30, inCost: 120, expected: 115},
+		{name: "Negative_Stock_Resets_To_Receipt_Cost", qty: -2, avg: 90, inQty: 5, inCost: 110, expected: 110},
+		{name: "Rounded_To_Four_Decimals", qty: 3, avg: 10, inQty: 3, inCost: 10.00005, expected: 10},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, movingAverage(test.qty, test.avg, test.inQty, test.inCost))
+		})
+	}
+}
+
+func TestConsumeFIFO(t *testing.T) {
+	layers := []domain.StockCostLayer{
+		{ID: 1, UnitCost: 100, QtyRemaining: 5},
+		{ID: 2, UnitCost: 0, QtyRemaining: 0},
+		{ID: 3, UnitCost: 120, QtyRemaining: 10},
+	}
+
+	tests := []struct {
+		name      string
+		qty       int
+		takes     []layerTake
+		cost      float64
+		shortfall int
+	}{
+		{
+			name:  "Oldest_Layer_Only",
+			qty:   3,
+			takes: []layerTake{{LayerID: 1, Qty: 3}},
+			cost:  300,
+		},
+		{
+			name:  "Spans_Layers_Skipping_Empty",
+			qty:   8,
+			takes: []layerTake{{LayerID: 1, Qty: 5}, {LayerID: 3, Qty: 3}},
+			cost:  860,
+		},
+		{
+			name:      "Shortfall_Beyond_Layers",
+			qty:       17,
+			takes:     []layerTake{{LayerID: 1, Qty: 5}, {LayerID: 3, Qty: 10}},
+			cost:      1700,
+			shortfall: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			takes, cost, shortfall := consumeFIFO(layers, test.qty)
+
+			assert.Equal(t, test.takes, takes)
+			assert.Equal(t, test.cost, cost)
+			assert.Equal(t, test.shortfall, shortfall)
+		})
+	}
+}
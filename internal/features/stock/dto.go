@@ -0,0 +1,152 @@
+package stock
+
+import (
+	"ans-spareparts-api/pkg/query"
+	"time"
+)
+
+// รูปแบบการคำนวณมูลค่าสต็อก
+const (
+	MethodAverage = "average"
+	MethodFIFO    = "fifo"
+)
+
+// การจัดกลุ่มรายงานมูลค่าสต็อก
+const (
+	GroupByCategory = "category"
+	GroupByLocation = "location"
+)
+
+type ReceiveInput struct {
+	ProductID uint
+	Quantity  int
+	UnitCost  float64
+	Reference string
+	UserID    uint
+}
+
+type IssueInput struct {
+	ProductID uint
+	Quantity  int
+	Reference string
+	UserID    uint
+}
+
+// movementSchema field ที่กรอง/เรียงได้ของ GET /stock/movements
+var movementSchema = query.Schema{
+	Fields: map[string]query.Field{
+		"id":         {Column: "id", Type: query.Int, Ops: []query.Op{query.OpEq, query.OpIn}, Sortable: true},
+		"product_id": {Column: "product_id", Type: query.Int, Ops: []query.Op{query.OpEq, query.OpIn}},
+		"type":       {Column: "type", Type: query.String, Ops: []query.Op{query.OpEq}},
+		"reference":  {Column: "reference", Type: query.String, Ops: []query.Op{query.OpEq, query.OpLike}},
+		"created_at": {Column: "created_at", Type: query.Time, Ops: query.Comparable, Sortable: true},
+	},
+	DefaultSort: []query.Sort{{Column: "id", Desc: true}},
+}
+
+type MovementQuery struct {
+	Limit   int
+	Offset  int
+	Sort    []query.Sort
+	Filters []query.Filter
+}
+
+type ValuationQuery struct {
+	AsOf    time.Time
+	GroupBy string
+	Method  string
+}
+
+// ValuationRow แถวดิบจาก repository: มูลค่าทั้งสองแบบต่อกลุ่ม
+type ValuationRow struct {
+	GroupKey     string
+	Products     int64
+	Quantity     int64
+	AverageValue float64
+	FIFOValue    float64
+}
+
+type MovementItem struct {
+	ID           uint
+	ProductID    uint
+	Type         string
+	Quantity     int
+	UnitCost     float64
+	TotalCost    float64
+	FIFOCost     float64
+	Reference    string
+	QtyAfter     int
+	AvgCostAfter float64
+	CreatedBy    uint
+	CreatedAt    time.Time
+}
+
+type MovementListOutput struct {
+	Items []*MovementItem
+	Total int64
+}
+
+type ValuationLine struct {
+	Group    string
+	Products int64
+	Quantity int64
+	Value    float64
+}
+
+type Valuation struct {
+	AsOf       time.Time
+	GroupBy    string
+	Method     string
+	Lines      []ValuationLine
+	TotalQty   int64
+	TotalValue float64
+}
+
+type ReceiveRequest struct {
+	ProductID uint    `json:"product_id"`
+	Quantity  int     `json:"quantity"`
+	UnitCost  float64 `json:"unit_cost"`
+	Reference string  `json:"reference"`
+}
+
+type IssueRequest struct {
+	ProductID uint   `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+	Reference string `json:"reference"`
+}
+
+type MovementResponse struct {
+	ID           uint      `json:"id"`
+	ProductID    uint      `json:"product_id"`
+	Type         string    `json:"type"`
+	Quantity     int       `json:"quantity"`
+	UnitCost     float64   `json:"unit_cost"`
+	TotalCost    float64   `json:"total_cost"`
+	FIFOCost     float64   `json:"fifo_cost"`
+	Reference    string    `json:"reference,omitempty"`
+	QtyAfter     int       `json:"qty_after"`
+	AvgCostAfter float64   `json:"avg_cost_after"`
+	CreatedBy    uint      `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type MovementListResponse struct {
+	Movements []MovementResponse `json:"movements"`
+	Total     int64              `json:"total"`
+}
+
+type ValuationLineResponse struct {
+	Group    string  `json:"group"`
+	Products int64   `json:"products"`
+	Quantity int64   `json:"quantity"`
+	Value    float64 `json:"value"`
+}
+
+type ValuationResponse struct {
+	AsOf       time.Time               `json:"as_of"`
+	GroupBy    string                  `json:"group_by"`
+	Method     string                  `json:"method"`
+	Lines      []ValuationLineResponse `json:"lines"`
+	TotalQty   int64                   `json:"total_qty"`
+	TotalValue float64                 `json:"total_value"`
+}
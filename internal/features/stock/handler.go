@@ -0,0 +1,216 @@
+package stock
+
+import (
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/internal/infra/jwtx"
+	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/query"
+	"ans-spareparts-api/pkg/response"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+func toMovementResponse(it *MovementItem) MovementResponse {
+	return MovementResponse(*it)
+}
+
+// mapError แปลง error จาก service เป็น response มาตรฐาน
+func mapError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, apperror.ErrInvalidInput):
+		return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid input")
+	case errors.Is(err, apperror.ErrNotFound):
+		return response.Error(c, fiber.StatusNotFound, "NOT_FOUND", "inventory for product not found")
+	case errors.Is(err, apperror.ErrInsufficientStock):
+		return response.Error(c, fiber.StatusUnprocessableEntity, "UNPROCESSABLE", "Insufficient Stock")
+	}
+	return response.Error(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "internal server occured")
+}
+
+// parseAsOf รับได้ทั้ง 2006-01-02 (นับถึงสิ้นวัน) และ RFC3339
+func parseAsOf(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseInLocation(time.DateOnly, s, time.Local); err == nil {
+		return d.Add(24*time.Hour - time.Nanosecond), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// Receive godoc
+// @Summary Receive stock with cost
+// @Description Inbound stock movement; updates moving-average cost and adds a FIFO cost layer (manager only)
+// @Tags stock
+// @Accept json
+// @Produce json
+// @Param request body ReceiveRequest true "Receive request"
+// @Success 201 {object} MovementResponse
+// @Failure 400 {object} response.ErrorBody
+// @Failure 404 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /stock/receive [post]
+func (h *Handler) Receive(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+	userClaims := c.Locals("user").(*jwtx.Claims)
+
+	var req ReceiveRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Warn("handler.stock.receive.invalid_body", zap.Error(err))
+		return response.Error(
+			c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid request body",
+		)
+	}
+
+	m, err := h.service.Receive(ctx, ReceiveInput{
+		ProductID: req.ProductID,
+		Quantity:  req.Quantity,
+		UnitCost:  req.UnitCost,
+		Reference: req.Reference,
+		UserID:    userClaims.UserID,
+	})
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	return response.Created(c, toMovementResponse(m))
+}
+
+// Issue godoc
+// @Summary Issue stock
+// @Description Outbound stock movement; consumes FIFO cost layers and records COGS (manager only)
+// @Tags stock
+// @Accept json
+// @Produce json
+// @Param request body IssueRequest true "Issue request"
+// @Success 201 {object} MovementResponse
+// @Failure 400 {object} response.ErrorBody
+// @Failure 404 {object} response.ErrorBody
+// @Failure 422 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /stock/issue [post]
+func (h *Handler) Issue(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+	userClaims := c.Locals("user").(*jwtx.Claims)
+
+	var req IssueRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Warn("handler.stock.issue.invalid_body", zap.Error(err))
+		return response.Error(
+			c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid request body",
+		)
+	}
+
+	m, err := h.service.Issue(ctx, IssueInput{
+		ProductID: req.ProductID,
+		Quantity:  req.Quantity,
+		Reference: req.Reference,
+		UserID:    userClaims.UserID,
+	})
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	return response.Created(c, toMovementResponse(m))
+}
+
+// ListMovements godoc
+// @Summary List stock movements
+// @Description Stock ledger, newest first (manager only)
+// @Tags stock
+// @Produce json
+// @Param product_id query int false "Product ID"
+// @Param type query string false "receive|issue"
+// @Param sort query string false "Comma separated, '-' prefix for DESC: id,created_at"
+// @Param limit query int false "Limit" default(10)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} MovementListResponse
+// @Failure 400 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /stock/movements [get]
+func (h *Handler) ListMovements(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+
+	p, err := query.FromCtx(c, movementSchema)
+	if err != nil {
+		log.Warn("handler.stock.movements.invalid_input", zap.Error(err))
+		return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", err.Error())
+	}
+
+	out, err := h.service.ListMovements(ctx, MovementQuery{
+		Limit:   p.Limit,
+		Offset:  p.Offset,
+		Sort:    p.Sort,
+		Filters: p.Filters,
+	})
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	res := make([]MovementResponse, len(out.Items))
+	for i, it := range out.Items {
+		res[i] = toMovementResponse(it)
+	}
+	return response.OK(c, MovementListResponse{Movements: res, Total: out.Total})
+}
+
+// Valuation godoc
+// @Summary Stock valuation report
+// @Description Stock value as of a date, grouped by category or location (manager only)
+// @Tags stock
+// @Produce json
+// @Param as_of query string false "Date (YYYY-MM-DD) or RFC3339, default now"
+// @Param group_by query string false "category | location" default(category)
+// @Param method query string false "average | fifo" default(average)
+// @Success 200 {object} ValuationResponse
+// @Failure 400 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /stock/valuation [get]
+func (h *Handler) Valuation(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+
+	asOf, err := parseAsOf(c.Query("as_of"))
+	if err != nil {
+		log.Warn("handler.stock.valuation.invalid_as_of", zap.Error(err))
+		return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid as_of request")
+	}
+
+	v, err := h.service.Valuation(ctx, ValuationQuery{
+		AsOf:    asOf,
+		GroupBy: c.Query("group_by"),
+		Method:  c.Query("method"),
+	})
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	lines := make([]ValuationLineResponse, len(v.Lines))
+	for i, l := range v.Lines {
+		lines[i] = ValuationLineResponse(l)
+	}
+	return response.OK(c, ValuationResponse{
+		AsOf:       v.AsOf,
+		GroupBy:    v.GroupBy,
+		Method:     v.Method,
+		Lines:      lines,
+		TotalQty:   v.TotalQty,
+		TotalValue: v.TotalValue,
+	})
+}
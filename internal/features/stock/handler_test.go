@@ -0,0 +1,151 @@
+package stock_test
+
+import (
+	"ans-spareparts-api/internal/features/stock"
+	"ans-spareparts-api/internal/infra/jwtx"
+	mocks "ans-spareparts-api/internal/mock"
+	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/response"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type HandlerTestSuite struct {
+	App         *fiber.App
+	MockService *mocks.StockService
+	Handler     *stock.Handler
+}
+
+func NewHandlerTestSuite() *HandlerTestSuite {
+	return &HandlerTestSuite{}
+}
+
+func (ts *HandlerTestSuite) SetUpHandlerTestSuite(t *testing.T) {
+	ts.MockService = mocks.NewStockService()
+	ts.Handler = stock.NewHandler(ts.MockService)
+	ts.App = fiber.New(fiber.Config{
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			return response.Error(c, fiber.StatusBadRequest, "INVALID_REQUEST", "invalid request body: "+err.Error())
+		},
+	})
+
+	t.Cleanup(func() {
+		ts.MockService.AssertExpectations(t)
+	})
+}
+
+func withUser(c *fiber.Ctx) error {
+	c.Locals("user", &jwtx.Claims{UserID: 5, Username: "Test", Role: "manager"})
+	return c.Next()
+}
+
+func TestStockHandler_Issue(t *testing.T) {
+	mockInput := stock.IssueInput{ProductID: 1, Quantity: 3, Reference: "INV-1", UserID: 5}
+
+	tests := []struct {
+		name           string
+		setup          func(*HandlerTestSuite)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name: "Success_Issued",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("Issue", mock.Anything, mockInput).Return(&stock.MovementItem{ID: 7, ProductID: 1, Type: "issue", Quantity: 3}, nil).Once()
+			},
+			expectedStatus: fiber.StatusCreated,
+		},
+		{
+			name: "Error_Insufficient_Stock",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("Issue", mock.Anything, mockInput).Return(nil, apperror.ErrInsufficientStock).Once()
+			},
+			expectedStatus: fiber.StatusUnprocessableEntity,
+			expectedBody: fiber.Map{
+				"code":    "UNPROCESSABLE",
+				"message": "Insufficient Stock",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewHandlerTestSuite()
+			ts.SetUpHandlerTestSuite(t)
+
+			ts.App.Post("/stock/issue", withUser, ts.Handler.Issue)
+			test.setup(ts)
+
+			body, _ := json.Marshal(stock.IssueRequest{ProductID: 1, Quantity: 3, Reference: "INV-1"})
+			req := httptest.NewRequest(fiber.MethodPost, "/stock/issue", bytes.NewBuffer(body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+			res, _ := ts.App.Test(req, -1)
+
+			assert.Equal(t, test.expectedStatus, res.StatusCode)
+			if test.expectedBody != nil {
+				resBody, _ := io.ReadAll(res.Body)
+				expectedBody, _ := json.Marshal(test.expectedBody)
+				assert.JSONEq(t, string(expectedBody), string(resBody))
+			}
+		})
+	}
+}
+
+func TestStockHandler_Valuation(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		setup          func(*HandlerTestSuite)
+		expectedStatus int
+	}{
+		{
+			name: "Success_As_Of_Date_End_Of_Day",
+			path: "/stock/valuation?as_of=2026-01-31&group_by=location&method=fifo",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("Valuation", mock.Anything, mock.MatchedBy(func(q stock.ValuationQuery) bool {
+					return q.AsOf.Format(time.DateOnly) == "2026-01-31" && q.AsOf.Hour() == 23 &&
+						q.GroupBy == "location" && q.Method == "fifo"
+				})).Return(&stock.Valuation{GroupBy: "location", Method: "fifo"}, nil).Once()
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "Error_Invalid_As_Of",
+			path:           "/stock/valuation?as_of=31-01-2026",
+			setup:          func(hts *HandlerTestSuite) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "Error_Invalid_Group",
+			path: "/stock/valuation?group_by=brand",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("Valuation", mock.Anything, mock.Anything).Return(nil, apperror.ErrInvalidInput).Once()
+			},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewHandlerTestSuite()
+			ts.SetUpHandlerTestSuite(t)
+
+			ts.App.Get("/stock/valuation", ts.Handler.Valuation)
+			test.setup(ts)
+
+			req := httptest.NewRequest(fiber.MethodGet, test.path, nil)
+			res, _ := ts.App.Test(req, -1)
+
+			assert.Equal(t, test.expectedStatus, res.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,422 @@
+package stock
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/features/inventory"
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/internal/infra/outbox"
+	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/query"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	// Receive รับสินค้าเข้า: ปรับต้นทุนเฉลี่ย สร้าง cost layer และบันทึก movement ใน transaction เดียว
+	Receive(ctx context.Context, m *domain.StockMovement) error
+	// Issue ตัดสต็อกออก: ตัด cost layer แบบ FIFO และบันทึกต้นทุนทั้งสองแบบลง movement
+	Issue(ctx context.Context, m *domain.StockMovement) error
+	// Adjust ปรับยอดมือของ inventory id ที่ต้นทุนเฉลี่ย ณ ขณะนั้น (m.Quantity มีเครื่องหมาย)
+	// เพิ่ม: สร้าง cost layer ที่ต้นทุนเฉลี่ย / ลด: ตัด cost layer แบบ FIFO เหมือน Issue
+	// version ไม่เป็น nil ต้องเท่ากับของแถวที่ล็อก ไม่งั้นได้ ErrVersionConflict; คืนแถวหลัง commit
+	Adjust(ctx context.Context, m *domain.StockMovement, inventoryID uint, version *uint) (*domain.Inventory, error)
+
+	ListMovements(ctx context.Context, q MovementQuery) ([]*domain.StockMovement, int64, error)
+	// Valuation มูลค่าสต็อก ณ เวลา asOf จาก movement ล่าสุดของแต่ละสินค้า (ยังไม่มี movement ใช้ยอดตั้งต้น)
+	Valuation(ctx context.Context, asOf time.Time, groupBy string) ([]ValuationRow, error)
+}
+
+type repository struct {
+	db  *gorm.DB
+	rdb *redis.Client
+}
+
+func NewRepository(db *gorm.DB, rdb *redis.Client) Repository {
+	return &repository{db: db, rdb: rdb}
+}
+
+// invalidateInventory ลบ cache ของ inventory หลังยอดเปลี่ยน ทั้ง key ตาม id และตาม product_id
+func (r *repository) invalidateInventory(ctx context.Context, inv *domain.Inventory) {
+	if err := inventory.DeleteCache(ctx, r.rdb, inv); err != nil {
+		ctxlog.From(ctx).Warn("repo.stock.cache.del_error", zap.Uint("inventory_id", inv.ID), zap.Uint("product_id", inv.ProductID), zap.Error(err))
+	}
+}
+
+// lockInventory SELECT ... FOR UPDATE แถว inventory ของสินค้า
+func lockInventory(tx *gorm.DB, productID uint) (*domain.Inventory, error) {
+	var inv domain.Inventory
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ?", productID).
+		First(&inv).Error; err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// issueLayers ตัด cost layer ของสินค้าแบบ FIFO คืนต้นทุนจาก layer และจำนวนที่ไม่มี layer รองรับ
+func issueLayers(tx *gorm.DB, productID uint, qty int) (float64, int, error) {
+	var layers []domain.StockCostLayer
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ? AND qty_remaining > 0", productID).
+		Order("id ASC").
+		Find(&layers).Error; err != nil {
+		return 0, 0, err
+	}
+
+	takes, layerCost, shortfall := consumeFIFO(layers, qty)
+	for _, t := range takes {
+		if err := tx.Model(&domain.StockCostLayer{}).
+			Where("id = ?", t.LayerID).
+			Update("qty_remaining", gorm.Expr("qty_remaining - ?", t.Qty)).Error; err != nil {
+			return 0, 0, err
+		}
+	}
+	return layerCost, shortfall, nil
+}
+
+// enqueueQuantityChanged เขียน event ยอดคงเหลือเปลี่ยนลง outbox ใน transaction เดียวกับ movement
+func enqueueQuantityChanged(tx *gorm.DB, inv *domain.Inventory, m *domain.StockMovement, delta int, source string) error {
+	return outbox.Enqueue(tx, domain.InventoryQuantityChanged{
+		ProductID:   m.ProductID,
+		InventoryID: inv.ID,
+		Delta:       delta,
+		Quantity:    m.QtyAfter,
+		Location:    inv.Location,
+		Version:     inv.Version + 1,
+		Source:      source,
+		Reference:   m.Reference,
+	})
+}
+
+// fifoValue มูลค่าคงเหลือของ cost layer ทั้งหมดของสินค้า
+func fifoValue(tx *gorm.DB, productID uint) (float64, error) {
+	var v float64
+	err := tx.Model(&domain.StockCostLayer{}).
+		Where("product_id = ? AND qty_remaining > 0", productID).
+		Select("COALESCE(SUM(qty_remaining * unit_cost), 0)").
+		Scan(&v).Error
+	return v, err
+}
+
+func (r *repository) Receive(ctx context.Context, m *domain.StockMovement) error {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	var inv *domain.Inventory
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		inv, err = lockInventory(tx, m.ProductID)
+		if err != nil {
+			return err
+		}
+		prevFIFO, err := fifoValue(tx, m.ProductID)
+		if err != nil {
+			return err
+		}
+
+		avg := movingAverage(inv.Quantity, inv.AvgCost, m.Quantity, m.UnitCost)
+		qtyAfter := inv.Quantity + m.Quantity
+
+		m.Type = domain.StockMoveReceive
+		m.TotalCost = roundMoney(float64(m.Quantity) * m.UnitCost)
+		m.FIFOCost = m.TotalCost
+		m.QtyAfter = qtyAfter
+		m.AvgCostAfter = avg
+		m.ValueAfter = roundMoney(float64(qtyAfter) * avg)
+		m.FIFOValueAfter = roundMoney(prevFIFO + m.TotalCost)
+		if err := tx.Create(m).Error; err != nil {
+			return err
+		}
+
+		layer := domain.StockCostLayer{
+			ProductID:    m.ProductID,
+			MovementID:   m.ID,
+			UnitCost:     m.UnitCost,
+			QtyReceived:  m.Quantity,
+			QtyRemaining: m.Quantity,
+		}
+		if err := tx.Create(&layer).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&domain.Inventory{}).
+			Where("product_id = ?", m.ProductID).
+			Updates(map[string]any{
+				"quantity": qtyAfter,
+				"avg_cost": avg,
+				"version":  gorm.Expr("version + 1"),
+			}).Error; err != nil {
+			return err
+		}
+		return enqueueQuantityChanged(tx, inv, m, m.Quantity, domain.QuantitySourceReceive)
+	})
+	if err != nil {
+		mapped := apperror.MapDBError("repo.stock.receive", err)
+		log.Debug("repo.stock.receive.fail", zap.Error(err), zap.Duration("duration", time.Since(start)))
+		return mapped
+	}
+	r.invalidateInventory(ctx, inv)
+
+	log.Info("repo.stock.receive.ok", zap.Uint("product_id", m.ProductID), zap.Int("quantity", m.Quantity), zap.Duration("duration", time.Since(start)))
+	return nil
+}
+
+func (r *repository) Issue(ctx context.Context, m *domain.StockMovement) error {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	var inv *domain.Inventory
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		inv, err = lockInventory(tx, m.ProductID)
+		if err != nil {
+			return err
+		}
+		if inv.Quantity < m.Quantity {
+			return apperror.ErrInsufficientStock
+		}
+		prevFIFO, err := fifoValue(tx, m.ProductID)
+		if err != nil {
+			return err
+		}
+
+		layerCost, shortfall, err := issueLayers(tx, m.ProductID, m.Quantity)
+		if err != nil {
+			return err
+		}
+
+		qtyAfter := inv.Quantity - m.Quantity
+
+		m.Type = domain.StockMoveIssue
+		m.UnitCost = inv.AvgCost
+		m.TotalCost = roundMoney(float64(m.Quantity) * inv.AvgCost)
+		// ส่วนที่ไม่มี layer รองรับ คิดด้วยต้นทุนเฉลี่ย
+		m.FIFOCost = roundMoney(layerCost + float64(shortfall)*inv.AvgCost)
+		m.QtyAfter = qtyAfter
+		m.AvgCostAfter = inv.AvgCost
+		m.ValueAfter = roundMoney(float64(qtyAfter) * inv.AvgCost)
+		m.FIFOValueAfter = roundMoney(prevFIFO - layerCost)
+		if err := tx.Create(m).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&domain.Inventory{}).
+			Where("product_id = ?", m.ProductID).
+			Updates(map[string]any{
+				"quantity": qtyAfter,
+				"version":  gorm.Expr("version + 1"),
+			}).Error; err != nil {
+			return err
+		}
+		return enqueueQuantityChanged(tx, inv, m, -m.Quantity, domain.QuantitySourceIssue)
+	})
+	if err != nil {
+		if errors.Is(err, apperror.ErrInsufficientStock) {
+			return err
+		}
+		mapped := apperror.MapDBError("repo.stock.issue", err)
+		log.Debug("repo.stock.issue.fail", zap.Error(err), zap.Duration("duration", time.Since(start)))
+		return mapped
+	}
+	r.invalidateInventory(ctx, inv)
+
+	log.Info("repo.stock.issue.ok", zap.Uint("product_id", m.ProductID), zap.Int("quantity", m.Quantity), zap.Duration("duration", time.Since(start)))
+	return nil
+}
+
+func (r *repository) Adjust(ctx context.Context, m *domain.StockMovement, inventoryID uint, version *uint) (*domain.Inventory, error) {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	var inv domain.Inventory
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&inv, inventoryID).Error; err != nil {
+			return err
+		}
+		if version != nil && inv.Version != *version {
+			return apperror.ErrVersionConflict
+		}
+		if inv.Quantity+m.Quantity < 0 {
+			return apperror.ErrInsufficientStock
+		}
+		prevFIFO, err := fifoValue(tx, inv.ProductID)
+		if err != nil {
+			return err
+		}
+
+		qtyAfter := inv.Quantity + m.Quantity
+
+		m.ProductID = inv.ProductID
+		m.Type = domain.StockMoveAdjust
+		m.UnitCost = inv.AvgCost
+		m.TotalCost = roundMoney(float64(m.Quantity) * inv.AvgCost)
+		m.QtyAfter = qtyAfter
+		m.AvgCostAfter = inv.AvgCost
+		m.ValueAfter = roundMoney(float64(qtyAfter) * inv.AvgCost)
+		if m.Quantity > 0 {
+			m.FIFOCost = m.TotalCost
+			m.FIFOValueAfter = roundMoney(prevFIFO + m.TotalCost)
+		} else {
+			layerCost, shortfall, err := issueLayers(tx, inv.ProductID, -m.Quantity)
+			if err != nil {
+				return err
+			}
+			// ส่วนที่ไม่มี layer รองรับ คิดด้วยต้นทุนเฉลี่ย เหมือน Issue
+			m.FIFOCost = -roundMoney(layerCost + float64(shortfall)*inv.AvgCost)
+			m.FIFOValueAfter = roundMoney(prevFIFO - layerCost)
+		}
+		if err := tx.Create(m).Error; err != nil {
+			return err
+		}
+
+		if m.Quantity > 0 {
+			layer := domain.StockCostLayer{
+				ProductID:    inv.ProductID,
+				MovementID:   m.ID,
+				UnitCost:     inv.AvgCost,
+				QtyReceived:  m.Quantity,
+				QtyRemaining: m.Quantity,
+			}
+			if err := tx.Create(&layer).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := enqueueQuantityChanged(tx, &inv, m, m.Quantity, domain.QuantitySourceAdjust); err != nil {
+			return err
+		}
+		// RETURNING เติมค่าที่บันทึกจริงกลับเข้า inv
+		return tx.Model(&inv).Clauses(clause.Returning{}).
+			Updates(map[string]any{
+				"quantity": qtyAfter,
+				"version":  gorm.Expr("version + 1"),
+			}).Error
+	})
+	if err != nil {
+		if errors.Is(err, apperror.ErrVersionConflict) || errors.Is(err, apperror.ErrInsufficientStock) {
+			return nil, err
+		}
+		mapped := apperror.MapDBError("repo.stock.adjust", err)
+		log.Debug("repo.stock.adjust.fail", zap.Uint("inventory_id", inventoryID), zap.Error(err), zap.Duration("duration", time.Since(start)))
+		return nil, mapped
+	}
+	r.invalidateInventory(ctx, &inv)
+
+	log.Info("repo.stock.adjust.ok", zap.Uint("product_id", m.ProductID), zap.Int("quantity", m.Quantity), zap.Duration("duration", time.Since(start)))
+	return &inv, nil
+}
+
+func (r *repository) ListMovements(ctx context.Context, q MovementQuery) ([]*domain.StockMovement, int64, error) {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	tx := r.db.WithContext(ctx).Model(&domain.StockMovement{})
+	tx = query.Where(tx, q.Filters)
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		m := apperror.MapDBError("repo.stock.listMovements.count", err)
+		log.Debug("repo.stock.listMovements.count_fail", zap.Error(err))
+		return nil, 0, m
+	}
+
+	var rows []*domain.StockMovement
+	tx = query.Order(tx, movementSchema.SortOrDefault(q.Sort))
+	if err := tx.Limit(q.Limit).Offset(q.Offset).Find(&rows).Error; err != nil {
+		m := apperror.MapDBError("repo.stock.listMovements", err)
+		log.Debug("repo.stock.listMovements.db_fail", zap.Error(err), zap.Duration("duration", time.Since(start)))
+		return nil, 0, m
+	}
+
+	log.Debug("repo.stock.listMovements.ok", zap.Int64("total", total), zap.Duration("duration", time.Since(start)))
+	return rows, total, nil
+}
+
+// groupColumns whitelist ของคอลัมน์ที่ใช้จัดกลุ่ม (ห้ามต่อ string จาก input ตรงๆ)
+var groupColumns = map[string]string{
+	GroupByCategory: "COALESCE(c.name, '')",
+	GroupByLocation: "COALESCE(i.location, '')",
+}
+
+func (r *repository) Valuation(ctx context.Context, asOf time.Time, groupBy string) ([]ValuationRow, error) {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	col, ok := groupColumns[groupBy]
+	if !ok {
+		return nil, apperror.ErrInvalidInput
+	}
+
+	// movement ล่าสุดของแต่ละสินค้า ณ เวลา asOf คือยอดคงเหลือและมูลค่า ณ ตอนนั้น
+	// สินค้าที่ยังไม่มี movement ณ asOf ใช้ยอดตั้งต้นแทน: ก่อน movement แรก หรือยอดใน inventories ถ้ายังไม่เคยมีเลย
+	// (ต้นทุนเฉลี่ยเปลี่ยนได้ทางการรับเข้าเท่านั้น ยอดก่อน movement แรกที่เป็น receive จึงมีต้นทุน 0
+	// ส่วน issue/adjust บันทึกต้นทุนเฉลี่ย ณ ขณะนั้นไว้ใน unit_cost)
+	query := `
+WITH last AS (
+    SELECT DISTINCT ON (m.product_id) m.product_id, m.qty_after, m.value_after, m.fifo_value_after
+    FROM stock_movements m
+    WHERE m.created_at <= ?
+    ORDER BY m.product_id, m.created_at DESC, m.id DESC
+),
+first AS (
+    SELECT DISTINCT ON (m.product_id) m.product_id, m.type, m.quantity, m.unit_cost, m.qty_after
+    FROM stock_movements m
+    ORDER BY m.product_id, m.created_at, m.id
+),
+opening AS (
+    SELECT o.product_id, o.qty_after, ROUND(o.qty_after * o.avg_cost, 2) AS value_after
+    FROM (
+        SELECT i.product_id,
+            CASE
+                WHEN f.product_id IS NULL THEN i.quantity
+                WHEN f.type = 'issue' THEN f.qty_after + f.quantity
+                ELSE f.qty_after - f.quantity
+            END AS qty_after,
+            CASE
+                WHEN f.product_id IS NULL THEN i.avg_cost
+                WHEN f.type = 'receive' THEN 0
+                ELSE f.unit_cost
+            END AS avg_cost
+        FROM inventories i
+        LEFT JOIN first f ON f.product_id = i.product_id
+        WHERE i.created_at <= ?
+            AND (i.deleted_at IS NULL OR i.deleted_at > ?)
+            AND NOT EXISTS (SELECT 1 FROM last WHERE last.product_id = i.product_id)
+    ) o
+    WHERE o.qty_after <> 0
+),
+stock AS (
+    SELECT product_id, qty_after, value_after, fifo_value_after FROM last
+    UNION ALL
+    -- ยอดตั้งต้นไม่มี cost layer ตอนตัดออกคิดด้วยต้นทุนเฉลี่ย มูลค่า FIFO จึงเท่ากับแบบถัวเฉลี่ย
+    SELECT product_id, qty_after, value_after, value_after FROM opening
+)
+SELECT ` + col + ` AS group_key,
+    COUNT(*) AS products,
+    COALESCE(SUM(stock.qty_after), 0) AS quantity,
+    COALESCE(SUM(stock.value_after), 0) AS average_value,
+    COALESCE(SUM(stock.fifo_value_after), 0) AS fifo_value
+FROM stock
+JOIN products p ON p.id = stock.product_id
+LEFT JOIN categories c ON c.id = p.category_id
+LEFT JOIN inventories i ON i.product_id = stock.product_id
+GROUP BY 1
+ORDER BY 1`
+
+	var rows []ValuationRow
+	if err := r.db.WithContext(ctx).Raw(query, asOf, asOf, asOf).Scan(&rows).Error; err != nil {
+		m := apperror.MapDBError("repo.stock.valuation", err)
+		log.Debug("repo.stock.valuation.db_fail", zap.Error(err), zap.Duration("duration", time.Since(start)))
+		return nil, m
+	}
+
+	log.Debug("repo.stock.valuation.ok", zap.Int("groups", len(rows)), zap.Duration("duration", time.Since(start)))
+	return rows, nil
+}
@@ -0,0 +1,169 @@
+package stock
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/utils"
+	"context"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type Service interface {
+	// Receive รับสินค้าเข้าพร้อมราคาทุน
+	Receive(ctx context.Context, in ReceiveInput) (*MovementItem, error)
+	// Issue ตัดสต็อกออก คืน movement ที่มีต้นทุนขาย (COGS) ทั้งแบบถัวเฉลี่ยและ FIFO
+	Issue(ctx context.Context, in IssueInput) (*MovementItem, error)
+	ListMovements(ctx context.Context, q MovementQuery) (*MovementListOutput, error)
+	// Valuation รายงานมูลค่าสต็อก ณ วันที่ จัดกลุ่มตามหมวดหมู่หรือตำแหน่งจัดเก็บ
+	Valuation(ctx context.Context, q ValuationQuery) (*Valuation, error)
+}
+
+type service struct {
+	stockRepo Repository
+}
+
+func NewService(stockRepo Repository) Service {
+	return &service{
+		stockRepo: stockRepo,
+	}
+}
+
+func toMovementItem(m *domain.StockMovement) *MovementItem {
+	return &MovementItem{
+		ID:           m.ID,
+		ProductID:    m.ProductID,
+		Type:         m.Type,
+		Quantity:     m.Quantity,
+		UnitCost:     m.UnitCost,
+		TotalCost:    m.TotalCost,
+		FIFOCost:     m.FIFOCost,
+		Reference:    m.Reference,
+		QtyAfter:     m.QtyAfter,
+		AvgCostAfter: m.AvgCostAfter,
+		CreatedBy:    m.CreatedBy,
+		CreatedAt:    m.CreatedAt,
+	}
+}
+
+func (i *service) Receive(ctx context.Context, in ReceiveInput) (*MovementItem, error) {
+	log := ctxlog.From(ctx)
+
+	if in.ProductID == 0 || in.Quantity <= 0 || in.UnitCost < 0 {
+		return nil, apperror.ErrInvalidInput
+	}
+
+	m := &domain.StockMovement{
+		ProductID: in.ProductID,
+		Quantity:  in.Quantity,
+		UnitCost:  roundCost(in.UnitCost),
+		Reference: strings.TrimSpace(in.Reference),
+		CreatedBy: in.UserID,
+	}
+	if err := i.stockRepo.Receive(ctx, m); err != nil {
+		return nil, err
+	}
+
+	log.Info("stock.received",
+		zap.Uint("product_id", m.ProductID),
+		zap.Int("quantity", m.Quantity),
+		zap.Float64("unit_cost", m.UnitCost),
+		zap.Float64("avg_cost_after", m.AvgCostAfter),
+	)
+	return toMovementItem(m), nil
+}
+
+func (i *service) Issue(ctx context.Context, in IssueInput) (*MovementItem, error) {
+	log := ctxlog.From(ctx)
+
+	if in.ProductID == 0 || in.Quantity <= 0 {
+		return nil, apperror.ErrInvalidInput
+	}
+
+	m := &domain.StockMovement{
+		ProductID: in.ProductID,
+		Quantity:  in.Quantity,
+		Reference: strings.TrimSpace(in.Reference),
+		CreatedBy: in.UserID,
+	}
+	if err := i.stockRepo.Issue(ctx, m); err != nil {
+		return nil, err
+	}
+
+	log.Info("stock.issued",
+		zap.Uint("product_id", m.ProductID),
+		zap.Int("quantity", m.Quantity),
+		zap.Float64("cogs_average", m.TotalCost),
+		zap.Float64("cogs_fifo", m.FIFOCost),
+	)
+	return toMovementItem(m), nil
+}
+
+func (i *service) ListMovements(ctx context.Context, q MovementQuery) (*MovementListOutput, error) {
+	limit, offset := utils.NormalizePagination(q.Limit, q.Offset)
+	rows, total, err := i.stockRepo.ListMovements(ctx, MovementQuery{
+		Limit:   limit,
+		Offset:  offset,
+		Sort:    q.Sort,
+		Filters: q.Filters,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*MovementItem, len(rows))
+	for idx, m := range rows {
+		items[idx] = toMovementItem(m)
+	}
+	return &MovementListOutput{Items: items, Total: total}, nil
+}
+
+func (i *service) Valuation(ctx context.Context, q ValuationQuery) (*Valuation, error) {
+	if q.GroupBy == "" {
+		q.GroupBy = GroupByCategory
+	}
+	if q.Method == "" {
+		q.Method = MethodAverage
+	}
+	if q.GroupBy != GroupByCategory && q.GroupBy != GroupByLocation {
+		return nil, apperror.ErrInvalidInput
+	}
+	if q.Method != MethodAverage && q.Method != MethodFIFO {
+		return nil, apperror.ErrInvalidInput
+	}
+	if q.AsOf.IsZero() {
+		q.AsOf = time.Now()
+	}
+
+	rows, err := i.stockRepo.Valuation(ctx, q.AsOf, q.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &Valuation{
+		AsOf:    q.AsOf,
+		GroupBy: q.GroupBy,
+		Method:  q.Method,
+		Lines:   make([]ValuationLine, 0, len(rows)),
+	}
+	for _, r := range rows {
+		value := r.AverageValue
+		if q.Method == MethodFIFO {
+			value = r.FIFOValue
+		}
+		out.Lines = append(out.Lines, ValuationLine{
+			Group:    r.GroupKey,
+			Products: r.Products,
+			Quantity: r.Quantity,
+			Value:    roundMoney(value),
+		})
+		out.TotalQty += r.Quantity
+		out.TotalValue += value
+	}
+	out.TotalValue = roundMoney(out.TotalValue)
+
+	return out, nil
+}
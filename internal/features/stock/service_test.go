@@ -0,0 +1,236 @@
+package stock_test
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/features/stock"
+	mocks "ans-spareparts-api/internal/mock"
+	"ans-spareparts-api/pkg/apperror"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type TestSuite struct {
+	Service       stock.Service
+	MockStockRepo *mocks.StockRepository
+	Ctx           context.Context
+}
+
+func NewTestSuite() *TestSuite {
+	return &TestSuite{}
+}
+
+func (ts *TestSuite) SetupTest(t *testing.T) {
+	ts.MockStockRepo = mocks.NewMockStockRepository()
+	ts.Service = stock.NewService(ts.MockStockRepo)
+	ts.Ctx = context.Background()
+
+	t.Cleanup(func() {
+		ts.MockStockRepo.AssertExpectations(t)
+	})
+}
+
+func TestStockService_Receive(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     stock.ReceiveInput
+		setup     func(ts *TestSuite)
+		assertErr func(*testing.T, error)
+		validate  func(*testing.T, *stock.MovementItem)
+	}{
+		{
+			name:  "Success_Received",
+			input: stock.ReceiveInput{ProductID: 1, Quantity: 10, UnitCost: 120.123456, Reference: " PO-001 ", UserID: 5},
+			setup: func(ts *TestSuite) {
+				ts.MockStockRepo.On("Receive", ts.Ctx, mock.MatchedBy(func(m *domain.StockMovement) bool {
+					m.Type = domain.StockMoveReceive
+					m.QtyAfter = 10
+					m.AvgCostAfter = m.UnitCost
+					return m.ProductID == 1 && m.UnitCost == 120.1235 && m.Reference == "PO-001" && m.CreatedBy == 5
+				})).Return(nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, it *stock.MovementItem) {
+				assert.Equal(t, domain.StockMoveReceive, it.Type)
+				assert.Equal(t, 10, it.QtyAfter)
+			},
+		},
+		{
+			name:  "Error_Zero_Quantity",
+			input: stock.ReceiveInput{ProductID: 1, Quantity: 0, UnitCost: 10},
+			setup: func(ts *TestSuite) {},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidInput)
+			},
+		},
+		{
+			name:  "Error_Negative_Cost",
+			input: stock.ReceiveInput{ProductID: 1, Quantity: 1, UnitCost: -1},
+			setup: func(ts *TestSuite) {},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidInput)
+			},
+		},
+		{
+			name:  "Error_Inventory_Not_Found",
+			input: stock.ReceiveInput{ProductID: 99, Quantity: 1, UnitCost: 10},
+			setup: func(ts *TestSuite) {
+				ts.MockStockRepo.On("Receive", ts.Ctx, mock.Anything).Return(apperror.ErrNotFound).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrNotFound)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewTestSuite()
+			ts.SetupTest(t)
+			test.setup(ts)
+
+			it, err := ts.Service.Receive(ts.Ctx, test.input)
+
+			test.assertErr(t, err)
+			if test.validate != nil {
+				test.validate(t, it)
+			}
+		})
+	}
+}
+
+func TestStockService_Issue(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     stock.IssueInput
+		setup     func(ts *TestSuite)
+		assertErr func(*testing.T, error)
+		validate  func(*testing.T, *stock.MovementItem)
+	}{
+		{
+			name:  "Success_Issued_With_COGS",
+			input: stock.IssueInput{ProductID: 1, Quantity: 3, Reference: "INV-1"},
+			setup: func(ts *TestSuite) {
+				ts.MockStockRepo.On("Issue", ts.Ctx, mock.MatchedBy(func(m *domain.StockMovement) bool {
+					m.TotalCost = 345
+					m.FIFOCost = 300
+					return m.ProductID == 1 && m.Quantity == 3
+				})).Return(nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, it *stock.MovementItem) {
+				assert.Equal(t, 345.0, it.TotalCost)
+				assert.Equal(t, 300.0, it.FIFOCost)
+			},
+		},
+		{
+			name:  "Error_Insufficient_Stock",
+			input: stock.IssueInput{ProductID: 1, Quantity: 100},
+			setup: func(ts *TestSuite) {
+				ts.MockStockRepo.On("Issue", ts.Ctx, mock.Anything).Return(apperror.ErrInsufficientStock).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInsufficientStock)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewTestSuite()
+			ts.SetupTest(t)
+			test.setup(ts)
+
+			it, err := ts.Service.Issue(ts.Ctx, test.input)
+
+			test.assertErr(t, err)
+			if test.validate != nil {
+				test.validate(t, it)
+			}
+		})
+	}
+}
+
+func TestStockService_Valuation(t *testing.T) {
+	asOf := time.Date(2026, 1, 31, 23, 59, 59, 0, time.UTC)
+	rows := []stock.ValuationRow{
+		{GroupKey: "Brakes", Products: 2, Quantity: 15, AverageValue: 1725.5, FIFOValue: 1700},
+		{GroupKey: "Filters", Products: 1, Quantity: 4, AverageValue: 200, FIFOValue: 210.25},
+	}
+
+	tests := []struct {
+		name      string
+		query     stock.ValuationQuery
+		setup     func(ts *TestSuite)
+		assertErr func(*testing.T, error)
+		validate  func(*testing.T, *stock.Valuation)
+	}{
+		{
+			name:  "Success_Average_By_Category_Default",
+			query: stock.ValuationQuery{AsOf: asOf},
+			setup: func(ts *TestSuite) {
+				ts.MockStockRepo.On("Valuation", ts.Ctx, asOf, stock.GroupByCategory).Return(rows, nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, v *stock.Valuation) {
+				assert.Equal(t, stock.MethodAverage, v.Method)
+				assert.Equal(t, int64(19), v.TotalQty)
+				assert.Equal(t, 1925.5, v.TotalValue)
+			},
+		},
+		{
+			name:  "Success_FIFO_By_Location",
+			query: stock.ValuationQuery{AsOf: asOf, GroupBy: stock.GroupByLocation, Method: stock.MethodFIFO},
+			setup: func(ts *TestSuite) {
+				ts.MockStockRepo.On("Valuation", ts.Ctx, asOf, stock.GroupByLocation).Return(rows, nil).Once()
+			},
+			assertErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+			validate: func(t *testing.T, v *stock.Valuation) {
+				assert.Equal(t, 1910.25, v.TotalValue)
+				assert.Equal(t, 210.25, v.Lines[1].Value)
+			},
+		},
+		{
+			name:  "Error_Unknown_Group",
+			query: stock.ValuationQuery{GroupBy: "brand"},
+			setup: func(ts *TestSuite) {},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidInput)
+			},
+		},
+		{
+			name:  "Error_Unknown_Method",
+			query: stock.ValuationQuery{Method: "lifo"},
+			setup: func(ts *TestSuite) {},
+			assertErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidInput)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewTestSuite()
+			ts.SetupTest(t)
+			test.setup(ts)
+
+			v, err := ts.Service.Valuation(ts.Ctx, test.query)
+
+			test.assertErr(t, err)
+			if test.validate != nil {
+				test.validate(t, v)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package stream
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// Event หนึ่งข้อความที่ส่งให้ client; ID คือ id ของ outbox event ใช้เป็น SSE id สำหรับ resume
+type Event struct {
+	ID        uint
+	Type      string
+	Data      json.RawMessage
+	ProductID uint
+	Location  string
+}
+
+// Filter เงื่อนไขของ client หนึ่งราย; ค่าว่างคือไม่กรอง
+type Filter struct {
+	ProductIDs []uint
+	Location   string
+}
+
+func (f Filter) Match(ev Event) bool {
+	if len(f.ProductIDs) > 0 && !slices.Contains(f.ProductIDs, ev.ProductID) {
+		return false
+	}
+	if f.Location != "" && f.Location != ev.Location {
+		return false
+	}
+	return true
+}
+
+// ReplayOutput event ที่พลาดไประหว่างหลุดการเชื่อมต่อ
+// Reset = พลาดไปมากเกินกว่าจะ replay ได้ client ควรโหลดยอดทั้งหมดใหม่
+type ReplayOutput struct {
+	Events []Event
+	Reset  bool
+}
@@ -0,0 +1,166 @@
+package stream
+
+import (
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/pkg/response"
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// retryMillis เวลาที่ EventSource รอก่อน reconnect เอง
+const retryMillis = 3000
+
+type Handler struct {
+	streamService Service
+	heartbeat     time.Duration
+}
+
+func NewHandler(streamService Service, heartbeat time.Duration) *Handler {
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+	return &Handler{
+		streamService: streamService,
+		heartbeat:     heartbeat,
+	}
+}
+
+// parseFilter อ่าน ?product_ids=1,2,3&location=A-01
+func parseFilter(c *fiber.Ctx) (Filter, error) {
+	f := Filter{Location: strings.TrimSpace(c.Query("location"))}
+	for _, s := range strings.Split(c.Query("product_ids"), ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(s, 10, 32)
+		if err != nil || id == 0 {
+			return Filter{}, fmt.Errorf("invalid product id %q", s)
+		}
+		f.ProductIDs = append(f.ProductIDs, uint(id))
+	}
+	return f, nil
+}
+
+// lastEventID จาก header Last-Event-ID (EventSource ส่งให้เองตอน reconnect) หรือ ?last_event_id=
+func lastEventID(c *fiber.Ctx) (uint, error) {
+	raw := strings.TrimSpace(c.Get("Last-Event-ID"))
+	if raw == "" {
+		raw = strings.TrimSpace(c.Query("last_event_id"))
+	}
+	if raw == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid Last-Event-ID %q", raw)
+	}
+	return uint(id), nil
+}
+
+// writeEvent เขียน event หนึ่งตัวในรูปแบบ SSE
+func writeEvent(w *bufio.Writer, ev Event) error {
+	_, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, ev.Data)
+	return err
+}
+
+// Inventory godoc
+// @Summary Stream inventory quantity changes
+// @Description Server-Sent Events stream of inventory.quantity.updated as they commit. Each message has id (use as Last-Event-ID to resume), event and data (JSON with product_id, inventory_id, delta, quantity, location, version, source). An "event: reset" means too much was missed to replay and the client should reload quantities. Comment lines (": ping") are heartbeats
+// @Tags stream
+// @Produce text/event-stream
+// @Param product_ids query string false "Comma separated product IDs"
+// @Param location query string false "Only this location"
+// @Param Last-Event-ID header string false "Resume after this event id"
+// @Param last_event_id query int false "Same as the Last-Event-ID header"
+// @Success 200 {string} string "text/event-stream"
+// @Failure 400 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /stream/inventory [get]
+func (h *Handler) Inventory(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+
+	f, err := parseFilter(c)
+	if err != nil {
+		log.Warn("handler.stream.inventory.invalid_filter", zap.Error(err))
+		return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", err.Error())
+	}
+	lastID, err := lastEventID(c)
+	if err != nil {
+		log.Warn("handler.stream.inventory.invalid_last_event_id", zap.Error(err))
+		return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", err.Error())
+	}
+
+	// subscribe ก่อน replay เพื่อไม่ให้ event ที่เกิดระหว่างนั้นหลุด (ซ้ำได้ ตัดด้วย replayed)
+	sub := h.streamService.Subscribe(f)
+	replay := &ReplayOutput{}
+	if lastID > 0 {
+		if replay, err = h.streamService.Replay(ctx, lastID, f); err != nil {
+			sub.Close()
+			return response.Error(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "internal server occured")
+		}
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no") // ปิด buffer ของ nginx
+
+	conn := c.Context().Conn()
+	heartbeat := h.heartbeat
+	log.Info("handler.stream.inventory.open", zap.Uints("product_ids", f.ProductIDs), zap.String("location", f.Location), zap.Uint("last_event_id", lastID))
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer sub.Close()
+		defer log.Info("handler.stream.inventory.closed")
+
+		// HTTP_WRITE_TIMEOUT ของ server ถูกตั้งครั้งเดียวต่อ response ซึ่งจะตัด stream ยาว
+		// จึงเลื่อน deadline ทุกครั้งที่เขียนแทน (client ที่หายไปจะถูกตัดภายในสองรอบ heartbeat)
+		flush := func() error {
+			_ = conn.SetWriteDeadline(time.Now().Add(2 * heartbeat))
+			return w.Flush()
+		}
+
+		fmt.Fprintf(w, "retry: %d\n\n", retryMillis)
+		if replay.Reset {
+			fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+		}
+		replayed := make(map[uint]bool, len(replay.Events))
+		for _, ev := range replay.Events {
+			_ = writeEvent(w, ev)
+			replayed[ev.ID] = true
+		}
+		if err := flush(); err != nil {
+			return
+		}
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case ev, ok := <-sub.C:
+				// hub หยุด หรือ client อ่านไม่ทัน: จบ stream ให้ client reconnect แล้ว replay
+				if !ok {
+					return
+				}
+				if replayed[ev.ID] {
+					continue
+				}
+				_ = writeEvent(w, ev)
+			case <-ticker.C:
+				fmt.Fprint(w, ": ping\n\n")
+			}
+			if err := flush(); err != nil {
+				return
+			}
+		}
+	})
+	return nil
+}
@@ -0,0 +1,65 @@
+package stream_test
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/features/stream"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamHandler_Inventory(t *testing.T) {
+	ts := NewTestSuite()
+	ts.SetupTest(t)
+
+	app := fiber.New()
+	app.Get("/stream/inventory", stream.NewHandler(ts.Service, time.Minute).Inventory)
+
+	ts.MockStreamRepo.On("Since", mock.Anything, domain.EventInventoryQuantityUpdated, uint(10), 4).Return([]*domain.OutboxEvent{
+		quantityEvent(11, `{"product_id":7,"quantity":5}`),
+		quantityEvent(12, `{"product_id":8,"quantity":1}`),
+	}, nil).Once()
+
+	// event สดหลังเชื่อมต่อ: 11 ซ้ำกับที่ replay ไปแล้วต้องถูกตัด, 13 ไม่ตรง filter
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		ts.Hub.Broadcast(stream.Event{ID: 11, Type: domain.EventInventoryQuantityUpdated, ProductID: 7, Data: []byte(`{"product_id":7,"quantity":5}`)})
+		ts.Hub.Broadcast(stream.Event{ID: 13, Type: domain.EventInventoryQuantityUpdated, ProductID: 8, Data: []byte(`{"product_id":8}`)})
+		ts.Hub.Broadcast(stream.Event{ID: 14, Type: domain.EventInventoryQuantityUpdated, ProductID: 7, Data: []byte(`{"product_id":7,"quantity":4}`)})
+		ts.Hub.Close()
+	}()
+
+	req := httptest.NewRequest(fiber.MethodGet, "/stream/inventory?product_ids=7", nil)
+	req.Header.Set("Last-Event-ID", "10")
+	res, err := app.Test(req, 5000)
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get(fiber.HeaderContentType))
+
+	body, _ := io.ReadAll(res.Body)
+	assert.Equal(t, "retry: 3000\n\n"+
+		"id: 11\nevent: inventory.quantity.updated\ndata: {\"product_id\":7,\"quantity\":5}\n\n"+
+		"id: 14\nevent: inventory.quantity.updated\ndata: {\"product_id\":7,\"quantity\":4}\n\n",
+		string(body))
+}
+
+func TestStreamHandler_Inventory_BadRequest(t *testing.T) {
+	ts := NewTestSuite()
+	ts.SetupTest(t)
+
+	app := fiber.New()
+	app.Get("/stream/inventory", stream.NewHandler(ts.Service, time.Minute).Inventory)
+
+	for _, path := range []string{"/stream/inventory?product_ids=7,abc", "/stream/inventory?last_event_id=-1"} {
+		res, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil), -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode, path)
+	}
+}
@@ -0,0 +1,157 @@
+package stream
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/internal/infra/outbox"
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// subscriberBuffer จำนวน event ที่ค้างได้ต่อ client ก่อนถูกตัดการเชื่อมต่อ
+const subscriberBuffer = 64
+
+// Subscription การรับ event ของ client หนึ่งราย; C ถูกปิดเมื่อ hub หยุด หรือ client อ่านไม่ทัน
+// (client จะ reconnect พร้อม Last-Event-ID แล้ว replay ส่วนที่หายไปเอง)
+type Subscription struct {
+	C      <-chan Event
+	c      chan Event
+	filter Filter
+	hub    *Hub
+	once   sync.Once
+}
+
+// Close ยกเลิกการรับ event (เรียกซ้ำได้)
+func (s *Subscription) Close() {
+	s.hub.remove(s)
+}
+
+// Hub กระจาย event จาก redis pub/sub ให้ client SSE ใน instance นี้
+// ทุก instance subscribe channel เดียวกัน จึงได้ event ครบไม่ว่า relay จะรันที่ instance ไหน
+type Hub struct {
+	rdb     *redis.Client
+	channel string
+
+	mu   sync.Mutex
+	subs map[*Subscription]struct{}
+	done bool
+}
+
+func NewHub(rdb *redis.Client, channel string) *Hub {
+	return &Hub{rdb: rdb, channel: channel, subs: make(map[*Subscription]struct{})}
+}
+
+// Run subscribe redis channel และกระจาย event จนกว่า ctx จะถูก cancel
+// (รันเป็น goroutine จาก main) ตอนหยุดจะปิดทุก Subscription เพื่อให้ stream ที่เปิดอยู่จบ
+func (h *Hub) Run(ctx context.Context) {
+	log := ctxlog.From(ctx)
+
+	ps := h.rdb.Subscribe(ctx, h.channel)
+	defer ps.Close()
+	defer h.Close()
+
+	msgs := ps.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("stream.hub.stopped")
+			return
+		case msg, ok := <-msgs:
+			if !ok {
+				return
+			}
+			ev, err := decode([]byte(msg.Payload))
+			if err != nil {
+				log.Warn("stream.hub.decode_fail", zap.Error(err))
+				continue
+			}
+			h.Broadcast(ev)
+		}
+	}
+}
+
+// Subscribe เริ่มรับ event ที่ตรง filter
+func (h *Hub) Subscribe(f Filter) *Subscription {
+	c := make(chan Event, subscriberBuffer)
+	s := &Subscription{C: c, c: c, filter: f, hub: h}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.done {
+		close(c)
+		return s
+	}
+	h.subs[s] = struct{}{}
+	return s
+}
+
+// Broadcast ส่ง event ให้ทุก client ที่ filter ตรง ไม่ block: client ที่ buffer เต็มจะถูกตัด
+func (h *Hub) Broadcast(ev Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for s := range h.subs {
+		if !s.filter.Match(ev) {
+			continue
+		}
+		select {
+		case s.c <- ev:
+		default:
+			h.removeLocked(s)
+		}
+	}
+}
+
+func (h *Hub) remove(s *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeLocked(s)
+}
+
+func (h *Hub) removeLocked(s *Subscription) {
+	delete(h.subs, s)
+	s.once.Do(func() { close(s.c) })
+}
+
+// Close ปิดทุก Subscription และไม่รับ subscriber ใหม่อีก
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.done = true
+	for s := range h.subs {
+		h.removeLocked(s)
+	}
+}
+
+// NewPublisher handler ของ outbox dispatcher: ส่ง event ที่ commit แล้วเข้า redis channel
+// ลงทะเบียนเฉพาะ event ที่ stream ใช้ (inventory.quantity.updated)
+func NewPublisher(rdb *redis.Client, channel string) outbox.HandlerFunc {
+	return func(ctx context.Context, env outbox.Envelope) error {
+		b, err := json.Marshal(env)
+		if err != nil {
+			return err
+		}
+		return rdb.Publish(ctx, channel, b).Err()
+	}
+}
+
+// decode แปลงข้อความจาก redis (outbox.Envelope) เป็น Event
+func decode(raw []byte) (Event, error) {
+	var env outbox.Envelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return Event{}, err
+	}
+	return fromRecord(env.ID, env.Type, env.Payload)
+}
+
+func fromRecord(id uint, eventType string, payload json.RawMessage) (Event, error) {
+	var p domain.InventoryQuantityChanged
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return Event{}, err
+	}
+	return Event{ID: id, Type: eventType, Data: payload, ProductID: p.ProductID, Location: p.Location}, nil
+}
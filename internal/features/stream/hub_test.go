@@ -0,0 +1,65 @@
+package stream_test
+
+import (
+	"ans-spareparts-api/internal/features/stream"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter_Match(t *testing.T) {
+	ev := stream.Event{ID: 1, ProductID: 7, Location: "A-01"}
+
+	assert.True(t, stream.Filter{}.Match(ev))
+	assert.True(t, stream.Filter{ProductIDs: []uint{3, 7}}.Match(ev))
+	assert.True(t, stream.Filter{ProductIDs: []uint{7}, Location: "A-01"}.Match(ev))
+	assert.False(t, stream.Filter{ProductIDs: []uint{3}}.Match(ev))
+	assert.False(t, stream.Filter{Location: "B-02"}.Match(ev))
+}
+
+func TestHub_Broadcast(t *testing.T) {
+	hub := stream.NewHub(nil, "test")
+
+	all := hub.Subscribe(stream.Filter{})
+	onlyP7 := hub.Subscribe(stream.Filter{ProductIDs: []uint{7}})
+	defer all.Close()
+	defer onlyP7.Close()
+
+	hub.Broadcast(stream.Event{ID: 1, ProductID: 3})
+	hub.Broadcast(stream.Event{ID: 2, ProductID: 7})
+
+	assert.Equal(t, uint(1), (<-all.C).ID)
+	assert.Equal(t, uint(2), (<-all.C).ID)
+	assert.Equal(t, uint(2), (<-onlyP7.C).ID)
+	assert.Empty(t, onlyP7.C)
+}
+
+func TestHub_SlowSubscriberIsDropped(t *testing.T) {
+	hub := stream.NewHub(nil, "test")
+	sub := hub.Subscribe(stream.Filter{})
+
+	// ส่งเกิน buffer โดยไม่อ่าน: subscriber ต้องถูกตัด ไม่ block hub
+	for i := 1; i <= 100; i++ {
+		hub.Broadcast(stream.Event{ID: uint(i)})
+	}
+
+	n := 0
+	for range sub.C {
+		n++
+	}
+	assert.Less(t, n, 100)
+	sub.Close() // เรียกซ้ำหลังถูกตัดแล้วต้องไม่ panic
+}
+
+func TestHub_Close(t *testing.T) {
+	hub := stream.NewHub(nil, "test")
+	sub := hub.Subscribe(stream.Filter{})
+
+	hub.Close()
+	_, ok := <-sub.C
+	assert.False(t, ok)
+
+	// หลังปิดแล้ว subscriber ใหม่ได้ channel ที่ปิดทันที
+	_, ok = <-hub.Subscribe(stream.Filter{}).C
+	assert.False(t, ok)
+}
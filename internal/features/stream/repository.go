@@ -0,0 +1,62 @@
+package stream
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/pkg/apperror"
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	// Since event ชนิดนี้ที่ client ซึ่งได้รับ afterID แล้วอาจยังไม่ได้รับ (ใช้ replay ตอน reconnect)
+	// id ถูกจองตอน insert แต่ commit ไม่เรียงตาม id (tx ที่จอง id น้อยกว่าอาจ commit ทีหลัง) จึงใช้ id > afterID ไม่ได้
+	// แต่ดูจากลำดับที่ relay ส่งจริงแทน: event ที่ส่งถึง hub หลัง afterID ย่อมส่งหลังจาก afterID ถูกสร้าง
+	// คือแถวที่ยัง pending ทั้งหมด กับแถวที่ส่งแล้ว/dead ที่ส่งครั้งล่าสุดหลัง created_at ของ afterID
+	// ได้ event ซ้ำกับที่ client เคยเห็นได้บ้าง (at-least-once) ถ้าไม่พบ afterID แล้วคืน ErrNotFound
+	// ข้อจำกัด: เทียบเวลาจากนาฬิกาของแต่ละ instance ถ้านาฬิกาเหลื่อมกันมากกว่าเวลาส่งของ relay อาจพลาด event ได้
+	Since(ctx context.Context, eventType string, afterID uint, limit int) ([]*domain.OutboxEvent, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Since(ctx context.Context, eventType string, afterID uint, limit int) ([]*domain.OutboxEvent, error) {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	var anchor domain.OutboxEvent
+	if err := r.db.WithContext(ctx).Select("id", "created_at").First(&anchor, afterID).Error; err != nil {
+		m := apperror.MapDBError("repo.stream.since.anchor", err)
+		log.Debug("repo.stream.since.anchor.fail", zap.Uint("after_id", afterID), zap.Error(err), zap.Duration("duration", time.Since(start)))
+		return nil, m
+	}
+
+	// published_at ของแถวที่ส่งแล้ว, next_attempt_at ของแถว dead (ตั้งหลังการส่งครั้งสุดท้ายเสมอ)
+	var rows []*domain.OutboxEvent
+	if err := r.db.WithContext(ctx).
+		Where("event_type = ? AND id <> ?", eventType, afterID).
+		Where("status = ? OR COALESCE(published_at, next_attempt_at) > ?", domain.OutboxPending, anchor.CreatedAt).
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:  "status = ?, COALESCE(published_at, next_attempt_at), id",
+			Vars: []any{domain.OutboxPending},
+		}}).
+		Limit(limit).
+		Find(&rows).Error; err != nil {
+		m := apperror.MapDBError("repo.stream.since", err)
+		log.Debug("repo.stream.since.fail", zap.Error(err), zap.Duration("duration", time.Since(start)))
+		return nil, m
+	}
+
+	log.Debug("repo.stream.since.ok", zap.Uint("after_id", afterID), zap.Int("count", len(rows)), zap.Duration("duration", time.Since(start)))
+	return rows, nil
+}
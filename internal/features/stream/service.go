@@ -0,0 +1,70 @@
+package stream
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/pkg/apperror"
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+)
+
+type Service interface {
+	// Subscribe รับ event สดที่ตรง filter; ต้อง Close เมื่อ client หลุด
+	Subscribe(f Filter) *Subscription
+	// Replay event ที่พลาดไปหลัง lastEventID (ตาม header Last-Event-ID) เรียงตามลำดับที่ relay ส่ง
+	Replay(ctx context.Context, lastEventID uint, f Filter) (*ReplayOutput, error)
+}
+
+type service struct {
+	streamRepo  Repository
+	hub         *Hub
+	replayLimit int
+}
+
+func NewService(streamRepo Repository, hub *Hub, replayLimit int) Service {
+	if replayLimit <= 0 {
+		replayLimit = 1000
+	}
+	return &service{
+		streamRepo:  streamRepo,
+		hub:         hub,
+		replayLimit: replayLimit,
+	}
+}
+
+func (i *service) Subscribe(f Filter) *Subscription {
+	return i.hub.Subscribe(f)
+}
+
+func (i *service) Replay(ctx context.Context, lastEventID uint, f Filter) (*ReplayOutput, error) {
+	log := ctxlog.From(ctx)
+
+	rows, err := i.streamRepo.Since(ctx, domain.EventInventoryQuantityUpdated, lastEventID, i.replayLimit+1)
+	if errors.Is(err, apperror.ErrNotFound) {
+		// id ที่ client ส่งมาไม่มีใน outbox (ถูกล้างไปแล้วหรือผิด) หาจุดต่อไม่ได้ ให้โหลดใหม่ทั้งหมด
+		log.Info("stream.replay.reset_unknown_id", zap.Uint("last_event_id", lastEventID))
+		return &ReplayOutput{Reset: true}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) > i.replayLimit {
+		log.Info("stream.replay.reset", zap.Uint("last_event_id", lastEventID))
+		return &ReplayOutput{Reset: true}, nil
+	}
+
+	out := &ReplayOutput{Events: make([]Event, 0, len(rows))}
+	for _, row := range rows {
+		ev, err := fromRecord(row.ID, row.EventType, row.Payload)
+		if err != nil {
+			log.Warn("stream.replay.decode_fail", zap.Uint("event_id", row.ID), zap.Error(err))
+			continue
+		}
+		if f.Match(ev) {
+			out.Events = append(out.Events, ev)
+		}
+	}
+	return out, nil
+}
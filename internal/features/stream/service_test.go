@@ -0,0 +1,112 @@
+package stream_test
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/features/stream"
+	mocks "ans-spareparts-api/internal/mock"
+	"ans-spareparts-api/pkg/apperror"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type TestSuite struct {
+	Service        stream.Service
+	Hub            *stream.Hub
+	MockStreamRepo *mocks.StreamRepository
+	Ctx            context.Context
+}
+
+func NewTestSuite() *TestSuite {
+	return &TestSuite{}
+}
+
+func (ts *TestSuite) SetupTest(t *testing.T) {
+	ts.MockStreamRepo = mocks.NewMockStreamRepository()
+	ts.Hub = stream.NewHub(nil, "test")
+	ts.Service = stream.NewService(ts.MockStreamRepo, ts.Hub, 3)
+	ts.Ctx = context.Background()
+
+	t.Cleanup(func() {
+		ts.Hub.Close()
+		ts.MockStreamRepo.AssertExpectations(t)
+	})
+}
+
+func quantityEvent(id uint, payload string) *domain.OutboxEvent {
+	return &domain.OutboxEvent{ID: id, EventType: domain.EventInventoryQuantityUpdated, Payload: []byte(payload)}
+}
+
+func TestStreamService_Replay(t *testing.T) {
+	tests := []struct {
+		name      string
+		filter    stream.Filter
+		rows      []*domain.OutboxEvent
+		wantIDs   []uint
+		wantReset bool
+	}{
+		{
+			name:   "Success_Filtered_By_Product",
+			filter: stream.Filter{ProductIDs: []uint{7}},
+			rows: []*domain.OutboxEvent{
+				quantityEvent(11, `{"product_id":7,"quantity":5,"location":"A-01"}`),
+				quantityEvent(12, `{"product_id":8,"quantity":1,"location":"A-01"}`),
+				quantityEvent(13, `{"product_id":7,"quantity":4,"location":"A-01"}`),
+			},
+			wantIDs: []uint{11, 13},
+		},
+		{
+			name:   "Success_Filtered_By_Location_Skips_Bad_Payload",
+			filter: stream.Filter{Location: "B-02"},
+			rows: []*domain.OutboxEvent{
+				quantityEvent(11, `not-json`),
+				quantityEvent(12, `{"product_id":8,"quantity":1,"location":"B-02"}`),
+			},
+			wantIDs: []uint{12},
+		},
+		{
+			name: "Reset_When_Too_Far_Behind",
+			rows: []*domain.OutboxEvent{
+				quantityEvent(11, `{}`), quantityEvent(12, `{}`), quantityEvent(13, `{}`), quantityEvent(14, `{}`),
+			},
+			wantReset: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := NewTestSuite()
+			ts.SetupTest(t)
+
+			ts.MockStreamRepo.On("Since", ts.Ctx, domain.EventInventoryQuantityUpdated, uint(10), 4).Return(tt.rows, nil).Once()
+
+			out, err := ts.Service.Replay(ts.Ctx, 10, tt.filter)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantReset, out.Reset)
+			ids := make([]uint, 0, len(out.Events))
+			for _, ev := range out.Events {
+				ids = append(ids, ev.ID)
+			}
+			if tt.wantIDs == nil {
+				assert.Empty(t, ids)
+			} else {
+				assert.Equal(t, tt.wantIDs, ids)
+			}
+		})
+	}
+}
+
+func TestStreamService_Replay_Unknown_LastEventID_Resets(t *testing.T) {
+	ts := NewTestSuite()
+	ts.SetupTest(t)
+
+	ts.MockStreamRepo.On("Since", ts.Ctx, domain.EventInventoryQuantityUpdated, uint(10), 4).Return(nil, apperror.ErrNotFound).Once()
+
+	out, err := ts.Service.Replay(ts.Ctx, 10, stream.Filter{})
+	require.NoError(t, err)
+	assert.True(t, out.Reset)
+	assert.Empty(t, out.Events)
+}
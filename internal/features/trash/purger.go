@@ -0,0 +1,51 @@
+package trash
+
+import (
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Purger service ที่ลบถาวรข้อมูลในถังขยะที่ถูกลบก่อน before ได้
+// (product.Service, category.Service, user.Service)
+type Purger interface {
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
+}
+
+// RunPurger ลบถาวรข้อมูลที่อยู่ในถังขยะนานกว่า retention ทุกๆ interval จนกว่า ctx จะถูก cancel
+// purgers ทำงานตามลำดับที่ส่งมา (รันเป็น goroutine จาก main)
+func RunPurger(ctx context.Context, retention, interval time.Duration, purgers ...Purger) {
+	log := ctxlog.From(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		PurgeOnce(ctx, time.Now().Add(-retention), purgers...)
+
+		select {
+		case <-ctx.Done():
+			log.Info("trash.purger.stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeOnce เรียก purgers ทุกตัวหนึ่งรอบ ตัวที่ล้มเหลวไม่หยุดตัวถัดไป
+func PurgeOnce(ctx context.Context, before time.Time, purgers ...Purger) int64 {
+	log := ctxlog.From(ctx)
+
+	var total int64
+	for _, p := range purgers {
+		n, err := p.PurgeTrash(ctx, before)
+		if err != nil {
+			log.Warn("trash.purger.purge_fail", zap.Error(err))
+			continue
+		}
+		total += n
+	}
+	return total
+}
@@ -1,5 +1,7 @@
 package user
 
+import "time"
+
 type UserUpdateRequest struct {
 	Email    string
 	Password string
@@ -37,3 +39,25 @@ type Item struct {
 // 	Items []*Item
 // 	Total int64
 // }
+
+// TrashItem a soft-deleted user.
+type TrashItem struct {
+	ID        uint
+	Username  string
+	Email     string
+	Role      string
+	DeletedAt time.Time
+}
+
+type TrashOutput struct {
+	Items []*TrashItem
+	Total int64
+}
+
+type TrashUserResponse struct {
+	ID        uint      `json:"id" example:"10"`
+	Username  string    `json:"username" example:"john"`
+	Email     string    `json:"email" example:"john@mail.com"`
+	Role      string    `json:"role" example:"cashier"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
@@ -5,7 +5,10 @@ import (
 	"ans-spareparts-api/internal/infra/httpx/ctxlog"
 	"ans-spareparts-api/internal/infra/jwtx"
 	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/query"
 	"ans-spareparts-api/pkg/response"
+	"errors"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
@@ -106,7 +109,7 @@ func (h *Handler) DeleteProfile(c *fiber.Ctx) error {
 		)
 	}
 
-	err = h.authService.Logout(ctx, token)
+	err = h.authService.Logout(ctx, token, "")
 	if err != nil {
 		if err == apperror.ErrInvalidToken {
 			return response.Error(
@@ -120,3 +123,88 @@ func (h *Handler) DeleteProfile(c *fiber.Ctx) error {
 	}
 	return response.NoContent(c)
 }
+
+// ListTrash godoc
+// @Summary List deleted users
+// @Description List soft-deleted users, most recently deleted first (manager only)
+// @Tags trash
+// @Produce json
+// @Param limit query int false "Page size"
+// @Param offset query int false "Offset"
+// @Success 200 {array} TrashUserResponse
+// @Failure 400 {object} response.ErrorBody
+// @Failure 403 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /trash/users [get]
+func (h *Handler) ListTrash(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+
+	// ถังขยะไม่มี filter/sort ใช้แค่ limit/offset
+	p, err := query.FromCtx(c, query.Schema{})
+	if err != nil {
+		log.Warn("handler.user.list_trash.invalid_input", zap.Error(err))
+		return response.Error(
+			c, fiber.StatusBadRequest, "BAD_REQUEST", err.Error(),
+		)
+	}
+
+	out, err := h.userService.ListTrash(ctx, p.Limit, p.Offset)
+	if err != nil {
+		return response.Error(
+			c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "internal server occured",
+		)
+	}
+
+	items := make([]*TrashUserResponse, len(out.Items))
+	for index, it := range out.Items {
+		items[index] = &TrashUserResponse{
+			ID:        it.ID,
+			Username:  it.Username,
+			Email:     it.Email,
+			Role:      it.Role,
+			DeletedAt: it.DeletedAt,
+		}
+	}
+
+	return response.Page(c, items, out.Total, p.Limit, p.Offset)
+}
+
+// Restore godoc
+// @Summary Restore deleted user
+// @Description Restore a soft-deleted user (manager only)
+// @Tags trash
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} response.ErrorBody
+// @Failure 403 {object} response.ErrorBody
+// @Failure 404 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /trash/users/{id}/restore [post]
+func (h *Handler) Restore(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Warn("handler.user.restore.invalid_id", zap.Error(err))
+		return response.Error(
+			c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid user id",
+		)
+	}
+
+	err = h.userService.RestoreUser(ctx, uint(id))
+	switch {
+	case err == nil:
+		return response.NoContent(c)
+	case errors.Is(err, apperror.ErrNotFound):
+		return response.Error(
+			c, fiber.StatusNotFound, "NOT_FOUND", "deleted user not found",
+		)
+	default:
+		return response.Error(
+			c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "internal server occured",
+		)
+	}
+}
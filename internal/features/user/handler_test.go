@@ -160,7 +160,7 @@ func TestUserHandler_DeleteProfile(t *testing.T) {
 			userID:      1,
 			setup: func(hts *HandlerTestSuite) {
 				hts.MockUserService.On("DeleteUser", mock.Anything, uint(1)).Return(nil).Once()
-				hts.MockAuthService.On("Logout", mock.Anything, mockToken).Return(nil).Once()
+				hts.MockAuthService.On("Logout", mock.Anything, mockToken, "").Return(nil).Once()
 			},
 			expectedStatusCode: fiber.StatusNoContent,
 		},
@@ -215,7 +215,7 @@ func TestUserHandler_DeleteProfile(t *testing.T) {
 			userID:      1,
 			setup: func(hts *HandlerTestSuite) {
 				hts.MockUserService.On("DeleteUser", mock.Anything, uint(1)).Return(nil).Once()
-				hts.MockAuthService.On("Logout", mock.Anything, mockToken).Return(apperror.ErrInvalidToken).Once()
+				hts.MockAuthService.On("Logout", mock.Anything, mockToken, "").Return(apperror.ErrInvalidToken).Once()
 
 			},
 			expectedStatusCode: fiber.StatusUnauthorized,
@@ -232,7 +232,7 @@ func TestUserHandler_DeleteProfile(t *testing.T) {
 			userID:      1,
 			setup: func(hts *HandlerTestSuite) {
 				hts.MockUserService.On("DeleteUser", mock.Anything, uint(1)).Return(nil).Once()
-				hts.MockAuthService.On("Logout", mock.Anything, mockToken).Return(apperror.ErrInternalServer).Once()
+				hts.MockAuthService.On("Logout", mock.Anything, mockToken, "").Return(apperror.ErrInternalServer).Once()
 
 			},
 			expectedStatusCode: fiber.StatusInternalServerError,
@@ -277,3 +277,58 @@ func TestUserHandler_DeleteProfile(t *testing.T) {
 		})
 	}
 }
+
+func TestUserHandler_Restore(t *testing.T) {
+	tests := []struct {
+		name               string
+		path               string
+		setup              func(*HandlerTestSuite)
+		expectedStatusCode int
+		expectedBody       interface{}
+	}{
+		{
+			name: "Success_Restore",
+			path: "/trash/users/3/restore",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockUserService.On("RestoreUser", mock.Anything, uint(3)).Return(nil).Once()
+			},
+			expectedStatusCode: fiber.StatusNoContent,
+		},
+		{
+			name:               "Error_Invalid_ID",
+			path:               "/trash/users/abc/restore",
+			setup:              func(hts *HandlerTestSuite) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+			expectedBody:       fiber.Map{"code": "BAD_REQUEST", "message": "invalid user id"},
+		},
+		{
+			name: "Error_Not_In_Trash",
+			path: "/trash/users/3/restore",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockUserService.On("RestoreUser", mock.Anything, uint(3)).Return(apperror.ErrNotFound).Once()
+			},
+			expectedStatusCode: fiber.StatusNotFound,
+			expectedBody:       fiber.Map{"code": "NOT_FOUND", "message": "deleted user not found"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewHandlerTestSuite()
+			ts.SetupHandlerTestSuite(t)
+
+			ts.App.Post("/trash/users/:id/restore", ts.Handler.Restore)
+			test.setup(ts)
+
+			res, _ := ts.App.Test(httptest.NewRequest(fiber.MethodPost, test.path, nil), -1)
+
+			assert.Equal(t, test.expectedStatusCode, res.StatusCode)
+			if test.expectedBody != nil {
+				expectedBody, _ := json.Marshal(test.expectedBody)
+				resBody, _ := io.ReadAll(res.Body)
+				assert.JSONEq(t, string(expectedBody), string(resBody))
+			}
+			ts.MockUserService.AssertExpectations(t)
+		})
+	}
+}
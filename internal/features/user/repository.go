@@ -29,6 +29,14 @@ type Repository interface {
 	GetByEmail(ctx context.Context, email string) (*domain.User, error)
 	// Create insert a new user into the database.
 	Create(ctx context.Context, user *domain.User) error
+
+	// -- Trash Method --
+	// ListDeleted users in the trash, most recently deleted first.
+	ListDeleted(ctx context.Context, limit, offset int) ([]*domain.User, int64, error)
+	// Restore clears deleted_at of a soft-deleted user.
+	Restore(ctx context.Context, id uint) error
+	// PurgeDeleted hard-deletes users deleted before the given time, skipping users that still own shifts.
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
 }
 
 // repository struct
@@ -189,3 +197,72 @@ func (r *repository) Delete(ctx context.Context, id uint) error {
 	log.Debug("repo.user.delete.ok", zap.Uint("user_id", id), zap.Duration("duraion", time.Since(start)))
 	return nil
 }
+
+func (r *repository) ListDeleted(ctx context.Context, limit, offset int) ([]*domain.User, int64, error) {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	tx := r.db.WithContext(ctx).Unscoped().Model(&domain.User{}).Where("deleted_at IS NOT NULL")
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		m := apperror.MapDBError("repo.user.listdeleted.count", err)
+		log.Debug("repo.user.listdeleted.count_err", zap.Error(err))
+		return nil, 0, m
+	}
+
+	var users []*domain.User
+	if err := tx.Order("deleted_at DESC, id DESC").Limit(limit).Offset(offset).Find(&users).Error; err != nil {
+		m := apperror.MapDBError("repo.user.listdeleted", err)
+		log.Debug("repo.user.listdeleted.db_err", zap.Error(err))
+		return nil, 0, m
+	}
+
+	log.Debug("repo.user.listdeleted.ok", zap.Int("count", len(users)), zap.Duration("duration", time.Since(start)))
+	return users, total, nil
+}
+
+func (r *repository) Restore(ctx context.Context, id uint) error {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	var user domain.User
+	if err := r.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL").First(&user, id).Error; err != nil {
+		m := apperror.MapDBError("repo.user.restore.get", err)
+		log.Debug("repo.user.restore.get.db_err", zap.Error(err))
+		return m
+	}
+
+	if err := r.db.WithContext(ctx).Unscoped().Model(&domain.User{}).Where("id = ?", id).
+		Update("deleted_at", nil).Error; err != nil {
+		m := apperror.MapDBError("repo.user.restore", err)
+		log.Debug("repo.user.restore.db_err", zap.Error(err))
+		return m
+	}
+
+	// del cache
+	if err := r.cache.del(ctx, r.cache.keyByID(id), r.cache.keyByUsername(user.Username), r.cache.keyByEmail(user.Email)); err != nil {
+		log.Warn("repo.user.restore.del_cache.fail", zap.Error(err))
+	}
+
+	log.Debug("repo.user.restore.ok", zap.Uint("user_id", id), zap.Duration("duration", time.Since(start)))
+	return nil
+}
+
+func (r *repository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	res := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM shifts s WHERE s.user_id = users.id)").
+		Delete(&domain.User{})
+	if res.Error != nil {
+		m := apperror.MapDBError("repo.user.purgedeleted", res.Error)
+		log.Debug("repo.user.purgedeleted.db_err", zap.Error(res.Error))
+		return 0, m
+	}
+
+	log.Debug("repo.user.purgedeleted.ok", zap.Int64("purged", res.RowsAffected), zap.Duration("duration", time.Since(start)))
+	return res.RowsAffected, nil
+}
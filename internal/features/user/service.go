@@ -1,9 +1,13 @@
 package user
 
 import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/features/audit"
 	"ans-spareparts-api/internal/infra/httpx/ctxlog"
 	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/utils"
 	"context"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -12,15 +16,21 @@ import (
 type Service interface {
 	GetUserProfile(ctx context.Context, userID uint) (*Item, error)
 	DeleteUser(ctx context.Context, userID uint) error
+
+	ListTrash(ctx context.Context, limit, offset int) (*TrashOutput, error)
+	RestoreUser(ctx context.Context, userID uint) error
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
 }
 
 type service struct {
 	userRepo Repository
+	auditor  audit.Recorder
 }
 
-func NewService(userRepo Repository) Service {
+func NewService(userRepo Repository, auditor audit.Recorder) Service {
 	return &service{
 		userRepo: userRepo,
+		auditor:  auditor,
 	}
 }
 
@@ -56,6 +66,58 @@ func (s *service) DeleteUser(ctx context.Context, userID uint) error {
 		return err
 	}
 
+	s.auditor.Record(ctx, audit.Entry{EntityType: audit.EntityUser, EntityID: userID, Action: domain.AuditDelete, Before: user})
 	log.Info("user profile deleted", zap.Uint("user_id", userID))
 	return nil
 }
+
+func (s *service) ListTrash(ctx context.Context, limit, offset int) (*TrashOutput, error) {
+	limit, offset = utils.NormalizePagination(limit, offset)
+
+	users, total, err := s.userRepo.ListDeleted(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*TrashItem, len(users))
+	for index, u := range users {
+		items[index] = &TrashItem{
+			ID:        u.ID,
+			Username:  u.Username,
+			Email:     u.Email,
+			Role:      u.Role,
+			DeletedAt: u.DeletedAt.Time,
+		}
+	}
+
+	return &TrashOutput{Items: items, Total: total}, nil
+}
+
+func (s *service) RestoreUser(ctx context.Context, userID uint) error {
+	log := ctxlog.From(ctx)
+
+	if err := s.userRepo.Restore(ctx, userID); err != nil {
+		return err
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		EntityType: audit.EntityUser,
+		EntityID:   userID,
+		Action:     domain.AuditRestore,
+		Changes:    map[string]audit.Change{"deleted": {Old: true, New: false}},
+	})
+	log.Info("user restored", zap.Uint("user_id", userID))
+	return nil
+}
+
+func (s *service) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	log := ctxlog.From(ctx)
+
+	purged, err := s.userRepo.PurgeDeleted(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+
+	log.Info("user trash purged", zap.Int64("purged", purged), zap.Time("before", before))
+	return purged, nil
+}
@@ -9,11 +9,13 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 type TestSuite struct {
 	Service      user.Service
 	MockUserRepo *mocks.UserRepository
+	MockAudit    *mocks.AuditService
 	Ctx          context.Context
 }
 
@@ -23,7 +25,10 @@ func newTestSuite() *TestSuite {
 
 func (ts *TestSuite) SetupTestSuite(t *testing.T) {
 	ts.MockUserRepo = mocks.NewMockUserRepository()
-	ts.Service = user.NewService(ts.MockUserRepo)
+	// audit เป็น best-effort: ยอมรับทุกการเรียก แล้วตรวจผ่าน MockAudit.Calls ใน test ที่สนใจ
+	ts.MockAudit = mocks.NewAuditService()
+	ts.MockAudit.On("Record", mock.Anything, mock.Anything).Maybe()
+	ts.Service = user.NewService(ts.MockUserRepo, ts.MockAudit)
 	ts.Ctx = context.Background()
 
 	t.Cleanup(func() {
@@ -0,0 +1,37 @@
+package webhook
+
+import (
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// RunDeliverer ส่ง webhook ที่ถึงเวลาทุกๆ interval จนกว่า ctx จะถูก cancel
+// (รันเป็น goroutine จาก main) ถ้ารอบไหนได้เต็ม batch จะส่งรอบต่อไปทันที
+func RunDeliverer(ctx context.Context, svc Service, batchSize int, interval time.Duration) {
+	log := ctxlog.From(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := svc.DeliverDue(ctx)
+			if err != nil {
+				log.Warn("webhook.deliverer.fail", zap.Error(err))
+			}
+			if err != nil || n < batchSize || ctx.Err() != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info("webhook.deliverer.stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package webhook
+
+import (
+	"ans-spareparts-api/pkg/query"
+	"encoding/json"
+	"time"
+)
+
+// deliverySchema field ที่กรองได้ของ GET /webhooks/:id/deliveries (ใหม่สุดก่อน)
+var deliverySchema = query.Schema{
+	Fields: map[string]query.Field{
+		"id":         {Column: "id", Type: query.Int, Ops: query.Comparable, Sortable: true},
+		"status":     {Column: "status", Type: query.String, Ops: []query.Op{query.OpEq, query.OpIn}},
+		"event_type": {Column: "event_type", Type: query.String, Ops: []query.Op{query.OpEq, query.OpIn}},
+		"event_id":   {Column: "event_id", Type: query.Int, Ops: []query.Op{query.OpEq}},
+		"created_at": {Column: "created_at", Type: query.Time, Ops: query.Comparable},
+	},
+	DefaultSort: []query.Sort{{Column: "id", Desc: true}},
+}
+
+// Body สิ่งที่ POST ไปยังปลายทาง
+type Body struct {
+	ID            uint            `json:"id"` // event id (ซ้ำได้ถ้า redeliver)
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   uint            `json:"aggregate_id"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Data          json.RawMessage `json:"data"`
+}
+
+type CreateInput struct {
+	URL         string
+	EventTypes  []string
+	Secret      string // ว่าง = สุ่มให้
+	Description string
+}
+
+// UpdateInput field ที่เป็น nil คือไม่เปลี่ยน
+type UpdateInput struct {
+	URL         *string
+	EventTypes  []string
+	Secret      *string
+	Description *string
+	IsActive    *bool
+}
+
+type Subscription struct {
+	ID          uint
+	URL         string
+	EventTypes  []string
+	Description string
+	IsActive    bool
+	// Secret มีเฉพาะตอนสร้าง / เปลี่ยน secret
+	Secret    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type DeliveryQuery struct {
+	SubscriptionID uint
+	Limit          int
+	Offset         int
+	Sort           []query.Sort
+	Filters        []query.Filter
+}
+
+type Delivery struct {
+	ID             uint
+	SubscriptionID uint
+	EventID        uint
+	EventType      string
+	Status         string
+	Attempts       int
+	NextAttemptAt  time.Time
+	ResponseStatus int
+	LastError      string
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
+
+type DeliveryListOutput struct {
+	Items []*Delivery
+	Total int64
+}
+
+type Attempt struct {
+	ID             uint
+	ResponseStatus int
+	ResponseBody   string
+	Error          string
+	DurationMs     int64
+	CreatedAt      time.Time
+}
+
+// DeliveryDetail delivery พร้อม body ที่ส่งและ log ทุกครั้งที่ส่ง
+type DeliveryDetail struct {
+	Delivery
+	Payload json.RawMessage
+	Log     []*Attempt
+}
+
+type CreateSubscriptionRequest struct {
+	URL         string   `json:"url"`
+	EventTypes  []string `json:"event_types"`
+	Secret      string   `json:"secret,omitempty"`
+	Description string   `json:"description"`
+}
+
+type UpdateSubscriptionRequest struct {
+	URL         *string  `json:"url,omitempty"`
+	EventTypes  []string `json:"event_types,omitempty"`
+	Secret      *string  `json:"secret,omitempty"`
+	Description *string  `json:"description,omitempty"`
+	IsActive    *bool    `json:"is_active,omitempty"`
+}
+
+type SubscriptionResponse struct {
+	ID          uint      `json:"id"`
+	URL         string    `json:"url"`
+	EventTypes  []string  `json:"event_types"`
+	Description string    `json:"description"`
+	IsActive    bool      `json:"is_active"`
+	Secret      string    `json:"secret,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type DeliveryResponse struct {
+	ID             uint       `json:"id"`
+	SubscriptionID uint       `json:"subscription_id"`
+	EventID        uint       `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	ResponseStatus int        `json:"response_status"`
+	LastError      string     `json:"last_error"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+}
+
+type AttemptResponse struct {
+	ID             uint      `json:"id"`
+	ResponseStatus int       `json:"response_status"`
+	ResponseBody   string    `json:"response_body"`
+	Error          string    `json:"error"`
+	DurationMs     int64     `json:"duration_ms"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type DeliveryDetailResponse struct {
+	DeliveryResponse
+	Payload json.RawMessage    `json:"payload"`
+	Log     []*AttemptResponse `json:"log"`
+}
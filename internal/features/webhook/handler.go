@@ -0,0 +1,282 @@
+package webhook
+
+import (
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/query"
+	"ans-spareparts-api/pkg/response"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	webhookService Service
+}
+
+func NewHandler(webhookService Service) *Handler {
+	return &Handler{
+		webhookService: webhookService,
+	}
+}
+
+func toSubscriptionResponse(s *Subscription) SubscriptionResponse {
+	return SubscriptionResponse(*s)
+}
+
+func toDeliveryResponse(d *Delivery) DeliveryResponse {
+	return DeliveryResponse(*d)
+}
+
+// mapError แปลง error จาก service เป็น response มาตรฐาน
+func mapError(c *fiber.Ctx, err error, notFound string) error {
+	switch {
+	case errors.Is(err, apperror.ErrInvalidInput):
+		return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", err.Error())
+	case errors.Is(err, apperror.ErrNotFound):
+		return response.Error(c, fiber.StatusNotFound, "NOT_FOUND", notFound)
+	}
+	return response.Error(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "internal server occured")
+}
+
+func parseID(c *fiber.Ctx) (uint, error) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	return uint(id), err
+}
+
+// CreateSubscription godoc
+// @Summary Create a webhook subscription
+// @Description Subscribe a URL to domain events (manager only). event_types takes names such as product.updated or inventory.quantity.updated, or "*" for all. If secret is empty one is generated; it is only returned in this response. Each delivery is signed: X-Webhook-Signature = "v1=" + hex(HMAC-SHA256(secret, X-Webhook-Timestamp + "." + body))
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param subscription body CreateSubscriptionRequest true "Subscription"
+// @Success 201 {object} SubscriptionResponse
+// @Failure 400 {object} response.ErrorBody
+// @Failure 403 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /webhooks [post]
+func (h *Handler) CreateSubscription(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+
+	var req CreateSubscriptionRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Warn("handler.webhook.create.invalid_body", zap.Error(err))
+		return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid request body")
+	}
+
+	sub, err := h.webhookService.CreateSubscription(ctx, CreateInput(req))
+	if err != nil {
+		return mapError(c, err, "webhook subscription not found")
+	}
+	return response.Created(c, toSubscriptionResponse(sub))
+}
+
+// ListSubscriptions godoc
+// @Summary List webhook subscriptions
+// @Description All webhook subscriptions (manager only). Secrets are never returned here
+// @Tags webhooks
+// @Produce json
+// @Success 200 {array} SubscriptionResponse
+// @Failure 403 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /webhooks [get]
+func (h *Handler) ListSubscriptions(c *fiber.Ctx) error {
+	subs, err := h.webhookService.ListSubscriptions(c.UserContext())
+	if err != nil {
+		return mapError(c, err, "webhook subscription not found")
+	}
+
+	items := make([]SubscriptionResponse, len(subs))
+	for index, s := range subs {
+		items[index] = toSubscriptionResponse(s)
+	}
+	return response.OK(c, items)
+}
+
+// GetSubscription godoc
+// @Summary Get a webhook subscription
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Success 200 {object} SubscriptionResponse
+// @Failure 400 {object} response.ErrorBody
+// @Failure 404 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /webhooks/{id} [get]
+func (h *Handler) GetSubscription(c *fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid subscription id")
+	}
+
+	sub, err := h.webhookService.GetSubscription(c.UserContext(), id)
+	if err != nil {
+		return mapError(c, err, "webhook subscription not found")
+	}
+	return response.OK(c, toSubscriptionResponse(sub))
+}
+
+// UpdateSubscription godoc
+// @Summary Update a webhook subscription
+// @Description Change URL, event types, secret, description or pause with is_active=false (manager only). Omitted fields are kept
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param subscription body UpdateSubscriptionRequest true "Fields to change"
+// @Success 200 {object} SubscriptionResponse
+// @Failure 400 {object} response.ErrorBody
+// @Failure 404 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /webhooks/{id} [patch]
+func (h *Handler) UpdateSubscription(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+
+	id, err := parseID(c)
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid subscription id")
+	}
+
+	var req UpdateSubscriptionRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Warn("handler.webhook.update.invalid_body", zap.Error(err))
+		return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid request body")
+	}
+
+	sub, err := h.webhookService.UpdateSubscription(ctx, id, UpdateInput(req))
+	if err != nil {
+		return mapError(c, err, "webhook subscription not found")
+	}
+	return response.OK(c, toSubscriptionResponse(sub))
+}
+
+// DeleteSubscription godoc
+// @Summary Delete a webhook subscription
+// @Description Permanently removes the subscription with its deliveries and logs (manager only)
+// @Tags webhooks
+// @Param id path int true "Subscription ID"
+// @Success 204
+// @Failure 400 {object} response.ErrorBody
+// @Failure 404 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /webhooks/{id} [delete]
+func (h *Handler) DeleteSubscription(c *fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid subscription id")
+	}
+
+	if err := h.webhookService.DeleteSubscription(c.UserContext(), id); err != nil {
+		return mapError(c, err, "webhook subscription not found")
+	}
+	return response.NoContent(c)
+}
+
+// ListDeliveries godoc
+// @Summary List deliveries of a webhook subscription
+// @Description Delivery log newest first (manager only). Filter with field[op]=value, e.g. status=dead
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param status query string false "pending | succeeded | dead"
+// @Param event_type query string false "Event type"
+// @Param limit query int false "Page size"
+// @Param offset query int false "Offset"
+// @Success 200 {array} DeliveryResponse
+// @Failure 400 {object} response.ErrorBody
+// @Failure 404 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /webhooks/{id}/deliveries [get]
+func (h *Handler) ListDeliveries(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	log := ctxlog.From(ctx)
+
+	id, err := parseID(c)
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid subscription id")
+	}
+
+	p, err := query.FromCtx(c, deliverySchema)
+	if err != nil {
+		log.Warn("handler.webhook.deliveries.invalid_input", zap.Error(err))
+		return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", err.Error())
+	}
+
+	out, err := h.webhookService.ListDeliveries(ctx, DeliveryQuery{
+		SubscriptionID: id,
+		Limit:          p.Limit,
+		Offset:         p.Offset,
+		Sort:           p.Sort,
+		Filters:        p.Filters,
+	})
+	if err != nil {
+		return mapError(c, err, "webhook subscription not found")
+	}
+
+	items := make([]DeliveryResponse, len(out.Items))
+	for index, d := range out.Items {
+		items[index] = toDeliveryResponse(d)
+	}
+	return response.Page(c, items, out.Total, p.Limit, p.Offset)
+}
+
+// GetDelivery godoc
+// @Summary Get a webhook delivery
+// @Description Delivery with the body that was sent and every attempt (status code, response body, error, duration)
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Delivery ID"
+// @Success 200 {object} DeliveryDetailResponse
+// @Failure 400 {object} response.ErrorBody
+// @Failure 404 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /webhooks/deliveries/{id} [get]
+func (h *Handler) GetDelivery(c *fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid delivery id")
+	}
+
+	d, err := h.webhookService.GetDelivery(c.UserContext(), id)
+	if err != nil {
+		return mapError(c, err, "webhook delivery not found")
+	}
+
+	log := make([]*AttemptResponse, len(d.Log))
+	for index, a := range d.Log {
+		r := AttemptResponse(*a)
+		log[index] = &r
+	}
+	return response.OK(c, DeliveryDetailResponse{
+		DeliveryResponse: toDeliveryResponse(&d.Delivery),
+		Payload:          d.Payload,
+		Log:              log,
+	})
+}
+
+// Redeliver godoc
+// @Summary Redeliver a webhook
+// @Description Queue the delivery again with a fresh retry budget, e.g. a dead-lettered one after the receiver is fixed (manager only)
+// @Tags webhooks
+// @Param id path int true "Delivery ID"
+// @Success 202
+// @Failure 400 {object} response.ErrorBody
+// @Failure 404 {object} response.ErrorBody
+// @Security BearerAuth
+// @Router /webhooks/deliveries/{id}/redeliver [post]
+func (h *Handler) Redeliver(c *fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid delivery id")
+	}
+
+	if err := h.webhookService.Redeliver(c.UserContext(), id); err != nil {
+		return mapError(c, err, "webhook delivery not found")
+	}
+	return c.SendStatus(fiber.StatusAccepted)
+}
@@ -0,0 +1,175 @@
+package webhook_test
+
+import (
+	"ans-spareparts-api/internal/features/webhook"
+	mocks "ans-spareparts-api/internal/mock"
+	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/response"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type HandlerTestSuite struct {
+	App         *fiber.App
+	MockService *mocks.WebhookService
+	Handler     *webhook.Handler
+}
+
+func NewHandlerTestSuite() *HandlerTestSuite {
+	return &HandlerTestSuite{}
+}
+
+func (ts *HandlerTestSuite) SetUpHandlerTestSuite(t *testing.T) {
+	ts.MockService = mocks.NewWebhookService()
+	ts.Handler = webhook.NewHandler(ts.MockService)
+	ts.App = fiber.New(fiber.Config{
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			return response.Error(c, fiber.StatusBadRequest, "INVALID_REQUEST", "invalid request body: "+err.Error())
+		},
+	})
+
+	t.Cleanup(func() {
+		ts.MockService.AssertExpectations(t)
+	})
+}
+
+func TestWebhookHandler_CreateSubscription(t *testing.T) {
+	mockInput := webhook.CreateInput{URL: "https://shop.example.com/hooks", EventTypes: []string{"product.updated"}}
+
+	tests := []struct {
+		name           string
+		setup          func(*HandlerTestSuite)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name: "Success_Created_Returns_Secret",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("CreateSubscription", mock.Anything, mockInput).
+					Return(&webhook.Subscription{ID: 1, URL: mockInput.URL, EventTypes: mockInput.EventTypes, IsActive: true, Secret: "whsec_x"}, nil).Once()
+			},
+			expectedStatus: fiber.StatusCreated,
+		},
+		{
+			name: "Error_BadRequest_Invalid_Input",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("CreateSubscription", mock.Anything, mockInput).
+					Return(nil, fmt.Errorf("webhook.url: %w", apperror.ErrInvalidInput)).Once()
+			},
+			expectedStatus: fiber.StatusBadRequest,
+			expectedBody: fiber.Map{
+				"code":    "BAD_REQUEST",
+				"message": "webhook.url: invalid input",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewHandlerTestSuite()
+			ts.SetUpHandlerTestSuite(t)
+
+			ts.App.Post("/webhooks", ts.Handler.CreateSubscription)
+			test.setup(ts)
+
+			body, _ := json.Marshal(webhook.CreateSubscriptionRequest{URL: mockInput.URL, EventTypes: mockInput.EventTypes})
+			req := httptest.NewRequest(fiber.MethodPost, "/webhooks", bytes.NewBuffer(body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+			res, _ := ts.App.Test(req, -1)
+
+			assert.Equal(t, test.expectedStatus, res.StatusCode)
+			resBody, _ := io.ReadAll(res.Body)
+			if test.expectedBody != nil {
+				expectedBody, _ := json.Marshal(test.expectedBody)
+				assert.JSONEq(t, string(expectedBody), string(resBody))
+			} else {
+				assert.Contains(t, string(resBody), `"secret":"whsec_x"`)
+			}
+		})
+	}
+}
+
+func TestWebhookHandler_Redeliver(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		setup          func(*HandlerTestSuite)
+		expectedStatus int
+	}{
+		{
+			name: "Success_Accepted",
+			path: "/webhooks/deliveries/9/redeliver",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("Redeliver", mock.Anything, uint(9)).Return(nil).Once()
+			},
+			expectedStatus: fiber.StatusAccepted,
+		},
+		{
+			name: "Error_NotFound",
+			path: "/webhooks/deliveries/9/redeliver",
+			setup: func(hts *HandlerTestSuite) {
+				hts.MockService.On("Redeliver", mock.Anything, uint(9)).Return(apperror.ErrNotFound).Once()
+			},
+			expectedStatus: fiber.StatusNotFound,
+		},
+		{
+			name:           "Error_BadRequest_Invalid_ID",
+			path:           "/webhooks/deliveries/abc/redeliver",
+			setup:          func(hts *HandlerTestSuite) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewHandlerTestSuite()
+			ts.SetUpHandlerTestSuite(t)
+
+			ts.App.Post("/webhooks/deliveries/:id/redeliver", ts.Handler.Redeliver)
+			test.setup(ts)
+
+			res, _ := ts.App.Test(httptest.NewRequest(fiber.MethodPost, test.path, nil), -1)
+
+			assert.Equal(t, test.expectedStatus, res.StatusCode)
+		})
+	}
+}
+
+func TestWebhookHandler_GetDelivery(t *testing.T) {
+	ts := NewHandlerTestSuite()
+	ts.SetUpHandlerTestSuite(t)
+
+	ts.App.Get("/webhooks/deliveries/:id", ts.Handler.GetDelivery)
+	ts.MockService.On("GetDelivery", mock.Anything, uint(9)).Return(&webhook.DeliveryDetail{
+		Delivery: webhook.Delivery{ID: 9, SubscriptionID: 1, Status: "dead", Attempts: 3},
+		Payload:  []byte(`{"id":3}`),
+		Log:      []*webhook.Attempt{{ID: 1, ResponseStatus: 500, Error: "unexpected status 500"}},
+	}, nil).Once()
+
+	res, _ := ts.App.Test(httptest.NewRequest(fiber.MethodGet, "/webhooks/deliveries/9", nil), -1)
+	assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+	var body struct {
+		ID      uint            `json:"id"`
+		Status  string          `json:"status"`
+		Payload json.RawMessage `json:"payload"`
+		Log     []struct {
+			ResponseStatus int `json:"response_status"`
+		} `json:"log"`
+	}
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+	assert.Equal(t, uint(9), body.ID)
+	assert.Equal(t, "dead", body.Status)
+	assert.JSONEq(t, `{"id":3}`, string(body.Payload))
+	assert.Len(t, body.Log, 1)
+	assert.Equal(t, 500, body.Log[0].ResponseStatus)
+}
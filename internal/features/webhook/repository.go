@@ -0,0 +1,285 @@
+package webhook
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/query"
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	CreateSubscription(ctx context.Context, s *domain.WebhookSubscription) error
+	GetSubscription(ctx context.Context, id uint) (*domain.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error)
+	// ListActive subscription ที่เปิดใช้อยู่ (ใช้ตอนกระจาย event)
+	ListActive(ctx context.Context) ([]*domain.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, s *domain.WebhookSubscription) error
+	// DeleteSubscription ลบถาวรพร้อม delivery และ log ทั้งหมด (ON DELETE CASCADE)
+	DeleteSubscription(ctx context.Context, id uint) error
+
+	// EnqueueDeliveries เพิ่ม delivery ใหม่ ข้ามคู่ subscription/event ที่มีอยู่แล้ว (outbox ส่งซ้ำได้)
+	EnqueueDeliveries(ctx context.Context, ds []*domain.WebhookDelivery) error
+	// ClaimDue จอง delivery ที่ถึงเวลาส่ง โดยเลื่อน next_attempt_at ออกไปอีก lease
+	// instance อื่นจึงไม่หยิบซ้ำระหว่างส่ง และถ้า process ตายกลางทางงานจะกลับมาเองเมื่อครบ lease
+	ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error)
+	// SaveAttempt บันทึก log การส่งหนึ่งครั้งและสถานะใหม่ของ delivery ใน transaction เดียว
+	SaveAttempt(ctx context.Context, d *domain.WebhookDelivery, a *domain.WebhookAttempt) error
+	GetDelivery(ctx context.Context, id uint) (*domain.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, q DeliveryQuery) ([]*domain.WebhookDelivery, int64, error)
+	ListAttempts(ctx context.Context, deliveryID uint) ([]*domain.WebhookAttempt, error)
+	// Redeliver ตั้ง delivery กลับเป็น pending พร้อมนับครั้งใหม่ (ใช้กับ dead-letter)
+	Redeliver(ctx context.Context, id uint, now time.Time) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) CreateSubscription(ctx context.Context, s *domain.WebhookSubscription) error {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	if err := r.db.WithContext(ctx).Create(s).Error; err != nil {
+		m := apperror.MapDBError("repo.webhook.createSubscription", err)
+		log.Debug("repo.webhook.createSubscription.fail", zap.Error(err), zap.Duration("duration", time.Since(start)))
+		return m
+	}
+
+	log.Info("repo.webhook.createSubscription.ok", zap.Uint("id", s.ID), zap.Duration("duration", time.Since(start)))
+	return nil
+}
+
+func (r *repository) GetSubscription(ctx context.Context, id uint) (*domain.WebhookSubscription, error) {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	var s domain.WebhookSubscription
+	if err := r.db.WithContext(ctx).First(&s, id).Error; err != nil {
+		m := apperror.MapDBError("repo.webhook.getSubscription", err)
+		log.Debug("repo.webhook.getSubscription.fail", zap.Error(err), zap.Duration("duration", time.Since(start)))
+		return nil, m
+	}
+	return &s, nil
+}
+
+func (r *repository) ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	return r.listSubscriptions(ctx, "repo.webhook.listSubscriptions", r.db.WithContext(ctx))
+}
+
+func (r *repository) ListActive(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	return r.listSubscriptions(ctx, "repo.webhook.listActive", r.db.WithContext(ctx).Where("is_active = ?", true))
+}
+
+func (r *repository) listSubscriptions(ctx context.Context, op string, tx *gorm.DB) ([]*domain.WebhookSubscription, error) {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	var rows []*domain.WebhookSubscription
+	if err := tx.Order("id").Find(&rows).Error; err != nil {
+		m := apperror.MapDBError(op, err)
+		log.Debug(op+".fail", zap.Error(err), zap.Duration("duration", time.Since(start)))
+		return nil, m
+	}
+	return rows, nil
+}
+
+func (r *repository) UpdateSubscription(ctx context.Context, s *domain.WebhookSubscription) error {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	res := r.db.WithContext(ctx).Model(&domain.WebhookSubscription{}).Where("id = ?", s.ID).
+		Updates(map[string]any{
+			"url":         s.URL,
+			"event_types": s.EventTypes,
+			"secret":      s.Secret,
+			"description": s.Description,
+			"is_active":   s.IsActive,
+			"updated_at":  time.Now(),
+		})
+	if res.Error != nil {
+		m := apperror.MapDBError("repo.webhook.updateSubscription", res.Error)
+		log.Debug("repo.webhook.updateSubscription.fail", zap.Error(res.Error), zap.Duration("duration", time.Since(start)))
+		return m
+	}
+	if res.RowsAffected == 0 {
+		return apperror.MapDBError("repo.webhook.updateSubscription", gorm.ErrRecordNotFound)
+	}
+
+	log.Info("repo.webhook.updateSubscription.ok", zap.Uint("id", s.ID), zap.Duration("duration", time.Since(start)))
+	return nil
+}
+
+func (r *repository) DeleteSubscription(ctx context.Context, id uint) error {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	res := r.db.WithContext(ctx).Delete(&domain.WebhookSubscription{}, id)
+	if res.Error != nil {
+		m := apperror.MapDBError("repo.webhook.deleteSubscription", res.Error)
+		log.Debug("repo.webhook.deleteSubscription.fail", zap.Error(res.Error), zap.Duration("duration", time.Since(start)))
+		return m
+	}
+	if res.RowsAffected == 0 {
+		return apperror.MapDBError("repo.webhook.deleteSubscription", gorm.ErrRecordNotFound)
+	}
+
+	log.Info("repo.webhook.deleteSubscription.ok", zap.Uint("id", id), zap.Duration("duration", time.Since(start)))
+	return nil
+}
+
+func (r *repository) EnqueueDeliveries(ctx context.Context, ds []*domain.WebhookDelivery) error {
+	if len(ds) == 0 {
+		return nil
+	}
+	log := ctxlog.From(ctx)
+
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "subscription_id"}, {Name: "event_id"}},
+			DoNothing: true,
+		}).
+		Create(&ds).Error; err != nil {
+		m := apperror.MapDBError("repo.webhook.enqueueDeliveries", err)
+		log.Debug("repo.webhook.enqueueDeliveries.fail", zap.Error(err))
+		return m
+	}
+	return nil
+}
+
+func (r *repository) ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error) {
+	log := ctxlog.From(ctx)
+
+	var rows []*domain.WebhookDelivery
+	if err := r.db.WithContext(ctx).Raw(`
+		UPDATE webhook_deliveries SET next_attempt_at = ?
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = ? AND next_attempt_at <= ?
+			ORDER BY next_attempt_at, id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		now.Add(lease), domain.WebhookDeliveryPending, now, limit,
+	).Scan(&rows).Error; err != nil {
+		m := apperror.MapDBError("repo.webhook.claimDue", err)
+		log.Debug("repo.webhook.claimDue.fail", zap.Error(err))
+		return nil, m
+	}
+	return rows, nil
+}
+
+func (r *repository) SaveAttempt(ctx context.Context, d *domain.WebhookDelivery, a *domain.WebhookAttempt) error {
+	log := ctxlog.From(ctx)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(a).Error; err != nil {
+			return err
+		}
+		return tx.Model(&domain.WebhookDelivery{}).Where("id = ?", d.ID).
+			Updates(map[string]any{
+				"status":          d.Status,
+				"attempts":        d.Attempts,
+				"next_attempt_at": d.NextAttemptAt,
+				"response_status": d.ResponseStatus,
+				"last_error":      d.LastError,
+				"delivered_at":    d.DeliveredAt,
+			}).Error
+	})
+	if err != nil {
+		m := apperror.MapDBError("repo.webhook.saveAttempt", err)
+		log.Debug("repo.webhook.saveAttempt.fail", zap.Uint("delivery_id", d.ID), zap.Error(err))
+		return m
+	}
+	return nil
+}
+
+func (r *repository) GetDelivery(ctx context.Context, id uint) (*domain.WebhookDelivery, error) {
+	log := ctxlog.From(ctx)
+
+	var d domain.WebhookDelivery
+	if err := r.db.WithContext(ctx).First(&d, id).Error; err != nil {
+		m := apperror.MapDBError("repo.webhook.getDelivery", err)
+		log.Debug("repo.webhook.getDelivery.fail", zap.Error(err))
+		return nil, m
+	}
+	return &d, nil
+}
+
+func (r *repository) ListDeliveries(ctx context.Context, q DeliveryQuery) ([]*domain.WebhookDelivery, int64, error) {
+	log := ctxlog.From(ctx)
+	start := time.Now()
+
+	tx := query.Where(r.db.WithContext(ctx).Model(&domain.WebhookDelivery{}), q.Filters).
+		Where("subscription_id = ?", q.SubscriptionID)
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		m := apperror.MapDBError("repo.webhook.listDeliveries.count", err)
+		log.Debug("repo.webhook.listDeliveries.count.fail", zap.Error(err))
+		return nil, 0, m
+	}
+
+	tx = query.Order(tx, deliverySchema.SortOrDefault(q.Sort))
+	if q.Offset != 0 {
+		tx = tx.Offset(q.Offset)
+	}
+	if q.Limit != 0 {
+		tx = tx.Limit(q.Limit)
+	}
+
+	// payload อาจใหญ่ ไม่ต้องดึงมาในหน้ารายการ
+	var rows []*domain.WebhookDelivery
+	if err := tx.Omit("payload").Find(&rows).Error; err != nil {
+		m := apperror.MapDBError("repo.webhook.listDeliveries", err)
+		log.Debug("repo.webhook.listDeliveries.fail", zap.Error(err), zap.Duration("duration", time.Since(start)))
+		return nil, 0, m
+	}
+
+	log.Debug("repo.webhook.listDeliveries.ok", zap.Int("count", len(rows)), zap.Duration("duration", time.Since(start)))
+	return rows, total, nil
+}
+
+func (r *repository) ListAttempts(ctx context.Context, deliveryID uint) ([]*domain.WebhookAttempt, error) {
+	log := ctxlog.From(ctx)
+
+	var rows []*domain.WebhookAttempt
+	if err := r.db.WithContext(ctx).Where("delivery_id = ?", deliveryID).Order("id").Find(&rows).Error; err != nil {
+		m := apperror.MapDBError("repo.webhook.listAttempts", err)
+		log.Debug("repo.webhook.listAttempts.fail", zap.Error(err))
+		return nil, m
+	}
+	return rows, nil
+}
+
+func (r *repository) Redeliver(ctx context.Context, id uint, now time.Time) error {
+	log := ctxlog.From(ctx)
+
+	res := r.db.WithContext(ctx).Model(&domain.WebhookDelivery{}).Where("id = ?", id).
+		Updates(map[string]any{
+			"status":          domain.WebhookDeliveryPending,
+			"attempts":        0,
+			"next_attempt_at": now,
+		})
+	if res.Error != nil {
+		m := apperror.MapDBError("repo.webhook.redeliver", res.Error)
+		log.Debug("repo.webhook.redeliver.fail", zap.Error(res.Error))
+		return m
+	}
+	if res.RowsAffected == 0 {
+		return apperror.MapDBError("repo.webhook.redeliver", gorm.ErrRecordNotFound)
+	}
+
+	log.Info("repo.webhook.redeliver.ok", zap.Uint("id", id))
+	return nil
+}
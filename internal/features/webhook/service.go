@@ -0,0 +1,405 @@
+package webhook
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/internal/infra/outbox"
+	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/utils"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxLoggedBody จำนวน byte ของ response ที่เก็บใน log การส่ง
+const maxLoggedBody = 1024
+
+type Service interface {
+	CreateSubscription(ctx context.Context, in CreateInput) (*Subscription, error)
+	GetSubscription(ctx context.Context, id uint) (*Subscription, error)
+	ListSubscriptions(ctx context.Context) ([]*Subscription, error)
+	UpdateSubscription(ctx context.Context, id uint, in UpdateInput) (*Subscription, error)
+	DeleteSubscription(ctx context.Context, id uint) error
+
+	ListDeliveries(ctx context.Context, q DeliveryQuery) (*DeliveryListOutput, error)
+	GetDelivery(ctx context.Context, id uint) (*DeliveryDetail, error)
+	// Redeliver ส่ง delivery เดิมอีกครั้ง (เช่นหลังปลายทางแก้ปัญหาแล้ว) นับจำนวนครั้งใหม่
+	Redeliver(ctx context.Context, id uint) error
+	// DeliverDue ส่ง delivery ที่ถึงเวลาหนึ่งรอบ คืนจำนวนที่ส่ง
+	DeliverDue(ctx context.Context) (int, error)
+}
+
+type Options struct {
+	MaxAttempts int           // ส่งไม่สำเร็จครบเท่านี้แล้วเป็น dead
+	Timeout     time.Duration // timeout ต่อหนึ่ง request
+	BatchSize   int
+	Client      *http.Client
+}
+
+type service struct {
+	webhookRepo Repository
+	opt         Options
+}
+
+func NewService(webhookRepo Repository, opt Options) Service {
+	if opt.MaxAttempts <= 0 {
+		opt.MaxAttempts = 8
+	}
+	if opt.Timeout <= 0 {
+		opt.Timeout = 10 * time.Second
+	}
+	if opt.BatchSize <= 0 {
+		opt.BatchSize = 50
+	}
+	if opt.Client == nil {
+		opt.Client = &http.Client{}
+	}
+	return &service{
+		webhookRepo: webhookRepo,
+		opt:         opt,
+	}
+}
+
+// --- helper ---
+
+func validateURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("webhook.url: %w", apperror.ErrInvalidInput)
+	}
+	return raw, nil
+}
+
+// normalizeEventTypes ต้องมีอย่างน้อยหนึ่งชนิด และต้องอยู่ในแค็ตตาล็อก (หรือ "*")
+func normalizeEventTypes(types []string) (domain.StringList, error) {
+	out := make(domain.StringList, 0, len(types))
+	for _, t := range types {
+		t = strings.TrimSpace(t)
+		if t != domain.WebhookAllEvents && !slices.Contains(domain.EventTypes, t) {
+			return nil, fmt.Errorf("webhook.event_types %q: %w", t, apperror.ErrInvalidInput)
+		}
+		if !slices.Contains(out, t) {
+			out = append(out, t)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("webhook.event_types: %w", apperror.ErrInvalidInput)
+	}
+	return out, nil
+}
+
+func toSubscription(s *domain.WebhookSubscription) *Subscription {
+	return &Subscription{
+		ID:          s.ID,
+		URL:         s.URL,
+		EventTypes:  s.EventTypes,
+		Description: s.Description,
+		IsActive:    s.IsActive,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}
+}
+
+func toDelivery(d *domain.WebhookDelivery) *Delivery {
+	return &Delivery{
+		ID:             d.ID,
+		SubscriptionID: d.SubscriptionID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+		ResponseStatus: d.ResponseStatus,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt,
+		DeliveredAt:    d.DeliveredAt,
+	}
+}
+
+// --- subscriptions ---
+
+func (i *service) CreateSubscription(ctx context.Context, in CreateInput) (*Subscription, error) {
+	log := ctxlog.From(ctx)
+
+	u, err := validateURL(in.URL)
+	if err != nil {
+		return nil, err
+	}
+	types, err := normalizeEventTypes(in.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+	secret := strings.TrimSpace(in.Secret)
+	if secret == "" {
+		if secret, err = GenerateSecret(); err != nil {
+			log.Error("webhook.create.secret_fail", zap.Error(err))
+			return nil, apperror.ErrInternalServer
+		}
+	}
+
+	sub := &domain.WebhookSubscription{
+		URL:         u,
+		EventTypes:  types,
+		Secret:      secret,
+		Description: strings.TrimSpace(in.Description),
+		IsActive:    true,
+	}
+	if err := i.webhookRepo.CreateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+
+	log.Info("webhook.subscription.created", zap.Uint("id", sub.ID), zap.Strings("event_types", types))
+	out := toSubscription(sub)
+	out.Secret = secret
+	return out, nil
+}
+
+func (i *service) GetSubscription(ctx context.Context, id uint) (*Subscription, error) {
+	sub, err := i.webhookRepo.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toSubscription(sub), nil
+}
+
+func (i *service) ListSubscriptions(ctx context.Context) ([]*Subscription, error) {
+	subs, err := i.webhookRepo.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Subscription, 0, len(subs))
+	for _, s := range subs {
+		out = append(out, toSubscription(s))
+	}
+	return out, nil
+}
+
+func (i *service) UpdateSubscription(ctx context.Context, id uint, in UpdateInput) (*Subscription, error) {
+	log := ctxlog.From(ctx)
+
+	sub, err := i.webhookRepo.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.URL != nil {
+		if sub.URL, err = validateURL(*in.URL); err != nil {
+			return nil, err
+		}
+	}
+	if in.EventTypes != nil {
+		if sub.EventTypes, err = normalizeEventTypes(in.EventTypes); err != nil {
+			return nil, err
+		}
+	}
+	if in.Secret != nil {
+		secret := strings.TrimSpace(*in.Secret)
+		if secret == "" {
+			return nil, fmt.Errorf("webhook.secret: %w", apperror.ErrInvalidInput)
+		}
+		sub.Secret = secret
+	}
+	if in.Description != nil {
+		sub.Description = strings.TrimSpace(*in.Description)
+	}
+	if in.IsActive != nil {
+		sub.IsActive = *in.IsActive
+	}
+
+	if err := i.webhookRepo.UpdateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+
+	log.Info("webhook.subscription.updated", zap.Uint("id", id))
+	out := toSubscription(sub)
+	if in.Secret != nil {
+		out.Secret = sub.Secret
+	}
+	return out, nil
+}
+
+func (i *service) DeleteSubscription(ctx context.Context, id uint) error {
+	if err := i.webhookRepo.DeleteSubscription(ctx, id); err != nil {
+		return err
+	}
+	ctxlog.From(ctx).Info("webhook.subscription.deleted", zap.Uint("id", id))
+	return nil
+}
+
+// --- deliveries ---
+
+func (i *service) ListDeliveries(ctx context.Context, q DeliveryQuery) (*DeliveryListOutput, error) {
+	if _, err := i.webhookRepo.GetSubscription(ctx, q.SubscriptionID); err != nil {
+		return nil, err
+	}
+
+	limit, offset := utils.NormalizePagination(q.Limit, q.Offset)
+	rows, total, err := i.webhookRepo.ListDeliveries(ctx, DeliveryQuery{
+		SubscriptionID: q.SubscriptionID,
+		Limit:          limit,
+		Offset:         offset,
+		Sort:           q.Sort,
+		Filters:        q.Filters,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*Delivery, 0, len(rows))
+	for _, d := range rows {
+		items = append(items, toDelivery(d))
+	}
+	return &DeliveryListOutput{Items: items, Total: total}, nil
+}
+
+func (i *service) GetDelivery(ctx context.Context, id uint) (*DeliveryDetail, error) {
+	d, err := i.webhookRepo.GetDelivery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	attempts, err := i.webhookRepo.ListAttempts(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &DeliveryDetail{Delivery: *toDelivery(d), Payload: d.Payload, Log: make([]*Attempt, 0, len(attempts))}
+	for _, a := range attempts {
+		out.Log = append(out.Log, &Attempt{
+			ID:             a.ID,
+			ResponseStatus: a.ResponseStatus,
+			ResponseBody:   a.ResponseBody,
+			Error:          a.Error,
+			DurationMs:     a.DurationMs,
+			CreatedAt:      a.CreatedAt,
+		})
+	}
+	return out, nil
+}
+
+func (i *service) Redeliver(ctx context.Context, id uint) error {
+	if err := i.webhookRepo.Redeliver(ctx, id, time.Now()); err != nil {
+		return err
+	}
+	ctxlog.From(ctx).Info("webhook.delivery.redeliver", zap.Uint("id", id))
+	return nil
+}
+
+func (i *service) DeliverDue(ctx context.Context) (int, error) {
+	log := ctxlog.From(ctx)
+
+	// lease ยาวกว่าเวลาส่งทั้ง batch แบบเลวร้ายสุดเล็กน้อย
+	lease := time.Duration(i.opt.BatchSize)*i.opt.Timeout + time.Minute
+	ds, err := i.webhookRepo.ClaimDue(ctx, time.Now(), i.opt.BatchSize, lease)
+	if err != nil {
+		return 0, err
+	}
+
+	subs := make(map[uint]*domain.WebhookSubscription)
+	for _, d := range ds {
+		sub, ok := subs[d.SubscriptionID]
+		if !ok {
+			if sub, err = i.webhookRepo.GetSubscription(ctx, d.SubscriptionID); err != nil {
+				if !errors.Is(err, apperror.ErrNotFound) {
+					return 0, err
+				}
+				// ถูกลบไประหว่างนั้น delivery จะหายไปตาม cascade
+				sub = nil
+			}
+			subs[d.SubscriptionID] = sub
+		}
+		if sub == nil {
+			continue
+		}
+
+		// subscription ที่ปิดอยู่ไม่ส่ง ย้ายเป็น dead ทันที (max 0) ให้ redeliver เองหลังเปิดใหม่
+		a, maxAttempts := &domain.WebhookAttempt{DeliveryID: d.ID, Error: "subscription is inactive"}, 0
+		if sub.IsActive {
+			a, maxAttempts = i.send(ctx, sub, d), i.opt.MaxAttempts
+		}
+		settle(d, a, maxAttempts, time.Now())
+		if err := i.webhookRepo.SaveAttempt(ctx, d, a); err != nil {
+			return 0, err
+		}
+		if d.Status == domain.WebhookDeliveryDead {
+			log.Warn("webhook.delivery.dead",
+				zap.Uint("delivery_id", d.ID),
+				zap.Uint("subscription_id", d.SubscriptionID),
+				zap.String("event_type", d.EventType),
+				zap.String("error", d.LastError),
+			)
+		}
+	}
+
+	if len(ds) > 0 {
+		log.Debug("webhook.deliver_due.ok", zap.Int("count", len(ds)))
+	}
+	return len(ds), nil
+}
+
+// send POST payload ไปยังปลายทางพร้อมลายเซ็น คืน log ของครั้งนี้ (ไม่คืน error)
+func (i *service) send(ctx context.Context, sub *domain.WebhookSubscription, d *domain.WebhookDelivery) *domain.WebhookAttempt {
+	ctx, cancel := context.WithTimeout(ctx, i.opt.Timeout)
+	defer cancel()
+
+	a := &domain.WebhookAttempt{DeliveryID: d.ID}
+	start := time.Now()
+	defer func() { a.DurationMs = time.Since(start).Milliseconds() }()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		a.Error = err.Error()
+		return a
+	}
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ans-spareparts-webhook/1")
+	req.Header.Set(HeaderDelivery, strconv.FormatUint(uint64(d.ID), 10))
+	req.Header.Set(HeaderEvent, d.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, ts, d.Payload))
+
+	res, err := i.opt.Client.Do(req)
+	if err != nil {
+		a.Error = err.Error()
+		return a
+	}
+	defer res.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, maxLoggedBody))
+	_, _ = io.Copy(io.Discard, res.Body)
+	a.ResponseStatus = res.StatusCode
+	a.ResponseBody = string(body)
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		a.Error = fmt.Sprintf("unexpected status %d", res.StatusCode)
+	}
+	return a
+}
+
+// settle อัปเดตสถานะ delivery จากผลการส่งหนึ่งครั้ง (backoff เดียวกับ outbox)
+func settle(d *domain.WebhookDelivery, a *domain.WebhookAttempt, maxAttempts int, now time.Time) {
+	d.Attempts++
+	d.ResponseStatus = a.ResponseStatus
+	d.LastError = a.Error
+
+	switch {
+	case a.Error == "":
+		d.Status = domain.WebhookDeliverySucceeded
+		d.DeliveredAt = &now
+	case d.Attempts >= maxAttempts:
+		d.Status = domain.WebhookDeliveryDead
+	default:
+		d.Status = domain.WebhookDeliveryPending
+		d.NextAttemptAt = now.Add(outbox.RetryDelay(d.Attempts))
+	}
+}
@@ -0,0 +1,264 @@
+package webhook_test
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/features/webhook"
+	"ans-spareparts-api/internal/infra/outbox"
+	mocks "ans-spareparts-api/internal/mock"
+	"ans-spareparts-api/pkg/apperror"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type TestSuite struct {
+	Service         webhook.Service
+	MockWebhookRepo *mocks.WebhookRepository
+	Ctx             context.Context
+}
+
+func NewTestSuite() *TestSuite {
+	return &TestSuite{}
+}
+
+func (ts *TestSuite) SetupTest(t *testing.T) {
+	ts.MockWebhookRepo = mocks.NewMockWebhookRepository()
+	ts.Service = webhook.NewService(ts.MockWebhookRepo, webhook.Options{MaxAttempts: 3, Timeout: 2 * time.Second, BatchSize: 10})
+	ts.Ctx = context.Background()
+
+	t.Cleanup(func() {
+		ts.MockWebhookRepo.AssertExpectations(t)
+	})
+}
+
+// receiver ปลายทางจำลอง ตรวจลายเซ็นทุก request และตอบตาม status ที่กำหนด
+type receiver struct {
+	*httptest.Server
+	status int32
+	calls  int32
+	badSig int32
+}
+
+func newReceiver(t *testing.T, secret string) *receiver {
+	r := &receiver{status: http.StatusOK}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&r.calls, 1)
+		body, _ := io.ReadAll(req.Body)
+		if err := webhook.Verify(secret, req.Header.Get(webhook.HeaderTimestamp), req.Header.Get(webhook.HeaderSignature), body, time.Minute, time.Now()); err != nil {
+			atomic.AddInt32(&r.badSig, 1)
+		}
+		w.WriteHeader(int(atomic.LoadInt32(&r.status)))
+		_, _ = w.Write([]byte("ack"))
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func TestWebhookService_DeliverDue(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		attempts   int
+		inactive   bool
+		wantStatus string
+		wantCalls  int32
+	}{
+		{name: "Success_Delivered", status: http.StatusNoContent, wantStatus: domain.WebhookDeliverySucceeded, wantCalls: 1},
+		{name: "Error_Retry_Later", status: http.StatusInternalServerError, wantStatus: domain.WebhookDeliveryPending, wantCalls: 1},
+		{name: "Error_Dead_After_Max_Attempts", status: http.StatusBadGateway, attempts: 2, wantStatus: domain.WebhookDeliveryDead, wantCalls: 1},
+		{name: "Inactive_Subscription_Dead_Without_Sending", status: http.StatusOK, inactive: true, wantStatus: domain.WebhookDeliveryDead, wantCalls: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := NewTestSuite()
+			ts.SetupTest(t)
+
+			recv := newReceiver(t, "s3cret")
+			recv.status = int32(tt.status)
+
+			sub := &domain.WebhookSubscription{ID: 1, URL: recv.URL, Secret: "s3cret", IsActive: !tt.inactive, EventTypes: domain.StringList{"*"}}
+			d := &domain.WebhookDelivery{ID: 9, SubscriptionID: 1, EventID: 3, EventType: domain.EventProductUpdated,
+				Payload: []byte(`{"id":3}`), Status: domain.WebhookDeliveryPending, Attempts: tt.attempts}
+
+			ts.MockWebhookRepo.On("ClaimDue", ts.Ctx, mock.Anything, 10, mock.Anything).Return([]*domain.WebhookDelivery{d}, nil).Once()
+			ts.MockWebhookRepo.On("GetSubscription", ts.Ctx, uint(1)).Return(sub, nil).Once()
+			ts.MockWebhookRepo.On("SaveAttempt", ts.Ctx, d, mock.AnythingOfType("*domain.WebhookAttempt")).Return(nil).Once()
+
+			n, err := ts.Service.DeliverDue(ts.Ctx)
+
+			require.NoError(t, err)
+			assert.Equal(t, 1, n)
+			assert.Equal(t, tt.wantCalls, recv.calls)
+			assert.Zero(t, recv.badSig)
+			assert.Equal(t, tt.wantStatus, d.Status)
+			assert.Equal(t, tt.attempts+1, d.Attempts)
+
+			a := ts.MockWebhookRepo.Calls[2].Arguments.Get(2).(*domain.WebhookAttempt)
+			assert.Equal(t, uint(9), a.DeliveryID)
+			switch tt.wantStatus {
+			case domain.WebhookDeliverySucceeded:
+				assert.NotNil(t, d.DeliveredAt)
+				assert.Empty(t, a.Error)
+				assert.Equal(t, tt.status, a.ResponseStatus)
+			case domain.WebhookDeliveryPending:
+				assert.WithinDuration(t, time.Now().Add(outbox.RetryDelay(1)), d.NextAttemptAt, time.Second)
+				assert.Equal(t, "ack", a.ResponseBody)
+				assert.NotEmpty(t, d.LastError)
+			default:
+				assert.Nil(t, d.DeliveredAt)
+				assert.NotEmpty(t, d.LastError)
+			}
+		})
+	}
+}
+
+func TestWebhookService_DeliverDue_DeletedSubscription(t *testing.T) {
+	ts := NewTestSuite()
+	ts.SetupTest(t)
+
+	d := &domain.WebhookDelivery{ID: 9, SubscriptionID: 1}
+	ts.MockWebhookRepo.On("ClaimDue", ts.Ctx, mock.Anything, 10, mock.Anything).Return([]*domain.WebhookDelivery{d}, nil).Once()
+	ts.MockWebhookRepo.On("GetSubscription", ts.Ctx, uint(1)).Return(nil, apperror.ErrNotFound).Once()
+
+	n, err := ts.Service.DeliverDue(ts.Ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	ts.MockWebhookRepo.AssertNotCalled(t, "SaveAttempt", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWebhookService_CreateSubscription(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     webhook.CreateInput
+		setup     func(ts *TestSuite)
+		assertOut func(*testing.T, *webhook.Subscription, error)
+	}{
+		{
+			name:  "Success_Generates_Secret",
+			input: webhook.CreateInput{URL: " https://shop.example.com/hooks ", EventTypes: []string{domain.EventProductUpdated, domain.EventProductUpdated, domain.EventInventoryQuantityUpdated}},
+			setup: func(ts *TestSuite) {
+				ts.MockWebhookRepo.On("CreateSubscription", ts.Ctx, mock.MatchedBy(func(s *domain.WebhookSubscription) bool {
+					s.ID = 1
+					return s.URL == "https://shop.example.com/hooks" && len(s.EventTypes) == 2 && s.IsActive && s.Secret != ""
+				})).Return(nil).Once()
+			},
+			assertOut: func(t *testing.T, out *webhook.Subscription, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, uint(1), out.ID)
+				assert.Regexp(t, `^whsec_`, out.Secret)
+			},
+		},
+		{
+			name:  "Success_Custom_Secret",
+			input: webhook.CreateInput{URL: "http://acc.local/in", EventTypes: []string{"*"}, Secret: "mine"},
+			setup: func(ts *TestSuite) {
+				ts.MockWebhookRepo.On("CreateSubscription", ts.Ctx, mock.MatchedBy(func(s *domain.WebhookSubscription) bool {
+					return s.Secret == "mine"
+				})).Return(nil).Once()
+			},
+			assertOut: func(t *testing.T, out *webhook.Subscription, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, "mine", out.Secret)
+			},
+		},
+		{
+			name:  "Error_Invalid_URL",
+			input: webhook.CreateInput{URL: "ftp://example.com", EventTypes: []string{"*"}},
+			setup: func(ts *TestSuite) {},
+			assertOut: func(t *testing.T, _ *webhook.Subscription, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidInput)
+			},
+		},
+		{
+			name:  "Error_Unknown_Event_Type",
+			input: webhook.CreateInput{URL: "https://example.com", EventTypes: []string{"product.exploded"}},
+			setup: func(ts *TestSuite) {},
+			assertOut: func(t *testing.T, _ *webhook.Subscription, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidInput)
+			},
+		},
+		{
+			name:  "Error_No_Event_Types",
+			input: webhook.CreateInput{URL: "https://example.com"},
+			setup: func(ts *TestSuite) {},
+			assertOut: func(t *testing.T, _ *webhook.Subscription, err error) {
+				assert.ErrorIs(t, err, apperror.ErrInvalidInput)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := NewTestSuite()
+			ts.SetupTest(t)
+			tt.setup(ts)
+
+			out, err := ts.Service.CreateSubscription(ts.Ctx, tt.input)
+			tt.assertOut(t, out, err)
+		})
+	}
+}
+
+func TestWebhookService_UpdateSubscription_Pause(t *testing.T) {
+	ts := NewTestSuite()
+	ts.SetupTest(t)
+
+	off := false
+	ts.MockWebhookRepo.On("GetSubscription", ts.Ctx, uint(1)).
+		Return(&domain.WebhookSubscription{ID: 1, URL: "https://example.com", Secret: "s", IsActive: true, EventTypes: domain.StringList{"*"}}, nil).Once()
+	ts.MockWebhookRepo.On("UpdateSubscription", ts.Ctx, mock.MatchedBy(func(s *domain.WebhookSubscription) bool {
+		return !s.IsActive && s.Secret == "s" && s.URL == "https://example.com"
+	})).Return(nil).Once()
+
+	out, err := ts.Service.UpdateSubscription(ts.Ctx, 1, webhook.UpdateInput{IsActive: &off})
+
+	require.NoError(t, err)
+	assert.False(t, out.IsActive)
+	assert.Empty(t, out.Secret)
+}
+
+func TestSink_Publish(t *testing.T) {
+	repo := mocks.NewMockWebhookRepository()
+	sink := webhook.NewSink(repo)
+	ctx := context.Background()
+
+	repo.On("ListActive", ctx).Return([]*domain.WebhookSubscription{
+		{ID: 1, EventTypes: domain.StringList{domain.EventInventoryQuantityUpdated}},
+		{ID: 2, EventTypes: domain.StringList{domain.EventProductCreated}},
+		{ID: 3, EventTypes: domain.StringList{"*"}},
+	}, nil).Once()
+	repo.On("EnqueueDeliveries", ctx, mock.Anything).Return(nil).Once()
+
+	err := sink.Publish(ctx, outbox.Envelope{
+		ID: 42, Type: domain.EventInventoryQuantityUpdated, AggregateType: "inventory", AggregateID: 7,
+		Payload: []byte(`{"product_id":7,"quantity":3}`),
+	})
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+
+	ds := repo.Calls[1].Arguments.Get(1).([]*domain.WebhookDelivery)
+	require.Len(t, ds, 2)
+	assert.Equal(t, uint(1), ds[0].SubscriptionID)
+	assert.Equal(t, uint(3), ds[1].SubscriptionID)
+	for _, d := range ds {
+		assert.Equal(t, uint(42), d.EventID)
+		assert.Equal(t, domain.WebhookDeliveryPending, d.Status)
+
+		var body webhook.Body
+		require.NoError(t, json.Unmarshal(d.Payload, &body))
+		assert.Equal(t, uint(42), body.ID)
+		assert.Equal(t, uint(7), body.AggregateID)
+		assert.JSONEq(t, `{"product_id":7,"quantity":3}`, string(body.Data))
+	}
+}
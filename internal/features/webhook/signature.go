@@ -0,0 +1,60 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// header ที่แนบไปกับทุก request ของ webhook
+const (
+	HeaderDelivery  = "X-Webhook-Delivery"  // id ของ delivery ใช้กันซ้ำฝั่งผู้รับ
+	HeaderEvent     = "X-Webhook-Event"     // ชนิด event เช่น product.updated
+	HeaderTimestamp = "X-Webhook-Timestamp" // unix seconds ตอนส่ง
+	HeaderSignature = "X-Webhook-Signature" // "v1=" + hex(HMAC-SHA256(secret, timestamp + "." + body))
+)
+
+const signaturePrefix = "v1="
+
+var (
+	ErrInvalidSignature = errors.New("webhook: invalid signature")
+	ErrStaleTimestamp   = errors.New("webhook: timestamp outside tolerance")
+)
+
+// Sign ลายเซ็นของ body; ใส่ timestamp ในข้อความที่เซ็นเพื่อกัน replay
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify ตรวจลายเซ็นฝั่งผู้รับ (ใช้ในเทสต์และเป็นตัวอย่างให้ระบบปลายทาง)
+// timestamp ต้องห่างจาก now ไม่เกิน tolerance
+func Verify(secret, timestamp, signature string, body []byte, tolerance time.Duration, now time.Time) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if d := now.Sub(time.Unix(ts, 0)); d > tolerance || d < -tolerance {
+		return ErrStaleTimestamp
+	}
+	if !hmac.Equal([]byte(signature), []byte(Sign(secret, ts, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// GenerateSecret secret สุ่มสำหรับ subscription ที่ไม่ได้ระบุมาเอง
+func GenerateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
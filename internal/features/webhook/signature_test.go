@@ -0,0 +1,49 @@
+package webhook_test
+
+import (
+	"ans-spareparts-api/internal/features/webhook"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignVerify(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	body := []byte(`{"id":1,"type":"product.updated"}`)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	sig := webhook.Sign("s3cret", now.Unix(), body)
+
+	assert.Regexp(t, `^v1=[0-9a-f]{64}$`, sig)
+	assert.NoError(t, webhook.Verify("s3cret", ts, sig, body, 5*time.Minute, now.Add(time.Minute)))
+
+	tests := []struct {
+		name    string
+		secret  string
+		ts      string
+		body    []byte
+		now     time.Time
+		wantErr error
+	}{
+		{name: "Wrong_Secret", secret: "other", ts: ts, body: body, now: now, wantErr: webhook.ErrInvalidSignature},
+		{name: "Tampered_Body", secret: "s3cret", ts: ts, body: []byte(`{"id":2}`), now: now, wantErr: webhook.ErrInvalidSignature},
+		{name: "Tampered_Timestamp", secret: "s3cret", ts: strconv.FormatInt(now.Unix()+1, 10), body: body, now: now, wantErr: webhook.ErrInvalidSignature},
+		{name: "Invalid_Timestamp", secret: "s3cret", ts: "abc", body: body, now: now, wantErr: webhook.ErrInvalidSignature},
+		{name: "Stale_Timestamp", secret: "s3cret", ts: ts, body: body, now: now.Add(10 * time.Minute), wantErr: webhook.ErrStaleTimestamp},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, webhook.Verify(tt.secret, tt.ts, sig, tt.body, 5*time.Minute, tt.now), tt.wantErr)
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	a, err := webhook.GenerateSecret()
+	assert.NoError(t, err)
+	b, _ := webhook.GenerateSecret()
+
+	assert.Regexp(t, `^whsec_[0-9a-f]{64}$`, a)
+	assert.NotEqual(t, a, b)
+}
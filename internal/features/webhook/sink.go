@@ -0,0 +1,58 @@
+package webhook
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/infra/outbox"
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Sink กระจาย event จาก outbox เป็น delivery ของทุก subscription ที่สมัครรับ
+// (ลงทะเบียนกับ outbox relay) การส่งจริงและ retry ต่อปลายทางทำโดย RunDeliverer
+// ปลายทางหนึ่งล่มจึงไม่ทำให้ปลายทางอื่นได้รับซ้ำ
+type Sink struct {
+	webhookRepo Repository
+}
+
+func NewSink(webhookRepo Repository) *Sink {
+	return &Sink{webhookRepo: webhookRepo}
+}
+
+func (s *Sink) Name() string { return "webhook_subscriptions" }
+
+func (s *Sink) Publish(ctx context.Context, env outbox.Envelope) error {
+	subs, err := s.webhookRepo.ListActive(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(Body{
+		ID:            env.ID,
+		Type:          env.Type,
+		AggregateType: env.AggregateType,
+		AggregateID:   env.AggregateID,
+		OccurredAt:    env.OccurredAt,
+		Data:          env.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var ds []*domain.WebhookDelivery
+	for _, sub := range subs {
+		if !sub.Wants(env.Type) {
+			continue
+		}
+		ds = append(ds, &domain.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			EventID:        env.ID,
+			EventType:      env.Type,
+			Payload:        body,
+			Status:         domain.WebhookDeliveryPending,
+			NextAttemptAt:  now,
+		})
+	}
+	return s.webhookRepo.EnqueueDeliveries(ctx, ds)
+}
@@ -10,6 +10,7 @@ import (
 type ctxKey string
 
 var loggerKey ctxKey = "ctx_logger"
+var requestIDKey ctxKey = "ctx_request_id"
 
 // With ใส่ logger ลงใน context (ควรเรียกครั้งแรกใน middleware)
 func With(ctx context.Context, zapLogger *zap.Logger) context.Context {
@@ -48,3 +49,14 @@ func AddFields(ctx context.Context, fields ...zap.Field) {
 		setter.SetUserContext(newCtx)
 	}
 }
+
+// WithRequestID ผูก request ID ลง context ให้ชั้นที่ไม่เห็น fiber.Ctx (เช่น audit) อ่านได้
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID คืนค่าว่างถ้าไม่ได้มาจาก HTTP request (เช่น background job)
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
@@ -0,0 +1,156 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// ErrLockLost คีย์ไม่ได้เป็นของ token นี้แล้ว (lock หมดอายุและมี request อื่นจองต่อ หรือถูกลบไป)
+var ErrLockLost = errors.New("idempotency: lock is not held by this owner")
+
+// Record สถานะของ Idempotency-Key หนึ่งคีย์: กำลังทำ (Done=false) หรือเสร็จแล้วพร้อม response ที่ตอบไป
+type Record struct {
+	Fingerprint string    `json:"fingerprint"`
+	Owner       string    `json:"owner,omitempty"` // token สุ่มของผู้จอง มีเฉพาะตอนกำลังทำ
+	Done        bool      `json:"done"`
+	Status      int       `json:"status,omitempty"`
+	ContentType string    `json:"content_type,omitempty"`
+	Body        []byte    `json:"body,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type Store interface {
+	// Acquire จองคีย์ด้วย fingerprint ของ request นี้ไว้ lockTTL คืน token ของผู้จอง
+	// ถ้ามีคนจองไว้แล้วคืน record เดิมและ token ว่าง
+	Acquire(ctx context.Context, key, fingerprint string, lockTTL time.Duration) (rec *Record, token string, err error)
+	// Extend ต่ออายุ lock อีก lockTTL ถ้ายังเป็นของ token นี้ ไม่งั้นได้ ErrLockLost
+	Extend(ctx context.Context, key, token string, lockTTL time.Duration) error
+	// Complete เก็บ response ที่ตอบไปแล้วไว้ ttl เพื่อตอบซ้ำ เฉพาะเมื่อ lock ยังเป็นของ token นี้ (ErrLockLost)
+	Complete(ctx context.Context, key, token string, rec *Record, ttl time.Duration) error
+	// Release ลบคีย์ที่จองไว้ (request ล้มเหลว ให้ client ลองใหม่ด้วยคีย์เดิมได้)
+	// ไม่ลบ lock หรือ response ของผู้อื่น
+	Release(ctx context.Context, key, token string) error
+}
+
+// Fingerprint ตัวแทนของ request: method + path/query + body
+func Fingerprint(method, url string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{' '})
+	h.Write([]byte(url))
+	h.Write([]byte{'\n'})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// การตรวจเจ้าของกับการเขียน/ลบต้องเป็น atomic เดียวกัน ไม่งั้น lock ที่หมดอายุแล้วถูกจองใหม่
+// จะโดน request เดิมเขียนทับหรือลบทิ้งได้ (KEYS[1] = คีย์, ARGV[1] = token)
+var (
+	// ARGV[2] = record ที่เสร็จแล้ว, ARGV[3] = ttl (ms)
+	completeScript = redis.NewScript(`
+local cur = redis.call('GET', KEYS[1])
+if not cur or cjson.decode(cur).owner ~= ARGV[1] then return 0 end
+redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+return 1`)
+
+	releaseScript = redis.NewScript(`
+local cur = redis.call('GET', KEYS[1])
+if not cur or cjson.decode(cur).owner ~= ARGV[1] then return 0 end
+return redis.call('DEL', KEYS[1])`)
+
+	// ARGV[2] = lockTTL (ms)
+	extendScript = redis.NewScript(`
+local cur = redis.call('GET', KEYS[1])
+if not cur or cjson.decode(cur).owner ~= ARGV[1] then return 0 end
+return redis.call('PEXPIRE', KEYS[1], ARGV[2])`)
+)
+
+type redisStore struct {
+	rdb    *redis.Client
+	prefix string
+}
+
+func NewRedisStore(rdb *redis.Client) Store {
+	return &redisStore{rdb: rdb, prefix: "idem:"}
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (s *redisStore) Acquire(ctx context.Context, key, fingerprint string, lockTTL time.Duration) (*Record, string, error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, "", err
+	}
+	lock, err := json.Marshal(Record{Fingerprint: fingerprint, Owner: token, CreatedAt: time.Now()})
+	if err != nil {
+		return nil, "", err
+	}
+
+	// คีย์อาจหมดอายุระหว่าง SETNX กับ GET จึงลองซ้ำอีกครั้ง
+	for range 2 {
+		ok, err := s.rdb.SetNX(ctx, s.prefix+key, lock, lockTTL).Result()
+		if err != nil {
+			return nil, "", err
+		}
+		if ok {
+			return nil, token, nil
+		}
+
+		raw, err := s.rdb.Get(ctx, s.prefix+key).Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		var rec Record
+		if err := json.Unmarshal(raw, &rec); err != nil {
+			return nil, "", err
+		}
+		return &rec, "", nil
+	}
+	return nil, "", errors.New("idempotency: key kept expiring")
+}
+
+// runOwned รัน script ที่ตรวจเจ้าของก่อน ผลเป็น 0 แปลว่า lock ไม่ใช่ของ token นี้แล้ว
+func (s *redisStore) runOwned(ctx context.Context, script *redis.Script, key, token string, args ...any) error {
+	n, err := script.Run(ctx, s.rdb, []string{s.prefix + key}, append([]any{token}, args...)...).Int()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+func (s *redisStore) Extend(ctx context.Context, key, token string, lockTTL time.Duration) error {
+	return s.runOwned(ctx, extendScript, key, token, lockTTL.Milliseconds())
+}
+
+func (s *redisStore) Complete(ctx context.Context, key, token string, rec *Record, ttl time.Duration) error {
+	done := *rec
+	done.Owner = ""
+	raw, err := json.Marshal(done)
+	if err != nil {
+		return err
+	}
+	return s.runOwned(ctx, completeScript, key, token, raw, ttl.Milliseconds())
+}
+
+func (s *redisStore) Release(ctx context.Context, key, token string) error {
+	return s.runOwned(ctx, releaseScript, key, token)
+}
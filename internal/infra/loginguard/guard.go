@@ -0,0 +1,169 @@
+package loginguard
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Options เกณฑ์การล็อก ค่าที่เป็น 0 ใช้ค่า default
+type Options struct {
+	// MaxUserFailures ใส่รหัสผิดกี่ครั้ง (ต่อ username ภายใน Window) แล้วล็อกบัญชี
+	MaxUserFailures int
+	// MaxIPFailures ผิดกี่ครั้งจาก IP เดียว (ทุก username รวมกัน) แล้วล็อก IP
+	MaxIPFailures int
+	Window        time.Duration
+	Lockout       time.Duration
+	// DelayStep หน่วงครั้งแรกหลังผิด แล้วเพิ่มเท่าตัวทุกครั้งที่ผิดซ้ำ ไม่เกิน MaxDelay
+	DelayStep time.Duration
+	MaxDelay  time.Duration
+}
+
+func (o Options) withDefaults() Options {
+	if o.MaxUserFailures <= 0 {
+		o.MaxUserFailures = 5
+	}
+	if o.MaxIPFailures <= 0 {
+		o.MaxIPFailures = 20
+	}
+	if o.Window <= 0 {
+		o.Window = 15 * time.Minute
+	}
+	if o.Lockout <= 0 {
+		o.Lockout = 15 * time.Minute
+	}
+	if o.DelayStep <= 0 {
+		o.DelayStep = 250 * time.Millisecond
+	}
+	if o.MaxDelay <= 0 {
+		o.MaxDelay = 4 * time.Second
+	}
+	return o
+}
+
+// Status สถานะการ login ของ username + IP คู่หนึ่ง
+type Status struct {
+	// Failures จำนวนครั้งที่ username นี้ใส่ผิดใน Window
+	Failures int
+	// Delay เวลาที่ควรหน่วงก่อนตรวจรหัสผ่านครั้งถัดไป
+	Delay time.Duration
+	// LockedFor > 0 คือถูกล็อกอยู่ (บัญชีหรือ IP) อีกนานเท่านี้
+	LockedFor time.Duration
+}
+
+func (s *Status) Locked() bool {
+	return s.LockedFor > 0
+}
+
+// recordFailure นับความผิดพลาด ถึงเกณฑ์แล้วตั้ง lock และล้าง counter ในคราวเดียว
+// คืน {จำนวนครั้ง, ระยะล็อกเป็น ms (0 = ยังไม่ล็อก)}
+var recordFailure = redis.NewScript(`
+local n = redis.call('INCR', KEYS[1])
+if n == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+if n >= tonumber(ARGV[2]) then
+	redis.call('SET', KEYS[2], '1', 'PX', ARGV[3])
+	redis.call('DEL', KEYS[1])
+	return {n, tonumber(ARGV[3])}
+end
+return {n, 0}
+`)
+
+// Guard นับการ login ที่ล้มเหลวต่อ username และต่อ IP ใน redis (ใช้ร่วมกันทุก instance)
+type Guard struct {
+	rdb    *redis.Client
+	opt    Options
+	prefix string
+}
+
+func NewRedisGuard(rdb *redis.Client, opt Options) *Guard {
+	return &Guard{rdb: rdb, opt: opt.withDefaults(), prefix: "login:"}
+}
+
+func (g *Guard) Check(ctx context.Context, username, ip string) (*Status, error) {
+	pipe := g.rdb.Pipeline()
+	userLock := pipe.PTTL(ctx, g.lockKey("user", normalize(username)))
+	ipLock := pipe.PTTL(ctx, g.lockKey("ip", ip))
+	failures := pipe.Get(ctx, g.failKey("user", normalize(username)))
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	n, err := failures.Int()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+	return &Status{
+		Failures:  n,
+		Delay:     g.delay(n),
+		LockedFor: max(userLock.Val(), ipLock.Val(), 0),
+	}, nil
+}
+
+// Fail บันทึกว่า login ไม่สำเร็จ คืนสถานะหลังนับแล้ว (อาจถูกล็อกจากครั้งนี้)
+func (g *Guard) Fail(ctx context.Context, username, ip string) (*Status, error) {
+	userN, userLock, err := g.fail(ctx, "user", normalize(username), g.opt.MaxUserFailures)
+	if err != nil {
+		return nil, err
+	}
+	_, ipLock, err := g.fail(ctx, "ip", ip, g.opt.MaxIPFailures)
+	if err != nil {
+		return nil, err
+	}
+	return &Status{
+		Failures:  userN,
+		Delay:     g.delay(userN),
+		LockedFor: max(userLock, ipLock),
+	}, nil
+}
+
+// Reset ล้าง counter หลัง login สำเร็จ
+func (g *Guard) Reset(ctx context.Context, username, ip string) error {
+	return g.rdb.Del(ctx, g.failKey("user", normalize(username)), g.failKey("ip", ip)).Err()
+}
+
+// Unlock ปลดล็อกบัญชีและล้าง counter ของ username (ไม่แตะ lock ของ IP)
+func (g *Guard) Unlock(ctx context.Context, username string) error {
+	name := normalize(username)
+	return g.rdb.Del(ctx, g.lockKey("user", name), g.failKey("user", name)).Err()
+}
+
+func (g *Guard) fail(ctx context.Context, kind, id string, threshold int) (int, time.Duration, error) {
+	res, err := recordFailure.Run(ctx, g.rdb, []string{g.failKey(kind, id), g.lockKey(kind, id)},
+		g.opt.Window.Milliseconds(), threshold, g.opt.Lockout.Milliseconds()).Int64Slice()
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(res[0]), time.Duration(res[1]) * time.Millisecond, nil
+}
+
+// delay หน่วงแบบเพิ่มเท่าตัว: 1 ครั้ง = DelayStep, 2 ครั้ง = 2*DelayStep, ...
+func (g *Guard) delay(failures int) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+	d := g.opt.DelayStep
+	for range failures - 1 {
+		d *= 2
+		if d >= g.opt.MaxDelay {
+			return g.opt.MaxDelay
+		}
+	}
+	return min(d, g.opt.MaxDelay)
+}
+
+func (g *Guard) failKey(kind, id string) string {
+	return g.prefix + "fail:" + kind + ":" + id
+}
+
+func (g *Guard) lockKey(kind, id string) string {
+	return g.prefix + "lock:" + kind + ":" + id
+}
+
+// normalize ให้ "Admin" กับ "admin" ใช้ counter เดียวกัน กันการเลี่ยงเกณฑ์ด้วยตัวพิมพ์
+func normalize(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
@@ -0,0 +1,37 @@
+package loginguard
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuard_Delay(t *testing.T) {
+	g := &Guard{opt: Options{DelayStep: 250 * time.Millisecond, MaxDelay: 2 * time.Second}.withDefaults()}
+
+	tests := []struct {
+		failures int
+		expected time.Duration
+	}{
+		{failures: 0, expected: 0},
+		{failures: 1, expected: 250 * time.Millisecond},
+		{failures: 2, expected: 500 * time.Millisecond},
+		{failures: 3, expected: time.Second},
+		{failures: 4, expected: 2 * time.Second},
+		{failures: 50, expected: 2 * time.Second},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, g.delay(tt.failures), "failures=%d", tt.failures)
+	}
+}
+
+func TestGuard_Keys(t *testing.T) {
+	g := NewRedisGuard(nil, Options{})
+
+	assert.Equal(t, "login:fail:user:admin", g.failKey("user", normalize("  Admin ")))
+	assert.Equal(t, "login:lock:ip:10.0.0.1", g.lockKey("ip", "10.0.0.1"))
+	assert.Equal(t, 5, g.opt.MaxUserFailures)
+	assert.Equal(t, 15*time.Minute, g.opt.Lockout)
+}
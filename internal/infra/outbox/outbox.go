@@ -0,0 +1,79 @@
+package outbox
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"context"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Envelope รูปแบบ event ที่ส่งให้ sink ทุกตัว; ID ใช้กันซ้ำฝั่งผู้รับ (ส่งแบบ at-least-once)
+type Envelope struct {
+	ID            uint            `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   uint            `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+	RequestID     string          `json:"request_id,omitempty"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Attempt       int             `json:"attempt"` // เริ่มที่ 1
+}
+
+// Enqueue เขียน event ลง outbox ด้วย tx เดียวกับการแก้ไขข้อมูล
+// ต้องเรียกภายใน db.Transaction เพื่อให้ event ถูก commit/rollback พร้อมข้อมูล
+func Enqueue(tx *gorm.DB, events ...domain.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	ctx := tx.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	now := time.Now()
+	rows := make([]*domain.OutboxEvent, 0, len(events))
+	for _, e := range events {
+		row, err := NewRecord(ctx, e, now)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, row)
+	}
+	return tx.Create(&rows).Error
+}
+
+// NewRecord แปลง event เป็นแถวของ outbox_events ที่พร้อมส่งทันที
+func NewRecord(ctx context.Context, e domain.Event, now time.Time) (*domain.OutboxEvent, error) {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.OutboxEvent{
+		EventType:     e.EventType(),
+		AggregateType: domain.AggregateType(e.EventType()),
+		AggregateID:   e.AggregateID(),
+		Payload:       payload,
+		RequestID:     ctxlog.RequestID(ctx),
+		Status:        domain.OutboxPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}, nil
+}
+
+// envelopeOf ใช้ตอน relay ส่ง (Attempt = ครั้งที่กำลังส่ง)
+func envelopeOf(ev *domain.OutboxEvent) Envelope {
+	return Envelope{
+		ID:            ev.ID,
+		Type:          ev.EventType,
+		AggregateType: ev.AggregateType,
+		AggregateID:   ev.AggregateID,
+		Payload:       ev.Payload,
+		RequestID:     ev.RequestID,
+		OccurredAt:    ev.CreatedAt,
+		Attempt:       ev.Attempts + 1,
+	}
+}
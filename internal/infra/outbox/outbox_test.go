@@ -0,0 +1,87 @@
+package outbox_test
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/internal/infra/outbox"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRecord(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx := ctxlog.WithRequestID(context.Background(), "req-1")
+
+	rec, err := outbox.NewRecord(ctx, domain.InventoryQuantityChanged{
+		ProductID: 3, InventoryID: 9, Delta: -2, Quantity: 8, Version: 4, Source: domain.QuantitySourceIssue,
+	}, now)
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.EventInventoryQuantityUpdated, rec.EventType)
+	assert.Equal(t, "inventory", rec.AggregateType)
+	assert.Equal(t, uint(3), rec.AggregateID)
+	assert.Equal(t, "req-1", rec.RequestID)
+	assert.Equal(t, domain.OutboxPending, rec.Status)
+	assert.Equal(t, now, rec.NextAttemptAt)
+	assert.JSONEq(t, `{"product_id":3,"inventory_id":9,"delta":-2,"quantity":8,"location":"","version":4,"source":"issue"}`, string(rec.Payload))
+}
+
+func TestDispatcher(t *testing.T) {
+	d := outbox.NewDispatcher()
+
+	var got []string
+	d.Subscribe(domain.EventProductCreated, func(_ context.Context, env outbox.Envelope) error {
+		got = append(got, "product:"+env.Type)
+		return nil
+	})
+	d.Subscribe("*", func(_ context.Context, env outbox.Envelope) error {
+		got = append(got, "all:"+env.Type)
+		if env.Type == domain.EventCategoryDeleted {
+			return errors.New("boom")
+		}
+		return nil
+	})
+
+	require.NoError(t, d.Publish(context.Background(), outbox.Envelope{Type: domain.EventProductCreated}))
+	assert.Error(t, d.Publish(context.Background(), outbox.Envelope{Type: domain.EventCategoryDeleted}))
+	assert.Equal(t, []string{
+		"product:" + domain.EventProductCreated,
+		"all:" + domain.EventProductCreated,
+		"all:" + domain.EventCategoryDeleted,
+	}, got)
+}
+
+func TestWebhook(t *testing.T) {
+	status := http.StatusNoContent
+	var gotHeader http.Header
+	var gotBody outbox.Envelope
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Clone()
+		b, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(b, &gotBody)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	sink := outbox.NewWebhook(srv.URL, srv.Client())
+	env := outbox.Envelope{ID: 42, Type: domain.EventProductUpdated, AggregateID: 3, Payload: []byte(`{"product_id":3}`), Attempt: 1}
+
+	require.NoError(t, sink.Publish(context.Background(), env))
+	assert.Equal(t, "42", gotHeader.Get("X-Event-ID"))
+	assert.Equal(t, domain.EventProductUpdated, gotHeader.Get("X-Event-Type"))
+	assert.Equal(t, "application/json", gotHeader.Get("Content-Type"))
+	assert.Equal(t, uint(42), gotBody.ID)
+	assert.JSONEq(t, `{"product_id":3}`, string(gotBody.Payload))
+
+	status = http.StatusInternalServerError
+	assert.Error(t, sink.Publish(context.Background(), env))
+}
@@ -0,0 +1,179 @@
+package outbox
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type Options struct {
+	BatchSize   int           // จำนวน event ต่อรอบ
+	MaxAttempts int           // ส่งไม่สำเร็จครบเท่านี้แล้วเป็น dead
+	Timeout     time.Duration // เวลาสูงสุดของการส่ง event หนึ่งตัวไปทุก sink
+}
+
+// Relay อ่าน event ที่ยังไม่ได้ส่งจาก outbox แล้วส่งให้ทุก sink
+// ถ้า sink ใดล้มเหลว event ทั้งตัวจะถูกส่งซ้ำทุก sink ในรอบถัดไป (at-least-once)
+// ลำดับ event ไม่การันตีเมื่อมีการ retry ผู้รับควรเทียบ version ใน payload เอง
+type Relay struct {
+	db    *gorm.DB
+	sinks []Sink
+	opt   Options
+}
+
+func NewRelay(db *gorm.DB, opt Options, sinks ...Sink) *Relay {
+	if opt.BatchSize <= 0 {
+		opt.BatchSize = 100
+	}
+	if opt.MaxAttempts <= 0 {
+		opt.MaxAttempts = 10
+	}
+	if opt.Timeout <= 0 {
+		opt.Timeout = 10 * time.Second
+	}
+	return &Relay{db: db, sinks: sinks, opt: opt}
+}
+
+// Run ส่ง event ทุกๆ interval จนกว่า ctx จะถูก cancel (รันเป็น goroutine จาก main)
+// ถ้ารอบไหนได้เต็ม batch จะดึงรอบต่อไปทันทีไม่รอ interval
+func (r *Relay) Run(ctx context.Context, interval time.Duration) {
+	log := ctxlog.From(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := r.RelayOnce(ctx)
+			if err != nil {
+				log.Warn("outbox.relay.fail", zap.Error(err))
+			}
+			if err != nil || n < r.opt.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info("outbox.relay.stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce ส่ง event ที่ถึงเวลาหนึ่ง batch คืนจำนวน event ที่ประมวลผล
+// จอง event ด้วย lease (เลื่อน next_attempt_at) แล้ว commit ก่อนส่ง จึงไม่ถือ lock/transaction ค้างระหว่างรอ sink
+// หลาย instance รัน relay พร้อมกันได้โดยไม่หยิบซ้ำ ถ้า process ตายกลางทาง event จะกลับมาเองเมื่อครบ lease
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	log := ctxlog.From(ctx)
+
+	// lease ยาวกว่าเวลาส่งทั้ง batch แบบเลวร้ายสุดเล็กน้อย
+	lease := time.Duration(r.opt.BatchSize)*r.opt.Timeout + time.Minute
+	rows, err := r.claimDue(ctx, time.Now(), lease)
+	if err != nil {
+		return 0, fmt.Errorf("outbox.relay: %w", err)
+	}
+
+	for _, ev := range rows {
+		sendErr := r.deliver(ctx, ev)
+		if sendErr != nil {
+			log.Warn("outbox.relay.deliver_fail",
+				zap.Uint("event_id", ev.ID),
+				zap.String("event_type", ev.EventType),
+				zap.Int("attempt", ev.Attempts+1),
+				zap.Error(sendErr),
+			)
+		}
+		if err := r.db.WithContext(ctx).Model(&domain.OutboxEvent{}).Where("id = ?", ev.ID).
+			Updates(settle(ev, sendErr, r.opt.MaxAttempts, time.Now())).Error; err != nil {
+			// event ยัง pending อยู่ จะถูกส่งซ้ำเมื่อครบ lease (at-least-once)
+			return 0, fmt.Errorf("outbox.relay: %w", err)
+		}
+	}
+	return len(rows), nil
+}
+
+// claimDue จอง event ที่ถึงเวลาส่งในคำสั่งเดียว (autocommit) แบบเดียวกับ webhook ClaimDue
+func (r *Relay) claimDue(ctx context.Context, now time.Time, lease time.Duration) ([]*domain.OutboxEvent, error) {
+	var rows []*domain.OutboxEvent
+	if err := r.db.WithContext(ctx).Raw(`
+		UPDATE outbox_events SET next_attempt_at = ?
+		WHERE id IN (
+			SELECT id FROM outbox_events
+			WHERE status = ? AND next_attempt_at <= ?
+			ORDER BY id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		now.Add(lease), domain.OutboxPending, now, r.opt.BatchSize,
+	).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	// RETURNING ไม่รับประกันลำดับ เรียงตาม id ให้ส่งตามลำดับที่เขียน
+	sort.Slice(rows, func(a, b int) bool { return rows[a].ID < rows[b].ID })
+	return rows, nil
+}
+
+func (r *Relay) deliver(ctx context.Context, ev *domain.OutboxEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, r.opt.Timeout)
+	defer cancel()
+
+	env := envelopeOf(ev)
+	var errs []error
+	for _, s := range r.sinks {
+		if err := s.Publish(ctx, env); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// settle ค่าที่ต้องอัปเดตของ event หลังส่งหนึ่งครั้ง
+func settle(ev *domain.OutboxEvent, sendErr error, maxAttempts int, now time.Time) map[string]any {
+	attempts := ev.Attempts + 1
+	if sendErr == nil {
+		return map[string]any{
+			"status":       domain.OutboxPublished,
+			"attempts":     attempts,
+			"published_at": now,
+			"last_error":   "",
+		}
+	}
+
+	status := domain.OutboxPending
+	if attempts >= maxAttempts {
+		status = domain.OutboxDead
+	}
+	return map[string]any{
+		"status":          status,
+		"attempts":        attempts,
+		"next_attempt_at": now.Add(RetryDelay(attempts)),
+		"last_error":      sendErr.Error(),
+	}
+}
+
+// RetryDelay backoff แบบ exponential: 2s, 4s, 8s, ... สูงสุด 30 นาที
+func RetryDelay(attempts int) time.Duration {
+	const max = 30 * time.Minute
+	if attempts < 1 {
+		attempts = 1
+	}
+	if attempts > 20 {
+		return max
+	}
+	d := time.Duration(1<<attempts) * time.Second
+	if d > max {
+		return max
+	}
+	return d
+}
@@ -0,0 +1,62 @@
+package outbox
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, 2*time.Second, RetryDelay(0))
+	assert.Equal(t, 2*time.Second, RetryDelay(1))
+	assert.Equal(t, 8*time.Second, RetryDelay(3))
+	assert.Equal(t, 30*time.Minute, RetryDelay(11))
+	assert.Equal(t, 30*time.Minute, RetryDelay(64))
+}
+
+func TestSettle(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		attempts   int
+		sendErr    error
+		wantStatus string
+	}{
+		{name: "Published", attempts: 0, wantStatus: domain.OutboxPublished},
+		{name: "RetryLater", attempts: 2, sendErr: errors.New("boom"), wantStatus: domain.OutboxPending},
+		{name: "DeadAfterMaxAttempts", attempts: 4, sendErr: errors.New("boom"), wantStatus: domain.OutboxDead},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := settle(&domain.OutboxEvent{Attempts: tt.attempts}, tt.sendErr, 5, now)
+
+			assert.Equal(t, tt.wantStatus, got["status"])
+			assert.Equal(t, tt.attempts+1, got["attempts"])
+			if tt.sendErr == nil {
+				assert.Equal(t, now, got["published_at"])
+				assert.Equal(t, "", got["last_error"])
+				return
+			}
+			assert.Equal(t, now.Add(RetryDelay(tt.attempts+1)), got["next_attempt_at"])
+			assert.Equal(t, "boom", got["last_error"])
+		})
+	}
+}
+
+func TestEnvelopeOf(t *testing.T) {
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	env := envelopeOf(&domain.OutboxEvent{
+		ID: 7, EventType: domain.EventProductUpdated, AggregateType: "product", AggregateID: 3,
+		Payload: []byte(`{"product_id":3}`), RequestID: "req-1", Attempts: 2, CreatedAt: at,
+	})
+
+	assert.Equal(t, Envelope{
+		ID: 7, Type: domain.EventProductUpdated, AggregateType: "product", AggregateID: 3,
+		Payload: []byte(`{"product_id":3}`), RequestID: "req-1", OccurredAt: at, Attempt: 3,
+	}, env)
+}
@@ -0,0 +1,134 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Sink ปลายทางของ event; คืน error เมื่อส่งไม่สำเร็จ relay จะ retry ให้
+// Publish อาจถูกเรียกซ้ำด้วย event เดิม ผู้รับต้องกันซ้ำด้วย Envelope.ID
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, env Envelope) error
+}
+
+// --- In-process ---
+
+type HandlerFunc func(ctx context.Context, env Envelope) error
+
+// Dispatcher ส่ง event ให้ handler ภายใน process เดียวกัน
+type Dispatcher struct {
+	mu       sync.RWMutex
+	handlers map[string][]HandlerFunc
+}
+
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{handlers: make(map[string][]HandlerFunc)}
+}
+
+// Subscribe ลงทะเบียน handler ของชนิด event ("*" = ทุกชนิด)
+func (d *Dispatcher) Subscribe(eventType string, fn HandlerFunc) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers[eventType] = append(d.handlers[eventType], fn)
+}
+
+func (d *Dispatcher) Name() string { return "inprocess" }
+
+func (d *Dispatcher) Publish(ctx context.Context, env Envelope) error {
+	d.mu.RLock()
+	handlers := append(append([]HandlerFunc(nil), d.handlers[env.Type]...), d.handlers["*"]...)
+	d.mu.RUnlock()
+
+	var errs []error
+	for _, fn := range handlers {
+		if err := fn(ctx, env); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// --- Redis Streams ---
+
+// RedisStream เพิ่ม event ลง stream ด้วย XADD; ผู้รับอ่านด้วย consumer group ของตัวเอง
+type RedisStream struct {
+	rdb    *redis.Client
+	stream string
+	maxLen int64
+}
+
+// NewRedisStream maxLen <= 0 = ไม่ตัด stream
+func NewRedisStream(rdb *redis.Client, stream string, maxLen int64) *RedisStream {
+	return &RedisStream{rdb: rdb, stream: stream, maxLen: maxLen}
+}
+
+func (s *RedisStream) Name() string { return "redis_stream" }
+
+func (s *RedisStream) Publish(ctx context.Context, env Envelope) error {
+	body, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+	args := &redis.XAddArgs{
+		Stream: s.stream,
+		Values: map[string]any{"id": env.ID, "type": env.Type, "envelope": string(body)},
+	}
+	if s.maxLen > 0 {
+		args.MaxLen = s.maxLen
+		args.Approx = true
+	}
+	return s.rdb.XAdd(ctx, args).Err()
+}
+
+// --- Webhook ---
+
+// Webhook POST envelope เป็น JSON ไปที่ URL เดียว; ตอบ 2xx ถือว่าสำเร็จ
+type Webhook struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhook(url string, client *http.Client) *Webhook {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Webhook{url: url, client: client}
+}
+
+func (w *Webhook) Name() string { return "webhook" }
+
+func (w *Webhook) Publish(ctx context.Context, env Envelope) error {
+	body, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", fmt.Sprint(env.ID))
+	req.Header.Set("X-Event-Type", env.Type)
+
+	res, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook %s: status %d", w.url, res.StatusCode)
+	}
+	return nil
+}
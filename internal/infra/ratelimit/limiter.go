@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Result ผลการขอใช้โควต้าหนึ่งครั้ง
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset เวลาจนกว่าจะมีโควต้าว่างอีก 1 ครั้ง (ถูกปฏิเสธ = ต้องรอเท่านี้)
+	Reset time.Duration
+}
+
+type Limiter interface {
+	// Allow นับ request ของ key ใน sliding window ยาว window ไม่เกิน limit ครั้ง
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (*Result, error)
+}
+
+// slidingWindow เก็บเวลาของแต่ละ request ใน sorted set แล้วนับเฉพาะที่อยู่ใน window
+// ใช้เวลาจาก redis (TIME) เพื่อไม่ให้นาฬิกาของแต่ละ instance เหลื่อมกัน
+var slidingWindow = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[3])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', KEYS[1], window)
+
+local reset = 0
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+return {allowed, limit - count, reset}
+`)
+
+type redisLimiter struct {
+	rdb    *redis.Client
+	prefix string
+}
+
+func NewRedisLimiter(rdb *redis.Client) Limiter {
+	return &redisLimiter{rdb: rdb, prefix: "ratelimit:"}
+}
+
+func (l *redisLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (*Result, error) {
+	member, err := newMember()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := slidingWindow.Run(ctx, l.rdb, []string{l.prefix + key},
+		window.Milliseconds(), limit, member).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(res) != 3 {
+		return nil, fmt.Errorf("ratelimit: unexpected script result %v", res)
+	}
+
+	return &Result{
+		Allowed:   res[0] == 1,
+		Limit:     limit,
+		Remaining: int(max(res[1], 0)),
+		Reset:     time.Duration(res[2]) * time.Millisecond,
+	}, nil
+}
+
+// newMember ค่าไม่ซ้ำของแต่ละ request (หลาย request ในมิลลิวินาทีเดียวกันต้องนับแยก)
+func newMember() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -35,6 +35,8 @@ func RequireAuth(tm jwtx.TokenManager) fiber.Handler {
 
 		ctx := jwtx.InjectClaims(c.UserContext(), claims)
 		c.SetUserContext(ctx)
+		// handler อ่าน claims ผ่าน c.Locals("user")
+		c.Locals("user", claims)
 
 		// ผูกข้อมูลลง context/log
 		ctxlog.AddFields(ctx,
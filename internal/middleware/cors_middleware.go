@@ -17,22 +17,11 @@ func RegisterSecurity(app *fiber.App, cfg *config.Config) {
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     cfg.HTTP.CORSAllowOrigins,
 		AllowMethods:     "GET,POST,HEAD,PUT,DELETE,PATCH,OPTION",
-		AllowHeaders:     "Origin,Content-Type,Accept,Authorization",
-		ExposeHeaders:    "Content-Length",
+		AllowHeaders:     "Origin,Content-Type,Accept,Authorization,Idempotency-Key",
+		ExposeHeaders:    "Content-Length,ETag,Last-Modified,Idempotent-Replayed,Retry-After,X-RateLimit-Limit,X-RateLimit-Remaining,X-RateLimit-Reset",
 		AllowCredentials: true,
 		MaxAge:           3600, // 1 Hour
 	}))
 
-	// Rate Limiter: ป้องกัน brute force / flood attack
-	// app.Use(limiter.New(limiter.Config{
-	// 	Max:        100,             // 100 request
-	// 	Expiration: 1 * time.Minute, // ภายใน 1 นาที
-
-	// 	// response
-	// 	LimitReached: func(c *fiber.Ctx) error {
-	// 		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
-	// 			"error": "limiter 100 request per 1 minues",
-	// 		})
-	// 	},
-	// }))
+	// Rate limit ไม่ได้ตั้งตรงนี้: ใช้ middleware.RateLimit (redis) แยกตามกลุ่ม route ใน router
 }
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"ans-spareparts-api/pkg/httpcache"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ETag ทำ ETag จาก hash ของ body ที่ handler ตอบ แล้วตอบ 304 ถ้า If-None-Match ตรง
+// ใช้กับ list endpoint ที่ไม่มี updated_at เดียวให้อ้างอิง; handler ที่ตั้ง ETag เองแล้วจะไม่ถูกแตะ
+func ETag() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		res := c.Response()
+		if res.StatusCode() != fiber.StatusOK || res.IsBodyStream() || len(res.Header.Peek(fiber.HeaderETag)) > 0 {
+			return nil
+		}
+		body := res.Body()
+		if len(body) == 0 {
+			return nil
+		}
+		if httpcache.Fresh(c, httpcache.TagBytes(body), time.Time{}) {
+			return httpcache.NotModified(c)
+		}
+		return nil
+	}
+}
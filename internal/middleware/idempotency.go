@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/internal/infra/idempotency"
+	"ans-spareparts-api/internal/infra/jwtx"
+	"ans-spareparts-api/pkg/response"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed ติดมากับ response ที่ตอบซ้ำจากที่เก็บไว้
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLen = 255
+)
+
+// Idempotency ทำให้ POST/PUT/PATCH/DELETE ที่ส่ง Idempotency-Key มาทำงานครั้งเดียว
+//   - คีย์เดิม + request เดิม ที่เสร็จแล้ว: ตอบ response เดิมซ้ำ (ไม่เรียก handler)
+//   - คีย์เดิม + body/path ต่างกัน: 422
+//   - คีย์เดิมที่ยังทำอยู่: 409 พร้อม Retry-After
+//
+// คีย์แยกตามผู้ใช้ (ต้องวางหลัง RequireAuth) response 5xx หรือ error จะไม่ถูกเก็บ ลองใหม่ด้วยคีย์เดิมได้
+// ถ้า store ใช้ไม่ได้จะปล่อย request ผ่านไปตามปกติ (เหมือน blacklist ของ JWT)
+//
+// lock ถูกต่ออายุทุกครึ่ง lockTTL ระหว่างที่ handler ยังทำอยู่ การเก็บ/ลบคีย์ตอนจบทำเฉพาะเมื่อ lock ยังเป็นของ
+// request นี้ ถ้าต่ออายุไม่ทัน (เช่น redis ขาดช่วงนานกว่า lockTTL) request อื่นจองคีย์ต่อได้และ handler อาจทำซ้ำ
+func Idempotency(store idempotency.Store, ttl, lockTTL time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		switch c.Method() {
+		case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete:
+		default:
+			return c.Next()
+		}
+		key := strings.TrimSpace(c.Get(HeaderIdempotencyKey))
+		if key == "" {
+			return c.Next()
+		}
+		if len(key) > maxIdempotencyKeyLen {
+			return response.Error(c, fiber.StatusBadRequest, "BAD_REQUEST", "Idempotency-Key must be at most 255 characters")
+		}
+
+		ctx := c.UserContext()
+		log := ctxlog.From(ctx).With(zap.String("idempotency_key", key))
+
+		scope := "anon"
+		if claims, ok := c.Locals("user").(*jwtx.Claims); ok {
+			scope = strconv.FormatUint(uint64(claims.UserID), 10)
+		}
+		storeKey := scope + ":" + key
+		fingerprint := idempotency.Fingerprint(c.Method(), c.OriginalURL(), c.Body())
+
+		rec, token, err := store.Acquire(ctx, storeKey, fingerprint, lockTTL)
+		if err != nil {
+			log.Warn("middleware.idempotency.store_fail", zap.Error(err))
+			return c.Next()
+		}
+
+		if token == "" {
+			switch {
+			case rec.Fingerprint != fingerprint:
+				log.Warn("middleware.idempotency.mismatch")
+				return response.Error(c, fiber.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_REUSED",
+					"Idempotency-Key was already used for a different request")
+			case !rec.Done:
+				log.Info("middleware.idempotency.in_flight")
+				c.Set(fiber.HeaderRetryAfter, "1")
+				return response.Error(c, fiber.StatusConflict, "IDEMPOTENCY_IN_PROGRESS",
+					"a request with this Idempotency-Key is still in progress")
+			}
+
+			log.Info("middleware.idempotency.replay", zap.Int("status", rec.Status))
+			c.Set(HeaderIdempotentReplayed, "true")
+			if rec.ContentType != "" {
+				c.Set(fiber.HeaderContentType, rec.ContentType)
+			}
+			return c.Status(rec.Status).Send(rec.Body)
+		}
+
+		stop := keepLock(ctx, store, storeKey, token, lockTTL, log)
+		err = c.Next()
+		stop()
+
+		res := c.Response()
+		if err != nil || res.StatusCode() >= fiber.StatusInternalServerError || res.IsBodyStream() {
+			if rerr := store.Release(ctx, storeKey, token); rerr != nil {
+				log.Warn("middleware.idempotency.release_fail", zap.Error(rerr))
+			}
+			return err
+		}
+
+		if cerr := store.Complete(ctx, storeKey, token, &idempotency.Record{
+			Fingerprint: fingerprint,
+			Done:        true,
+			Status:      res.StatusCode(),
+			ContentType: string(res.Header.ContentType()),
+			Body:        append([]byte(nil), res.Body()...),
+			CreatedAt:   time.Now(),
+		}, ttl); cerr != nil {
+			log.Warn("middleware.idempotency.complete_fail", zap.Error(cerr))
+		}
+		return nil
+	}
+}
+
+// keepLock ต่ออายุ lock ทุกครึ่ง lockTTL จนกว่าจะเรียก stop (รอ goroutine จบก่อนคืน)
+func keepLock(ctx context.Context, store idempotency.Store, key, token string, lockTTL time.Duration, log *zap.Logger) (stop func()) {
+	if lockTTL <= 0 {
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(lockTTL / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := store.Extend(ctx, key, token, lockTTL)
+				if err == nil || errors.Is(err, context.Canceled) {
+					continue
+				}
+				log.Warn("middleware.idempotency.extend_fail", zap.Error(err))
+				if errors.Is(err, idempotency.ErrLockLost) {
+					return
+				}
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
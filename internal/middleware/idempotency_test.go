@@ -0,0 +1,273 @@
+package middleware_test
+
+import (
+	"ans-spareparts-api/internal/infra/idempotency"
+	"ans-spareparts-api/internal/infra/jwtx"
+	"ans-spareparts-api/internal/middleware"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memStore Store ในหน่วยความจำสำหรับเทสต์ (ไม่สน TTL) ตรวจเจ้าของด้วย token เหมือน redisStore
+type memStore struct {
+	mu      sync.Mutex
+	recs    map[string]*idempotency.Record
+	err     error
+	seq     int
+	extends int
+}
+
+func newMemStore() *memStore {
+	return &memStore{recs: make(map[string]*idempotency.Record)}
+}
+
+func (s *memStore) Acquire(_ context.Context, key, fingerprint string, _ time.Duration) (*idempotency.Record, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, "", s.err
+	}
+	if rec, ok := s.recs[key]; ok {
+		return rec, "", nil
+	}
+	s.seq++
+	token := fmt.Sprintf("owner-%d", s.seq)
+	s.recs[key] = &idempotency.Record{Fingerprint: fingerprint, Owner: token}
+	return nil, token, nil
+}
+
+// owned ต้องถือ mu ก่อนเรียก
+func (s *memStore) owned(key, token string) bool {
+	rec, ok := s.recs[key]
+	return ok && rec.Owner == token
+}
+
+func (s *memStore) Extend(_ context.Context, key, token string, _ time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.owned(key, token) {
+		return idempotency.ErrLockLost
+	}
+	s.extends++
+	return nil
+}
+
+func (s *memStore) Complete(_ context.Context, key, token string, rec *idempotency.Record, _ time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.owned(key, token) {
+		return idempotency.ErrLockLost
+	}
+	s.recs[key] = rec
+	return nil
+}
+
+func (s *memStore) Release(_ context.Context, key, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.owned(key, token) {
+		return idempotency.ErrLockLost
+	}
+	delete(s.recs, key)
+	return nil
+}
+
+type idemApp struct {
+	app    *fiber.App
+	store  *memStore
+	calls  int
+	status int
+	// during เรียกระหว่าง handler ทำงาน (จำลองสิ่งที่เกิดขึ้นกับคีย์ขณะ request แรกยังไม่จบ)
+	during func()
+}
+
+func newIdemApp() *idemApp {
+	return newIdemAppWithLockTTL(time.Minute)
+}
+
+func newIdemAppWithLockTTL(lockTTL time.Duration) *idemApp {
+	a := &idemApp{app: fiber.New(), store: newMemStore(), status: fiber.StatusCreated}
+	a.app.Use(func(c *fiber.Ctx) error {
+		if uid := c.Get("X-Test-User"); uid != "" {
+			id := uint(1)
+			if uid == "2" {
+				id = 2
+			}
+			c.Locals("user", &jwtx.Claims{UserID: id})
+		}
+		return c.Next()
+	})
+	a.app.Use(middleware.Idempotency(a.store, time.Hour, lockTTL))
+	handler := func(c *fiber.Ctx) error {
+		a.calls++
+		if a.during != nil {
+			a.during()
+		}
+		return c.Status(a.status).JSON(fiber.Map{"call": a.calls})
+	}
+	a.app.Post("/stock/issue", handler)
+	a.app.Get("/stock/issue", handler)
+	return a
+}
+
+func (a *idemApp) do(t *testing.T, method, key, user, body string) (*http.Response, string) {
+	req := httptest.NewRequest(method, "/stock/issue", strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if key != "" {
+		req.Header.Set(middleware.HeaderIdempotencyKey, key)
+	}
+	if user != "" {
+		req.Header.Set("X-Test-User", user)
+	}
+	res, err := a.app.Test(req, -1)
+	require.NoError(t, err)
+	b, _ := io.ReadAll(res.Body)
+	return res, string(b)
+}
+
+func TestIdempotency_ReplaysStoredResponse(t *testing.T) {
+	a := newIdemApp()
+
+	res1, body1 := a.do(t, fiber.MethodPost, "k-1", "1", `{"product_id":7,"quantity":2}`)
+	res2, body2 := a.do(t, fiber.MethodPost, "k-1", "1", `{"product_id":7,"quantity":2}`)
+
+	assert.Equal(t, fiber.StatusCreated, res1.StatusCode)
+	assert.Empty(t, res1.Header.Get(middleware.HeaderIdempotentReplayed))
+	assert.Equal(t, fiber.StatusCreated, res2.StatusCode)
+	assert.Equal(t, "true", res2.Header.Get(middleware.HeaderIdempotentReplayed))
+	assert.Equal(t, fiber.MIMEApplicationJSON, res2.Header.Get(fiber.HeaderContentType))
+	assert.JSONEq(t, body1, body2)
+	assert.Equal(t, 1, a.calls)
+}
+
+func TestIdempotency_RejectsDifferentBody(t *testing.T) {
+	a := newIdemApp()
+
+	a.do(t, fiber.MethodPost, "k-1", "1", `{"product_id":7,"quantity":2}`)
+	res, body := a.do(t, fiber.MethodPost, "k-1", "1", `{"product_id":7,"quantity":5}`)
+
+	assert.Equal(t, fiber.StatusUnprocessableEntity, res.StatusCode)
+	assert.Contains(t, body, "IDEMPOTENCY_KEY_REUSED")
+	assert.Equal(t, 1, a.calls)
+}
+
+func TestIdempotency_ConflictWhileInFlight(t *testing.T) {
+	a := newIdemApp()
+	body := `{"product_id":7,"quantity":2}`
+	a.store.recs["1:k-1"] = &idempotency.Record{
+		Fingerprint: idempotency.Fingerprint(fiber.MethodPost, "/stock/issue", []byte(body)),
+	}
+
+	res, resBody := a.do(t, fiber.MethodPost, "k-1", "1", body)
+
+	assert.Equal(t, fiber.StatusConflict, res.StatusCode)
+	assert.Equal(t, "1", res.Header.Get(fiber.HeaderRetryAfter))
+	assert.Contains(t, resBody, "IDEMPOTENCY_IN_PROGRESS")
+	assert.Equal(t, 0, a.calls)
+}
+
+func TestIdempotency_ServerErrorIsNotStored(t *testing.T) {
+	a := newIdemApp()
+	a.status = fiber.StatusInternalServerError
+
+	a.do(t, fiber.MethodPost, "k-1", "1", `{}`)
+	a.status = fiber.StatusCreated
+	res, _ := a.do(t, fiber.MethodPost, "k-1", "1", `{}`)
+
+	assert.Equal(t, fiber.StatusCreated, res.StatusCode)
+	assert.Empty(t, res.Header.Get(middleware.HeaderIdempotentReplayed))
+	assert.Equal(t, 2, a.calls)
+}
+
+func TestIdempotency_ExtendsLockWhileHandlerRuns(t *testing.T) {
+	a := newIdemAppWithLockTTL(20 * time.Millisecond)
+	a.during = func() { time.Sleep(70 * time.Millisecond) }
+
+	res, _ := a.do(t, fiber.MethodPost, "k-1", "1", `{}`)
+
+	assert.Equal(t, fiber.StatusCreated, res.StatusCode)
+	a.store.mu.Lock()
+	defer a.store.mu.Unlock()
+	assert.GreaterOrEqual(t, a.store.extends, 2)
+	assert.True(t, a.store.recs["1:k-1"].Done)
+}
+
+// lock หมดอายุระหว่าง handler แล้ว request อื่นจองต่อ: request แรกต้องไม่เขียนทับหรือลบคีย์ของอีกคน
+func TestIdempotency_DoesNotTouchLockTakenOver(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+	}{
+		{name: "Complete", status: fiber.StatusCreated},
+		{name: "Release", status: fiber.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newIdemApp()
+			a.status = tt.status
+			other := &idempotency.Record{Fingerprint: "other", Owner: "owner-other"}
+			a.during = func() {
+				a.store.mu.Lock()
+				defer a.store.mu.Unlock()
+				a.store.recs["1:k-1"] = other
+			}
+
+			a.do(t, fiber.MethodPost, "k-1", "1", `{}`)
+
+			assert.Same(t, other, a.store.recs["1:k-1"])
+		})
+	}
+}
+
+func TestIdempotency_PassThrough(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		keys   [2]string
+		users  [2]string
+		setup  func(a *idemApp)
+	}{
+		{name: "No_Key", method: fiber.MethodPost},
+		{name: "Get_Is_Ignored", method: fiber.MethodGet, keys: [2]string{"k-1", "k-1"}},
+		{name: "Keys_Are_Per_User", method: fiber.MethodPost, keys: [2]string{"k-1", "k-1"}, users: [2]string{"1", "2"}},
+		{name: "Store_Down_Fails_Open", method: fiber.MethodPost, keys: [2]string{"k-1", "k-1"},
+			setup: func(a *idemApp) { a.store.err = errors.New("redis down") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newIdemApp()
+			if tt.setup != nil {
+				tt.setup(a)
+			}
+
+			for i := range 2 {
+				res, _ := a.do(t, tt.method, tt.keys[i], tt.users[i], `{}`)
+				assert.Empty(t, res.Header.Get(middleware.HeaderIdempotentReplayed))
+			}
+			assert.Equal(t, 2, a.calls)
+		})
+	}
+}
+
+func TestIdempotency_KeyTooLong(t *testing.T) {
+	a := newIdemApp()
+
+	res, _ := a.do(t, fiber.MethodPost, strings.Repeat("k", 256), "1", `{}`)
+
+	assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+	assert.Equal(t, 0, a.calls)
+}
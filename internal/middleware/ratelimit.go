@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"ans-spareparts-api/internal/infra/httpx/ctxlog"
+	"ans-spareparts-api/internal/infra/jwtx"
+	"ans-spareparts-api/internal/infra/ratelimit"
+	"ans-spareparts-api/pkg/response"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	HeaderRateLimitLimit     = "X-RateLimit-Limit"
+	HeaderRateLimitRemaining = "X-RateLimit-Remaining"
+	// HeaderRateLimitReset จำนวนวินาทีจนกว่าจะมีโควต้าว่าง
+	HeaderRateLimitReset = "X-RateLimit-Reset"
+)
+
+// RateLimitRule โควต้าของกลุ่ม route หนึ่ง: ไม่เกิน Limit request ใน Window
+// Name แยก counter ของแต่ละกลุ่ม เช่น "auth" กับ "api" นับไม่ปนกัน
+type RateLimitRule struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+}
+
+// RateLimit จำกัดจำนวน request ต่อผู้ใช้ (จาก claims) หรือต่อ IP ถ้ายังไม่ได้ Login
+// counter อยู่ใน redis จึงใช้ร่วมกันทุก instance ตอบ 429 พร้อม Retry-After เมื่อเกินโควต้า
+// Limit <= 0 คือปิด ถ้า redis ใช้ไม่ได้จะปล่อย request ผ่าน (เหมือน Idempotency)
+func RateLimit(limiter ratelimit.Limiter, rule RateLimitRule) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if limiter == nil || rule.Limit <= 0 || rule.Window <= 0 {
+			return c.Next()
+		}
+
+		key := rule.Name + ":ip:" + c.IP()
+		if claims, ok := c.Locals("user").(*jwtx.Claims); ok {
+			key = rule.Name + ":user:" + strconv.FormatUint(uint64(claims.UserID), 10)
+		}
+
+		ctx := c.UserContext()
+		res, err := limiter.Allow(ctx, key, rule.Limit, rule.Window)
+		if err != nil {
+			ctxlog.From(ctx).Warn("middleware.ratelimit.store_fail", zap.String("rule", rule.Name), zap.Error(err))
+			return c.Next()
+		}
+
+		reset := strconv.Itoa(int(math.Ceil(res.Reset.Seconds())))
+		c.Set(HeaderRateLimitLimit, strconv.Itoa(res.Limit))
+		c.Set(HeaderRateLimitRemaining, strconv.Itoa(res.Remaining))
+		c.Set(HeaderRateLimitReset, reset)
+
+		if !res.Allowed {
+			ctxlog.From(ctx).Info("middleware.ratelimit.limited", zap.String("rule", rule.Name), zap.String("key", key))
+			c.Set(fiber.HeaderRetryAfter, reset)
+			return response.Error(c, fiber.StatusTooManyRequests, "RATE_LIMITED", "too many requests, please retry later")
+		}
+		return c.Next()
+	}
+}
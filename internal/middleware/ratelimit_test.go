@@ -0,0 +1,126 @@
+package middleware_test
+
+import (
+	"ans-spareparts-api/internal/infra/jwtx"
+	"ans-spareparts-api/internal/infra/ratelimit"
+	"ans-spareparts-api/internal/middleware"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countLimiter นับแบบ fixed ไม่มี window พอสำหรับเทสต์ middleware
+type countLimiter struct {
+	counts map[string]int
+	keys   []string
+	err    error
+}
+
+func (l *countLimiter) Allow(_ context.Context, key string, limit int, window time.Duration) (*ratelimit.Result, error) {
+	if l.err != nil {
+		return nil, l.err
+	}
+	l.keys = append(l.keys, key)
+	if l.counts[key] >= limit {
+		return &ratelimit.Result{Limit: limit, Reset: 1500 * time.Millisecond}, nil
+	}
+	l.counts[key]++
+	return &ratelimit.Result{Allowed: true, Limit: limit, Remaining: limit - l.counts[key], Reset: window}, nil
+}
+
+func newRateLimitApp(l ratelimit.Limiter, rule middleware.RateLimitRule) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if c.Get("X-Test-User") != "" {
+			c.Locals("user", &jwtx.Claims{UserID: 7})
+		}
+		return c.Next()
+	})
+	app.Use(middleware.RateLimit(l, rule))
+	app.Get("/", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	return app
+}
+
+func TestRateLimit_Headers_And_TooManyRequests(t *testing.T) {
+	l := &countLimiter{counts: map[string]int{}}
+	app := newRateLimitApp(l, middleware.RateLimitRule{Name: "auth", Limit: 2, Window: time.Minute})
+
+	statuses := []int{}
+	for i := range 3 {
+		res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil), -1)
+		require.NoError(t, err)
+		statuses = append(statuses, res.StatusCode)
+
+		assert.Equal(t, "2", res.Header.Get(middleware.HeaderRateLimitLimit))
+		switch i {
+		case 0:
+			assert.Equal(t, "1", res.Header.Get(middleware.HeaderRateLimitRemaining))
+			assert.Equal(t, "60", res.Header.Get(middleware.HeaderRateLimitReset))
+		case 2:
+			assert.Equal(t, "0", res.Header.Get(middleware.HeaderRateLimitRemaining))
+			assert.Equal(t, "2", res.Header.Get(fiber.HeaderRetryAfter))
+		}
+	}
+
+	assert.Equal(t, []int{fiber.StatusOK, fiber.StatusOK, fiber.StatusTooManyRequests}, statuses)
+	assert.Equal(t, "auth:ip:0.0.0.0", l.keys[0])
+}
+
+func TestRateLimit_Key(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     bool
+		expected string
+	}{
+		{name: "By_User_When_Logged_In", user: true, expected: "api:user:7"},
+		{name: "By_IP_When_Anonymous", expected: "api:ip:0.0.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &countLimiter{counts: map[string]int{}}
+			app := newRateLimitApp(l, middleware.RateLimitRule{Name: "api", Limit: 5, Window: time.Minute})
+
+			req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+			if tt.user {
+				req.Header.Set("X-Test-User", "1")
+			}
+			_, err := app.Test(req, -1)
+			require.NoError(t, err)
+
+			assert.Equal(t, []string{tt.expected}, l.keys)
+		})
+	}
+}
+
+func TestRateLimit_PassThrough(t *testing.T) {
+	tests := []struct {
+		name    string
+		limiter ratelimit.Limiter
+		rule    middleware.RateLimitRule
+	}{
+		{name: "Nil_Limiter", rule: middleware.RateLimitRule{Name: "api", Limit: 1, Window: time.Minute}},
+		{name: "Disabled_Rule", limiter: &countLimiter{counts: map[string]int{}}, rule: middleware.RateLimitRule{Name: "api"}},
+		{name: "Store_Down_Fails_Open", limiter: &countLimiter{err: errors.New("redis down")},
+			rule: middleware.RateLimitRule{Name: "api", Limit: 1, Window: time.Minute}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newRateLimitApp(tt.limiter, tt.rule)
+
+			for range 3 {
+				res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil), -1)
+				require.NoError(t, err)
+				assert.Equal(t, fiber.StatusOK, res.StatusCode)
+				assert.Empty(t, res.Header.Get(middleware.HeaderRateLimitLimit))
+			}
+		})
+	}
+}
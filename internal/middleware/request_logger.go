@@ -35,7 +35,7 @@ func RequestLogger(root *zap.Logger) fiber.Handler {
 		)
 
 		// Inject logger -> context
-		ctx := ctxlog.With(c.UserContext(), reqLogger)
+		ctx := ctxlog.WithRequestID(ctxlog.With(c.UserContext(), reqLogger), reqID)
 		c.SetUserContext(ctx)
 
 		err := c.Next()
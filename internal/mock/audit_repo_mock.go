@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/features/audit"
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type AuditRepository struct {
+	mock.Mock
+}
+
+func NewMockAuditRepository() *AuditRepository {
+	return &AuditRepository{}
+}
+
+func (m *AuditRepository) Create(ctx context.Context, log *domain.AuditLog) error {
+	args := m.Called(ctx, log)
+	return args.Error(0)
+}
+
+func (m *AuditRepository) List(ctx context.Context, q audit.ListQuery) ([]*domain.AuditLog, int64, error) {
+	args := m.Called(ctx, q)
+
+	var rows []*domain.AuditLog
+	if args.Get(0) != nil {
+		rows = args.Get(0).([]*domain.AuditLog)
+	}
+	return rows, args.Get(1).(int64), args.Error(2)
+}
@@ -0,0 +1,29 @@
+package mocks
+
+import (
+	"ans-spareparts-api/internal/features/audit"
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// AuditService ใช้แทนทั้ง audit.Service และ audit.Recorder ใน service อื่น
+type AuditService struct {
+	mock.Mock
+}
+
+func NewAuditService() *AuditService {
+	return &AuditService{}
+}
+
+func (m *AuditService) Record(ctx context.Context, e audit.Entry) {
+	m.Called(ctx, e)
+}
+
+func (m *AuditService) List(ctx context.Context, q audit.ListQuery) (*audit.ListOutput, error) {
+	args := m.Called(ctx, q)
+	if out, ok := args.Get(0).(*audit.ListOutput); ok {
+		return out, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
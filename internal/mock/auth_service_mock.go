@@ -24,15 +24,27 @@ func (m *AuthService) Register(ctx context.Context, input auth.RegisterInput) (*
 	return nil, args.Error(1)
 }
 
-func (m *AuthService) Login(ctx context.Context, input auth.LoginInput) (*domain.User, string, error) {
+func (m *AuthService) Login(ctx context.Context, input auth.LoginInput) (*domain.User, *auth.Tokens, error) {
 	args := m.Called(ctx, input)
-	if value, ok := args.Get(0).(*domain.User); ok {
-		return value, args.String(1), args.Error(2)
+	user, _ := args.Get(0).(*domain.User)
+	tokens, _ := args.Get(1).(*auth.Tokens)
+	return user, tokens, args.Error(2)
+}
+
+func (m *AuthService) Refresh(ctx context.Context, refreshToken string) (*auth.Tokens, error) {
+	args := m.Called(ctx, refreshToken)
+	if value, ok := args.Get(0).(*auth.Tokens); ok {
+		return value, args.Error(1)
 	}
-	return nil, args.String(1), args.Error(2)
+	return nil, args.Error(1)
+}
+
+func (m *AuthService) Logout(ctx context.Context, accessToken, refreshToken string) error {
+	args := m.Called(ctx, accessToken, refreshToken)
+	return args.Error(0)
 }
 
-func (m *AuthService) Logout(ctx context.Context, token string) error {
-	args := m.Called(ctx, token)
+func (m *AuthService) UnlockUser(ctx context.Context, userID uint) error {
+	args := m.Called(ctx, userID)
 	return args.Error(0)
 }
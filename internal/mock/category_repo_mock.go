@@ -4,6 +4,7 @@ import (
 	"ans-spareparts-api/internal/domain"
 	"ans-spareparts-api/internal/features/category"
 	"context"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -57,3 +58,23 @@ func (m *CategoryRepository) List(ctx context.Context, q category.ListQuery) ([]
 	count := args.Get(1).(int64)
 	return categories, count, args.Error(2)
 }
+
+func (m *CategoryRepository) ListDeleted(ctx context.Context, limit, offset int) ([]*domain.Category, int64, error) {
+	args := m.Called(ctx, limit, offset)
+
+	var rows []*domain.Category
+	if args.Get(0) != nil {
+		rows = args.Get(0).([]*domain.Category)
+	}
+	return rows, args.Get(1).(int64), args.Error(2)
+}
+
+func (m *CategoryRepository) Restore(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *CategoryRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
@@ -3,6 +3,7 @@ package mocks
 import (
 	"ans-spareparts-api/internal/features/category"
 	"context"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -59,3 +60,21 @@ func (m *CategoryService) List(ctx context.Context, query category.ListQuery) (*
 	}
 	return nil, args.Error(1)
 }
+
+func (m *CategoryService) ListTrash(ctx context.Context, limit, offset int) (*category.TrashOutput, error) {
+	args := m.Called(ctx, limit, offset)
+	if out, ok := args.Get(0).(*category.TrashOutput); ok {
+		return out, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *CategoryService) RestoreCategory(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *CategoryService) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
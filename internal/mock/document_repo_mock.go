@@ -0,0 +1,21 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type DocumentRepository struct {
+	mock.Mock
+}
+
+func NewMockDocumentRepository() *DocumentRepository {
+	return &DocumentRepository{}
+}
+
+func (m *DocumentRepository) Next(ctx context.Context, tx *gorm.DB, docType, branch string, year int) (int64, error) {
+	args := m.Called(ctx, tx, docType, branch, year)
+	return args.Get(0).(int64), args.Error(1)
+}
@@ -0,0 +1,25 @@
+package mocks
+
+import (
+	"ans-spareparts-api/internal/features/document"
+	"context"
+
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type DocumentService struct {
+	mock.Mock
+}
+
+func NewDocumentService() *DocumentService {
+	return &DocumentService{}
+}
+
+func (m *DocumentService) NextNumber(ctx context.Context, tx *gorm.DB, in document.NextNumberInput) (*document.Number, error) {
+	args := m.Called(ctx, tx, in)
+	if value, ok := args.Get(0).(*document.Number); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
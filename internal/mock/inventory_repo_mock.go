@@ -43,11 +43,6 @@ func (i *InventoryRepository) List(ctx context.Context, q inventory.ListQuery) (
 	return inv, count, args.Error(2)
 }
 
-func (i *InventoryRepository) UpdateQuantity(ctx context.Context, id uint, quantity int) error {
-	args := i.Called(ctx, id, quantity)
-	return args.Error(0)
-}
-
 func (i *InventoryRepository) Create(ctx context.Context, inv *domain.Inventory) (*domain.Inventory, error) {
 	args := i.Called(ctx, inv)
 	if inv, ok := args.Get(0).(*domain.Inventory); ok {
@@ -60,3 +55,15 @@ func (i *InventoryRepository) Delete(ctx context.Context, id uint) error {
 	args := i.Called(ctx, id)
 	return args.Error(0)
 }
+
+func (i *InventoryRepository) Export(ctx context.Context, q inventory.ListQuery, fn func(*inventory.ExportRow) error) error {
+	args := i.Called(ctx, q, fn)
+	if rows, ok := args.Get(0).([]*inventory.ExportRow); ok {
+		for _, row := range rows {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
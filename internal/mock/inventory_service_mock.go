@@ -3,6 +3,7 @@ package mocks
 import (
 	"ans-spareparts-api/internal/features/inventory"
 	"context"
+	"io"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -46,3 +47,11 @@ func (m *InventoryService) List(ctx context.Context, q inventory.ListQuery) (*in
 	}
 	return nil, args.Error(1)
 }
+
+func (m *InventoryService) ExportInventories(ctx context.Context, q inventory.ListQuery, format string, out io.Writer) error {
+	args := m.Called(ctx, q, format, out)
+	if body, ok := args.Get(0).(string); ok {
+		_, _ = io.WriteString(out, body)
+	}
+	return args.Error(1)
+}
@@ -0,0 +1,42 @@
+package mocks
+
+import (
+	"ans-spareparts-api/internal/infra/loginguard"
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type LoginGuard struct {
+	mock.Mock
+}
+
+func NewLoginGuard() *LoginGuard {
+	return &LoginGuard{}
+}
+
+func (m *LoginGuard) Check(ctx context.Context, username, ip string) (*loginguard.Status, error) {
+	args := m.Called(ctx, username, ip)
+	if value, ok := args.Get(0).(*loginguard.Status); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *LoginGuard) Fail(ctx context.Context, username, ip string) (*loginguard.Status, error) {
+	args := m.Called(ctx, username, ip)
+	if value, ok := args.Get(0).(*loginguard.Status); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *LoginGuard) Reset(ctx context.Context, username, ip string) error {
+	args := m.Called(ctx, username, ip)
+	return args.Error(0)
+}
+
+func (m *LoginGuard) Unlock(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
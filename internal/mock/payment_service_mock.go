@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"ans-spareparts-api/internal/features/payment"
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type PaymentService struct {
+	mock.Mock
+}
+
+func NewPaymentService() *PaymentService {
+	return &PaymentService{}
+}
+
+func (m *PaymentService) PromptPayQR(ctx context.Context, amount float64) (*payment.QRItem, error) {
+	args := m.Called(ctx, amount)
+	if value, ok := args.Get(0).(*payment.QRItem); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *PaymentService) Tender(ctx context.Context, in payment.TenderInput) (*payment.TenderResult, error) {
+	args := m.Called(ctx, in)
+	if value, ok := args.Get(0).(*payment.TenderResult); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
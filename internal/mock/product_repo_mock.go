@@ -4,6 +4,7 @@ import (
 	"ans-spareparts-api/internal/domain"
 	"ans-spareparts-api/internal/features/product"
 	"context"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -59,3 +60,57 @@ func (r *ProductRepository) Delete(ctx context.Context, id uint) error {
 	args := r.Called(ctx, id)
 	return args.Error(0)
 }
+
+func (r *ProductRepository) DeletedSKUs(ctx context.Context, skus []string) ([]string, error) {
+	args := r.Called(ctx, skus)
+	if value, ok := args.Get(0).([]string); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (r *ProductRepository) UpsertBySKU(ctx context.Context, records []product.ImportRecord) (int, int, error) {
+	args := r.Called(ctx, records)
+	return args.Int(0), args.Int(1), args.Error(2)
+}
+
+func (r *ProductRepository) Export(ctx context.Context, q product.ListQuery, fn func(*product.ExportRow) error) error {
+	args := r.Called(ctx, q, fn)
+	// ถ้ากำหนดแถวไว้ ให้เรียก fn ตามลำดับเหมือน cursor จริง
+	if rows, ok := args.Get(0).([]*product.ExportRow); ok {
+		for _, row := range rows {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (r *ProductRepository) Facets(ctx context.Context, q product.ListQuery) (*product.Facets, error) {
+	args := r.Called(ctx, q)
+	if f, ok := args.Get(0).(*product.Facets); ok {
+		return f, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (r *ProductRepository) ListDeleted(ctx context.Context, limit, offset int) ([]*domain.Product, int64, error) {
+	args := r.Called(ctx, limit, offset)
+
+	var rows []*domain.Product
+	if args.Get(0) != nil {
+		rows = args.Get(0).([]*domain.Product)
+	}
+	return rows, args.Get(1).(int64), args.Error(2)
+}
+
+func (r *ProductRepository) Restore(ctx context.Context, id uint) error {
+	args := r.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (r *ProductRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	args := r.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
@@ -3,6 +3,8 @@ package mocks
 import (
 	"ans-spareparts-api/internal/features/product"
 	"context"
+	"io"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -59,3 +61,38 @@ func (m *ProductService) List(ctx context.Context, query product.ListQuery) (*pr
 	}
 	return nil, args.Error(1)
 }
+
+func (m *ProductService) ImportProducts(ctx context.Context, in product.ImportInput) (*product.ImportReport, error) {
+	args := m.Called(ctx, in)
+	if value, ok := args.Get(0).(*product.ImportReport); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductService) ExportProducts(ctx context.Context, q product.ListQuery, format string, out io.Writer) error {
+	args := m.Called(ctx, q, format, out)
+	// จำลองการเขียนข้อมูลลง stream
+	if body, ok := args.Get(0).(string); ok {
+		_, _ = io.WriteString(out, body)
+	}
+	return args.Error(1)
+}
+
+func (m *ProductService) ListTrash(ctx context.Context, limit, offset int) (*product.TrashOutput, error) {
+	args := m.Called(ctx, limit, offset)
+	if out, ok := args.Get(0).(*product.TrashOutput); ok {
+		return out, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductService) RestoreProduct(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *ProductService) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
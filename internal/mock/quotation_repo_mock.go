@@ -0,0 +1,57 @@
+package mocks
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/features/quotation"
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type QuotationRepository struct {
+	mock.Mock
+}
+
+func NewMockQuotationRepository() *QuotationRepository {
+	return &QuotationRepository{}
+}
+
+func (m *QuotationRepository) GetByID(ctx context.Context, id uint) (*domain.Quotation, error) {
+	args := m.Called(ctx, id)
+	if value, ok := args.Get(0).(*domain.Quotation); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *QuotationRepository) List(ctx context.Context, q quotation.ListQuery) ([]*domain.Quotation, int64, error) {
+	args := m.Called(ctx, q)
+
+	var rows []*domain.Quotation
+	if args.Get(0) != nil {
+		rows = args.Get(0).([]*domain.Quotation)
+	}
+	return rows, args.Get(1).(int64), args.Error(2)
+}
+
+// Create จำลองการออกเลขใน transaction (tx = nil) ก่อนบันทึก
+func (m *QuotationRepository) Create(ctx context.Context, q *domain.Quotation, number quotation.NumberFunc) error {
+	code, err := number(nil)
+	if err != nil {
+		return err
+	}
+	q.Number = code
+	args := m.Called(ctx, q)
+	return args.Error(0)
+}
+
+func (m *QuotationRepository) UpdateStatus(ctx context.Context, id uint, from, to string) error {
+	args := m.Called(ctx, id, from, to)
+	return args.Error(0)
+}
+
+func (m *QuotationRepository) ExpireBefore(ctx context.Context, t time.Time) (int64, error) {
+	args := m.Called(ctx, t)
+	return args.Get(0).(int64), args.Error(1)
+}
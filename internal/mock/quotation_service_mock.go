@@ -0,0 +1,53 @@
+package mocks
+
+import (
+	"ans-spareparts-api/internal/features/quotation"
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type QuotationService struct {
+	mock.Mock
+}
+
+func NewQuotationService() *QuotationService {
+	return &QuotationService{}
+}
+
+func (m *QuotationService) CreateQuotation(ctx context.Context, in quotation.CreateInput) (*quotation.Item, error) {
+	args := m.Called(ctx, in)
+	if value, ok := args.Get(0).(*quotation.Item); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *QuotationService) GetQuotation(ctx context.Context, id uint) (*quotation.Item, error) {
+	args := m.Called(ctx, id)
+	if value, ok := args.Get(0).(*quotation.Item); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *QuotationService) List(ctx context.Context, q quotation.ListQuery) (*quotation.ListOutput, error) {
+	args := m.Called(ctx, q)
+	if value, ok := args.Get(0).(*quotation.ListOutput); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *QuotationService) UpdateStatus(ctx context.Context, id uint, status string) (*quotation.Item, error) {
+	args := m.Called(ctx, id, status)
+	if value, ok := args.Get(0).(*quotation.Item); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *QuotationService) ExpireStale(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
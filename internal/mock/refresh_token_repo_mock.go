@@ -0,0 +1,43 @@
+package mocks
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type RefreshTokenRepository struct {
+	mock.Mock
+}
+
+func NewMockRefreshTokenRepository() *RefreshTokenRepository {
+	return &RefreshTokenRepository{}
+}
+
+func (m *RefreshTokenRepository) Create(ctx context.Context, t *domain.RefreshToken) error {
+	args := m.Called(ctx, t)
+	return args.Error(0)
+}
+
+func (m *RefreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	args := m.Called(ctx, tokenHash)
+	if value, ok := args.Get(0).(*domain.RefreshToken); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *RefreshTokenRepository) Rotate(ctx context.Context, tokenHash string, next *domain.RefreshToken, now time.Time) (*domain.RefreshToken, error) {
+	args := m.Called(ctx, tokenHash, next, now)
+	if value, ok := args.Get(0).(*domain.RefreshToken); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, now time.Time) (int64, error) {
+	args := m.Called(ctx, familyID, now)
+	return args.Get(0).(int64), args.Error(1)
+}
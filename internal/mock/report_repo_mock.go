@@ -0,0 +1,33 @@
+package mocks
+
+import (
+	"ans-spareparts-api/internal/features/report"
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type ReportRepository struct {
+	mock.Mock
+}
+
+func NewMockReportRepository() *ReportRepository {
+	return &ReportRepository{}
+}
+
+func (m *ReportRepository) OutboundValueByProduct(ctx context.Context, from, to time.Time) ([]report.ProductValueRow, error) {
+	args := m.Called(ctx, from, to)
+	if value, ok := args.Get(0).([]report.ProductValueRow); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ReportRepository) DeadStock(ctx context.Context, cutoff time.Time) ([]report.DeadStockRow, error) {
+	args := m.Called(ctx, cutoff)
+	if value, ok := args.Get(0).([]report.DeadStockRow); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"ans-spareparts-api/internal/features/report"
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type ReportService struct {
+	mock.Mock
+}
+
+func NewReportService() *ReportService {
+	return &ReportService{}
+}
+
+func (m *ReportService) ABC(ctx context.Context, q report.ABCQuery) (*report.ABCReport, error) {
+	args := m.Called(ctx, q)
+	if value, ok := args.Get(0).(*report.ABCReport); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ReportService) DeadStock(ctx context.Context, q report.DeadStockQuery) (*report.DeadStockReport, error) {
+	args := m.Called(ctx, q)
+	if value, ok := args.Get(0).(*report.DeadStockReport); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -0,0 +1,42 @@
+package mocks
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type ShiftRepository struct {
+	mock.Mock
+}
+
+func NewMockShiftRepository() *ShiftRepository {
+	return &ShiftRepository{}
+}
+
+func (m *ShiftRepository) GetByID(ctx context.Context, id uint) (*domain.Shift, error) {
+	args := m.Called(ctx, id)
+	if value, ok := args.Get(0).(*domain.Shift); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ShiftRepository) GetOpenByUser(ctx context.Context, userID uint) (*domain.Shift, error) {
+	args := m.Called(ctx, userID)
+	if value, ok := args.Get(0).(*domain.Shift); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ShiftRepository) Create(ctx context.Context, s *domain.Shift) error {
+	args := m.Called(ctx, s)
+	return args.Error(0)
+}
+
+func (m *ShiftRepository) Close(ctx context.Context, s *domain.Shift) error {
+	args := m.Called(ctx, s)
+	return args.Error(0)
+}
@@ -0,0 +1,56 @@
+package mocks
+
+import (
+	"ans-spareparts-api/internal/features/shift"
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type ShiftService struct {
+	mock.Mock
+}
+
+func NewShiftService() *ShiftService {
+	return &ShiftService{}
+}
+
+func (m *ShiftService) OpenShift(ctx context.Context, in shift.OpenInput) (*shift.Item, error) {
+	args := m.Called(ctx, in)
+	if value, ok := args.Get(0).(*shift.Item); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ShiftService) CurrentShift(ctx context.Context, userID uint) (*shift.Item, error) {
+	args := m.Called(ctx, userID)
+	if value, ok := args.Get(0).(*shift.Item); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ShiftService) XReport(ctx context.Context, in shift.ReportInput) (*shift.Report, error) {
+	args := m.Called(ctx, in)
+	if value, ok := args.Get(0).(*shift.Report); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ShiftService) CloseShift(ctx context.Context, in shift.CloseInput) (*shift.Report, error) {
+	args := m.Called(ctx, in)
+	if value, ok := args.Get(0).(*shift.Report); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ShiftService) ZReport(ctx context.Context, in shift.ReportInput) (*shift.Report, error) {
+	args := m.Called(ctx, in)
+	if value, ok := args.Get(0).(*shift.Report); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -0,0 +1,52 @@
+package mocks
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/features/stock"
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type StockRepository struct {
+	mock.Mock
+}
+
+func NewMockStockRepository() *StockRepository {
+	return &StockRepository{}
+}
+
+func (m *StockRepository) Receive(ctx context.Context, mv *domain.StockMovement) error {
+	args := m.Called(ctx, mv)
+	return args.Error(0)
+}
+
+func (m *StockRepository) Issue(ctx context.Context, mv *domain.StockMovement) error {
+	args := m.Called(ctx, mv)
+	return args.Error(0)
+}
+
+func (m *StockRepository) Adjust(ctx context.Context, mv *domain.StockMovement, inventoryID uint, version *uint) (*domain.Inventory, error) {
+	args := m.Called(ctx, mv, inventoryID, version)
+	if inv, ok := args.Get(0).(*domain.Inventory); ok {
+		return inv, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *StockRepository) ListMovements(ctx context.Context, q stock.MovementQuery) ([]*domain.StockMovement, int64, error) {
+	args := m.Called(ctx, q)
+	if value, ok := args.Get(0).([]*domain.StockMovement); ok {
+		return value, args.Get(1).(int64), args.Error(2)
+	}
+	return nil, args.Get(1).(int64), args.Error(2)
+}
+
+func (m *StockRepository) Valuation(ctx context.Context, asOf time.Time, groupBy string) ([]stock.ValuationRow, error) {
+	args := m.Called(ctx, asOf, groupBy)
+	if value, ok := args.Get(0).([]stock.ValuationRow); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
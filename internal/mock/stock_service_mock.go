@@ -0,0 +1,48 @@
+package mocks
+
+import (
+	"ans-spareparts-api/internal/features/stock"
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type StockService struct {
+	mock.Mock
+}
+
+func NewStockService() *StockService {
+	return &StockService{}
+}
+
+func (m *StockService) Receive(ctx context.Context, in stock.ReceiveInput) (*stock.MovementItem, error) {
+	args := m.Called(ctx, in)
+	if value, ok := args.Get(0).(*stock.MovementItem); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *StockService) Issue(ctx context.Context, in stock.IssueInput) (*stock.MovementItem, error) {
+	args := m.Called(ctx, in)
+	if value, ok := args.Get(0).(*stock.MovementItem); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *StockService) ListMovements(ctx context.Context, q stock.MovementQuery) (*stock.MovementListOutput, error) {
+	args := m.Called(ctx, q)
+	if value, ok := args.Get(0).(*stock.MovementListOutput); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *StockService) Valuation(ctx context.Context, q stock.ValuationQuery) (*stock.Valuation, error) {
+	args := m.Called(ctx, q)
+	if value, ok := args.Get(0).(*stock.Valuation); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
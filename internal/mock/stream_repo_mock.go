@@ -0,0 +1,24 @@
+package mocks
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type StreamRepository struct {
+	mock.Mock
+}
+
+func NewMockStreamRepository() *StreamRepository {
+	return &StreamRepository{}
+}
+
+func (m *StreamRepository) Since(ctx context.Context, eventType string, afterID uint, limit int) ([]*domain.OutboxEvent, error) {
+	args := m.Called(ctx, eventType, afterID, limit)
+	if value, ok := args.Get(0).([]*domain.OutboxEvent); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
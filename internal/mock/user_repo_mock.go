@@ -3,6 +3,7 @@ package mocks
 import (
 	"ans-spareparts-api/internal/domain"
 	"context"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -55,3 +56,23 @@ func (m *UserRepository) Delete(ctx context.Context, id uint) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
+
+func (m *UserRepository) ListDeleted(ctx context.Context, limit, offset int) ([]*domain.User, int64, error) {
+	args := m.Called(ctx, limit, offset)
+
+	var rows []*domain.User
+	if args.Get(0) != nil {
+		rows = args.Get(0).([]*domain.User)
+	}
+	return rows, args.Get(1).(int64), args.Error(2)
+}
+
+func (m *UserRepository) Restore(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *UserRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
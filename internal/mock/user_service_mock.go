@@ -3,6 +3,7 @@ package mocks
 import (
 	"ans-spareparts-api/internal/features/user"
 	"context"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -27,3 +28,21 @@ func (m *UserService) DeleteUser(ctx context.Context, userID uint) error {
 	args := m.Called(ctx, userID)
 	return args.Error(0)
 }
+
+func (m *UserService) ListTrash(ctx context.Context, limit, offset int) (*user.TrashOutput, error) {
+	args := m.Called(ctx, limit, offset)
+	if out, ok := args.Get(0).(*user.TrashOutput); ok {
+		return out, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *UserService) RestoreUser(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *UserService) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
@@ -0,0 +1,106 @@
+package mocks
+
+import (
+	"ans-spareparts-api/internal/domain"
+	"ans-spareparts-api/internal/features/webhook"
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type WebhookRepository struct {
+	mock.Mock
+}
+
+func NewMockWebhookRepository() *WebhookRepository {
+	return &WebhookRepository{}
+}
+
+func (m *WebhookRepository) CreateSubscription(ctx context.Context, s *domain.WebhookSubscription) error {
+	args := m.Called(ctx, s)
+	return args.Error(0)
+}
+
+func (m *WebhookRepository) GetSubscription(ctx context.Context, id uint) (*domain.WebhookSubscription, error) {
+	args := m.Called(ctx, id)
+	if value, ok := args.Get(0).(*domain.WebhookSubscription); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhookRepository) ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	args := m.Called(ctx)
+	if value, ok := args.Get(0).([]*domain.WebhookSubscription); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhookRepository) ListActive(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	args := m.Called(ctx)
+	if value, ok := args.Get(0).([]*domain.WebhookSubscription); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhookRepository) UpdateSubscription(ctx context.Context, s *domain.WebhookSubscription) error {
+	args := m.Called(ctx, s)
+	return args.Error(0)
+}
+
+func (m *WebhookRepository) DeleteSubscription(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *WebhookRepository) EnqueueDeliveries(ctx context.Context, ds []*domain.WebhookDelivery) error {
+	args := m.Called(ctx, ds)
+	return args.Error(0)
+}
+
+func (m *WebhookRepository) ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error) {
+	args := m.Called(ctx, now, limit, lease)
+	if value, ok := args.Get(0).([]*domain.WebhookDelivery); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhookRepository) SaveAttempt(ctx context.Context, d *domain.WebhookDelivery, a *domain.WebhookAttempt) error {
+	args := m.Called(ctx, d, a)
+	return args.Error(0)
+}
+
+func (m *WebhookRepository) GetDelivery(ctx context.Context, id uint) (*domain.WebhookDelivery, error) {
+	args := m.Called(ctx, id)
+	if value, ok := args.Get(0).(*domain.WebhookDelivery); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhookRepository) ListDeliveries(ctx context.Context, q webhook.DeliveryQuery) ([]*domain.WebhookDelivery, int64, error) {
+	args := m.Called(ctx, q)
+
+	var rows []*domain.WebhookDelivery
+	if args.Get(0) != nil {
+		rows = args.Get(0).([]*domain.WebhookDelivery)
+	}
+	return rows, args.Get(1).(int64), args.Error(2)
+}
+
+func (m *WebhookRepository) ListAttempts(ctx context.Context, deliveryID uint) ([]*domain.WebhookAttempt, error) {
+	args := m.Called(ctx, deliveryID)
+	if value, ok := args.Get(0).([]*domain.WebhookAttempt); ok {
+		return value, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhookRepository) Redeliver(ctx context.Context, id uint, now time.Time) error {
+	args := m.Called(ctx, id, now)
+	return args.Error(0)
+}
@@ -0,0 +1,79 @@
+package mocks
+
+import (
+	"ans-spareparts-api/internal/features/webhook"
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type WebhookService struct {
+	mock.Mock
+}
+
+func NewWebhookService() *WebhookService {
+	return &WebhookService{}
+}
+
+func (m *WebhookService) CreateSubscription(ctx context.Context, in webhook.CreateInput) (*webhook.Subscription, error) {
+	args := m.Called(ctx, in)
+	if out, ok := args.Get(0).(*webhook.Subscription); ok {
+		return out, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhookService) GetSubscription(ctx context.Context, id uint) (*webhook.Subscription, error) {
+	args := m.Called(ctx, id)
+	if out, ok := args.Get(0).(*webhook.Subscription); ok {
+		return out, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhookService) ListSubscriptions(ctx context.Context) ([]*webhook.Subscription, error) {
+	args := m.Called(ctx)
+	if out, ok := args.Get(0).([]*webhook.Subscription); ok {
+		return out, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhookService) UpdateSubscription(ctx context.Context, id uint, in webhook.UpdateInput) (*webhook.Subscription, error) {
+	args := m.Called(ctx, id, in)
+	if out, ok := args.Get(0).(*webhook.Subscription); ok {
+		return out, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhookService) DeleteSubscription(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *WebhookService) ListDeliveries(ctx context.Context, q webhook.DeliveryQuery) (*webhook.DeliveryListOutput, error) {
+	args := m.Called(ctx, q)
+	if out, ok := args.Get(0).(*webhook.DeliveryListOutput); ok {
+		return out, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhookService) GetDelivery(ctx context.Context, id uint) (*webhook.DeliveryDetail, error) {
+	args := m.Called(ctx, id)
+	if out, ok := args.Get(0).(*webhook.DeliveryDetail); ok {
+		return out, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhookService) Redeliver(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *WebhookService) DeliverDue(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
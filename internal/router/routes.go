@@ -1,13 +1,24 @@
 package router
 
 import (
+	"ans-spareparts-api/internal/features/audit"
 	"ans-spareparts-api/internal/features/auth"
 	"ans-spareparts-api/internal/features/category"
 	"ans-spareparts-api/internal/features/inventory"
+	"ans-spareparts-api/internal/features/payment"
 	"ans-spareparts-api/internal/features/product"
+	"ans-spareparts-api/internal/features/quotation"
+	"ans-spareparts-api/internal/features/report"
+	"ans-spareparts-api/internal/features/shift"
+	"ans-spareparts-api/internal/features/stock"
+	"ans-spareparts-api/internal/features/stream"
 	"ans-spareparts-api/internal/features/user"
+	"ans-spareparts-api/internal/features/webhook"
+	"ans-spareparts-api/internal/infra/idempotency"
 	"ans-spareparts-api/internal/infra/jwtx"
+	"ans-spareparts-api/internal/infra/ratelimit"
 	"ans-spareparts-api/internal/middleware"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -18,8 +29,29 @@ type Deps struct {
 	ProductUC   product.Service
 	CategoryUC  category.Service
 	InventoryUC inventory.Service
+	QuotationUC quotation.Service
+	ShiftUC     shift.Service
+	PaymentUC   payment.Service
+	StockUC     stock.Service
+	ReportUC    report.Service
+	AuditUC     audit.Service
+	WebhookUC   webhook.Service
+	StreamUC    stream.Service
 
 	TokenManager jwtx.TokenManager
+
+	// StreamHeartbeat ระยะห่างของ ": ping" ใน SSE
+	StreamHeartbeat time.Duration
+
+	// Idempotency-Key ของ request ที่แก้ไขข้อมูล
+	IdempotencyStore   idempotency.Store
+	IdempotencyTTL     time.Duration
+	IdempotencyLockTTL time.Duration
+
+	// RateLimiter เป็น nil ได้ (ไม่จำกัด) AuthRateLimit ใช้กับ login/register
+	RateLimiter   ratelimit.Limiter
+	RateLimit     middleware.RateLimitRule
+	AuthRateLimit middleware.RateLimitRule
 }
 
 func RegisterRoutes(app *fiber.App, d Deps) {
@@ -29,34 +61,58 @@ func RegisterRoutes(app *fiber.App, d Deps) {
 	productHandler := product.NewHandler(d.ProductUC)
 	categoryHandler := category.NewHandler(d.CategoryUC)
 	inventoryHandler := inventory.NewHandler(d.InventoryUC)
+	quotationHandler := quotation.NewHandler(d.QuotationUC)
+	shiftHandler := shift.NewHandler(d.ShiftUC)
+	paymentHandler := payment.NewHandler(d.PaymentUC)
+	stockHandler := stock.NewHandler(d.StockUC)
+	reportHandler := report.NewHandler(d.ReportUC)
+	auditHandler := audit.NewHandler(d.AuditUC)
+	webhookHandler := webhook.NewHandler(d.WebhookUC)
+	streamHandler := stream.NewHandler(d.StreamUC, d.StreamHeartbeat)
 
 	// --- กำหนด Group /v1 ---
+	// middleware ที่ส่งให้ Group ถูก Use บนทั้ง prefix ของ group และทำงานกับทุก route ที่ลงทะเบียนหลังจากนั้น
+	// route ที่ไม่ต้อง Login จึงต้องลงทะเบียนก่อน requireAuth และ middleware ของ Manager ใส่ราย route
+	// (ยกเว้น group ที่ทั้ง prefix เป็นของ Manager เช่น /trash)
 	api := app.Group("/v1")
-	// --- RequireAuth path
-	requireAuth := api.Group("/", middleware.RequireAuth(d.TokenManager))
-	// --- RequireRole path
-	requireRole := requireAuth.Group("/", middleware.RequireRole("manager"))
 
 	// ---  Auth ไม่ต้องใช้ JWT ---
+	// login/register นับต่อ IP และเข้มกว่า route อื่น
+	authLimit := middleware.RateLimit(d.RateLimiter, d.AuthRateLimit)
 	authGroup := api.Group("/auth")
-	authGroup.Post("/register", authHandler.Register)
-	authGroup.Post("/login", authHandler.Login)
-	requireAuth.Post("/auth/logout", authHandler.Logout)
+	authGroup.Post("/register", authLimit, authHandler.Register)
+	authGroup.Post("/login", authLimit, authHandler.Login)
+	// refresh/logout ไม่ต้องใช้ access token (อาจหมดอายุไปแล้ว) ใช้ refresh token ใน body แทน
+	authGroup.Post("/refresh", authHandler.Refresh)
+	authGroup.Post("/logout", authHandler.Logout)
+
+	// --- RequireAuth path: ทุก route ใต้ /v1 ที่ลงทะเบียนหลังจากนี้
+	requireAuth := api.Group("/",
+		middleware.RequireAuth(d.TokenManager),
+		middleware.RateLimit(d.RateLimiter, d.RateLimit),
+		middleware.Idempotency(d.IdempotencyStore, d.IdempotencyTTL, d.IdempotencyLockTTL),
+	)
+	// --- RequireRole ใส่ต่อ route (วางหลัง requireAuth เสมอ)
+	manager := middleware.RequireRole("manager")
 
 	// ---  User (ต้อง Login) ---
 	users := requireAuth.Group("/users")
 	users.Get("/:id", userHandler.GetProfile)
 	users.Delete("/:id", userHandler.DeleteProfile)
+	// ปลดล็อกบัญชีที่ใส่รหัสผิดจนถูกล็อก (ต้อง Login และ เป็น Manager)
+	users.Post("/:id/unlock", manager, authHandler.UnlockUser)
 
 	// --- Products (ต้อง Login) ---
 	products := requireAuth.Group("/products")
-	products.Get("/", productHandler.List)
+	products.Get("/", middleware.ETag(), productHandler.List)
+	products.Get("/export", productHandler.ExportProducts)
 	products.Get("/:id", productHandler.GetProductDetail)
 	// --- Products (ต้อง Login และ เป็น Manager) ---
-	productManager := requireRole.Group("/products")
-	productManager.Post("/:id", productHandler.CreateProduct)
-	productManager.Patch("/:id", productHandler.UpdateProduct)
-	productManager.Delete("/:id", productHandler.DeleteProduct)
+	// ต้องลงทะเบียนก่อน "/:id" ไม่งั้น path import จะถูกจับเป็น id
+	products.Post("/import", manager, productHandler.ImportProducts)
+	products.Post("/:id", manager, productHandler.CreateProduct)
+	products.Patch("/:id", manager, productHandler.UpdateProduct)
+	products.Delete("/:id", manager, productHandler.DeleteProduct)
 	// เรียก Inventory ด้วย ProductID
 	products.Get("/:id/inventory", inventoryHandler.GetInventoryByProductID)
 
@@ -65,14 +121,75 @@ func RegisterRoutes(app *fiber.App, d Deps) {
 	categories.Get("/", categoryHandler.List)
 	categories.Get("/:id", categoryHandler.GetCategory)
 	// --- Category (ต้่อง Login และ Role == "manager")
-	categoriesManager := requireRole.Group("/categories")
-	categoriesManager.Patch("/:id", categoryHandler.UpdateCategory)
-	categoriesManager.Delete("/:id", categoryHandler.DeleteCategory)
+	categories.Patch("/:id", manager, categoryHandler.UpdateCategory)
+	categories.Delete("/:id", manager, categoryHandler.DeleteCategory)
 
 	// --- Inventory (ต้อง Login) ---
+	// export มีต้นทุนจึงจำกัดเฉพาะ Manager และต้องลงทะเบียนก่อน "/:id"
 	inventories := requireAuth.Group("/inventories")
-	inventories.Get("/", inventoryHandler.List)
+	inventories.Get("/export", manager, inventoryHandler.ExportInventories)
+	inventories.Get("/", middleware.ETag(), inventoryHandler.List)
 	inventories.Get("/:id", inventoryHandler.GetInventoryByID)
-	inventories.Get("/:id", inventoryHandler.UpdateQuantity)
+	inventories.Patch("/:id", inventoryHandler.UpdateQuantity)
+
+	// --- Stream ยอดสต็อกแบบสด SSE (ต้อง Login) ---
+	requireAuth.Get("/stream/inventory", streamHandler.Inventory)
+
+	// --- Trash ข้อมูลที่ถูกลบ (ต้อง Login และ เป็น Manager) ---
+	trashGroup := requireAuth.Group("/trash", manager)
+	trashGroup.Get("/products", productHandler.ListTrash)
+	trashGroup.Post("/products/:id/restore", productHandler.Restore)
+	trashGroup.Get("/categories", categoryHandler.ListTrash)
+	trashGroup.Post("/categories/:id/restore", categoryHandler.Restore)
+	trashGroup.Get("/users", userHandler.ListTrash)
+	trashGroup.Post("/users/:id/restore", userHandler.Restore)
+
+	// --- Audit log ประวัติการแก้ไข (ต้อง Login และ เป็น Manager) ---
+	requireAuth.Get("/audit", manager, auditHandler.List)
+
+	// --- Webhooks ส่ง event ให้ระบบภายนอก (ต้อง Login และ เป็น Manager) ---
+	webhooks := requireAuth.Group("/webhooks", manager)
+	webhooks.Post("/", webhookHandler.CreateSubscription)
+	webhooks.Get("/", webhookHandler.ListSubscriptions)
+	// ต้องลงทะเบียนก่อน "/:id" ไม่งั้น deliveries จะถูกจับเป็น id
+	webhooks.Get("/deliveries/:id", webhookHandler.GetDelivery)
+	webhooks.Post("/deliveries/:id/redeliver", webhookHandler.Redeliver)
+	webhooks.Get("/:id", webhookHandler.GetSubscription)
+	webhooks.Patch("/:id", webhookHandler.UpdateSubscription)
+	webhooks.Delete("/:id", webhookHandler.DeleteSubscription)
+	webhooks.Get("/:id/deliveries", webhookHandler.ListDeliveries)
+
+	// --- Quotation (ต้อง Login) ---
+	quotations := requireAuth.Group("/quotations")
+	quotations.Post("/", quotationHandler.CreateQuotation)
+	quotations.Get("/", quotationHandler.List)
+	quotations.Get("/:id", quotationHandler.GetQuotation)
+	quotations.Get("/:id/render", quotationHandler.RenderQuotation)
+	quotations.Patch("/:id/status", quotationHandler.UpdateStatus)
+
+	// --- Shift ลิ้นชักเงินสด (ต้อง Login) ---
+	shifts := requireAuth.Group("/shifts")
+	shifts.Post("/open", shiftHandler.OpenShift)
+	shifts.Get("/current", shiftHandler.CurrentShift)
+	shifts.Get("/:id/x-report", shiftHandler.XReport)
+	shifts.Post("/:id/close", shiftHandler.CloseShift)
+	shifts.Get("/:id/z-report", shiftHandler.ZReport)
+
+	// --- Payment (ต้อง Login) ---
+	payments := requireAuth.Group("/payments")
+	payments.Post("/promptpay-qr", paymentHandler.PromptPayQR)
+	payments.Post("/tender", paymentHandler.Tender)
+
+	// --- Stock ต้นทุนและมูลค่าสต็อก (ต้อง Login และ เป็น Manager) ---
+	stocks := requireAuth.Group("/stock", manager)
+	stocks.Post("/receive", stockHandler.Receive)
+	stocks.Post("/issue", stockHandler.Issue)
+	stocks.Get("/movements", stockHandler.ListMovements)
+	stocks.Get("/valuation", stockHandler.Valuation)
+
+	// --- Reports (ต้อง Login และ เป็น Manager) ---
+	reports := requireAuth.Group("/reports", manager)
+	reports.Get("/abc", reportHandler.ABC)
+	reports.Get("/dead-stock", reportHandler.DeadStock)
 
 }
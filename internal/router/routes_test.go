@@ -0,0 +1,300 @@
+package router_test
+
+import (
+	"ans-spareparts-api/internal/features/auth"
+	"ans-spareparts-api/internal/features/inventory"
+	"ans-spareparts-api/internal/infra/jwtx"
+	"ans-spareparts-api/internal/infra/loginguard"
+	"ans-spareparts-api/internal/infra/ratelimit"
+	"ans-spareparts-api/internal/middleware"
+	mocks "ans-spareparts-api/internal/mock"
+	"ans-spareparts-api/internal/router"
+	"ans-spareparts-api/pkg/apperror"
+	"ans-spareparts-api/pkg/testutil/fixtures"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// countLimiter นับแบบ fixed ไม่มี window และจำ key ที่ถูกเรียก (แยก rule ได้จาก prefix ของ key)
+type countLimiter struct {
+	counts map[string]int
+	keys   []string
+}
+
+func (l *countLimiter) Allow(_ context.Context, key string, limit int, window time.Duration) (*ratelimit.Result, error) {
+	l.keys = append(l.keys, key)
+	if l.counts[key] >= limit {
+		return &ratelimit.Result{Limit: limit, Reset: time.Second}, nil
+	}
+	l.counts[key]++
+	return &ratelimit.Result{Allowed: true, Limit: limit, Remaining: limit - l.counts[key], Reset: window}, nil
+}
+
+// tokenManager ยอมรับ token ตามตาราง token -> role
+type tokenManager struct {
+	*mocks.TokenIssuer
+	roles map[string]string
+}
+
+func (m *tokenManager) ValidateToken(token string) (*jwtx.Claims, error) {
+	role, ok := m.roles[token]
+	if !ok {
+		return nil, jwtx.ErrInvalidToken
+	}
+	return &jwtx.Claims{UserID: 1, Username: role, Role: role}, nil
+}
+
+func (m *tokenManager) IsBlacklisted(ctx context.Context, jwtID string) (bool, error) {
+	return false, nil
+}
+
+// countGuard ล็อก username เมื่อผิดครบ max ครั้ง (แทน loginguard ที่ใช้ redis)
+type countGuard struct {
+	max      int
+	failures map[string]int
+}
+
+func (g *countGuard) status(username string) *loginguard.Status {
+	st := &loginguard.Status{Failures: g.failures[username]}
+	if st.Failures >= g.max {
+		st.LockedFor = 15 * time.Minute
+	}
+	return st
+}
+
+func (g *countGuard) Check(_ context.Context, username, _ string) (*loginguard.Status, error) {
+	return g.status(username), nil
+}
+
+func (g *countGuard) Fail(_ context.Context, username, _ string) (*loginguard.Status, error) {
+	g.failures[username]++
+	return g.status(username), nil
+}
+
+func (g *countGuard) Reset(_ context.Context, username, _ string) error {
+	delete(g.failures, username)
+	return nil
+}
+
+func (g *countGuard) Unlock(_ context.Context, username string) error {
+	delete(g.failures, username)
+	return nil
+}
+
+type RouterTestSuite struct {
+	App           *fiber.App
+	Limiter       *countLimiter
+	MockAuth      *mocks.AuthService
+	MockInventory *mocks.InventoryService
+	Deps          router.Deps
+}
+
+func NewRouterTestSuite() *RouterTestSuite {
+	return &RouterTestSuite{}
+}
+
+func (ts *RouterTestSuite) SetupTest(t *testing.T) {
+	ts.Limiter = &countLimiter{counts: map[string]int{}}
+	ts.MockAuth = mocks.NewAuthService()
+	ts.MockInventory = mocks.NewInventoryService()
+	ts.Deps = router.Deps{
+		AuthUC:        ts.MockAuth,
+		InventoryUC:   ts.MockInventory,
+		TokenManager:  &tokenManager{TokenIssuer: mocks.NewTokenIssuer(), roles: map[string]string{"cashier-token": "cashier", "manager-token": "manager"}},
+		RateLimiter:   ts.Limiter,
+		RateLimit:     middleware.RateLimitRule{Name: "api", Limit: 100, Window: time.Minute},
+		AuthRateLimit: middleware.RateLimitRule{Name: "auth", Limit: 2, Window: time.Minute},
+	}
+
+	t.Cleanup(func() {
+		ts.MockAuth.AssertExpectations(t)
+		ts.MockInventory.AssertExpectations(t)
+	})
+}
+
+// Build สร้าง app หลังจากแต่ละ test ปรับ Deps เสร็จแล้ว
+func (ts *RouterTestSuite) Build() {
+	ts.App = fiber.New()
+	router.RegisterRoutes(ts.App, ts.Deps)
+}
+
+func (ts *RouterTestSuite) Do(t *testing.T, method, path, token, body string) *http.Response {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := ts.App.Test(req, -1)
+	require.NoError(t, err)
+	return res
+}
+
+func TestRoutes_Login_Uses_Auth_RateLimit_Without_Token(t *testing.T) {
+	ts := NewRouterTestSuite()
+	ts.SetupTest(t)
+	ts.Build()
+
+	ts.MockAuth.On("Login", mock.Anything, mock.Anything).Return(nil, nil, apperror.ErrUnauthorized).Twice()
+
+	statuses := []int{}
+	for range 3 {
+		res := ts.Do(t, fiber.MethodPost, "/v1/auth/login", "", `{"username":"somchai","password":"wrong"}`)
+		statuses = append(statuses, res.StatusCode)
+		assert.Equal(t, "2", res.Header.Get(middleware.HeaderRateLimitLimit))
+	}
+
+	// ไม่ผ่าน RequireAuth (ไม่ได้ 401 จาก middleware ก่อนถึง handler) และนับด้วย rule auth เท่านั้น
+	assert.Equal(t, []int{fiber.StatusUnauthorized, fiber.StatusUnauthorized, fiber.StatusTooManyRequests}, statuses)
+	for _, key := range ts.Limiter.keys {
+		assert.Regexp(t, `^auth:ip:`, key)
+	}
+}
+
+func TestRoutes_Manager_Middleware_Does_Not_Leak(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		token          string
+		setup          func(ts *RouterTestSuite)
+		expectedStatus int
+	}{
+		{
+			name:   "Cashier_Reads_Inventory",
+			method: fiber.MethodGet,
+			path:   "/v1/inventories/1",
+			token:  "cashier-token",
+			setup: func(ts *RouterTestSuite) {
+				ts.MockInventory.On("GetInventoryByID", mock.Anything, uint(1)).Return(&inventory.Item{ID: 1}, nil).Once()
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "Cashier_Cannot_Export_Inventory",
+			method:         fiber.MethodGet,
+			path:           "/v1/inventories/export",
+			token:          "cashier-token",
+			setup:          func(ts *RouterTestSuite) {},
+			expectedStatus: fiber.StatusForbidden,
+		},
+		{
+			name:           "Cashier_Cannot_Unlock_User",
+			method:         fiber.MethodPost,
+			path:           "/v1/users/2/unlock",
+			token:          "cashier-token",
+			setup:          func(ts *RouterTestSuite) {},
+			expectedStatus: fiber.StatusForbidden,
+		},
+		{
+			name:   "Manager_Unlocks_User",
+			method: fiber.MethodPost,
+			path:   "/v1/users/2/unlock",
+			token:  "manager-token",
+			setup: func(ts *RouterTestSuite) {
+				ts.MockAuth.On("UnlockUser", mock.Anything, uint(2)).Return(nil).Once()
+			},
+			expectedStatus: fiber.StatusNoContent,
+		},
+		{
+			name:           "Missing_Token",
+			method:         fiber.MethodGet,
+			path:           "/v1/inventories/1",
+			setup:          func(ts *RouterTestSuite) {},
+			expectedStatus: fiber.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewRouterTestSuite()
+			ts.SetupTest(t)
+			ts.Build()
+			test.setup(ts)
+
+			res := ts.Do(t, test.method, test.path, test.token, "")
+
+			assert.Equal(t, test.expectedStatus, res.StatusCode)
+		})
+	}
+}
+
+func TestRoutes_Login_Locks_After_Repeated_Failures(t *testing.T) {
+	ts := NewRouterTestSuite()
+	ts.SetupTest(t)
+
+	// ใช้ auth service จริงเพื่อให้ทั้ง route, handler และการนับผิดทำงานร่วมกัน
+	user := fixtures.ValidUser()
+	userRepo := mocks.NewMockUserRepository()
+	userRepo.On("GetByUsername", mock.Anything, user.Username).Return(user, nil).Times(5)
+	hasher := mocks.NewHasher()
+	hasher.On("CompareHashAndPassword", "wrong-password", user.Password).Return(apperror.ErrUnauthorized).Times(5)
+	auditor := mocks.NewAuditService()
+	auditor.On("Record", mock.Anything, mock.Anything).Maybe()
+	guard := &countGuard{max: 5, failures: map[string]int{}}
+
+	ts.Deps.AuthUC = auth.NewService(userRepo, mocks.NewTokenIssuer(), hasher, "", auditor, guard, mocks.NewMockRefreshTokenRepository(), time.Hour)
+	ts.Deps.AuthRateLimit = middleware.RateLimitRule{}
+	ts.Build()
+	t.Cleanup(func() {
+		userRepo.AssertExpectations(t)
+		hasher.AssertExpectations(t)
+	})
+
+	body := `{"username":"` + user.Username + `","password":"wrong-password"}`
+	for range 5 {
+		res := ts.Do(t, fiber.MethodPost, "/v1/auth/login", "", body)
+		assert.Equal(t, fiber.StatusUnauthorized, res.StatusCode)
+	}
+
+	res := ts.Do(t, fiber.MethodPost, "/v1/auth/login", "", body)
+	assert.Equal(t, fiber.StatusLocked, res.StatusCode)
+	assert.Equal(t, "900", res.Header.Get(fiber.HeaderRetryAfter))
+}
+
+func TestRoutes_Refresh_And_Logout_Without_Access_Token(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		setup          func(ts *RouterTestSuite)
+		expectedStatus int
+	}{
+		{
+			name: "Refresh",
+			path: "/v1/auth/refresh",
+			setup: func(ts *RouterTestSuite) {
+				ts.MockAuth.On("Refresh", mock.Anything, "refresh-1").Return(&auth.Tokens{AccessToken: "a", RefreshToken: "r"}, nil).Once()
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name: "Logout",
+			path: "/v1/auth/logout",
+			setup: func(ts *RouterTestSuite) {
+				ts.MockAuth.On("Logout", mock.Anything, "", "refresh-1").Return(nil).Once()
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := NewRouterTestSuite()
+			ts.SetupTest(t)
+			ts.Build()
+			test.setup(ts)
+
+			res := ts.Do(t, fiber.MethodPost, test.path, "", `{"refresh_token":"refresh-1"}`)
+
+			assert.Equal(t, test.expectedStatus, res.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,12 @@
+-- document_sequences: เลขที่เอกสารแยกตามประเภท/สาขา/ปี
+CREATE TABLE IF NOT EXISTS document_sequences (
+    id SERIAL PRIMARY KEY,
+    doc_type VARCHAR(10) NOT NULL,
+    branch VARCHAR(5) NOT NULL DEFAULT '00000',
+    year INTEGER NOT NULL,
+    last_no BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_document_sequences_key UNIQUE (doc_type, branch, year)
+);
@@ -0,0 +1,38 @@
+-- quotations
+CREATE TABLE IF NOT EXISTS quotations (
+    id SERIAL PRIMARY KEY,
+    number VARCHAR(50) NOT NULL UNIQUE,
+    customer_name VARCHAR(255) NOT NULL,
+    customer_phone VARCHAR(50),
+    customer_tax_id VARCHAR(20),
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+    valid_until TIMESTAMP NOT NULL,
+    total NUMERIC(12,2) NOT NULL DEFAULT 0,
+    note TEXT,
+    created_by INTEGER,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP NULL
+);
+
+-- ใช้ตอน sweep ใบเสนอราคาที่หมดอายุ
+CREATE INDEX IF NOT EXISTS idx_quotations_status_valid_until ON quotations (status, valid_until);
+
+-- quotation_lines (ราคา ณ เวลาที่เสนอ)
+CREATE TABLE IF NOT EXISTS quotation_lines (
+    id SERIAL PRIMARY KEY,
+    quotation_id INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    sku VARCHAR(100) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    quantity INTEGER NOT NULL,
+    unit_price NUMERIC(10,2) NOT NULL,
+    line_total NUMERIC(12,2) NOT NULL,
+
+    CONSTRAINT fk_quotation_lines_quotation
+        FOREIGN KEY (quotation_id) REFERENCES quotations(id) ON DELETE CASCADE,
+    CONSTRAINT fk_quotation_lines_product
+        FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_quotation_lines_quotation_id ON quotation_lines (quotation_id);
@@ -0,0 +1,34 @@
+-- shifts: กะลิ้นชักเงินสด
+CREATE TABLE IF NOT EXISTS shifts (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    terminal VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    opening_float NUMERIC(12,2) NOT NULL DEFAULT 0,
+    opened_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    closed_at TIMESTAMP NULL,
+    closing_note TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_shifts_user
+        FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+-- เปิดกะค้างได้ครั้งละหนึ่งกะ ต่อผู้ใช้ และ ต่อเครื่อง
+CREATE UNIQUE INDEX IF NOT EXISTS uq_shifts_open_user ON shifts (user_id) WHERE status = 'open';
+CREATE UNIQUE INDEX IF NOT EXISTS uq_shifts_open_terminal ON shifts (terminal) WHERE status = 'open';
+
+-- shift_totals: ยอดต่อช่องทางชำระเงินตอนปิดกะ
+CREATE TABLE IF NOT EXISTS shift_totals (
+    id SERIAL PRIMARY KEY,
+    shift_id INTEGER NOT NULL,
+    method VARCHAR(20) NOT NULL,
+    expected NUMERIC(12,2) NOT NULL DEFAULT 0,
+    counted NUMERIC(12,2) NOT NULL DEFAULT 0,
+    variance NUMERIC(12,2) NOT NULL DEFAULT 0,
+
+    CONSTRAINT fk_shift_totals_shift
+        FOREIGN KEY (shift_id) REFERENCES shifts(id) ON DELETE CASCADE,
+    CONSTRAINT uq_shift_totals_method UNIQUE (shift_id, method)
+);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS stock_cost_layers;
+DROP TABLE IF EXISTS stock_movements;
+ALTER TABLE inventories DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE inventories DROP COLUMN IF EXISTS avg_cost;
@@ -0,0 +1,47 @@
+-- ต้นทุนถัวเฉลี่ยต่อหน่วยของสต็อกคงเหลือ
+ALTER TABLE inventories ADD COLUMN IF NOT EXISTS avg_cost NUMERIC(14,4) NOT NULL DEFAULT 0;
+-- domain.Inventory เป็น soft delete แต่ตารางเดิมไม่มีคอลัมน์นี้
+ALTER TABLE inventories ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+
+-- stock_movements: ledger การเคลื่อนไหวสต็อก
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    quantity INTEGER NOT NULL,
+    unit_cost NUMERIC(14,4) NOT NULL DEFAULT 0,
+    total_cost NUMERIC(14,2) NOT NULL DEFAULT 0,
+    fifo_cost NUMERIC(14,2) NOT NULL DEFAULT 0,
+    reference VARCHAR(100),
+    qty_after INTEGER NOT NULL,
+    avg_cost_after NUMERIC(14,4) NOT NULL DEFAULT 0,
+    value_after NUMERIC(14,2) NOT NULL DEFAULT 0,
+    fifo_value_after NUMERIC(14,2) NOT NULL DEFAULT 0,
+    created_by INTEGER,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_stock_movements_product
+        FOREIGN KEY (product_id) REFERENCES products(id),
+    CONSTRAINT ck_stock_movements_type CHECK (type IN ('receive', 'issue')),
+    CONSTRAINT ck_stock_movements_quantity CHECK (quantity > 0)
+);
+
+-- รายงาน as-of: หา movement ล่าสุดของแต่ละสินค้าก่อนวันที่กำหนด
+CREATE INDEX IF NOT EXISTS idx_stock_movements_product_created ON stock_movements (product_id, created_at, id);
+
+-- stock_cost_layers: ชั้นต้นทุนสำหรับ FIFO
+CREATE TABLE IF NOT EXISTS stock_cost_layers (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL,
+    movement_id INTEGER NOT NULL,
+    unit_cost NUMERIC(14,4) NOT NULL,
+    qty_received INTEGER NOT NULL,
+    qty_remaining INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_stock_cost_layers_movement
+        FOREIGN KEY (movement_id) REFERENCES stock_movements(id) ON DELETE CASCADE,
+    CONSTRAINT ck_stock_cost_layers_remaining CHECK (qty_remaining >= 0 AND qty_remaining <= qty_received)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_cost_layers_open ON stock_cost_layers (product_id, id) WHERE qty_remaining > 0;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_products_search_trgm;
+DROP INDEX IF EXISTS idx_products_search_vector;
+ALTER TABLE products DROP COLUMN IF EXISTS search_vector;
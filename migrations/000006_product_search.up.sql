@@ -0,0 +1,17 @@
+-- ค้นหาสินค้าด้วย full-text + trigram
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- ใช้ config 'simple' เพราะ SKU/รหัสอะไหล่ไม่ควรถูก stem และ Postgres ไม่มี dictionary ภาษาไทย
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(sku, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(name, '')), 'B') ||
+        setweight(to_tsvector('simple', coalesce(description, '')), 'C')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector);
+
+-- expression ต้องตรงกับ searchDocument() ใน product/search.go
+-- ใช้กับ ILIKE '%..%' (ค้นกลางคำภาษาไทย) และ word_similarity (พิมพ์ผิด)
+CREATE INDEX IF NOT EXISTS idx_products_search_trgm ON products
+    USING GIN ((sku || ' ' || name || ' ' || COALESCE(description, '')) gin_trgm_ops);
@@ -0,0 +1,4 @@
+-- optimistic locking: ทุกการแก้ไขต้อง UPDATE ... WHERE version = ? แล้วเพิ่ม version
+ALTER TABLE products ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE inventories ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,18 @@
+-- audit_logs: บันทึกทุกการแก้ไขข้อมูล ใคร/เมื่อไหร่/แก้อะไร (append-only)
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id INTEGER,
+    actor_username VARCHAR(100),
+    actor_role VARCHAR(20),
+    request_id VARCHAR(64),
+    entity_type VARCHAR(30) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    changes JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- ประวัติของ record หนึ่ง / ของผู้ใช้หนึ่งคน
+CREATE INDEX IF NOT EXISTS idx_audit_logs_entity ON audit_logs (entity_type, entity_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor ON audit_logs (actor_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created ON audit_logs (created_at);
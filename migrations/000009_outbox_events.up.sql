@@ -0,0 +1,20 @@
+-- outbox_events: event ที่เขียนใน transaction เดียวกับการแก้ไขข้อมูล รอ relay ส่งต่อ
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(50) NOT NULL,
+    aggregate_type VARCHAR(30) NOT NULL,
+    aggregate_id INTEGER NOT NULL,
+    payload JSONB NOT NULL,
+    request_id VARCHAR(64),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    published_at TIMESTAMP NULL,
+
+    CONSTRAINT ck_outbox_events_status CHECK (status IN ('pending', 'published', 'dead'))
+);
+
+-- relay ดึงเฉพาะแถวที่ถึงเวลาส่ง เรียงตามลำดับที่เกิด
+CREATE INDEX IF NOT EXISTS idx_outbox_events_pending ON outbox_events (next_attempt_at, id) WHERE status = 'pending';
@@ -0,0 +1,9 @@
+DELETE FROM stock_movements WHERE type = 'adjust';
+
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS ck_stock_movements_type;
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS ck_stock_movements_quantity;
+
+ALTER TABLE stock_movements
+    ADD CONSTRAINT ck_stock_movements_type CHECK (type IN ('receive', 'issue'));
+ALTER TABLE stock_movements
+    ADD CONSTRAINT ck_stock_movements_quantity CHECK (quantity > 0);
@@ -0,0 +1,8 @@
+-- adjust: ปรับยอดมือผ่าน PATCH /inventories/:id เก็บ quantity แบบมีเครื่องหมาย (ลบ = ลดยอด)
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS ck_stock_movements_type;
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS ck_stock_movements_quantity;
+
+ALTER TABLE stock_movements
+    ADD CONSTRAINT ck_stock_movements_type CHECK (type IN ('receive', 'issue', 'adjust'));
+ALTER TABLE stock_movements
+    ADD CONSTRAINT ck_stock_movements_quantity CHECK (quantity > 0 OR (type = 'adjust' AND quantity <> 0));
@@ -13,4 +13,7 @@ var (
 	ErrTokenExpired      = errors.New("token expired")
 	ErrInsufficientStock = errors.New("insufficient stock")
 	ErrInvalidSKU        = errors.New("sku is invalid or contains restricted characters")
+	ErrInvalidState      = errors.New("invalid state transition")
+	ErrVersionConflict   = errors.New("resource was modified by another request")
+	ErrAccountLocked     = errors.New("account temporarily locked")
 )
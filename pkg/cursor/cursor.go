@@ -0,0 +1,138 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidCursor = errors.New("cursor: invalid cursor")
+
+// Sort คอลัมน์หนึ่งของการเรียง (query.Sort เป็น alias ของ type นี้)
+type Sort struct {
+	Column string
+	Desc   bool
+}
+
+// Cursor ตำแหน่งของแถวสุดท้ายในหน้าก่อน: ค่าของทุกคอลัมน์ที่ใช้เรียง + ID (กันค่าซ้ำ)
+// ผู้ใช้เห็นเป็น string ทึบ (base64 ของ JSON) ไม่ควรไปพึ่งโครงสร้างข้างใน
+type Cursor struct {
+	Key    string `json:"k,omitempty"` // การเรียงตอนสร้าง cursor (ดู Key)
+	Values []any  `json:"v"`           // ค่าตามลำดับคอลัมน์ใน Key
+	ID     uint   `json:"id"`
+}
+
+// Key ลายเซ็นของการเรียงรวมทิศทาง เช่น "-quantity,location"
+// ใช้ตรวจว่า cursor มาจากการเรียงแบบเดียวกับ request ปัจจุบัน
+func Key(sorts []Sort) string {
+	parts := make([]string, len(sorts))
+	for i, s := range sorts {
+		parts[i] = s.Column
+		if s.Desc {
+			parts[i] = "-" + s.Column
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// New สร้าง cursor ของแถวสุดท้าย; value คืนค่าของคอลัมน์ในแถวนั้น
+func New(sorts []Sort, id uint, value func(column string) any) Cursor {
+	values := make([]any, len(sorts))
+	for i, s := range sorts {
+		values[i] = value(s.Column)
+	}
+	return Cursor{Key: Key(sorts), Values: values, ID: id}
+}
+
+func Encode(c Cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decode คืน nil เมื่อ s ว่าง (หน้าแรก)
+func Decode(s string) (*Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.ID == 0 || len(c.Values) == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// Apply เพิ่มเงื่อนไข keyset และ ORDER BY ทุกคอลัมน์ใน sorts แล้วตามด้วย id (ทิศทางเดียวกับคอลัมน์แรก)
+// after ต้องมาจากการเรียงเดียวกัน (query.Parse ตรวจ Key ให้แล้ว)
+// column ต้องมาจากโค้ด (whitelist) ห้ามรับจากผู้ใช้ตรงๆ และต้องไม่เป็น NULL
+// เพราะการเทียบกับ NULL ไม่เป็นจริง แถวนั้นจะหายจากหน้าถัดไป (ใช้ COALESCE ใน schema)
+func Apply(tx *gorm.DB, sorts []Sort, after *Cursor) *gorm.DB {
+	keys := append(append([]Sort{}, sorts...), Sort{Column: "id", Desc: sorts[0].Desc})
+
+	if after != nil {
+		sql, args := seek(keys, append(append([]any{}, after.Values...), after.ID))
+		tx = tx.Where(sql, args...)
+	}
+	for _, k := range keys {
+		dir := "ASC"
+		if k.Desc {
+			dir = "DESC"
+		}
+		tx = tx.Order(fmt.Sprintf("%s %s", k.Column, dir))
+	}
+	return tx
+}
+
+// seek เงื่อนไข "อยู่หลัง vals" ตามลำดับ keys
+// ทิศทางเดียวกันทั้งหมดใช้ row value (a, b, id) > (?, ?, ?) ซึ่งใช้ index ได้
+// ถ้าทิศทางปนกันต้องกระจายเป็น a > ? OR (a = ? AND b < ?) OR ...
+func seek(keys []Sort, vals []any) (string, []any) {
+	op := func(desc bool) string {
+		if desc {
+			return "<"
+		}
+		return ">"
+	}
+
+	mixed := false
+	cols := make([]string, len(keys))
+	marks := make([]string, len(keys))
+	for i, k := range keys {
+		cols[i], marks[i] = k.Column, "?"
+		mixed = mixed || k.Desc != keys[0].Desc
+	}
+	if !mixed {
+		return fmt.Sprintf("(%s) %s (%s)", strings.Join(cols, ", "), op(keys[0].Desc), strings.Join(marks, ", ")), vals
+	}
+
+	ors := make([]string, len(keys))
+	var args []any
+	for i, k := range keys {
+		ands := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			ands = append(ands, keys[j].Column+" = ?")
+			args = append(args, vals[j])
+		}
+		ands = append(ands, fmt.Sprintf("%s %s ?", k.Column, op(k.Desc)))
+		args = append(args, vals[i])
+		ors[i] = "(" + strings.Join(ands, " AND ") + ")"
+	}
+	return "(" + strings.Join(ors, " OR ") + ")", args
+}
+
+// Page ตัดแถวที่ดึงมาเกิน (repo ดึง limit+1) และสร้าง cursor ของหน้าถัดไป
+// next เป็น "" เมื่อไม่มีหน้าถัดไปแล้ว
+func Page[T any](rows []T, limit int, key func(T) Cursor) ([]T, string) {
+	if limit <= 0 || len(rows) <= limit {
+		return rows, ""
+	}
+	rows = rows[:limit]
+	return rows, Encode(key(rows[len(rows)-1]))
+}
@@ -0,0 +1,73 @@
+package cursor_test
+
+import (
+	"ans-spareparts-api/pkg/cursor"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	s := cursor.Encode(cursor.Cursor{Key: "-created_at", Values: []any{"2025-01-02T03:04:05Z"}, ID: 42})
+
+	c, err := cursor.Decode(s)
+	require.NoError(t, err)
+	assert.Equal(t, "-created_at", c.Key)
+	assert.Equal(t, []any{"2025-01-02T03:04:05Z"}, c.Values)
+	assert.Equal(t, uint(42), c.ID)
+
+	c, err = cursor.Decode("")
+	assert.NoError(t, err)
+	assert.Nil(t, c)
+
+	for _, bad := range []string{"%%%", "bm90LWpzb24", cursor.Encode(cursor.Cursor{Values: []any{1}}), cursor.Encode(cursor.Cursor{ID: 1})} {
+		_, err = cursor.Decode(bad)
+		assert.ErrorIs(t, err, cursor.ErrInvalidCursor, bad)
+	}
+}
+
+func TestKeyAndNew(t *testing.T) {
+	sorts := []cursor.Sort{{Column: "quantity", Desc: true}, {Column: "name"}}
+	assert.Equal(t, "-quantity,name", cursor.Key(sorts))
+	assert.NotEqual(t, cursor.Key(sorts), cursor.Key([]cursor.Sort{{Column: "quantity"}, {Column: "name"}}))
+
+	c := cursor.New(sorts, 7, func(column string) any { return column + "-value" })
+	assert.Equal(t, cursor.Cursor{Key: "-quantity,name", Values: []any{"quantity-value", "name-value"}, ID: 7}, c)
+}
+
+func TestApply(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+
+	var rows []map[string]any
+	stmt := cursor.Apply(db.Table("products"), []cursor.Sort{{Column: "created_at", Desc: true}}, &cursor.Cursor{Values: []any{"2025-01-02T03:04:05Z"}, ID: 7}).
+		Limit(11).Find(&rows).Statement
+	assert.Equal(t, `SELECT * FROM "products" WHERE (created_at, id) < ($1, $2) ORDER BY created_at DESC,id DESC LIMIT $3`, stmt.SQL.String())
+	assert.Equal(t, []any{"2025-01-02T03:04:05Z", uint(7), 11}, stmt.Vars)
+
+	stmt = cursor.Apply(db.Table("categories"), []cursor.Sort{{Column: "name"}}, nil).Find(&rows).Statement
+	assert.Equal(t, `SELECT * FROM "categories" ORDER BY name ASC,id ASC`, stmt.SQL.String())
+
+	// ทุกคอลัมน์ใน sort ถูกใช้ และทิศทางที่ปนกันกระจายเป็น OR
+	stmt = cursor.Apply(db.Table("inventories"), []cursor.Sort{{Column: "quantity", Desc: true}, {Column: "location"}}, &cursor.Cursor{Values: []any{5, "A-01"}, ID: 3}).
+		Find(&rows).Statement
+	assert.Equal(t, `SELECT * FROM "inventories" WHERE ((quantity < $1) OR (quantity = $2 AND location > $3) OR (quantity = $4 AND location = $5 AND id < $6)) ORDER BY quantity DESC,location ASC,id DESC`, stmt.SQL.String())
+	assert.Equal(t, []any{5, 5, "A-01", 5, "A-01", uint(3)}, stmt.Vars)
+}
+
+func TestPage(t *testing.T) {
+	key := func(n int) cursor.Cursor { return cursor.Cursor{Values: []any{n * 10}, ID: uint(n)} }
+
+	rows, next := cursor.Page([]int{1, 2, 3}, 2, key)
+	assert.Equal(t, []int{1, 2}, rows)
+	c, _ := cursor.Decode(next)
+	assert.Equal(t, uint(2), c.ID)
+	assert.Equal(t, []any{float64(20)}, c.Values)
+
+	rows, next = cursor.Page([]int{1, 2}, 2, key)
+	assert.Equal(t, []int{1, 2}, rows)
+	assert.Empty(t, next)
+}
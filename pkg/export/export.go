@@ -0,0 +1,143 @@
+package export
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// รูปแบบไฟล์ที่รองรับ
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+var ErrUnsupportedFormat = errors.New("export: unsupported format")
+
+// Writer เขียนข้อมูลทีละแถว ต้องเรียก Close เพื่อ flush ข้อมูลที่เหลือ
+type Writer interface {
+	Write(row []any) error
+	Close() error
+}
+
+// Supported ตรวจว่ารองรับรูปแบบนี้หรือไม่ (ใช้ตรวจก่อนเริ่ม stream)
+func Supported(format string) bool {
+	return format == FormatCSV || format == FormatXLSX
+}
+
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// NewWriter สร้าง Writer ตามรูปแบบ และเขียนหัวคอลัมน์ให้ทันที
+func NewWriter(w io.Writer, format string, header []string) (Writer, error) {
+	row := make([]any, len(header))
+	for i, h := range header {
+		row[i] = h
+	}
+
+	var out Writer
+	switch format {
+	case FormatCSV:
+		out = &csvWriter{w: csv.NewWriter(w)}
+	case FormatXLSX:
+		f := excelize.NewFile()
+		sw, err := f.NewStreamWriter(f.GetSheetName(0))
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		out = &xlsxWriter{dst: w, f: f, sw: sw}
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+
+	if err := out.Write(row); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) Write(row []any) error {
+	rec := make([]string, len(row))
+	for i, v := range row {
+		rec[i] = csvValue(v)
+	}
+	return c.w.Write(rec)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// csvValue แปลงค่าเป็น string; ข้อความที่ขึ้นต้นด้วยอักขระสูตร (=, +, @) ใส่ ' นำหน้า
+// กันไม่ให้ spreadsheet ตีความเป็นสูตร ('-' ไม่รวม เพราะเป็นค่าติดลบ/SKU ได้ปกติ)
+func csvValue(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		if t != "" && strings.ContainsRune("=+@\t\r", rune(t[0])) {
+			return "'" + t
+		}
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case time.Time:
+		return t.Format(time.RFC3339)
+	case *time.Time:
+		if t == nil {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+	return fmt.Sprint(v)
+}
+
+// xlsxWriter ใช้ StreamWriter ของ excelize ซึ่งพักแถวลงไฟล์ชั่วคราวเมื่อข้อมูลใหญ่ ไม่ค้างใน memory ทั้งหมด
+type xlsxWriter struct {
+	dst io.Writer
+	f   *excelize.File
+	sw  *excelize.StreamWriter
+	row int
+}
+
+func (x *xlsxWriter) Write(row []any) error {
+	x.row++
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+	for i, v := range row {
+		if p, ok := v.(*time.Time); ok {
+			if p == nil {
+				row[i] = nil
+			} else {
+				row[i] = *p
+			}
+		}
+	}
+	return x.sw.SetRow(cell, row)
+}
+
+func (x *xlsxWriter) Close() error {
+	defer x.f.Close()
+	if err := x.sw.Flush(); err != nil {
+		return err
+	}
+	return x.f.Write(x.dst)
+}
@@ -0,0 +1,47 @@
+package export_test
+
+import (
+	"ans-spareparts-api/pkg/export"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := export.NewWriter(&buf, export.FormatCSV, []string{"sku", "name", "price", "qty", "updated_at"})
+	assert.NoError(t, err)
+
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.NoError(t, w.Write([]any{"BRK-001", "Brake, front", 450.5, 10, ts}))
+	assert.NoError(t, w.Write([]any{"-ABC", "=HYPERLINK(\"x\")", 0.0, -1, nil}))
+	assert.NoError(t, w.Close())
+
+	expected := "sku,name,price,qty,updated_at\n" +
+		"BRK-001,\"Brake, front\",450.5,10,2026-01-02T03:04:05Z\n" +
+		"-ABC,\"'=HYPERLINK(\"\"x\"\")\",0,-1,\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := export.NewWriter(&buf, export.FormatXLSX, []string{"sku", "price"})
+	assert.NoError(t, err)
+	assert.NoError(t, w.Write([]any{"BRK-001", 450.5}))
+	assert.NoError(t, w.Close())
+
+	f, err := excelize.OpenReader(&buf)
+	assert.NoError(t, err)
+	rows, err := f.GetRows(f.GetSheetName(0))
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"sku", "price"}, {"BRK-001", "450.5"}}, rows)
+}
+
+func TestNewWriter_Unsupported(t *testing.T) {
+	_, err := export.NewWriter(&bytes.Buffer{}, "ods", nil)
+	assert.ErrorIs(t, err, export.ErrUnsupportedFormat)
+	assert.False(t, export.Supported("ods"))
+}
@@ -0,0 +1,113 @@
+package httpcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// VersionTag สร้าง strong ETag จาก version ของ resource เช่น "3"
+// ถ้า representation รวมหลายแถว ส่ง version ของแถวรองตามมา ("3.7") แต่ If-Match จะอ่านแค่ตัวแรก
+func VersionTag(versions ...uint) string {
+	parts := make([]string, len(versions))
+	for i, v := range versions {
+		parts[i] = strconv.FormatUint(uint64(v), 10)
+	}
+	return `"` + strings.Join(parts, ".") + `"`
+}
+
+// ParseVersion อ่าน version ตัวแรกจาก ETag ที่สร้างด้วย VersionTag; weak ETag ใช้ไม่ได้
+func ParseVersion(etag string) (uint, bool) {
+	etag = strings.TrimSpace(etag)
+	if len(etag) < 3 || etag[0] != '"' || etag[len(etag)-1] != '"' {
+		return 0, false
+	}
+	head, _, _ := strings.Cut(etag[1:len(etag)-1], ".")
+	v, err := strconv.ParseUint(head, 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint(v), true
+}
+
+// ExpectedVersion version ที่ client คาดว่าจะแก้ จาก If-Match (มาก่อน) หรือ field version ใน body
+// fromHeader=true เมื่อมาจาก If-Match (ชนกันตอบ 412) ไม่งั้นมาจาก body (ตอบ 409)
+// ok=false คือไม่ได้ส่งมาทั้งสองทาง; If-Match ที่อ่านไม่ออกได้ version 0 ซึ่งไม่มีแถวไหนตรง
+func ExpectedVersion(c *fiber.Ctx, body *uint) (version uint, fromHeader bool, ok bool) {
+	if im := c.Get(fiber.HeaderIfMatch); im != "" {
+		v, _ := ParseVersion(im)
+		return v, true, true
+	}
+	if body != nil {
+		return *body, false, true
+	}
+	return 0, false, false
+}
+
+// ConflictStatus สถานะที่ใช้ตอบเมื่อ version ไม่ตรง
+func ConflictStatus(fromHeader bool) (int, string) {
+	if fromHeader {
+		return fiber.StatusPreconditionFailed, "PRECONDITION_FAILED"
+	}
+	return fiber.StatusConflict, "CONFLICT"
+}
+
+// TagBytes สร้าง strong ETag จากเนื้อหา response ทั้งก้อน (ใช้กับหน้า list)
+func TagBytes(b []byte) string {
+	sum := sha256.Sum256(b)
+	return quote(sum[:])
+}
+
+func quote(sum []byte) string {
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// Fresh ตั้ง header ETag / Last-Modified แล้วบอกว่าสำเนาที่ client ถืออยู่ยังใช้ได้ (ควรตอบ 304) หรือไม่
+// ถ้ามี If-None-Match จะใช้อันนี้อย่างเดียว ไม่มีจึงค่อยดู If-Modified-Since (RFC 9110 13.2.2)
+// modified เป็น zero value ได้ เมื่อ resource ไม่มีเวลาแก้ไขเดียว
+func Fresh(c *fiber.Ctx, etag string, modified time.Time) bool {
+	c.Set(fiber.HeaderETag, etag)
+	// ให้ browser/proxy เก็บได้แต่ต้องถามกลับทุกครั้ง (ข้อมูลเป็นของผู้ใช้ที่ login)
+	c.Set(fiber.HeaderCacheControl, "private, no-cache")
+	if !modified.IsZero() {
+		c.Set(fiber.HeaderLastModified, modified.UTC().Format(http.TimeFormat))
+	}
+
+	if m := c.Method(); m != fiber.MethodGet && m != fiber.MethodHead {
+		return false
+	}
+	if inm := c.Get(fiber.HeaderIfNoneMatch); inm != "" {
+		return matchAny(inm, etag)
+	}
+	if ims := c.Get(fiber.HeaderIfModifiedSince); ims != "" && !modified.IsZero() {
+		since, err := http.ParseTime(ims)
+		if err != nil {
+			return false
+		}
+		// Last-Modified ละเอียดแค่วินาที
+		return !modified.Truncate(time.Second).After(since)
+	}
+	return false
+}
+
+// NotModified ตอบ 304 โดยไม่มี body (header ETag ที่ตั้งไว้ยังอยู่)
+func NotModified(c *fiber.Ctx) error {
+	c.Context().ResetBody()
+	return c.SendStatus(fiber.StatusNotModified)
+}
+
+// matchAny เทียบแบบ weak ตามที่ If-None-Match กำหนด (ไม่สน prefix W/)
+func matchAny(header, etag string) bool {
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimPrefix(strings.TrimSpace(t), "W/")
+		if t == "*" || t == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package httpcache_test
+
+import (
+	"ans-spareparts-api/pkg/httpcache"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionTag(t *testing.T) {
+	assert.Equal(t, `"3"`, httpcache.VersionTag(3))
+	assert.Equal(t, `"3.7"`, httpcache.VersionTag(3, 7))
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, httpcache.TagBytes([]byte(`{"data":[]}`)))
+
+	for etag, want := range map[string]uint{`"3"`: 3, `"3.7"`: 3, ` "12" `: 12} {
+		v, ok := httpcache.ParseVersion(etag)
+		assert.True(t, ok, etag)
+		assert.Equal(t, want, v, etag)
+	}
+	for _, bad := range []string{`W/"3"`, `3`, `""`, `"abc"`, `*`} {
+		_, ok := httpcache.ParseVersion(bad)
+		assert.False(t, ok, bad)
+	}
+}
+
+func TestExpectedVersion(t *testing.T) {
+	three := uint(3)
+	tests := []struct {
+		name           string
+		ifMatch        string
+		body           *uint
+		wantVersion    uint
+		wantFromHeader bool
+		wantOK         bool
+	}{
+		{name: "IfMatch", ifMatch: `"5"`, body: &three, wantVersion: 5, wantFromHeader: true, wantOK: true},
+		{name: "IfMatch_Unparsable", ifMatch: `W/"5"`, wantVersion: 0, wantFromHeader: true, wantOK: true},
+		{name: "Body", body: &three, wantVersion: 3, wantOK: true},
+		{name: "Missing"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Patch("/", func(c *fiber.Ctx) error {
+				v, fromHeader, ok := httpcache.ExpectedVersion(c, test.body)
+				assert.Equal(t, test.wantVersion, v)
+				assert.Equal(t, test.wantFromHeader, fromHeader)
+				assert.Equal(t, test.wantOK, ok)
+				return nil
+			})
+			req := httptest.NewRequest(fiber.MethodPatch, "/", nil)
+			if test.ifMatch != "" {
+				req.Header.Set(fiber.HeaderIfMatch, test.ifMatch)
+			}
+			_, err := app.Test(req, -1)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestFresh(t *testing.T) {
+	modified := time.Date(2025, 1, 2, 3, 4, 5, 600, time.UTC)
+	etag := httpcache.VersionTag(3, 7)
+
+	tests := []struct {
+		name     string
+		method   string
+		headers  map[string]string
+		expected int
+	}{
+		{name: "Unconditional", expected: fiber.StatusOK},
+		{name: "IfNoneMatch_Match", headers: map[string]string{"If-None-Match": etag}, expected: fiber.StatusNotModified},
+		{name: "IfNoneMatch_List_And_Weak", headers: map[string]string{"If-None-Match": `"other", W/` + etag}, expected: fiber.StatusNotModified},
+		{name: "IfNoneMatch_Star", headers: map[string]string{"If-None-Match": "*"}, expected: fiber.StatusNotModified},
+		{name: "IfNoneMatch_Stale", headers: map[string]string{"If-None-Match": `"other"`}, expected: fiber.StatusOK},
+		{
+			// If-None-Match มาก่อน If-Modified-Since เสมอ
+			name: "IfNoneMatch_Stale_Wins_Over_IfModifiedSince",
+			headers: map[string]string{
+				"If-None-Match":     `"other"`,
+				"If-Modified-Since": modified.Add(time.Hour).Format(http.TimeFormat),
+			},
+			expected: fiber.StatusOK,
+		},
+		{name: "IfModifiedSince_Same_Second", headers: map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)}, expected: fiber.StatusNotModified},
+		{name: "IfModifiedSince_Older", headers: map[string]string{"If-Modified-Since": modified.Add(-time.Second).Format(http.TimeFormat)}, expected: fiber.StatusOK},
+		{name: "IfModifiedSince_Invalid", headers: map[string]string{"If-Modified-Since": "yesterday"}, expected: fiber.StatusOK},
+		{name: "Not_GET", method: fiber.MethodPost, headers: map[string]string{"If-None-Match": etag}, expected: fiber.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			app := fiber.New()
+			app.All("/", func(c *fiber.Ctx) error {
+				if httpcache.Fresh(c, etag, modified) {
+					return httpcache.NotModified(c)
+				}
+				return c.SendString("body")
+			})
+
+			method := test.method
+			if method == "" {
+				method = fiber.MethodGet
+			}
+			req := httptest.NewRequest(method, "/", nil)
+			for k, v := range test.headers {
+				req.Header.Set(k, v)
+			}
+			res, err := app.Test(req, -1)
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, res.StatusCode)
+			assert.Equal(t, etag, res.Header.Get(fiber.HeaderETag))
+			assert.Equal(t, "Thu, 02 Jan 2025 03:04:05 GMT", res.Header.Get(fiber.HeaderLastModified))
+		})
+	}
+}
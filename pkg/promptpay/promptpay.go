@@ -0,0 +1,132 @@
+package promptpay
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidTarget PromptPay ID ไม่ใช่เบอร์มือถือ / เลขประจำตัว 13 หลัก / e-Wallet 15 หลัก
+var ErrInvalidTarget = errors.New("promptpay: invalid target id")
+
+// ErrInvalidAmount จำนวนเงินติดลบ
+var ErrInvalidAmount = errors.New("promptpay: invalid amount")
+
+// EMVCo tag ที่ใช้ใน PromptPay QR
+const (
+	tagPayloadFormat    = "00"
+	tagPointOfInit      = "01"
+	tagMerchantAccount  = "29"
+	tagCountry          = "58"
+	tagCurrency         = "53"
+	tagAmount           = "54"
+	tagCRC              = "63"
+	subTagAID           = "00"
+	subTagMobile        = "01"
+	subTagNationalID    = "02"
+	subTagEWallet       = "03"
+	promptPayAID        = "A000000677010111"
+	payloadFormatV1     = "01"
+	pointOfInitStatic   = "11" // ใช้ซ้ำได้ (ไม่มีจำนวนเงิน)
+	pointOfInitDynamic  = "12" // ใช้ครั้งเดียว (มีจำนวนเงิน)
+	countryTH           = "TH"
+	currencyTHB         = "764"
+	thaiCountryCode     = "66"
+	mobileFieldLength   = 13
+	nationalIDLength    = 13
+	eWalletIDLength     = 15
+	crcPlaceholderField = tagCRC + "04"
+)
+
+// Payload สร้าง EMVCo payload ของ PromptPay
+// target: เบอร์มือถือ (เช่น 081-234-5678), เลขประจำตัวประชาชน/ผู้เสียภาษี 13 หลัก หรือ e-Wallet ID 15 หลัก
+// amount: จำนวนเงิน (บาท) ถ้า <= 0 จะได้ QR แบบ static ให้ลูกค้ากรอกเอง
+func Payload(target string, amount float64) (string, error) {
+	if amount < 0 {
+		return "", ErrInvalidAmount
+	}
+
+	account, err := merchantAccount(target)
+	if err != nil {
+		return "", err
+	}
+
+	init := pointOfInitStatic
+	if amount > 0 {
+		init = pointOfInitDynamic
+	}
+
+	var b strings.Builder
+	b.WriteString(field(tagPayloadFormat, payloadFormatV1))
+	b.WriteString(field(tagPointOfInit, init))
+	b.WriteString(field(tagMerchantAccount, account))
+	b.WriteString(field(tagCountry, countryTH))
+	b.WriteString(field(tagCurrency, currencyTHB))
+	if amount > 0 {
+		b.WriteString(field(tagAmount, fmt.Sprintf("%.2f", amount)))
+	}
+
+	// CRC คำนวณรวม "6304" ด้วย
+	b.WriteString(crcPlaceholderField)
+	payload := b.String()
+	return payload + fmt.Sprintf("%04X", CRC16(payload)), nil
+}
+
+// merchantAccount สร้าง sub-field ของ tag 29 ตามชนิดของ target
+func merchantAccount(target string) (string, error) {
+	id := digitsOnly(target)
+
+	var sub string
+	switch {
+	case len(id) >= 9 && len(id) <= 10:
+		// เบอร์มือถือ: ตัด 0 นำหน้า ใส่รหัสประเทศ 66 แล้ว pad 0 ด้านหน้าให้ครบ 13 หลัก
+		mobile := thaiCountryCode + strings.TrimPrefix(id, "0")
+		sub = field(subTagMobile, leftPad(mobile, mobileFieldLength))
+	case len(id) == nationalIDLength:
+		sub = field(subTagNationalID, id)
+	case len(id) == eWalletIDLength:
+		sub = field(subTagEWallet, id)
+	default:
+		return "", ErrInvalidTarget
+	}
+
+	return field(subTagAID, promptPayAID) + sub, nil
+}
+
+// field เข้ารหัสแบบ TLV: tag(2) + length(2) + value
+func field(tag, value string) string {
+	return fmt.Sprintf("%s%02d%s", tag, len(value), value)
+}
+
+func digitsOnly(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func leftPad(s string, n int) string {
+	if len(s) >= n {
+		return s
+	}
+	return strings.Repeat("0", n-len(s)) + s
+}
+
+// CRC16 คำนวณ CRC-16/CCITT-FALSE (poly 0x1021, init 0xFFFF) ตามที่ EMVCo กำหนด
+func CRC16(s string) uint16 {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,70 @@
+package promptpay_test
+
+import (
+	"ans-spareparts-api/pkg/promptpay"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCRC16(t *testing.T) {
+	// check value ของ CRC-16/CCITT-FALSE
+	assert.Equal(t, uint16(0x29B1), promptpay.CRC16("123456789"))
+}
+
+func TestPayload(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		amount   float64
+		expected string
+		err      error
+	}{
+		// payload อ้างอิงที่เผยแพร่ใน promptpay-qr (github.com/dtinth/promptpay-qr)
+		{
+			name:     "Reference_Static_Mobile_Zero",
+			target:   "000-000-0000",
+			expected: "00020101021129370016A000000677010111011300660000000005802TH530376463048956",
+		},
+		{
+			name:     "Reference_Static_Mobile",
+			target:   "081-234-5678",
+			expected: "00020101021129370016A000000677010111011300668123456785802TH530376463045D82",
+		},
+		{
+			name:     "Dynamic_Mobile_With_Amount",
+			target:   "0812345678",
+			amount:   4.22,
+			expected: "00020101021229370016A000000677010111011300668123456785802TH530376454044.2263045D49",
+		},
+		{
+			name:     "Static_NationalID",
+			target:   "1-1111-11111-11-1",
+			expected: "00020101021129370016A000000677010111021311111111111115802TH530376463047B5A",
+		},
+		{
+			name:   "Error_Invalid_Target",
+			target: "1234",
+			err:    promptpay.ErrInvalidTarget,
+		},
+		{
+			name:   "Error_Negative_Amount",
+			target: "0812345678",
+			amount: -1,
+			err:    promptpay.ErrInvalidAmount,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			payload, err := promptpay.Payload(test.target, test.amount)
+
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, payload)
+		})
+	}
+}
@@ -0,0 +1,288 @@
+package query
+
+import (
+	"ans-spareparts-api/pkg/cursor"
+	"ans-spareparts-api/pkg/utils"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Type ชนิดค่าของ field ใช้แปลงค่าจาก query string
+type Type int
+
+const (
+	String Type = iota
+	Int
+	Float
+	Bool
+	Time
+)
+
+// Op ตัวดำเนินการของ filter: field[op]=value (ไม่ใส่ op = eq)
+type Op string
+
+const (
+	OpEq   Op = "eq"
+	OpNe   Op = "ne"
+	OpGt   Op = "gt"
+	OpGte  Op = "gte"
+	OpLt   Op = "lt"
+	OpLte  Op = "lte"
+	OpLike Op = "like"
+	OpIn   Op = "in" // ค่าคั่นด้วย comma
+)
+
+var opSQL = map[Op]string{
+	OpEq:   "=",
+	OpNe:   "<>",
+	OpGt:   ">",
+	OpGte:  ">=",
+	OpLt:   "<",
+	OpLte:  "<=",
+	OpLike: "ILIKE",
+	OpIn:   "IN",
+}
+
+// Comparable ตัวดำเนินการที่ใช้กับตัวเลข/เวลา
+var Comparable = []Op{OpEq, OpNe, OpGt, OpGte, OpLt, OpLte}
+
+// Field field ที่อนุญาตของ resource หนึ่ง; Column ต้องเป็นชื่อคอลัมน์จริงที่กำหนดในโค้ด
+type Field struct {
+	Column   string
+	Type     Type
+	Ops      []Op // ว่าง = กรองไม่ได้
+	Sortable bool
+}
+
+// Schema whitelist ของ field ที่กรอง/เรียงได้ ต่อ resource
+type Schema struct {
+	Fields      map[string]Field
+	DefaultSort []Sort
+}
+
+// Sort ใช้ type เดียวกับ cursor เพื่อส่ง sorts ต่อให้ cursor.Apply ได้ตรงๆ
+type Sort = cursor.Sort
+
+type Filter struct {
+	Column string
+	Op     Op
+	Value  any
+}
+
+// Params ผลการ parse query string ของ list endpoint
+type Params struct {
+	Limit   int
+	Offset  int
+	Search  string
+	Sort    []Sort // ว่าง = ผู้ใช้ไม่ได้ระบุ ใช้ Schema.SortOrDefault
+	Filters []Filter
+
+	// Keyset ส่ง ?cursor= มา (แม้ค่าว่าง); After เป็น nil คือหน้าแรก
+	Keyset bool
+	After  *cursor.Cursor
+}
+
+// Error parameter ที่ไม่ถูกต้อง ข้อความใช้ตอบ client ได้เลย
+type Error struct {
+	Param string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("invalid %s request", e.Param)
+}
+
+// ชื่อ parameter ที่ไม่ใช่ filter
+var reserved = map[string]bool{"limit": true, "offset": true, "sort": true, "search": true, "cursor": true}
+
+// SortOrDefault คืน DefaultSort เมื่อผู้ใช้ไม่ได้ระบุ sort
+func (s Schema) SortOrDefault(sorts []Sort) []Sort {
+	if len(sorts) == 0 {
+		return s.DefaultSort
+	}
+	return sorts
+}
+
+// FromCtx parse query string ของ request ตาม schema
+func FromCtx(c *fiber.Ctx, s Schema) (*Params, error) {
+	return Parse(c.Queries(), c.Context().QueryArgs().Has("cursor"), s)
+}
+
+// Parse แปลง query string เป็น Params; key ที่ไม่รู้จักและไม่มี [op] จะถูกข้าม
+func Parse(values map[string]string, keyset bool, s Schema) (*Params, error) {
+	p := &Params{Search: strings.TrimSpace(values["search"]), Keyset: keyset}
+
+	var err error
+	if p.Limit, err = parseInt(values, "limit", 10); err != nil {
+		return nil, err
+	}
+	if p.Offset, err = parseInt(values, "offset", 0); err != nil {
+		return nil, err
+	}
+	p.Limit, p.Offset = utils.NormalizePagination(p.Limit, p.Offset)
+
+	if p.Sort, err = parseSort(values["sort"], s); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys) // ลำดับ filter คงที่ (SQL เดิมทุกครั้ง)
+	for _, k := range keys {
+		if reserved[k] {
+			continue
+		}
+		f, ok, err := parseFilter(k, values[k], s)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			p.Filters = append(p.Filters, f)
+		}
+	}
+
+	if p.After, err = cursor.Decode(values["cursor"]); err != nil {
+		return nil, &Error{Param: "cursor"}
+	}
+	// cursor ต้องมาจากการเรียงแบบเดียวกัน (คอลัมน์และทิศทาง) ไม่งั้นค่าเทียบกันไม่ได้
+	if p.After != nil {
+		sorts := s.SortOrDefault(p.Sort)
+		if p.After.Key != cursor.Key(sorts) || len(p.After.Values) != len(sorts) {
+			return nil, &Error{Param: "cursor"}
+		}
+	}
+	return p, nil
+}
+
+func parseInt(values map[string]string, key string, def int) (int, error) {
+	v, ok := values[key]
+	if !ok || v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, &Error{Param: key}
+	}
+	return n, nil
+}
+
+// parseSort "-price,name" -> price DESC, name ASC
+func parseSort(raw string, s Schema) ([]Sort, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var out []Sort
+	seen := map[string]bool{}
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		desc := strings.HasPrefix(part, "-")
+		name := strings.TrimPrefix(strings.TrimPrefix(part, "-"), "+")
+		f, ok := s.Fields[name]
+		if !ok || !f.Sortable || seen[name] {
+			return nil, &Error{Param: "sort"}
+		}
+		seen[name] = true
+		out = append(out, Sort{Column: f.Column, Desc: desc})
+	}
+	return out, nil
+}
+
+// parseFilter "price[gte]" = "100"; ok=false คือ key ที่ไม่เกี่ยวกับ filter
+func parseFilter(key, raw string, s Schema) (Filter, bool, error) {
+	name, op := key, OpEq
+	if i := strings.IndexByte(key, '['); i > 0 && strings.HasSuffix(key, "]") {
+		name, op = key[:i], Op(key[i+1:len(key)-1])
+	} else if _, ok := s.Fields[key]; !ok {
+		return Filter{}, false, nil
+	}
+
+	f, ok := s.Fields[name]
+	if !ok || !slices.Contains(f.Ops, op) {
+		return Filter{}, false, &Error{Param: "filter " + key}
+	}
+
+	if op == OpIn {
+		parts := strings.Split(raw, ",")
+		vals := make([]any, 0, len(parts))
+		for _, part := range parts {
+			v, err := convert(strings.TrimSpace(part), f.Type)
+			if err != nil {
+				return Filter{}, false, &Error{Param: "filter " + key}
+			}
+			vals = append(vals, v)
+		}
+		return Filter{Column: f.Column, Op: op, Value: vals}, true, nil
+	}
+
+	v, err := convert(strings.TrimSpace(raw), f.Type)
+	if err != nil {
+		return Filter{}, false, &Error{Param: "filter " + key}
+	}
+	if op == OpLike {
+		// convert คืน string เฉพาะ field ชนิด String; schema ที่เปิด like ให้ชนิดอื่นถือว่าไม่ถูกต้อง
+		str, ok := v.(string)
+		if !ok {
+			return Filter{}, false, &Error{Param: "filter " + key}
+		}
+		v = "%" + EscapeLike(str) + "%"
+	}
+	return Filter{Column: f.Column, Op: op, Value: v}, true, nil
+}
+
+func convert(raw string, t Type) (any, error) {
+	switch t {
+	case Int:
+		return strconv.ParseInt(raw, 10, 64)
+	case Float:
+		return strconv.ParseFloat(raw, 64)
+	case Bool:
+		return strconv.ParseBool(raw)
+	case Time:
+		if d, err := time.Parse("2006-01-02", raw); err == nil {
+			return d, nil
+		}
+		return time.Parse(time.RFC3339, raw)
+	}
+	return raw, nil
+}
+
+// EscapeLike กัน % และ _ ที่ผู้ใช้พิมพ์มาไม่ให้กลายเป็น wildcard ของ LIKE/ILIKE
+func EscapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// Where เพิ่มเงื่อนไขของทุก filter
+func Where(tx *gorm.DB, filters []Filter) *gorm.DB {
+	for _, f := range filters {
+		if f.Op == OpIn {
+			tx = tx.Where(fmt.Sprintf("%s IN ?", f.Column), f.Value)
+			continue
+		}
+		tx = tx.Where(fmt.Sprintf("%s %s ?", f.Column, opSQL[f.Op]), f.Value)
+	}
+	return tx
+}
+
+// Order เรียงตาม sorts แล้วตามด้วย id เพื่อให้ลำดับคงที่ระหว่างหน้า
+func Order(tx *gorm.DB, sorts []Sort) *gorm.DB {
+	return OrderBy(tx, sorts, "id")
+}
+
+// OrderBy เหมือน Order แต่กำหนดคอลัมน์ id ที่ใช้ปิดท้ายเอง สำหรับ query ที่ join แล้ว "id" กำกวม
+func OrderBy(tx *gorm.DB, sorts []Sort, idColumn string) *gorm.DB {
+	for _, s := range sorts {
+		dir := "ASC"
+		if s.Desc {
+			dir = "DESC"
+		}
+		tx = tx.Order(fmt.Sprintf("%s %s", s.Column, dir))
+	}
+	return tx.Order(idColumn)
+}
@@ -0,0 +1,155 @@
+package query_test
+
+import (
+	"ans-spareparts-api/pkg/cursor"
+	"ans-spareparts-api/pkg/query"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var schema = query.Schema{
+	Fields: map[string]query.Field{
+		"id":          {Column: "id", Type: query.Int, Ops: []query.Op{query.OpEq, query.OpIn}, Sortable: true},
+		"name":        {Column: "name", Type: query.String, Ops: []query.Op{query.OpEq, query.OpLike}, Sortable: true},
+		"price":       {Column: "price", Type: query.Float, Ops: query.Comparable, Sortable: true},
+		"category_id": {Column: "category_id", Type: query.Int, Ops: []query.Op{query.OpEq, query.OpIn}},
+		"is_active":   {Column: "is_active", Type: query.Bool, Ops: []query.Op{query.OpEq}},
+		"created_at":  {Column: "created_at", Type: query.Time, Ops: query.Comparable, Sortable: true},
+		// schema ที่ตั้งผิด: like กับ field ตัวเลข ต้องตอบ error ไม่ panic
+		"code": {Column: "code", Type: query.Int, Ops: []query.Op{query.OpLike}},
+	},
+	DefaultSort: []query.Sort{{Column: "created_at", Desc: true}},
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   map[string]string
+		keyset   bool
+		wantErr  string
+		validate func(*testing.T, *query.Params)
+	}{
+		{
+			name:   "Defaults",
+			values: map[string]string{},
+			validate: func(t *testing.T, p *query.Params) {
+				assert.Equal(t, 10, p.Limit)
+				assert.Equal(t, 0, p.Offset)
+				assert.Nil(t, p.Sort)
+				assert.Nil(t, p.Filters)
+				assert.False(t, p.Keyset)
+			},
+		},
+		{
+			name: "Sort_Filters_And_Pagination",
+			values: map[string]string{
+				"limit": "500", "offset": "-1", "sort": "-price,name", "search": " brake ",
+				"price[gte]": "100", "category_id": "3", "is_active": "true",
+				"created_at[lt]": "2025-01-31", "id[in]": "1, 2", "name[like]": "50%", "format": "csv",
+			},
+			validate: func(t *testing.T, p *query.Params) {
+				assert.Equal(t, 100, p.Limit)
+				assert.Equal(t, 0, p.Offset)
+				assert.Equal(t, "brake", p.Search)
+				assert.Equal(t, []query.Sort{{Column: "price", Desc: true}, {Column: "name"}}, p.Sort)
+				assert.Equal(t, []query.Filter{
+					{Column: "category_id", Op: query.OpEq, Value: int64(3)},
+					{Column: "created_at", Op: query.OpLt, Value: time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)},
+					{Column: "id", Op: query.OpIn, Value: []any{int64(1), int64(2)}},
+					{Column: "is_active", Op: query.OpEq, Value: true},
+					{Column: "name", Op: query.OpLike, Value: `%50\%%`},
+					{Column: "price", Op: query.OpGte, Value: float64(100)},
+				}, p.Filters)
+			},
+		},
+		{
+			name:   "Keyset_Cursor_Matches_Sort",
+			values: map[string]string{"sort": "-price,name", "cursor": cursor.Encode(cursor.Cursor{Key: "-price,name", Values: []any{100, "Oil"}, ID: 2})},
+			keyset: true,
+			validate: func(t *testing.T, p *query.Params) {
+				assert.True(t, p.Keyset)
+				assert.Equal(t, uint(2), p.After.ID)
+			},
+		},
+		{name: "Error_Limit", values: map[string]string{"limit": "ten"}, wantErr: "invalid limit request"},
+		{name: "Error_Offset", values: map[string]string{"offset": "x"}, wantErr: "invalid offset request"},
+		{name: "Error_Sort_Raw_SQL", values: map[string]string{"sort": "name; DROP TABLE products"}, wantErr: "invalid sort request"},
+		{name: "Error_Sort_Not_Sortable", values: map[string]string{"sort": "is_active"}, wantErr: "invalid sort request"},
+		{name: "Error_Sort_Duplicate", values: map[string]string{"sort": "name,-name"}, wantErr: "invalid sort request"},
+		{name: "Error_Filter_Unknown_Field", values: map[string]string{"password[eq]": "x"}, wantErr: "invalid filter password[eq] request"},
+		{name: "Error_Filter_Op_Not_Allowed", values: map[string]string{"is_active[gt]": "true"}, wantErr: "invalid filter is_active[gt] request"},
+		{name: "Error_Filter_Bad_Value", values: map[string]string{"price[lte]": "cheap"}, wantErr: "invalid filter price[lte] request"},
+		{name: "Error_Filter_Like_Non_String", values: map[string]string{"code[like]": "12"}, wantErr: "invalid filter code[like] request"},
+		{name: "Error_Cursor", values: map[string]string{"cursor": "???"}, keyset: true, wantErr: "invalid cursor request"},
+		{
+			name:    "Error_Cursor_From_Other_Sort",
+			values:  map[string]string{"cursor": cursor.Encode(cursor.Cursor{Key: "name", Values: []any{"Oil"}, ID: 2})},
+			keyset:  true,
+			wantErr: "invalid cursor request",
+		},
+		{
+			name:    "Error_Cursor_From_Other_Direction",
+			values:  map[string]string{"sort": "-name", "cursor": cursor.Encode(cursor.Cursor{Key: "name", Values: []any{"Oil"}, ID: 2})},
+			keyset:  true,
+			wantErr: "invalid cursor request",
+		},
+		{
+			name:    "Error_Cursor_Values_Mismatch",
+			values:  map[string]string{"sort": "-price,name", "cursor": cursor.Encode(cursor.Cursor{Key: "-price,name", Values: []any{100}, ID: 2})},
+			keyset:  true,
+			wantErr: "invalid cursor request",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := query.Parse(test.values, test.keyset, schema)
+			if test.wantErr != "" {
+				var qe *query.Error
+				assert.ErrorAs(t, err, &qe)
+				assert.EqualError(t, err, test.wantErr)
+				assert.Nil(t, p)
+				return
+			}
+			require.NoError(t, err)
+			test.validate(t, p)
+		})
+	}
+}
+
+func TestWhereAndOrder(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+
+	var rows []map[string]any
+	tx := query.Where(db.Table("products"), []query.Filter{
+		{Column: "price", Op: query.OpGte, Value: float64(100)},
+		{Column: "name", Op: query.OpLike, Value: "%pad%"},
+		{Column: "id", Op: query.OpIn, Value: []any{int64(1), int64(2)}},
+	})
+	stmt := query.Order(tx, []query.Sort{{Column: "price", Desc: true}, {Column: "name"}}).Find(&rows).Statement
+
+	assert.Equal(t, `SELECT * FROM "products" WHERE price >= $1 AND name ILIKE $2 AND id IN ($3,$4) ORDER BY price DESC,name ASC,id`, stmt.SQL.String())
+	assert.Equal(t, []any{float64(100), "%pad%", int64(1), int64(2)}, stmt.Vars)
+}
+
+func TestOrderBy_Qualified_ID(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+
+	var rows []map[string]any
+	tx := db.Table("products").Joins("LEFT JOIN inventories i ON i.product_id = products.id")
+	stmt := query.OrderBy(tx, []query.Sort{{Column: "products.price", Desc: true}}, "products.id").Find(&rows).Statement
+
+	assert.Equal(t, `SELECT * FROM "products" LEFT JOIN inventories i ON i.product_id = products.id ORDER BY products.price DESC,products.id`, stmt.SQL.String())
+}
+
+func TestSortOrDefault(t *testing.T) {
+	assert.Equal(t, schema.DefaultSort, schema.SortOrDefault(nil))
+	assert.Equal(t, []query.Sort{{Column: "name"}}, schema.SortOrDefault([]query.Sort{{Column: "name"}}))
+}
@@ -48,3 +48,23 @@ func Page(c *fiber.Ctx, data any, total int64, limit, offset int) error {
 		},
 	})
 }
+
+// ใช้ตอบกลับแบบ cursor (keyset) pagination: next_cursor ว่างคือหน้าสุดท้าย
+func CursorPage(c *fiber.Ctx, data any, limit int, nextCursor string) error {
+	return CursorPageWith(c, data, limit, nextCursor, nil)
+}
+
+// CursorPageWith เหมือน CursorPage แต่เพิ่ม key ระดับบนสุด (เช่น facets)
+func CursorPageWith(c *fiber.Ctx, data any, limit int, nextCursor string, extra fiber.Map) error {
+	body := fiber.Map{
+		"data": data,
+		"meta": fiber.Map{
+			"limit":       limit,
+			"next_cursor": nextCursor,
+		},
+	}
+	for k, v := range extra {
+		body[k] = v
+	}
+	return c.Status(fiber.StatusOK).JSON(body)
+}